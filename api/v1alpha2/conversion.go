@@ -0,0 +1,484 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation holds the fields of an object which can't be represented in the
+// API version the object was converted to, so converting it back doesn't lose them.
+const ConversionDataAnnotation = "infrastructure.cluster.x-k8s.io/conversion-data"
+
+// ConvertTo converts this OpenStackCluster to the Hub version (v1alpha3).
+func (src *OpenStackCluster) ConvertTo(dstRaw conversion.Hub) error {
+	return clusterConversion.convertTo(withoutKeyPairs(src), dstRaw.(*infrav1.OpenStackCluster))
+}
+
+// withoutKeyPairs returns a copy of the cluster without the key pairs, which were removed in
+// v1alpha3 as kubeadm reads them from the cluster secrets. They are dropped instead of being kept
+// in the conversion data, so their private keys don't end up in an annotation.
+func withoutKeyPairs(src *OpenStackCluster) *OpenStackCluster {
+	dst := src.DeepCopy()
+	dst.Spec.CAKeyPair = KeyPair{}
+	dst.Spec.EtcdCAKeyPair = KeyPair{}
+	dst.Spec.FrontProxyCAKeyPair = KeyPair{}
+	dst.Spec.SAKeyPair = KeyPair{}
+	return dst
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackCluster.
+func (dst *OpenStackCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	return clusterConversion.convertFrom(srcRaw.(*infrav1.OpenStackCluster), dst)
+}
+
+var clusterConversion = versionConversion{
+	newSpoke: func() object { return &OpenStackCluster{} },
+	newHub:   func() object { return &infrav1.OpenStackCluster{} },
+	toHub: func(spoke, hub object) error {
+		return convertClusterToHub(spoke.(*OpenStackCluster), hub.(*infrav1.OpenStackCluster))
+	},
+	fromHub: func(hub, spoke object) error {
+		return convertClusterFromHub(hub.(*infrav1.OpenStackCluster), spoke.(*OpenStackCluster))
+	},
+}
+
+func convertClusterToHub(src *OpenStackCluster, dst *infrav1.OpenStackCluster) error {
+	if err := Convert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// The Cluster API v1alpha2 contract reports the first API endpoint in the status.
+	if len(src.Status.APIEndpoints) > 0 {
+		dst.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{
+			Host: src.Status.APIEndpoints[0].Host,
+			Port: src.Status.APIEndpoints[0].Port,
+		}
+	}
+	return nil
+}
+
+func convertClusterFromHub(src *infrav1.OpenStackCluster, dst *OpenStackCluster) error {
+	if err := Convert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster(src, dst, nil); err != nil {
+		return err
+	}
+
+	// The Cluster API v1alpha2 contract uses the first API endpoint in the status, so the endpoints
+	// keep their order, which lists the selected control plane machine first after a failover.
+	// Without endpoints in the status, the control plane endpoint is reported.
+	dst.Status.APIEndpoints = nil
+	for _, endpoint := range src.Status.APIEndpoints {
+		dst.Status.APIEndpoints = append(dst.Status.APIEndpoints, APIEndpoint{Host: endpoint.Host, Port: endpoint.Port})
+	}
+	if len(dst.Status.APIEndpoints) == 0 && !src.Spec.ControlPlaneEndpoint.IsZero() {
+		dst.Status.APIEndpoints = []APIEndpoint{{
+			Host: src.Spec.ControlPlaneEndpoint.Host,
+			Port: src.Spec.ControlPlaneEndpoint.Port,
+		}}
+	}
+	return nil
+}
+
+// ConvertTo converts this OpenStackClusterList to the Hub version (v1alpha3).
+func (src *OpenStackClusterList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.OpenStackClusterList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.OpenStackCluster, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackClusterList.
+func (dst *OpenStackClusterList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.OpenStackClusterList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]OpenStackCluster, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts this OpenStackMachine to the Hub version (v1alpha3).
+func (src *OpenStackMachine) ConvertTo(dstRaw conversion.Hub) error {
+	return machineConversion.convertTo(src, dstRaw.(*infrav1.OpenStackMachine))
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackMachine.
+func (dst *OpenStackMachine) ConvertFrom(srcRaw conversion.Hub) error {
+	return machineConversion.convertFrom(srcRaw.(*infrav1.OpenStackMachine), dst)
+}
+
+var machineConversion = versionConversion{
+	newSpoke: func() object { return &OpenStackMachine{} },
+	newHub:   func() object { return &infrav1.OpenStackMachine{} },
+	toHub: func(spoke, hub object) error {
+		return Convert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine(spoke.(*OpenStackMachine), hub.(*infrav1.OpenStackMachine), nil)
+	},
+	fromHub: func(hub, spoke object) error {
+		return Convert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine(hub.(*infrav1.OpenStackMachine), spoke.(*OpenStackMachine), nil)
+	},
+}
+
+// ConvertTo converts this OpenStackMachineList to the Hub version (v1alpha3).
+func (src *OpenStackMachineList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.OpenStackMachineList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.OpenStackMachine, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackMachineList.
+func (dst *OpenStackMachineList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.OpenStackMachineList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]OpenStackMachine, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts this OpenStackMachineTemplate to the Hub version (v1alpha3).
+func (src *OpenStackMachineTemplate) ConvertTo(dstRaw conversion.Hub) error {
+	return machineTemplateConversion.convertTo(src, dstRaw.(*infrav1.OpenStackMachineTemplate))
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackMachineTemplate.
+func (dst *OpenStackMachineTemplate) ConvertFrom(srcRaw conversion.Hub) error {
+	return machineTemplateConversion.convertFrom(srcRaw.(*infrav1.OpenStackMachineTemplate), dst)
+}
+
+var machineTemplateConversion = versionConversion{
+	newSpoke: func() object { return &OpenStackMachineTemplate{} },
+	newHub:   func() object { return &infrav1.OpenStackMachineTemplate{} },
+	toHub: func(spoke, hub object) error {
+		return Convert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate(spoke.(*OpenStackMachineTemplate), hub.(*infrav1.OpenStackMachineTemplate), nil)
+	},
+	fromHub: func(hub, spoke object) error {
+		return Convert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate(hub.(*infrav1.OpenStackMachineTemplate), spoke.(*OpenStackMachineTemplate), nil)
+	},
+}
+
+// ConvertTo converts this OpenStackMachineTemplateList to the Hub version (v1alpha3).
+func (src *OpenStackMachineTemplateList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.OpenStackMachineTemplateList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.OpenStackMachineTemplate, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackMachineTemplateList.
+func (dst *OpenStackMachineTemplateList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.OpenStackMachineTemplateList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]OpenStackMachineTemplate, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts this OpenStackClusterIdentity to the Hub version (v1alpha3).
+func (src *OpenStackClusterIdentity) ConvertTo(dstRaw conversion.Hub) error {
+	return clusterIdentityConversion.convertTo(src, dstRaw.(*infrav1.OpenStackClusterIdentity))
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackClusterIdentity.
+func (dst *OpenStackClusterIdentity) ConvertFrom(srcRaw conversion.Hub) error {
+	return clusterIdentityConversion.convertFrom(srcRaw.(*infrav1.OpenStackClusterIdentity), dst)
+}
+
+var clusterIdentityConversion = versionConversion{
+	newSpoke: func() object { return &OpenStackClusterIdentity{} },
+	newHub:   func() object { return &infrav1.OpenStackClusterIdentity{} },
+	toHub: func(spoke, hub object) error {
+		return Convert_v1alpha2_OpenStackClusterIdentity_To_v1alpha3_OpenStackClusterIdentity(spoke.(*OpenStackClusterIdentity), hub.(*infrav1.OpenStackClusterIdentity), nil)
+	},
+	fromHub: func(hub, spoke object) error {
+		return Convert_v1alpha3_OpenStackClusterIdentity_To_v1alpha2_OpenStackClusterIdentity(hub.(*infrav1.OpenStackClusterIdentity), spoke.(*OpenStackClusterIdentity), nil)
+	},
+}
+
+// ConvertTo converts this OpenStackClusterIdentityList to the Hub version (v1alpha3).
+func (src *OpenStackClusterIdentityList) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*infrav1.OpenStackClusterIdentityList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]infrav1.OpenStackClusterIdentity, len(src.Items))
+	for i := range src.Items {
+		if err := src.Items[i].ConvertTo(&dst.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha3) to this OpenStackClusterIdentityList.
+func (dst *OpenStackClusterIdentityList) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*infrav1.OpenStackClusterIdentityList)
+	dst.ListMeta = src.ListMeta
+	dst.Items = make([]OpenStackClusterIdentity, len(src.Items))
+	for i := range src.Items {
+		if err := dst.Items[i].ConvertFrom(&src.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec drops the CA key pairs and
+// DisableServerTags, which are preserved in the conversion data of the OpenStackCluster.
+func Convert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec(in *OpenStackClusterSpec, out *infrav1.OpenStackClusterSpec, s apiconversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec(in, out, s)
+}
+
+// Convert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec drops the control plane
+// endpoint, which is converted to the API endpoints of the OpenStackCluster status.
+func Convert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec(in *infrav1.OpenStackClusterSpec, out *OpenStackClusterSpec, s apiconversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec(in, out, s)
+}
+
+// Convert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus drops the API endpoints,
+// which are converted to the control plane endpoint of the OpenStackCluster spec.
+func Convert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus(in *OpenStackClusterStatus, out *infrav1.OpenStackClusterStatus, s apiconversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus(in, out, s)
+}
+
+// Convert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus drops the failure domains,
+// which are preserved in the conversion data of the OpenStackCluster.
+func Convert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus(in *infrav1.OpenStackClusterStatus, out *OpenStackClusterStatus, s apiconversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus(in, out, s)
+}
+
+// Convert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus converts the error reason
+// and message to the failure reason and message.
+func Convert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus(in *OpenStackMachineStatus, out *infrav1.OpenStackMachineStatus, s apiconversion.Scope) error {
+	if err := autoConvert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus(in, out, s); err != nil {
+		return err
+	}
+	out.FailureReason = in.ErrorReason
+	out.FailureMessage = in.ErrorMessage
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus converts the failure reason
+// and message to the error reason and message.
+func Convert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus(in *infrav1.OpenStackMachineStatus, out *OpenStackMachineStatus, s apiconversion.Scope) error {
+	if err := autoConvert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus(in, out, s); err != nil {
+		return err
+	}
+	out.ErrorReason = in.FailureReason
+	out.ErrorMessage = in.FailureMessage
+	return nil
+}
+
+// Convert_v1alpha3_Network_To_v1alpha2_Network drops the virtual IP of the APIServer,
+// which is preserved in the conversion data of the OpenStackCluster.
+func Convert_v1alpha3_Network_To_v1alpha2_Network(in *infrav1.Network, out *Network, s apiconversion.Scope) error {
+	return autoConvert_v1alpha3_Network_To_v1alpha2_Network(in, out, s)
+}
+
+// Convert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec drops the trusted image
+// certificates, which are preserved in the conversion data of the OpenStackMachine.
+func Convert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec(in *infrav1.OpenStackMachineSpec, out *OpenStackMachineSpec, s apiconversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec(in, out, s)
+}
+
+// Convert_v1alpha3_PortOpts_To_v1alpha2_PortOpts drops the device tag, which is preserved in the
+// conversion data of the OpenStackMachine.
+func Convert_v1alpha3_PortOpts_To_v1alpha2_PortOpts(in *infrav1.PortOpts, out *PortOpts, s apiconversion.Scope) error {
+	return autoConvert_v1alpha3_PortOpts_To_v1alpha2_PortOpts(in, out, s)
+}
+
+// object is an API object which can be converted.
+type object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// versionConversion converts an API type between this version and the hub version. The functions
+// convert the fields of both versions, the conversion data is handled by convert.
+type versionConversion struct {
+	newSpoke func() object
+	newHub   func() object
+	toHub    func(spoke, hub object) error
+	fromHub  func(hub, spoke object) error
+}
+
+func (c versionConversion) convertTo(src, dst object) error {
+	return convert(src, dst, c.toHub, c.fromHub, c.newSpoke, c.newHub)
+}
+
+func (c versionConversion) convertFrom(src, dst object) error {
+	return convert(src, dst, c.fromHub, c.toHub, c.newHub, c.newSpoke)
+}
+
+// convert converts src to dst with forward. The fields of dst which were lost when dst was converted
+// to the version of src are restored from the conversion data of src, and src is stored in the
+// conversion data of dst if dst can't represent all of its fields. backward converts back, newSrc and
+// newDst return empty objects of the versions of src and dst.
+func convert(src, dst object, forward, backward func(in, out object) error, newSrc, newDst func() object) error {
+	if err := forward(src, dst); err != nil {
+		return err
+	}
+
+	restored := newDst()
+	ok, err := getConversionData(src, restored)
+	if err != nil {
+		return err
+	}
+	if ok {
+		// The restored object converted to the version of src and back is what dst would be if src
+		// wasn't changed since, so the fields which differ from the restored object were lost.
+		intermediate, base := newSrc(), newDst()
+		if err := backward(restored, intermediate); err != nil {
+			return err
+		}
+		if err := forward(intermediate, base); err != nil {
+			return err
+		}
+		for _, name := range contentFields {
+			if field := reflect.ValueOf(dst).Elem().FieldByName(name); field.IsValid() {
+				restoreLostFields(field, reflect.ValueOf(base).Elem().FieldByName(name), reflect.ValueOf(restored).Elem().FieldByName(name))
+			}
+		}
+	}
+
+	back := newSrc()
+	if err := backward(dst, back); err != nil {
+		return err
+	}
+	for _, name := range contentFields {
+		if field := reflect.ValueOf(src).Elem().FieldByName(name); field.IsValid() {
+			if !equality.Semantic.DeepEqual(field.Interface(), reflect.ValueOf(back).Elem().FieldByName(name).Interface()) {
+				return setConversionData(dst, src)
+			}
+		}
+	}
+	return setConversionData(dst, nil)
+}
+
+// contentFields are the fields of the API objects which are converted, the object metadata is
+// copied as is.
+var contentFields = []string{"Spec", "Status"}
+
+// restoreLostFields sets current to restored where the conversion lost a field, i.e. where base
+// differs from restored. Fields which were changed since, i.e. where current differs from base,
+// are kept: structs, pointers and slices of the same length are descended into to restore the
+// fields they contain.
+func restoreLostFields(current, base, restored reflect.Value) {
+	if equality.Semantic.DeepEqual(base.Interface(), restored.Interface()) {
+		return
+	}
+	if equality.Semantic.DeepEqual(current.Interface(), base.Interface()) {
+		current.Set(restored)
+		return
+	}
+	switch current.Kind() {
+	case reflect.Struct:
+		for i := 0; i < current.NumField(); i++ {
+			// Structs with unexported fields, e.g. metav1.Time, are compared as a whole.
+			if current.Type().Field(i).PkgPath != "" {
+				return
+			}
+		}
+		for i := 0; i < current.NumField(); i++ {
+			restoreLostFields(current.Field(i), base.Field(i), restored.Field(i))
+		}
+	case reflect.Ptr:
+		if !current.IsNil() && !base.IsNil() && !restored.IsNil() {
+			restoreLostFields(current.Elem(), base.Elem(), restored.Elem())
+		}
+	case reflect.Slice:
+		if current.Len() == base.Len() && base.Len() == restored.Len() {
+			for i := 0; i < current.Len(); i++ {
+				restoreLostFields(current.Index(i), base.Index(i), restored.Index(i))
+			}
+		}
+	}
+}
+
+// getConversionData unmarshals the conversion data of obj into data. It returns false
+// if obj has no conversion data.
+func getConversionData(obj metav1.Object, data interface{}) (bool, error) {
+	value, ok := obj.GetAnnotations()[ConversionDataAnnotation]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(value), data); err != nil {
+		return false, fmt.Errorf("failed to unmarshal the %s annotation: %v", ConversionDataAnnotation, err)
+	}
+	return true, nil
+}
+
+// setConversionData sets the conversion data of obj to data without its metadata, or
+// removes it if data is nil. The annotations are copied, as the conversion functions
+// share them between the converted objects.
+func setConversionData(obj metav1.Object, data runtime.Object) error {
+	annotations := map[string]string{}
+	for k, v := range obj.GetAnnotations() {
+		if k != ConversionDataAnnotation {
+			annotations[k] = v
+		}
+	}
+	if data != nil {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(data)
+		if err != nil {
+			return err
+		}
+		delete(u, "metadata")
+		value, err := json.Marshal(u)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the %s annotation: %v", ConversionDataAnnotation, err)
+		}
+		annotations[ConversionDataAnnotation] = string(value)
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/equality"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/utils/pointer"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+func newTestOpenStackCluster() *OpenStackCluster {
+	return &OpenStackCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster",
+			Annotations: map[string]string{"owner": "team"},
+		},
+		Spec: OpenStackClusterSpec{
+			CloudsSecret:                    &corev1.SecretReference{Name: "cloud-config"},
+			CloudName:                       "openstack",
+			NodeCIDR:                        "10.6.0.0/24",
+			UseOctavia:                      true,
+			ManagedAPIServerLoadBalancer:    true,
+			APIServerLoadBalancerFloatingIP: "172.24.4.10",
+			APIServerLoadBalancerPort:       6443,
+			CAKeyPair:                       KeyPair{Cert: []byte("cert"), Key: []byte("key")},
+			DisableServerTags:               true,
+		},
+		Status: OpenStackClusterStatus{
+			Ready:        true,
+			APIEndpoints: []APIEndpoint{{Host: "172.24.4.10", Port: 6443}},
+			Network:      &Network{Name: "network", ID: "1"},
+		},
+	}
+}
+
+func TestOpenStackClusterConvertTo(t *testing.T) {
+	src := newTestOpenStackCluster()
+	hub := &infrav1.OpenStackCluster{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to v1alpha3: %v", err)
+	}
+
+	if hub.Spec.ControlPlaneEndpoint != (infrav1.APIEndpoint{Host: "172.24.4.10", Port: 6443}) {
+		t.Errorf("expected the API endpoint to be converted to the control plane endpoint, got %+v", hub.Spec.ControlPlaneEndpoint)
+	}
+	if !hub.Spec.UseOctavia || hub.Status.Network.ID != "1" {
+		t.Errorf("expected the common fields to be converted, got %+v", hub)
+	}
+	if hub.Annotations["owner"] != "team" || hub.Annotations[ConversionDataAnnotation] == "" {
+		t.Errorf("expected the annotations and disableServerTags to be preserved, got %v", hub.Annotations)
+	}
+	data := &OpenStackCluster{}
+	if _, err := getConversionData(hub, data); err != nil || data.Spec.CAKeyPair.HasCertAndKey() {
+		t.Errorf("expected the key pairs not to be kept in the conversion data, got %v: %v", hub.Annotations, err)
+	}
+	if len(src.Spec.CAKeyPair.Key) == 0 {
+		t.Errorf("expected the source key pairs not to be modified")
+	}
+	if src.Annotations[ConversionDataAnnotation] != "" {
+		t.Errorf("expected the source annotations not to be modified, got %v", src.Annotations)
+	}
+
+	restored := &OpenStackCluster{}
+	if err := restored.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from v1alpha3: %v", err)
+	}
+	delete(restored.Annotations, ConversionDataAnnotation)
+	expected := src.DeepCopy()
+	expected.Spec.CAKeyPair = KeyPair{}
+	if !reflect.DeepEqual(restored, expected) {
+		t.Errorf("expected the round-trip to only drop the key pairs,\nexpected %+v\ngot      %+v", expected, restored)
+	}
+}
+
+func TestOpenStackClusterConvertFrom(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   infrav1.OpenStackClusterStatus
+		expected []APIEndpoint
+	}{
+		{
+			name: "failed over control plane machine",
+			status: infrav1.OpenStackClusterStatus{
+				Ready:               true,
+				APIEndpoints:        []infrav1.APIEndpoint{{Host: "10.6.0.6", Port: 6443}, {Host: "10.6.0.5", Port: 6443}},
+				ControlPlaneMachine: "control-plane-1",
+				FailureDomains:      infrav1.FailureDomains{"nova": {ControlPlane: true}},
+			},
+			expected: []APIEndpoint{{Host: "10.6.0.6", Port: 6443}, {Host: "10.6.0.5", Port: 6443}},
+		},
+		{
+			name:     "without endpoints in the status",
+			status:   infrav1.OpenStackClusterStatus{Ready: true},
+			expected: []APIEndpoint{{Host: "10.6.0.5", Port: 6443}},
+		},
+	}
+	for _, tt := range tests {
+		hub := &infrav1.OpenStackCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec: infrav1.OpenStackClusterSpec{
+				NodeCIDR:             "10.6.0.0/24",
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Host: "10.6.0.5", Port: 6443},
+			},
+			Status: tt.status,
+		}
+		dst := &OpenStackCluster{}
+		if err := dst.ConvertFrom(hub); err != nil {
+			t.Fatalf("%s: failed to convert from v1alpha3: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(dst.Status.APIEndpoints, tt.expected) {
+			t.Errorf("%s: expected the API endpoints %+v, got %+v", tt.name, tt.expected, dst.Status.APIEndpoints)
+		}
+
+		restored := &infrav1.OpenStackCluster{}
+		if err := dst.ConvertTo(restored); err != nil {
+			t.Fatalf("%s: failed to convert to v1alpha3: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(restored, hub) {
+			t.Errorf("%s: expected the round-trip to be lossless,\nexpected %+v\ngot      %+v", tt.name, hub, restored)
+		}
+	}
+}
+
+func TestOpenStackMachineConversion(t *testing.T) {
+	reason := capierrors.UpdateMachineError
+	src := &OpenStackMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine"},
+		Spec: OpenStackMachineSpec{
+			ProviderID: pointer.StringPtr("openstack:////1"),
+			Flavor:     "m1.medium",
+			Image:      "ubuntu",
+			Networks:   []NetworkParam{{UUID: "1", Subports: []SubportParam{{UUID: "2", SegmentationID: 100}}}},
+		},
+		Status: OpenStackMachineStatus{
+			ErrorReason:  &reason,
+			ErrorMessage: pointer.StringPtr("failed"),
+		},
+	}
+	hub := &infrav1.OpenStackMachine{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to v1alpha3: %v", err)
+	}
+	if hub.Status.FailureReason == nil || *hub.Status.FailureReason != reason || *hub.Status.FailureMessage != "failed" {
+		t.Errorf("expected the error to be converted to the failure, got %+v", hub.Status)
+	}
+
+	restored := &OpenStackMachine{}
+	if err := restored.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from v1alpha3: %v", err)
+	}
+	if !reflect.DeepEqual(restored, src) {
+		t.Errorf("expected the round-trip to be lossless,\nexpected %+v\ngot      %+v", src, restored)
+	}
+
+	hub.Status.FloatingIP = "172.24.4.11"
+	hub.Status.InstanceID = "instance"
+	if err := restored.ConvertFrom(hub); err != nil {
+		t.Fatalf("failed to convert from v1alpha3: %v", err)
+	}
+	restoredHub := &infrav1.OpenStackMachine{}
+	if err := restored.ConvertTo(restoredHub); err != nil {
+		t.Fatalf("failed to convert to v1alpha3: %v", err)
+	}
+	if restoredHub.Status.FloatingIP != "172.24.4.11" {
+		t.Errorf("expected the allocated floating IP to be preserved, got %q", restoredHub.Status.FloatingIP)
+	}
+	if restoredHub.Status.InstanceID != "instance" {
+		t.Errorf("expected the instance ID to be preserved, got %q", restoredHub.Status.InstanceID)
+	}
+}
+
+func TestFuzzyConversion(t *testing.T) {
+	tests := []struct {
+		name  string
+		spoke func() object
+		hub   func() object
+	}{
+		{name: "OpenStackCluster", spoke: clusterConversion.newSpoke, hub: clusterConversion.newHub},
+		{name: "OpenStackMachine", spoke: machineConversion.newSpoke, hub: machineConversion.newHub},
+		{name: "OpenStackMachineTemplate", spoke: machineTemplateConversion.newSpoke, hub: machineTemplateConversion.newHub},
+		{name: "OpenStackClusterIdentity", spoke: clusterIdentityConversion.newSpoke, hub: clusterIdentityConversion.newHub},
+	}
+	fuzzer := fuzz.NewWithSeed(1).NilChance(0.2).Funcs(
+		// The type metadata is set by the scheme.
+		func(*metav1.TypeMeta, fuzz.Continue) {},
+		// The object metadata is copied as is, it only has to keep the conversion data.
+		func(meta *metav1.ObjectMeta, c fuzz.Continue) {
+			meta.Name = c.RandString()
+			meta.Annotations = map[string]string{c.RandString(): c.RandString()}
+		},
+		// The key pairs are dropped when converting to v1alpha3.
+		func(*KeyPair, fuzz.Continue) {},
+		// Times are marshalled with a precision of seconds.
+		func(t *metav1.Time, c fuzz.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+	)
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			spoke := tt.spoke()
+			fuzzer.Fuzz(spoke)
+			hub := tt.hub()
+			if err := spoke.(conversion.Convertible).ConvertTo(hub.(conversion.Hub)); err != nil {
+				t.Fatalf("%s: failed to convert to v1alpha3: %v", tt.name, err)
+			}
+			restoredSpoke := tt.spoke()
+			if err := restoredSpoke.(conversion.Convertible).ConvertFrom(hub.(conversion.Hub)); err != nil {
+				t.Fatalf("%s: failed to convert from v1alpha3: %v", tt.name, err)
+			}
+			if !equality.Semantic.DeepEqual(restoredSpoke, spoke) {
+				t.Fatalf("%s: expected the v1alpha2 round-trip to be lossless: %s", tt.name, diff.ObjectReflectDiff(spoke, restoredSpoke))
+			}
+
+			fuzzer.Fuzz(hub)
+			spoke = tt.spoke()
+			if err := spoke.(conversion.Convertible).ConvertFrom(hub.(conversion.Hub)); err != nil {
+				t.Fatalf("%s: failed to convert from v1alpha3: %v", tt.name, err)
+			}
+			restoredHub := tt.hub()
+			if err := spoke.(conversion.Convertible).ConvertTo(restoredHub.(conversion.Hub)); err != nil {
+				t.Fatalf("%s: failed to convert to v1alpha3: %v", tt.name, err)
+			}
+			if !equality.Semantic.DeepEqual(restoredHub, hub) {
+				t.Fatalf("%s: expected the v1alpha3 round-trip to be lossless: %s", tt.name, diff.ObjectReflectDiff(hub, restoredHub))
+			}
+		}
+	}
+}
@@ -14,4 +14,6 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package controllers
+// +k8s:conversion-gen=sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3
+
+package v1alpha2
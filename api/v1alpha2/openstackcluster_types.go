@@ -32,6 +32,11 @@ const (
 // OpenStackClusterSpec defines the desired state of OpenStackCluster
 type OpenStackClusterSpec struct {
 
+	// IdentityRef references the OpenStackClusterIdentity holding the openstack credentials.
+	// It takes precedence over CloudsSecret and CloudName.
+	// +optional
+	IdentityRef *OpenStackIdentityReference `json:"identityRef,omitempty"`
+
 	// The name of the secret containing the openstack credentials
 	// +optional
 	CloudsSecret *corev1.SecretReference `json:"cloudsSecret"`
@@ -89,7 +94,10 @@ type OpenStackClusterSpec struct {
 	// Tags for all resources in cluster
 	Tags []string `json:"tags,omitempty"`
 
-	// Default: True. In case of server tag errors, set to False
+	// DisableServerTags disables tagging servers.
+	// Deprecated: servers are only tagged when the compute API supports
+	// microversion 2.52, which is detected automatically. It's ignored and
+	// removed in v1alpha3.
 	DisableServerTags bool `json:"disableServerTags,omitempty"`
 
 	// CAKeyPair is the key pair for ca certs.
@@ -124,11 +132,19 @@ type OpenStackClusterStatus struct {
 	// GlobalSecurityGroup contains all the information about the OpenStack Security
 	// Group that needs to be applied to all nodes, both control plane and worker nodes.
 	GlobalSecurityGroup *SecurityGroup `json:"globalSecurityGroup,omitempty"`
+
+	// ApplicationCredentialExpiresAt is when the application credential used to manage
+	// the cluster expires. It is only set if the cluster uses an expiring application credential.
+	// +optional
+	ApplicationCredentialExpiresAt *metav1.Time `json:"applicationCredentialExpiresAt,omitempty"`
+
+	// Conditions describe the observed state of the credentials and resources.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=openstackclusters,scope=Namespaced
-// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 
 // OpenStackCluster is the Schema for the openstackclusters API
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha2-openstackcluster,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackclusters,versions=v1alpha2,name=validation.openstackcluster.infrastructure.cluster.x-k8s.io
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha2-openstackcluster,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackclusters,versions=v1alpha2,name=default.openstackcluster.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Defaulter = &OpenStackCluster{}
+var _ webhook.Validator = &OpenStackCluster{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of OpenStackClusters.
+func (r *OpenStackCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// Default sets the defaults of the OpenStackCluster.
+func (r *OpenStackCluster) Default() {
+	if r.Spec.ManagedAPIServerLoadBalancer && r.Spec.APIServerLoadBalancerPort == 0 {
+		r.Spec.APIServerLoadBalancerPort = infrav1.DefaultAPIServerLoadBalancerPort
+	}
+	if r.Spec.NodeCIDR != "" && len(r.Spec.DNSNameservers) == 0 && len(infrav1.DefaultDNSNameservers) > 0 {
+		r.Spec.DNSNameservers = append([]string{}, infrav1.DefaultDNSNameservers...)
+	}
+}
+
+// ValidateCreate validates the OpenStackCluster on creation like its v1alpha3 version.
+func (r *OpenStackCluster) ValidateCreate() error {
+	hub := &infrav1.OpenStackCluster{}
+	if err := r.ConvertTo(hub); err != nil {
+		return err
+	}
+	return hub.ValidateCreate()
+}
+
+// ValidateUpdate validates the OpenStackCluster on update like its v1alpha3 version.
+func (r *OpenStackCluster) ValidateUpdate(old runtime.Object) error {
+	hub, oldHub := &infrav1.OpenStackCluster{}, &infrav1.OpenStackCluster{}
+	if err := r.ConvertTo(hub); err != nil {
+		return err
+	}
+	if err := old.(*OpenStackCluster).ConvertTo(oldHub); err != nil {
+		return err
+	}
+	return hub.ValidateUpdate(oldHub)
+}
+
+// ValidateDelete allows deleting all OpenStackClusters.
+func (r *OpenStackCluster) ValidateDelete() error {
+	return nil
+}
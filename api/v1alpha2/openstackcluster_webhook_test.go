@@ -14,19 +14,19 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package phases
+package v1alpha2
 
 import (
-	"github.com/pkg/errors"
-	"k8s.io/klog"
-	"sigs.k8s.io/cluster-api/cmd/clusterctl/clusterdeployer/clusterclient"
+	"testing"
 )
 
-func ApplyBootstrapComponents(client clusterclient.Client, components string) error {
-	klog.Info("Applying Bootstrap-only Components")
-	if err := client.Apply(components); err != nil {
-		return errors.Wrap(err, "unable to apply bootstrap-only components")
+func TestOpenStackClusterValidateCreate(t *testing.T) {
+	cluster := newTestOpenStackCluster()
+	if err := cluster.ValidateCreate(); err != nil {
+		t.Errorf("expected the cluster to be valid, got %v", err)
+	}
+	cluster.Spec.NodeCIDR = "10.6.0.0"
+	if err := cluster.ValidateCreate(); err == nil {
+		t.Errorf("expected the node CIDR to be validated like in v1alpha3")
 	}
-
-	return nil
 }
@@ -36,11 +36,14 @@ type OpenStackMachineSpec struct {
 	// ProviderID is the unique identifier as specified by the cloud provider.
 	ProviderID *string `json:"providerID,omitempty"`
 
-	// The name of the secret containing the openstack credentials
+	// The name of the secret containing the openstack credentials.
+	// Deprecated: machines use the credentials of their OpenStackCluster. This is only
+	// used if the OpenStackCluster has no credentials configured.
 	// +optional
 	CloudsSecret *corev1.SecretReference `json:"cloudsSecret"`
 
-	// The name of the cloud to use from the clouds secret
+	// The name of the cloud to use from the clouds secret.
+	// Deprecated: machines use the credentials of their OpenStackCluster.
 	// +optional
 	CloudName string `json:"cloudName"`
 
@@ -57,6 +60,11 @@ type OpenStackMachineSpec struct {
 	// A networks object. Required parameter when there are multiple networks defined for the tenant.
 	// When you do not specify the networks parameter, the server attaches to the only network created for the current tenant.
 	Networks []NetworkParam `json:"networks,omitempty"`
+
+	// Ports to be attached to the server instance, in addition to the ports created for Networks.
+	// They allow to configure e.g. SR-IOV ports or allowed address pairs per port.
+	Ports []PortOpts `json:"ports,omitempty"`
+
 	// The floatingIP which will be associated to the machine, only used for master.
 	// The floatingIP should have been created and haven't been associated.
 	FloatingIP string `json:"floatingIP,omitempty"`
@@ -71,10 +79,12 @@ type OpenStackMachineSpec struct {
 	UserDataSecret *corev1.SecretReference `json:"userDataSecret,omitempty"`
 
 	// Whether the server instance is created on a trunk port or not.
+	// Subports of the trunks are configured per network.
 	Trunk bool `json:"trunk,omitempty"`
 
 	// Machine tags
-	// Requires Nova api 2.52 minimum!
+	// Servers are only tagged if the compute API supports microversion 2.52,
+	// other resources are always tagged.
 	Tags []string `json:"tags,omitempty"`
 
 	// Metadata mapping. Allows you to create a map of key value pairs to add to the server instance.
@@ -101,6 +111,14 @@ type OpenStackMachineStatus struct {
 	// +optional
 	InstanceState *InstanceState `json:"instanceState,omitempty"`
 
+	// Subports contains the trunk subports created for this machine.
+	// +optional
+	Subports []Subport `json:"subports,omitempty"`
+
+	// Conditions describe the observed state of the credentials and resources.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
 	ErrorReason *errors.MachineStatusError `json:"errorReason,omitempty"`
 
 	// ErrorMessage will be set in the event that there is a terminal problem
@@ -125,7 +143,6 @@ type OpenStackMachineStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=openstackmachines,scope=Namespaced
-// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 
 // OpenStackMachine is the Schema for the openstackmachines API
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha2-openstackmachine,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachines,versions=v1alpha2,name=validation.openstackmachine.infrastructure.cluster.x-k8s.io
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha2-openstackmachine,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachines,versions=v1alpha2,name=default.openstackmachine.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Defaulter = &OpenStackMachine{}
+var _ webhook.Validator = &OpenStackMachine{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of OpenStackMachines.
+func (r *OpenStackMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// Default sets the defaults of the OpenStackMachine.
+func (r *OpenStackMachine) Default() {
+	defaultOpenStackMachineSpec(&r.Spec)
+}
+
+func defaultOpenStackMachineSpec(spec *OpenStackMachineSpec) {
+	for i := range spec.Networks {
+		for j := range spec.Networks[i].Subports {
+			if spec.Networks[i].Subports[j].SegmentationType == "" {
+				spec.Networks[i].Subports[j].SegmentationType = infrav1.DefaultSegmentationType
+			}
+		}
+	}
+}
+
+// ValidateCreate validates the OpenStackMachine on creation like its v1alpha3 version.
+func (r *OpenStackMachine) ValidateCreate() error {
+	hub := &infrav1.OpenStackMachine{}
+	if err := r.ConvertTo(hub); err != nil {
+		return err
+	}
+	return hub.ValidateCreate()
+}
+
+// ValidateUpdate validates the OpenStackMachine on update like its v1alpha3 version.
+func (r *OpenStackMachine) ValidateUpdate(old runtime.Object) error {
+	hub, oldHub := &infrav1.OpenStackMachine{}, &infrav1.OpenStackMachine{}
+	if err := r.ConvertTo(hub); err != nil {
+		return err
+	}
+	if err := old.(*OpenStackMachine).ConvertTo(oldHub); err != nil {
+		return err
+	}
+	return hub.ValidateUpdate(oldHub)
+}
+
+// ValidateDelete allows deleting all OpenStackMachines.
+func (r *OpenStackMachine) ValidateDelete() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha2-openstackmachinetemplate,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachinetemplates,versions=v1alpha2,name=validation.openstackmachinetemplate.infrastructure.cluster.x-k8s.io
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha2-openstackmachinetemplate,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachinetemplates,versions=v1alpha2,name=default.openstackmachinetemplate.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Defaulter = &OpenStackMachineTemplate{}
+var _ webhook.Validator = &OpenStackMachineTemplate{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of OpenStackMachineTemplates.
+func (r *OpenStackMachineTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// Default sets the defaults of the OpenStackMachines created from the template.
+func (r *OpenStackMachineTemplate) Default() {
+	defaultOpenStackMachineSpec(&r.Spec.Template.Spec)
+}
+
+// ValidateCreate validates the OpenStackMachineTemplate on creation like its v1alpha3 version.
+func (r *OpenStackMachineTemplate) ValidateCreate() error {
+	hub := &infrav1.OpenStackMachineTemplate{}
+	if err := r.ConvertTo(hub); err != nil {
+		return err
+	}
+	return hub.ValidateCreate()
+}
+
+// ValidateUpdate validates the OpenStackMachineTemplate on update like its v1alpha3 version.
+func (r *OpenStackMachineTemplate) ValidateUpdate(old runtime.Object) error {
+	hub, oldHub := &infrav1.OpenStackMachineTemplate{}, &infrav1.OpenStackMachineTemplate{}
+	if err := r.ConvertTo(hub); err != nil {
+		return err
+	}
+	if err := old.(*OpenStackMachineTemplate).ConvertTo(oldHub); err != nil {
+		return err
+	}
+	return hub.ValidateUpdate(oldHub)
+}
+
+// ValidateDelete allows deleting all OpenStackMachineTemplates.
+func (r *OpenStackMachineTemplate) ValidateDelete() error {
+	return nil
+}
@@ -1,5 +1,10 @@
 package v1alpha2
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type ExternalRouterIPParam struct {
 	// The FixedIP in the corresponding subnet
 	FixedIP string `json:"fixedIP,omitempty"`
@@ -41,6 +46,22 @@ type NetworkParam struct {
 	Filter Filter `json:"filter,omitempty"`
 	// Subnet within a network to use
 	Subnets []SubnetParam `json:"subnets,omitempty"`
+	// Subports to attach to the trunk of the port on this network.
+	// Only used if trunk is enabled for the machine.
+	Subports []SubportParam `json:"subports,omitempty"`
+}
+
+// SubportParam selects the network of a subport, which is attached with its segmentation to the
+// trunk of the port of the machine on the network.
+type SubportParam struct {
+	// The UUID of the network the subport is created on.
+	UUID string `json:"uuid,omitempty"`
+	// Filters for optional network query
+	Filter Filter `json:"filter,omitempty"`
+	// SegmentationType is the segmentation type of the subport. Defaults to vlan.
+	SegmentationType string `json:"segmentationType,omitempty"`
+	// SegmentationID is the segmentation ID of the subport, e.g. the VLAN ID.
+	SegmentationID int `json:"segmentationID"`
 }
 
 type Filter struct {
@@ -62,6 +83,51 @@ type Filter struct {
 	NotTagsAny   string `json:"notTagsAny,omitempty"`
 }
 
+// PortOpts configures a port of the machine, which is created on the network before the server.
+type PortOpts struct {
+	// ID of the OpenStack network on which to create the port.
+	NetworkID string `json:"networkId"`
+	// Used to make the name of the port unique. If unspecified, instead the 0-based index of the port in the list is used.
+	NameSuffix string `json:"nameSuffix,omitempty"`
+	// Description of the port.
+	Description string `json:"description,omitempty"`
+	// MACAddress of the port. Only used on creation.
+	MACAddress string `json:"macAddress,omitempty"`
+	// Specify pairs of subnet and/or IP address. These should be subnets of the network with the given NetworkID.
+	// Only used on creation.
+	FixedIPs []FixedIP `json:"fixedIPs,omitempty"`
+	// The virtual network interface card (vNIC) type that is bound to the neutron port, e.g. normal, direct or macvtap.
+	// Only used on creation.
+	VNICType string `json:"vnicType,omitempty"`
+	// A dictionary that enables the application running on the specified
+	// host to pass and receive virtual network interface (VIF) port-specific
+	// information to the plug-in. Only used on creation.
+	Profile map[string]string `json:"profile,omitempty"`
+	// Enables or disables port security of the port. When disabled, no security groups are applied.
+	PortSecurity *bool `json:"portSecurity,omitempty"`
+	// AllowedAddressPairs are the IP/MAC address pairs the port accepts in addition to its own.
+	AllowedAddressPairs []AddressPair `json:"allowedAddressPairs,omitempty"`
+	// ID of the QoS policy applied to the port.
+	QoSPolicyID string `json:"qosPolicyId,omitempty"`
+}
+
+// FixedIP selects the subnet and optionally the IP address of a fixed IP of a port.
+type FixedIP struct {
+	// The ID of the subnet to get the IP address from.
+	SubnetID string `json:"subnetId"`
+	// The IP address to use. If unspecified, an address is allocated from the subnet.
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// AddressPair is an IP address, and optionally a MAC address, which a port accepts traffic for
+// in addition to its own fixed IPs and MAC address.
+type AddressPair struct {
+	// IPAddress is the IP address or CIDR of the address pair.
+	IPAddress string `json:"ipAddress"`
+	// MACAddress of the address pair. Defaults to the MAC address of the port.
+	MACAddress string `json:"macAddress,omitempty"`
+}
+
 type SubnetParam struct {
 	// The UUID of the network. Required if you omit the port attribute.
 	UUID string `json:"uuid,omitempty"`
@@ -149,6 +215,16 @@ type Router struct {
 	ID   string `json:"id"`
 }
 
+// Subport represents basic information about a trunk subport created for a machine
+type Subport struct {
+	PortID    string `json:"portID"`
+	NetworkID string `json:"networkID"`
+	TrunkID   string `json:"trunkID"`
+
+	SegmentationType string `json:"segmentationType"`
+	SegmentationID   int    `json:"segmentationID"`
+}
+
 // LoadBalancer represents basic information about the associated OpenStack LoadBalancer
 type LoadBalancer struct {
 	Name       string `json:"name"`
@@ -205,3 +281,58 @@ var (
 
 	InstanceStateShutoff = InstanceState("SHUTOFF")
 )
+
+// ConditionType is the type of a Condition.
+type ConditionType string
+
+const (
+	// AuthenticatedCondition reports whether the credentials in the clouds secret
+	// authenticate against the OpenStack cloud.
+	AuthenticatedCondition ConditionType = "Authenticated"
+)
+
+// Condition describes the state of an aspect of an OpenStack resource.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition changed from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a one-word CamelCase reason for the last transition of the condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable message with details about the last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GetCondition returns the condition of the given type, or nil if it isn't set.
+func GetCondition(conditions []Condition, conditionType ConditionType) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition. The LastTransitionTime is only
+// updated if the status changes.
+func SetCondition(conditions *[]Condition, condition Condition) {
+	existing := GetCondition(*conditions, condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, condition)
+		return
+	}
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+}
@@ -0,0 +1,1683 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by conversion-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	unsafe "unsafe"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	conversion "k8s.io/apimachinery/pkg/conversion"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	v1alpha3 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+)
+
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*APIEndpoint)(nil), (*v1alpha3.APIEndpoint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_APIEndpoint_To_v1alpha3_APIEndpoint(a.(*APIEndpoint), b.(*v1alpha3.APIEndpoint), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.APIEndpoint)(nil), (*APIEndpoint)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_APIEndpoint_To_v1alpha2_APIEndpoint(a.(*v1alpha3.APIEndpoint), b.(*APIEndpoint), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*AddressPair)(nil), (*v1alpha3.AddressPair)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_AddressPair_To_v1alpha3_AddressPair(a.(*AddressPair), b.(*v1alpha3.AddressPair), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.AddressPair)(nil), (*AddressPair)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_AddressPair_To_v1alpha2_AddressPair(a.(*v1alpha3.AddressPair), b.(*AddressPair), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*AllowedNamespaces)(nil), (*v1alpha3.AllowedNamespaces)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_AllowedNamespaces_To_v1alpha3_AllowedNamespaces(a.(*AllowedNamespaces), b.(*v1alpha3.AllowedNamespaces), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.AllowedNamespaces)(nil), (*AllowedNamespaces)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_AllowedNamespaces_To_v1alpha2_AllowedNamespaces(a.(*v1alpha3.AllowedNamespaces), b.(*AllowedNamespaces), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Condition)(nil), (*v1alpha3.Condition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Condition_To_v1alpha3_Condition(a.(*Condition), b.(*v1alpha3.Condition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.Condition)(nil), (*Condition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Condition_To_v1alpha2_Condition(a.(*v1alpha3.Condition), b.(*Condition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ExternalRouterIPParam)(nil), (*v1alpha3.ExternalRouterIPParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_ExternalRouterIPParam_To_v1alpha3_ExternalRouterIPParam(a.(*ExternalRouterIPParam), b.(*v1alpha3.ExternalRouterIPParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.ExternalRouterIPParam)(nil), (*ExternalRouterIPParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_ExternalRouterIPParam_To_v1alpha2_ExternalRouterIPParam(a.(*v1alpha3.ExternalRouterIPParam), b.(*ExternalRouterIPParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Filter)(nil), (*v1alpha3.Filter)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Filter_To_v1alpha3_Filter(a.(*Filter), b.(*v1alpha3.Filter), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.Filter)(nil), (*Filter)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Filter_To_v1alpha2_Filter(a.(*v1alpha3.Filter), b.(*Filter), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*FixedIP)(nil), (*v1alpha3.FixedIP)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_FixedIP_To_v1alpha3_FixedIP(a.(*FixedIP), b.(*v1alpha3.FixedIP), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.FixedIP)(nil), (*FixedIP)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_FixedIP_To_v1alpha2_FixedIP(a.(*v1alpha3.FixedIP), b.(*FixedIP), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*LoadBalancer)(nil), (*v1alpha3.LoadBalancer)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(a.(*LoadBalancer), b.(*v1alpha3.LoadBalancer), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.LoadBalancer)(nil), (*LoadBalancer)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_LoadBalancer_To_v1alpha2_LoadBalancer(a.(*v1alpha3.LoadBalancer), b.(*LoadBalancer), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Network)(nil), (*v1alpha3.Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Network_To_v1alpha3_Network(a.(*Network), b.(*v1alpha3.Network), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Network_To_v1alpha2_Network(a.(*v1alpha3.Network), b.(*Network), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*NetworkParam)(nil), (*v1alpha3.NetworkParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_NetworkParam_To_v1alpha3_NetworkParam(a.(*NetworkParam), b.(*v1alpha3.NetworkParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.NetworkParam)(nil), (*NetworkParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_NetworkParam_To_v1alpha2_NetworkParam(a.(*v1alpha3.NetworkParam), b.(*NetworkParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackCluster)(nil), (*v1alpha3.OpenStackCluster)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster(a.(*OpenStackCluster), b.(*v1alpha3.OpenStackCluster), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackCluster)(nil), (*OpenStackCluster)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster(a.(*v1alpha3.OpenStackCluster), b.(*OpenStackCluster), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackClusterIdentity)(nil), (*v1alpha3.OpenStackClusterIdentity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterIdentity_To_v1alpha3_OpenStackClusterIdentity(a.(*OpenStackClusterIdentity), b.(*v1alpha3.OpenStackClusterIdentity), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackClusterIdentity)(nil), (*OpenStackClusterIdentity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterIdentity_To_v1alpha2_OpenStackClusterIdentity(a.(*v1alpha3.OpenStackClusterIdentity), b.(*OpenStackClusterIdentity), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackClusterIdentityList)(nil), (*v1alpha3.OpenStackClusterIdentityList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterIdentityList_To_v1alpha3_OpenStackClusterIdentityList(a.(*OpenStackClusterIdentityList), b.(*v1alpha3.OpenStackClusterIdentityList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackClusterIdentityList)(nil), (*OpenStackClusterIdentityList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterIdentityList_To_v1alpha2_OpenStackClusterIdentityList(a.(*v1alpha3.OpenStackClusterIdentityList), b.(*OpenStackClusterIdentityList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackClusterIdentitySpec)(nil), (*v1alpha3.OpenStackClusterIdentitySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterIdentitySpec_To_v1alpha3_OpenStackClusterIdentitySpec(a.(*OpenStackClusterIdentitySpec), b.(*v1alpha3.OpenStackClusterIdentitySpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackClusterIdentitySpec)(nil), (*OpenStackClusterIdentitySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterIdentitySpec_To_v1alpha2_OpenStackClusterIdentitySpec(a.(*v1alpha3.OpenStackClusterIdentitySpec), b.(*OpenStackClusterIdentitySpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackClusterList)(nil), (*v1alpha3.OpenStackClusterList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterList_To_v1alpha3_OpenStackClusterList(a.(*OpenStackClusterList), b.(*v1alpha3.OpenStackClusterList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackClusterList)(nil), (*OpenStackClusterList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterList_To_v1alpha2_OpenStackClusterList(a.(*v1alpha3.OpenStackClusterList), b.(*OpenStackClusterList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackClusterSpec)(nil), (*v1alpha3.OpenStackClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec(a.(*OpenStackClusterSpec), b.(*v1alpha3.OpenStackClusterSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackClusterSpec)(nil), (*OpenStackClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec(a.(*v1alpha3.OpenStackClusterSpec), b.(*OpenStackClusterSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackClusterStatus)(nil), (*v1alpha3.OpenStackClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus(a.(*OpenStackClusterStatus), b.(*v1alpha3.OpenStackClusterStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackClusterStatus)(nil), (*OpenStackClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus(a.(*v1alpha3.OpenStackClusterStatus), b.(*OpenStackClusterStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackIdentityReference)(nil), (*v1alpha3.OpenStackIdentityReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackIdentityReference_To_v1alpha3_OpenStackIdentityReference(a.(*OpenStackIdentityReference), b.(*v1alpha3.OpenStackIdentityReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackIdentityReference)(nil), (*OpenStackIdentityReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackIdentityReference_To_v1alpha2_OpenStackIdentityReference(a.(*v1alpha3.OpenStackIdentityReference), b.(*OpenStackIdentityReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackIdentitySecretReference)(nil), (*v1alpha3.OpenStackIdentitySecretReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackIdentitySecretReference_To_v1alpha3_OpenStackIdentitySecretReference(a.(*OpenStackIdentitySecretReference), b.(*v1alpha3.OpenStackIdentitySecretReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackIdentitySecretReference)(nil), (*OpenStackIdentitySecretReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackIdentitySecretReference_To_v1alpha2_OpenStackIdentitySecretReference(a.(*v1alpha3.OpenStackIdentitySecretReference), b.(*OpenStackIdentitySecretReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachine)(nil), (*v1alpha3.OpenStackMachine)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine(a.(*OpenStackMachine), b.(*v1alpha3.OpenStackMachine), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachine)(nil), (*OpenStackMachine)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine(a.(*v1alpha3.OpenStackMachine), b.(*OpenStackMachine), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineList)(nil), (*v1alpha3.OpenStackMachineList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineList_To_v1alpha3_OpenStackMachineList(a.(*OpenStackMachineList), b.(*v1alpha3.OpenStackMachineList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachineList)(nil), (*OpenStackMachineList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineList_To_v1alpha2_OpenStackMachineList(a.(*v1alpha3.OpenStackMachineList), b.(*OpenStackMachineList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineSpec)(nil), (*v1alpha3.OpenStackMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec(a.(*OpenStackMachineSpec), b.(*v1alpha3.OpenStackMachineSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineStatus)(nil), (*v1alpha3.OpenStackMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus(a.(*OpenStackMachineStatus), b.(*v1alpha3.OpenStackMachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachineStatus)(nil), (*OpenStackMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus(a.(*v1alpha3.OpenStackMachineStatus), b.(*OpenStackMachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineTemplate)(nil), (*v1alpha3.OpenStackMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate(a.(*OpenStackMachineTemplate), b.(*v1alpha3.OpenStackMachineTemplate), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachineTemplate)(nil), (*OpenStackMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate(a.(*v1alpha3.OpenStackMachineTemplate), b.(*OpenStackMachineTemplate), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineTemplateList)(nil), (*v1alpha3.OpenStackMachineTemplateList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineTemplateList_To_v1alpha3_OpenStackMachineTemplateList(a.(*OpenStackMachineTemplateList), b.(*v1alpha3.OpenStackMachineTemplateList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachineTemplateList)(nil), (*OpenStackMachineTemplateList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineTemplateList_To_v1alpha2_OpenStackMachineTemplateList(a.(*v1alpha3.OpenStackMachineTemplateList), b.(*OpenStackMachineTemplateList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineTemplateResource)(nil), (*v1alpha3.OpenStackMachineTemplateResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineTemplateResource_To_v1alpha3_OpenStackMachineTemplateResource(a.(*OpenStackMachineTemplateResource), b.(*v1alpha3.OpenStackMachineTemplateResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachineTemplateResource)(nil), (*OpenStackMachineTemplateResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineTemplateResource_To_v1alpha2_OpenStackMachineTemplateResource(a.(*v1alpha3.OpenStackMachineTemplateResource), b.(*OpenStackMachineTemplateResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*OpenStackMachineTemplateSpec)(nil), (*v1alpha3.OpenStackMachineTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineTemplateSpec_To_v1alpha3_OpenStackMachineTemplateSpec(a.(*OpenStackMachineTemplateSpec), b.(*v1alpha3.OpenStackMachineTemplateSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.OpenStackMachineTemplateSpec)(nil), (*OpenStackMachineTemplateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineTemplateSpec_To_v1alpha2_OpenStackMachineTemplateSpec(a.(*v1alpha3.OpenStackMachineTemplateSpec), b.(*OpenStackMachineTemplateSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PortOpts)(nil), (*v1alpha3.PortOpts)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PortOpts_To_v1alpha3_PortOpts(a.(*PortOpts), b.(*v1alpha3.PortOpts), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RootVolume)(nil), (*v1alpha3.RootVolume)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_RootVolume_To_v1alpha3_RootVolume(a.(*RootVolume), b.(*v1alpha3.RootVolume), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.RootVolume)(nil), (*RootVolume)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_RootVolume_To_v1alpha2_RootVolume(a.(*v1alpha3.RootVolume), b.(*RootVolume), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Router)(nil), (*v1alpha3.Router)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Router_To_v1alpha3_Router(a.(*Router), b.(*v1alpha3.Router), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.Router)(nil), (*Router)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Router_To_v1alpha2_Router(a.(*v1alpha3.Router), b.(*Router), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecurityGroup)(nil), (*v1alpha3.SecurityGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(a.(*SecurityGroup), b.(*v1alpha3.SecurityGroup), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SecurityGroup)(nil), (*SecurityGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(a.(*v1alpha3.SecurityGroup), b.(*SecurityGroup), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecurityGroupFilter)(nil), (*v1alpha3.SecurityGroupFilter)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SecurityGroupFilter_To_v1alpha3_SecurityGroupFilter(a.(*SecurityGroupFilter), b.(*v1alpha3.SecurityGroupFilter), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SecurityGroupFilter)(nil), (*SecurityGroupFilter)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SecurityGroupFilter_To_v1alpha2_SecurityGroupFilter(a.(*v1alpha3.SecurityGroupFilter), b.(*SecurityGroupFilter), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecurityGroupParam)(nil), (*v1alpha3.SecurityGroupParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SecurityGroupParam_To_v1alpha3_SecurityGroupParam(a.(*SecurityGroupParam), b.(*v1alpha3.SecurityGroupParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SecurityGroupParam)(nil), (*SecurityGroupParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SecurityGroupParam_To_v1alpha2_SecurityGroupParam(a.(*v1alpha3.SecurityGroupParam), b.(*SecurityGroupParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SecurityGroupRule)(nil), (*v1alpha3.SecurityGroupRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SecurityGroupRule_To_v1alpha3_SecurityGroupRule(a.(*SecurityGroupRule), b.(*v1alpha3.SecurityGroupRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SecurityGroupRule)(nil), (*SecurityGroupRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SecurityGroupRule_To_v1alpha2_SecurityGroupRule(a.(*v1alpha3.SecurityGroupRule), b.(*SecurityGroupRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Subnet)(nil), (*v1alpha3.Subnet)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Subnet_To_v1alpha3_Subnet(a.(*Subnet), b.(*v1alpha3.Subnet), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.Subnet)(nil), (*Subnet)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Subnet_To_v1alpha2_Subnet(a.(*v1alpha3.Subnet), b.(*Subnet), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SubnetFilter)(nil), (*v1alpha3.SubnetFilter)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SubnetFilter_To_v1alpha3_SubnetFilter(a.(*SubnetFilter), b.(*v1alpha3.SubnetFilter), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SubnetFilter)(nil), (*SubnetFilter)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SubnetFilter_To_v1alpha2_SubnetFilter(a.(*v1alpha3.SubnetFilter), b.(*SubnetFilter), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SubnetParam)(nil), (*v1alpha3.SubnetParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SubnetParam_To_v1alpha3_SubnetParam(a.(*SubnetParam), b.(*v1alpha3.SubnetParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SubnetParam)(nil), (*SubnetParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SubnetParam_To_v1alpha2_SubnetParam(a.(*v1alpha3.SubnetParam), b.(*SubnetParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Subport)(nil), (*v1alpha3.Subport)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_Subport_To_v1alpha3_Subport(a.(*Subport), b.(*v1alpha3.Subport), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.Subport)(nil), (*Subport)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Subport_To_v1alpha2_Subport(a.(*v1alpha3.Subport), b.(*Subport), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*SubportParam)(nil), (*v1alpha3.SubportParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SubportParam_To_v1alpha3_SubportParam(a.(*SubportParam), b.(*v1alpha3.SubportParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha3.SubportParam)(nil), (*SubportParam)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_SubportParam_To_v1alpha2_SubportParam(a.(*v1alpha3.SubportParam), b.(*SubportParam), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*OpenStackClusterSpec)(nil), (*v1alpha3.OpenStackClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec(a.(*OpenStackClusterSpec), b.(*v1alpha3.OpenStackClusterSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*OpenStackClusterStatus)(nil), (*v1alpha3.OpenStackClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus(a.(*OpenStackClusterStatus), b.(*v1alpha3.OpenStackClusterStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*OpenStackMachineStatus)(nil), (*v1alpha3.OpenStackMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus(a.(*OpenStackMachineStatus), b.(*v1alpha3.OpenStackMachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.Network)(nil), (*Network)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_Network_To_v1alpha2_Network(a.(*v1alpha3.Network), b.(*Network), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.OpenStackClusterSpec)(nil), (*OpenStackClusterSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec(a.(*v1alpha3.OpenStackClusterSpec), b.(*OpenStackClusterSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.OpenStackClusterStatus)(nil), (*OpenStackClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus(a.(*v1alpha3.OpenStackClusterStatus), b.(*OpenStackClusterStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.OpenStackMachineSpec)(nil), (*OpenStackMachineSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec(a.(*v1alpha3.OpenStackMachineSpec), b.(*OpenStackMachineSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.OpenStackMachineStatus)(nil), (*OpenStackMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus(a.(*v1alpha3.OpenStackMachineStatus), b.(*OpenStackMachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha3.PortOpts)(nil), (*PortOpts)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha3_PortOpts_To_v1alpha2_PortOpts(a.(*v1alpha3.PortOpts), b.(*PortOpts), scope)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func autoConvert_v1alpha2_APIEndpoint_To_v1alpha3_APIEndpoint(in *APIEndpoint, out *v1alpha3.APIEndpoint, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Port = in.Port
+	return nil
+}
+
+// Convert_v1alpha2_APIEndpoint_To_v1alpha3_APIEndpoint is an autogenerated conversion function.
+func Convert_v1alpha2_APIEndpoint_To_v1alpha3_APIEndpoint(in *APIEndpoint, out *v1alpha3.APIEndpoint, s conversion.Scope) error {
+	return autoConvert_v1alpha2_APIEndpoint_To_v1alpha3_APIEndpoint(in, out, s)
+}
+
+func autoConvert_v1alpha3_APIEndpoint_To_v1alpha2_APIEndpoint(in *v1alpha3.APIEndpoint, out *APIEndpoint, s conversion.Scope) error {
+	out.Host = in.Host
+	out.Port = in.Port
+	return nil
+}
+
+// Convert_v1alpha3_APIEndpoint_To_v1alpha2_APIEndpoint is an autogenerated conversion function.
+func Convert_v1alpha3_APIEndpoint_To_v1alpha2_APIEndpoint(in *v1alpha3.APIEndpoint, out *APIEndpoint, s conversion.Scope) error {
+	return autoConvert_v1alpha3_APIEndpoint_To_v1alpha2_APIEndpoint(in, out, s)
+}
+
+func autoConvert_v1alpha2_AddressPair_To_v1alpha3_AddressPair(in *AddressPair, out *v1alpha3.AddressPair, s conversion.Scope) error {
+	out.IPAddress = in.IPAddress
+	out.MACAddress = in.MACAddress
+	return nil
+}
+
+// Convert_v1alpha2_AddressPair_To_v1alpha3_AddressPair is an autogenerated conversion function.
+func Convert_v1alpha2_AddressPair_To_v1alpha3_AddressPair(in *AddressPair, out *v1alpha3.AddressPair, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AddressPair_To_v1alpha3_AddressPair(in, out, s)
+}
+
+func autoConvert_v1alpha3_AddressPair_To_v1alpha2_AddressPair(in *v1alpha3.AddressPair, out *AddressPair, s conversion.Scope) error {
+	out.IPAddress = in.IPAddress
+	out.MACAddress = in.MACAddress
+	return nil
+}
+
+// Convert_v1alpha3_AddressPair_To_v1alpha2_AddressPair is an autogenerated conversion function.
+func Convert_v1alpha3_AddressPair_To_v1alpha2_AddressPair(in *v1alpha3.AddressPair, out *AddressPair, s conversion.Scope) error {
+	return autoConvert_v1alpha3_AddressPair_To_v1alpha2_AddressPair(in, out, s)
+}
+
+func autoConvert_v1alpha2_AllowedNamespaces_To_v1alpha3_AllowedNamespaces(in *AllowedNamespaces, out *v1alpha3.AllowedNamespaces, s conversion.Scope) error {
+	out.NamespaceList = *(*[]string)(unsafe.Pointer(&in.NamespaceList))
+	out.Selector = (*v1.LabelSelector)(unsafe.Pointer(in.Selector))
+	return nil
+}
+
+// Convert_v1alpha2_AllowedNamespaces_To_v1alpha3_AllowedNamespaces is an autogenerated conversion function.
+func Convert_v1alpha2_AllowedNamespaces_To_v1alpha3_AllowedNamespaces(in *AllowedNamespaces, out *v1alpha3.AllowedNamespaces, s conversion.Scope) error {
+	return autoConvert_v1alpha2_AllowedNamespaces_To_v1alpha3_AllowedNamespaces(in, out, s)
+}
+
+func autoConvert_v1alpha3_AllowedNamespaces_To_v1alpha2_AllowedNamespaces(in *v1alpha3.AllowedNamespaces, out *AllowedNamespaces, s conversion.Scope) error {
+	out.NamespaceList = *(*[]string)(unsafe.Pointer(&in.NamespaceList))
+	out.Selector = (*v1.LabelSelector)(unsafe.Pointer(in.Selector))
+	return nil
+}
+
+// Convert_v1alpha3_AllowedNamespaces_To_v1alpha2_AllowedNamespaces is an autogenerated conversion function.
+func Convert_v1alpha3_AllowedNamespaces_To_v1alpha2_AllowedNamespaces(in *v1alpha3.AllowedNamespaces, out *AllowedNamespaces, s conversion.Scope) error {
+	return autoConvert_v1alpha3_AllowedNamespaces_To_v1alpha2_AllowedNamespaces(in, out, s)
+}
+
+func autoConvert_v1alpha2_Condition_To_v1alpha3_Condition(in *Condition, out *v1alpha3.Condition, s conversion.Scope) error {
+	out.Type = v1alpha3.ConditionType(in.Type)
+	out.Status = corev1.ConditionStatus(in.Status)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha2_Condition_To_v1alpha3_Condition is an autogenerated conversion function.
+func Convert_v1alpha2_Condition_To_v1alpha3_Condition(in *Condition, out *v1alpha3.Condition, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Condition_To_v1alpha3_Condition(in, out, s)
+}
+
+func autoConvert_v1alpha3_Condition_To_v1alpha2_Condition(in *v1alpha3.Condition, out *Condition, s conversion.Scope) error {
+	out.Type = ConditionType(in.Type)
+	out.Status = corev1.ConditionStatus(in.Status)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha3_Condition_To_v1alpha2_Condition is an autogenerated conversion function.
+func Convert_v1alpha3_Condition_To_v1alpha2_Condition(in *v1alpha3.Condition, out *Condition, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Condition_To_v1alpha2_Condition(in, out, s)
+}
+
+func autoConvert_v1alpha2_ExternalRouterIPParam_To_v1alpha3_ExternalRouterIPParam(in *ExternalRouterIPParam, out *v1alpha3.ExternalRouterIPParam, s conversion.Scope) error {
+	out.FixedIP = in.FixedIP
+	if err := Convert_v1alpha2_SubnetParam_To_v1alpha3_SubnetParam(&in.Subnet, &out.Subnet, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_ExternalRouterIPParam_To_v1alpha3_ExternalRouterIPParam is an autogenerated conversion function.
+func Convert_v1alpha2_ExternalRouterIPParam_To_v1alpha3_ExternalRouterIPParam(in *ExternalRouterIPParam, out *v1alpha3.ExternalRouterIPParam, s conversion.Scope) error {
+	return autoConvert_v1alpha2_ExternalRouterIPParam_To_v1alpha3_ExternalRouterIPParam(in, out, s)
+}
+
+func autoConvert_v1alpha3_ExternalRouterIPParam_To_v1alpha2_ExternalRouterIPParam(in *v1alpha3.ExternalRouterIPParam, out *ExternalRouterIPParam, s conversion.Scope) error {
+	out.FixedIP = in.FixedIP
+	if err := Convert_v1alpha3_SubnetParam_To_v1alpha2_SubnetParam(&in.Subnet, &out.Subnet, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_ExternalRouterIPParam_To_v1alpha2_ExternalRouterIPParam is an autogenerated conversion function.
+func Convert_v1alpha3_ExternalRouterIPParam_To_v1alpha2_ExternalRouterIPParam(in *v1alpha3.ExternalRouterIPParam, out *ExternalRouterIPParam, s conversion.Scope) error {
+	return autoConvert_v1alpha3_ExternalRouterIPParam_To_v1alpha2_ExternalRouterIPParam(in, out, s)
+}
+
+func autoConvert_v1alpha2_Filter_To_v1alpha3_Filter(in *Filter, out *v1alpha3.Filter, s conversion.Scope) error {
+	out.Status = in.Status
+	out.Name = in.Name
+	out.Description = in.Description
+	out.AdminStateUp = (*bool)(unsafe.Pointer(in.AdminStateUp))
+	out.TenantID = in.TenantID
+	out.ProjectID = in.ProjectID
+	out.Shared = (*bool)(unsafe.Pointer(in.Shared))
+	out.ID = in.ID
+	out.Marker = in.Marker
+	out.Limit = in.Limit
+	out.SortKey = in.SortKey
+	out.SortDir = in.SortDir
+	out.Tags = in.Tags
+	out.TagsAny = in.TagsAny
+	out.NotTags = in.NotTags
+	out.NotTagsAny = in.NotTagsAny
+	return nil
+}
+
+// Convert_v1alpha2_Filter_To_v1alpha3_Filter is an autogenerated conversion function.
+func Convert_v1alpha2_Filter_To_v1alpha3_Filter(in *Filter, out *v1alpha3.Filter, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Filter_To_v1alpha3_Filter(in, out, s)
+}
+
+func autoConvert_v1alpha3_Filter_To_v1alpha2_Filter(in *v1alpha3.Filter, out *Filter, s conversion.Scope) error {
+	out.Status = in.Status
+	out.Name = in.Name
+	out.Description = in.Description
+	out.AdminStateUp = (*bool)(unsafe.Pointer(in.AdminStateUp))
+	out.TenantID = in.TenantID
+	out.ProjectID = in.ProjectID
+	out.Shared = (*bool)(unsafe.Pointer(in.Shared))
+	out.ID = in.ID
+	out.Marker = in.Marker
+	out.Limit = in.Limit
+	out.SortKey = in.SortKey
+	out.SortDir = in.SortDir
+	out.Tags = in.Tags
+	out.TagsAny = in.TagsAny
+	out.NotTags = in.NotTags
+	out.NotTagsAny = in.NotTagsAny
+	return nil
+}
+
+// Convert_v1alpha3_Filter_To_v1alpha2_Filter is an autogenerated conversion function.
+func Convert_v1alpha3_Filter_To_v1alpha2_Filter(in *v1alpha3.Filter, out *Filter, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Filter_To_v1alpha2_Filter(in, out, s)
+}
+
+func autoConvert_v1alpha2_FixedIP_To_v1alpha3_FixedIP(in *FixedIP, out *v1alpha3.FixedIP, s conversion.Scope) error {
+	out.SubnetID = in.SubnetID
+	out.IPAddress = in.IPAddress
+	return nil
+}
+
+// Convert_v1alpha2_FixedIP_To_v1alpha3_FixedIP is an autogenerated conversion function.
+func Convert_v1alpha2_FixedIP_To_v1alpha3_FixedIP(in *FixedIP, out *v1alpha3.FixedIP, s conversion.Scope) error {
+	return autoConvert_v1alpha2_FixedIP_To_v1alpha3_FixedIP(in, out, s)
+}
+
+func autoConvert_v1alpha3_FixedIP_To_v1alpha2_FixedIP(in *v1alpha3.FixedIP, out *FixedIP, s conversion.Scope) error {
+	out.SubnetID = in.SubnetID
+	out.IPAddress = in.IPAddress
+	return nil
+}
+
+// Convert_v1alpha3_FixedIP_To_v1alpha2_FixedIP is an autogenerated conversion function.
+func Convert_v1alpha3_FixedIP_To_v1alpha2_FixedIP(in *v1alpha3.FixedIP, out *FixedIP, s conversion.Scope) error {
+	return autoConvert_v1alpha3_FixedIP_To_v1alpha2_FixedIP(in, out, s)
+}
+
+func autoConvert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(in *LoadBalancer, out *v1alpha3.LoadBalancer, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.IP = in.IP
+	out.InternalIP = in.InternalIP
+	return nil
+}
+
+// Convert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer is an autogenerated conversion function.
+func Convert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(in *LoadBalancer, out *v1alpha3.LoadBalancer, s conversion.Scope) error {
+	return autoConvert_v1alpha2_LoadBalancer_To_v1alpha3_LoadBalancer(in, out, s)
+}
+
+func autoConvert_v1alpha3_LoadBalancer_To_v1alpha2_LoadBalancer(in *v1alpha3.LoadBalancer, out *LoadBalancer, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.IP = in.IP
+	out.InternalIP = in.InternalIP
+	return nil
+}
+
+// Convert_v1alpha3_LoadBalancer_To_v1alpha2_LoadBalancer is an autogenerated conversion function.
+func Convert_v1alpha3_LoadBalancer_To_v1alpha2_LoadBalancer(in *v1alpha3.LoadBalancer, out *LoadBalancer, s conversion.Scope) error {
+	return autoConvert_v1alpha3_LoadBalancer_To_v1alpha2_LoadBalancer(in, out, s)
+}
+
+func autoConvert_v1alpha2_Network_To_v1alpha3_Network(in *Network, out *v1alpha3.Network, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.Subnet = (*v1alpha3.Subnet)(unsafe.Pointer(in.Subnet))
+	out.Router = (*v1alpha3.Router)(unsafe.Pointer(in.Router))
+	out.APIServerLoadBalancer = (*v1alpha3.LoadBalancer)(unsafe.Pointer(in.APIServerLoadBalancer))
+	return nil
+}
+
+// Convert_v1alpha2_Network_To_v1alpha3_Network is an autogenerated conversion function.
+func Convert_v1alpha2_Network_To_v1alpha3_Network(in *Network, out *v1alpha3.Network, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Network_To_v1alpha3_Network(in, out, s)
+}
+
+func autoConvert_v1alpha3_Network_To_v1alpha2_Network(in *v1alpha3.Network, out *Network, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.Subnet = (*Subnet)(unsafe.Pointer(in.Subnet))
+	out.Router = (*Router)(unsafe.Pointer(in.Router))
+	out.APIServerLoadBalancer = (*LoadBalancer)(unsafe.Pointer(in.APIServerLoadBalancer))
+	// WARNING: in.APIServerVirtualIP requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha2_NetworkParam_To_v1alpha3_NetworkParam(in *NetworkParam, out *v1alpha3.NetworkParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	out.FixedIp = in.FixedIp
+	if err := Convert_v1alpha2_Filter_To_v1alpha3_Filter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	out.Subnets = *(*[]v1alpha3.SubnetParam)(unsafe.Pointer(&in.Subnets))
+	out.Subports = *(*[]v1alpha3.SubportParam)(unsafe.Pointer(&in.Subports))
+	return nil
+}
+
+// Convert_v1alpha2_NetworkParam_To_v1alpha3_NetworkParam is an autogenerated conversion function.
+func Convert_v1alpha2_NetworkParam_To_v1alpha3_NetworkParam(in *NetworkParam, out *v1alpha3.NetworkParam, s conversion.Scope) error {
+	return autoConvert_v1alpha2_NetworkParam_To_v1alpha3_NetworkParam(in, out, s)
+}
+
+func autoConvert_v1alpha3_NetworkParam_To_v1alpha2_NetworkParam(in *v1alpha3.NetworkParam, out *NetworkParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	out.FixedIp = in.FixedIp
+	if err := Convert_v1alpha3_Filter_To_v1alpha2_Filter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	out.Subnets = *(*[]SubnetParam)(unsafe.Pointer(&in.Subnets))
+	out.Subports = *(*[]SubportParam)(unsafe.Pointer(&in.Subports))
+	return nil
+}
+
+// Convert_v1alpha3_NetworkParam_To_v1alpha2_NetworkParam is an autogenerated conversion function.
+func Convert_v1alpha3_NetworkParam_To_v1alpha2_NetworkParam(in *v1alpha3.NetworkParam, out *NetworkParam, s conversion.Scope) error {
+	return autoConvert_v1alpha3_NetworkParam_To_v1alpha2_NetworkParam(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster(in *OpenStackCluster, out *v1alpha3.OpenStackCluster, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster(in *OpenStackCluster, out *v1alpha3.OpenStackCluster, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster(in *v1alpha3.OpenStackCluster, out *OpenStackCluster, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster(in *v1alpha3.OpenStackCluster, out *OpenStackCluster, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackClusterIdentity_To_v1alpha3_OpenStackClusterIdentity(in *OpenStackClusterIdentity, out *v1alpha3.OpenStackClusterIdentity, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha2_OpenStackClusterIdentitySpec_To_v1alpha3_OpenStackClusterIdentitySpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackClusterIdentity_To_v1alpha3_OpenStackClusterIdentity is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackClusterIdentity_To_v1alpha3_OpenStackClusterIdentity(in *OpenStackClusterIdentity, out *v1alpha3.OpenStackClusterIdentity, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackClusterIdentity_To_v1alpha3_OpenStackClusterIdentity(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackClusterIdentity_To_v1alpha2_OpenStackClusterIdentity(in *v1alpha3.OpenStackClusterIdentity, out *OpenStackClusterIdentity, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha3_OpenStackClusterIdentitySpec_To_v1alpha2_OpenStackClusterIdentitySpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackClusterIdentity_To_v1alpha2_OpenStackClusterIdentity is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackClusterIdentity_To_v1alpha2_OpenStackClusterIdentity(in *v1alpha3.OpenStackClusterIdentity, out *OpenStackClusterIdentity, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackClusterIdentity_To_v1alpha2_OpenStackClusterIdentity(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackClusterIdentityList_To_v1alpha3_OpenStackClusterIdentityList(in *OpenStackClusterIdentityList, out *v1alpha3.OpenStackClusterIdentityList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]v1alpha3.OpenStackClusterIdentity)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackClusterIdentityList_To_v1alpha3_OpenStackClusterIdentityList is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackClusterIdentityList_To_v1alpha3_OpenStackClusterIdentityList(in *OpenStackClusterIdentityList, out *v1alpha3.OpenStackClusterIdentityList, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackClusterIdentityList_To_v1alpha3_OpenStackClusterIdentityList(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackClusterIdentityList_To_v1alpha2_OpenStackClusterIdentityList(in *v1alpha3.OpenStackClusterIdentityList, out *OpenStackClusterIdentityList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]OpenStackClusterIdentity)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackClusterIdentityList_To_v1alpha2_OpenStackClusterIdentityList is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackClusterIdentityList_To_v1alpha2_OpenStackClusterIdentityList(in *v1alpha3.OpenStackClusterIdentityList, out *OpenStackClusterIdentityList, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackClusterIdentityList_To_v1alpha2_OpenStackClusterIdentityList(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackClusterIdentitySpec_To_v1alpha3_OpenStackClusterIdentitySpec(in *OpenStackClusterIdentitySpec, out *v1alpha3.OpenStackClusterIdentitySpec, s conversion.Scope) error {
+	if err := Convert_v1alpha2_OpenStackIdentitySecretReference_To_v1alpha3_OpenStackIdentitySecretReference(&in.SecretRef, &out.SecretRef, s); err != nil {
+		return err
+	}
+	out.CloudName = in.CloudName
+	out.AllowedNamespaces = (*v1alpha3.AllowedNamespaces)(unsafe.Pointer(in.AllowedNamespaces))
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackClusterIdentitySpec_To_v1alpha3_OpenStackClusterIdentitySpec is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackClusterIdentitySpec_To_v1alpha3_OpenStackClusterIdentitySpec(in *OpenStackClusterIdentitySpec, out *v1alpha3.OpenStackClusterIdentitySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackClusterIdentitySpec_To_v1alpha3_OpenStackClusterIdentitySpec(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackClusterIdentitySpec_To_v1alpha2_OpenStackClusterIdentitySpec(in *v1alpha3.OpenStackClusterIdentitySpec, out *OpenStackClusterIdentitySpec, s conversion.Scope) error {
+	if err := Convert_v1alpha3_OpenStackIdentitySecretReference_To_v1alpha2_OpenStackIdentitySecretReference(&in.SecretRef, &out.SecretRef, s); err != nil {
+		return err
+	}
+	out.CloudName = in.CloudName
+	out.AllowedNamespaces = (*AllowedNamespaces)(unsafe.Pointer(in.AllowedNamespaces))
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackClusterIdentitySpec_To_v1alpha2_OpenStackClusterIdentitySpec is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackClusterIdentitySpec_To_v1alpha2_OpenStackClusterIdentitySpec(in *v1alpha3.OpenStackClusterIdentitySpec, out *OpenStackClusterIdentitySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackClusterIdentitySpec_To_v1alpha2_OpenStackClusterIdentitySpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackClusterList_To_v1alpha3_OpenStackClusterList(in *OpenStackClusterList, out *v1alpha3.OpenStackClusterList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha3.OpenStackCluster, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_OpenStackCluster_To_v1alpha3_OpenStackCluster(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackClusterList_To_v1alpha3_OpenStackClusterList is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackClusterList_To_v1alpha3_OpenStackClusterList(in *OpenStackClusterList, out *v1alpha3.OpenStackClusterList, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackClusterList_To_v1alpha3_OpenStackClusterList(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackClusterList_To_v1alpha2_OpenStackClusterList(in *v1alpha3.OpenStackClusterList, out *OpenStackClusterList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpenStackCluster, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_OpenStackCluster_To_v1alpha2_OpenStackCluster(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackClusterList_To_v1alpha2_OpenStackClusterList is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackClusterList_To_v1alpha2_OpenStackClusterList(in *v1alpha3.OpenStackClusterList, out *OpenStackClusterList, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackClusterList_To_v1alpha2_OpenStackClusterList(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackClusterSpec_To_v1alpha3_OpenStackClusterSpec(in *OpenStackClusterSpec, out *v1alpha3.OpenStackClusterSpec, s conversion.Scope) error {
+	out.IdentityRef = (*v1alpha3.OpenStackIdentityReference)(unsafe.Pointer(in.IdentityRef))
+	out.CloudsSecret = (*corev1.SecretReference)(unsafe.Pointer(in.CloudsSecret))
+	out.CloudName = in.CloudName
+	out.NodeCIDR = in.NodeCIDR
+	out.DNSNameservers = *(*[]string)(unsafe.Pointer(&in.DNSNameservers))
+	out.ExternalRouterIPs = *(*[]v1alpha3.ExternalRouterIPParam)(unsafe.Pointer(&in.ExternalRouterIPs))
+	out.ExternalNetworkID = in.ExternalNetworkID
+	out.UseOctavia = in.UseOctavia
+	out.ManagedAPIServerLoadBalancer = in.ManagedAPIServerLoadBalancer
+	out.APIServerLoadBalancerFloatingIP = in.APIServerLoadBalancerFloatingIP
+	out.APIServerLoadBalancerPort = in.APIServerLoadBalancerPort
+	out.APIServerLoadBalancerAdditionalPorts = *(*[]int)(unsafe.Pointer(&in.APIServerLoadBalancerAdditionalPorts))
+	out.ManagedSecurityGroups = in.ManagedSecurityGroups
+	out.DisablePortSecurity = in.DisablePortSecurity
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	// WARNING: in.DisableServerTags requires manual conversion: does not exist in peer-type
+	// WARNING: in.CAKeyPair requires manual conversion: does not exist in peer-type
+	// WARNING: in.EtcdCAKeyPair requires manual conversion: does not exist in peer-type
+	// WARNING: in.FrontProxyCAKeyPair requires manual conversion: does not exist in peer-type
+	// WARNING: in.SAKeyPair requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha3_OpenStackClusterSpec_To_v1alpha2_OpenStackClusterSpec(in *v1alpha3.OpenStackClusterSpec, out *OpenStackClusterSpec, s conversion.Scope) error {
+	out.IdentityRef = (*OpenStackIdentityReference)(unsafe.Pointer(in.IdentityRef))
+	out.CloudsSecret = (*corev1.SecretReference)(unsafe.Pointer(in.CloudsSecret))
+	out.CloudName = in.CloudName
+	out.NodeCIDR = in.NodeCIDR
+	out.DNSNameservers = *(*[]string)(unsafe.Pointer(&in.DNSNameservers))
+	out.ExternalRouterIPs = *(*[]ExternalRouterIPParam)(unsafe.Pointer(&in.ExternalRouterIPs))
+	out.ExternalNetworkID = in.ExternalNetworkID
+	out.UseOctavia = in.UseOctavia
+	out.ManagedAPIServerLoadBalancer = in.ManagedAPIServerLoadBalancer
+	out.APIServerLoadBalancerFloatingIP = in.APIServerLoadBalancerFloatingIP
+	out.APIServerLoadBalancerPort = in.APIServerLoadBalancerPort
+	out.APIServerLoadBalancerAdditionalPorts = *(*[]int)(unsafe.Pointer(&in.APIServerLoadBalancerAdditionalPorts))
+	// WARNING: in.APIServerLoadBalancer requires manual conversion: does not exist in peer-type
+	// WARNING: in.DisableAPIServerFloatingIP requires manual conversion: does not exist in peer-type
+	// WARNING: in.APIServerVirtualIP requires manual conversion: does not exist in peer-type
+	out.ManagedSecurityGroups = in.ManagedSecurityGroups
+	out.DisablePortSecurity = in.DisablePortSecurity
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	// WARNING: in.ControlPlaneEndpoint requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha2_OpenStackClusterStatus_To_v1alpha3_OpenStackClusterStatus(in *OpenStackClusterStatus, out *v1alpha3.OpenStackClusterStatus, s conversion.Scope) error {
+	out.Ready = in.Ready
+	out.APIEndpoints = *(*[]v1alpha3.APIEndpoint)(unsafe.Pointer(&in.APIEndpoints))
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(v1alpha3.Network)
+		if err := Convert_v1alpha2_Network_To_v1alpha3_Network(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Network = nil
+	}
+	out.ControlPlaneSecurityGroup = (*v1alpha3.SecurityGroup)(unsafe.Pointer(in.ControlPlaneSecurityGroup))
+	out.GlobalSecurityGroup = (*v1alpha3.SecurityGroup)(unsafe.Pointer(in.GlobalSecurityGroup))
+	out.ApplicationCredentialExpiresAt = (*v1.Time)(unsafe.Pointer(in.ApplicationCredentialExpiresAt))
+	out.Conditions = *(*[]v1alpha3.Condition)(unsafe.Pointer(&in.Conditions))
+	return nil
+}
+
+func autoConvert_v1alpha3_OpenStackClusterStatus_To_v1alpha2_OpenStackClusterStatus(in *v1alpha3.OpenStackClusterStatus, out *OpenStackClusterStatus, s conversion.Scope) error {
+	out.Ready = in.Ready
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(Network)
+		if err := Convert_v1alpha3_Network_To_v1alpha2_Network(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Network = nil
+	}
+	out.ControlPlaneSecurityGroup = (*SecurityGroup)(unsafe.Pointer(in.ControlPlaneSecurityGroup))
+	out.GlobalSecurityGroup = (*SecurityGroup)(unsafe.Pointer(in.GlobalSecurityGroup))
+	out.APIEndpoints = *(*[]APIEndpoint)(unsafe.Pointer(&in.APIEndpoints))
+	// WARNING: in.ControlPlaneMachine requires manual conversion: does not exist in peer-type
+	out.ApplicationCredentialExpiresAt = (*v1.Time)(unsafe.Pointer(in.ApplicationCredentialExpiresAt))
+	// WARNING: in.FailureDomains requires manual conversion: does not exist in peer-type
+	out.Conditions = *(*[]Condition)(unsafe.Pointer(&in.Conditions))
+	return nil
+}
+
+func autoConvert_v1alpha2_OpenStackIdentityReference_To_v1alpha3_OpenStackIdentityReference(in *OpenStackIdentityReference, out *v1alpha3.OpenStackIdentityReference, s conversion.Scope) error {
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackIdentityReference_To_v1alpha3_OpenStackIdentityReference is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackIdentityReference_To_v1alpha3_OpenStackIdentityReference(in *OpenStackIdentityReference, out *v1alpha3.OpenStackIdentityReference, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackIdentityReference_To_v1alpha3_OpenStackIdentityReference(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackIdentityReference_To_v1alpha2_OpenStackIdentityReference(in *v1alpha3.OpenStackIdentityReference, out *OpenStackIdentityReference, s conversion.Scope) error {
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackIdentityReference_To_v1alpha2_OpenStackIdentityReference is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackIdentityReference_To_v1alpha2_OpenStackIdentityReference(in *v1alpha3.OpenStackIdentityReference, out *OpenStackIdentityReference, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackIdentityReference_To_v1alpha2_OpenStackIdentityReference(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackIdentitySecretReference_To_v1alpha3_OpenStackIdentitySecretReference(in *OpenStackIdentitySecretReference, out *v1alpha3.OpenStackIdentitySecretReference, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackIdentitySecretReference_To_v1alpha3_OpenStackIdentitySecretReference is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackIdentitySecretReference_To_v1alpha3_OpenStackIdentitySecretReference(in *OpenStackIdentitySecretReference, out *v1alpha3.OpenStackIdentitySecretReference, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackIdentitySecretReference_To_v1alpha3_OpenStackIdentitySecretReference(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackIdentitySecretReference_To_v1alpha2_OpenStackIdentitySecretReference(in *v1alpha3.OpenStackIdentitySecretReference, out *OpenStackIdentitySecretReference, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackIdentitySecretReference_To_v1alpha2_OpenStackIdentitySecretReference is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackIdentitySecretReference_To_v1alpha2_OpenStackIdentitySecretReference(in *v1alpha3.OpenStackIdentitySecretReference, out *OpenStackIdentitySecretReference, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackIdentitySecretReference_To_v1alpha2_OpenStackIdentitySecretReference(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine(in *OpenStackMachine, out *v1alpha3.OpenStackMachine, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine(in *OpenStackMachine, out *v1alpha3.OpenStackMachine, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine(in *v1alpha3.OpenStackMachine, out *OpenStackMachine, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine(in *v1alpha3.OpenStackMachine, out *OpenStackMachine, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackMachineList_To_v1alpha3_OpenStackMachineList(in *OpenStackMachineList, out *v1alpha3.OpenStackMachineList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha3.OpenStackMachine, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_OpenStackMachine_To_v1alpha3_OpenStackMachine(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachineList_To_v1alpha3_OpenStackMachineList is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachineList_To_v1alpha3_OpenStackMachineList(in *OpenStackMachineList, out *v1alpha3.OpenStackMachineList, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachineList_To_v1alpha3_OpenStackMachineList(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachineList_To_v1alpha2_OpenStackMachineList(in *v1alpha3.OpenStackMachineList, out *OpenStackMachineList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpenStackMachine, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_OpenStackMachine_To_v1alpha2_OpenStackMachine(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachineList_To_v1alpha2_OpenStackMachineList is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackMachineList_To_v1alpha2_OpenStackMachineList(in *v1alpha3.OpenStackMachineList, out *OpenStackMachineList, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachineList_To_v1alpha2_OpenStackMachineList(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec(in *OpenStackMachineSpec, out *v1alpha3.OpenStackMachineSpec, s conversion.Scope) error {
+	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
+	out.CloudsSecret = (*corev1.SecretReference)(unsafe.Pointer(in.CloudsSecret))
+	out.CloudName = in.CloudName
+	out.Flavor = in.Flavor
+	out.Image = in.Image
+	out.KeyName = in.KeyName
+	out.Networks = *(*[]v1alpha3.NetworkParam)(unsafe.Pointer(&in.Networks))
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]v1alpha3.PortOpts, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_PortOpts_To_v1alpha3_PortOpts(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ports = nil
+	}
+	out.FloatingIP = in.FloatingIP
+	out.AvailabilityZone = in.AvailabilityZone
+	out.SecurityGroups = *(*[]v1alpha3.SecurityGroupParam)(unsafe.Pointer(&in.SecurityGroups))
+	out.UserDataSecret = (*corev1.SecretReference)(unsafe.Pointer(in.UserDataSecret))
+	out.Trunk = in.Trunk
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	out.ServerMetadata = *(*map[string]string)(unsafe.Pointer(&in.ServerMetadata))
+	out.ConfigDrive = (*bool)(unsafe.Pointer(in.ConfigDrive))
+	out.RootVolume = (*v1alpha3.RootVolume)(unsafe.Pointer(in.RootVolume))
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec(in *OpenStackMachineSpec, out *v1alpha3.OpenStackMachineSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec(in *v1alpha3.OpenStackMachineSpec, out *OpenStackMachineSpec, s conversion.Scope) error {
+	out.ProviderID = (*string)(unsafe.Pointer(in.ProviderID))
+	out.CloudsSecret = (*corev1.SecretReference)(unsafe.Pointer(in.CloudsSecret))
+	out.CloudName = in.CloudName
+	out.Flavor = in.Flavor
+	out.Image = in.Image
+	out.KeyName = in.KeyName
+	out.Networks = *(*[]NetworkParam)(unsafe.Pointer(&in.Networks))
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]PortOpts, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_PortOpts_To_v1alpha2_PortOpts(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ports = nil
+	}
+	out.FloatingIP = in.FloatingIP
+	out.AvailabilityZone = in.AvailabilityZone
+	out.SecurityGroups = *(*[]SecurityGroupParam)(unsafe.Pointer(&in.SecurityGroups))
+	out.UserDataSecret = (*corev1.SecretReference)(unsafe.Pointer(in.UserDataSecret))
+	out.Trunk = in.Trunk
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	out.ServerMetadata = *(*map[string]string)(unsafe.Pointer(&in.ServerMetadata))
+	out.ConfigDrive = (*bool)(unsafe.Pointer(in.ConfigDrive))
+	out.RootVolume = (*RootVolume)(unsafe.Pointer(in.RootVolume))
+	// WARNING: in.TrustedImageCertificates requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha2_OpenStackMachineStatus_To_v1alpha3_OpenStackMachineStatus(in *OpenStackMachineStatus, out *v1alpha3.OpenStackMachineStatus, s conversion.Scope) error {
+	out.Ready = in.Ready
+	out.Addresses = *(*[]corev1.NodeAddress)(unsafe.Pointer(&in.Addresses))
+	out.InstanceState = (*v1alpha3.InstanceState)(unsafe.Pointer(in.InstanceState))
+	out.Subports = *(*[]v1alpha3.Subport)(unsafe.Pointer(&in.Subports))
+	out.Conditions = *(*[]v1alpha3.Condition)(unsafe.Pointer(&in.Conditions))
+	// WARNING: in.ErrorReason requires manual conversion: does not exist in peer-type
+	// WARNING: in.ErrorMessage requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha3_OpenStackMachineStatus_To_v1alpha2_OpenStackMachineStatus(in *v1alpha3.OpenStackMachineStatus, out *OpenStackMachineStatus, s conversion.Scope) error {
+	out.Ready = in.Ready
+	out.Addresses = *(*[]corev1.NodeAddress)(unsafe.Pointer(&in.Addresses))
+	// WARNING: in.InstanceID requires manual conversion: does not exist in peer-type
+	out.InstanceState = (*InstanceState)(unsafe.Pointer(in.InstanceState))
+	// WARNING: in.FloatingIP requires manual conversion: does not exist in peer-type
+	out.Subports = *(*[]Subport)(unsafe.Pointer(&in.Subports))
+	out.Conditions = *(*[]Condition)(unsafe.Pointer(&in.Conditions))
+	// WARNING: in.FailureReason requires manual conversion: does not exist in peer-type
+	// WARNING: in.FailureMessage requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate(in *OpenStackMachineTemplate, out *v1alpha3.OpenStackMachineTemplate, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha2_OpenStackMachineTemplateSpec_To_v1alpha3_OpenStackMachineTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate(in *OpenStackMachineTemplate, out *v1alpha3.OpenStackMachineTemplate, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate(in *v1alpha3.OpenStackMachineTemplate, out *OpenStackMachineTemplate, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha3_OpenStackMachineTemplateSpec_To_v1alpha2_OpenStackMachineTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate(in *v1alpha3.OpenStackMachineTemplate, out *OpenStackMachineTemplate, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackMachineTemplateList_To_v1alpha3_OpenStackMachineTemplateList(in *OpenStackMachineTemplateList, out *v1alpha3.OpenStackMachineTemplateList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha3.OpenStackMachineTemplate, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_OpenStackMachineTemplate_To_v1alpha3_OpenStackMachineTemplate(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachineTemplateList_To_v1alpha3_OpenStackMachineTemplateList is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachineTemplateList_To_v1alpha3_OpenStackMachineTemplateList(in *OpenStackMachineTemplateList, out *v1alpha3.OpenStackMachineTemplateList, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachineTemplateList_To_v1alpha3_OpenStackMachineTemplateList(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachineTemplateList_To_v1alpha2_OpenStackMachineTemplateList(in *v1alpha3.OpenStackMachineTemplateList, out *OpenStackMachineTemplateList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpenStackMachineTemplate, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha3_OpenStackMachineTemplate_To_v1alpha2_OpenStackMachineTemplate(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachineTemplateList_To_v1alpha2_OpenStackMachineTemplateList is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackMachineTemplateList_To_v1alpha2_OpenStackMachineTemplateList(in *v1alpha3.OpenStackMachineTemplateList, out *OpenStackMachineTemplateList, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachineTemplateList_To_v1alpha2_OpenStackMachineTemplateList(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackMachineTemplateResource_To_v1alpha3_OpenStackMachineTemplateResource(in *OpenStackMachineTemplateResource, out *v1alpha3.OpenStackMachineTemplateResource, s conversion.Scope) error {
+	if err := Convert_v1alpha2_OpenStackMachineSpec_To_v1alpha3_OpenStackMachineSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachineTemplateResource_To_v1alpha3_OpenStackMachineTemplateResource is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachineTemplateResource_To_v1alpha3_OpenStackMachineTemplateResource(in *OpenStackMachineTemplateResource, out *v1alpha3.OpenStackMachineTemplateResource, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachineTemplateResource_To_v1alpha3_OpenStackMachineTemplateResource(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachineTemplateResource_To_v1alpha2_OpenStackMachineTemplateResource(in *v1alpha3.OpenStackMachineTemplateResource, out *OpenStackMachineTemplateResource, s conversion.Scope) error {
+	if err := Convert_v1alpha3_OpenStackMachineSpec_To_v1alpha2_OpenStackMachineSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachineTemplateResource_To_v1alpha2_OpenStackMachineTemplateResource is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackMachineTemplateResource_To_v1alpha2_OpenStackMachineTemplateResource(in *v1alpha3.OpenStackMachineTemplateResource, out *OpenStackMachineTemplateResource, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachineTemplateResource_To_v1alpha2_OpenStackMachineTemplateResource(in, out, s)
+}
+
+func autoConvert_v1alpha2_OpenStackMachineTemplateSpec_To_v1alpha3_OpenStackMachineTemplateSpec(in *OpenStackMachineTemplateSpec, out *v1alpha3.OpenStackMachineTemplateSpec, s conversion.Scope) error {
+	if err := Convert_v1alpha2_OpenStackMachineTemplateResource_To_v1alpha3_OpenStackMachineTemplateResource(&in.Template, &out.Template, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_OpenStackMachineTemplateSpec_To_v1alpha3_OpenStackMachineTemplateSpec is an autogenerated conversion function.
+func Convert_v1alpha2_OpenStackMachineTemplateSpec_To_v1alpha3_OpenStackMachineTemplateSpec(in *OpenStackMachineTemplateSpec, out *v1alpha3.OpenStackMachineTemplateSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OpenStackMachineTemplateSpec_To_v1alpha3_OpenStackMachineTemplateSpec(in, out, s)
+}
+
+func autoConvert_v1alpha3_OpenStackMachineTemplateSpec_To_v1alpha2_OpenStackMachineTemplateSpec(in *v1alpha3.OpenStackMachineTemplateSpec, out *OpenStackMachineTemplateSpec, s conversion.Scope) error {
+	if err := Convert_v1alpha3_OpenStackMachineTemplateResource_To_v1alpha2_OpenStackMachineTemplateResource(&in.Template, &out.Template, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_OpenStackMachineTemplateSpec_To_v1alpha2_OpenStackMachineTemplateSpec is an autogenerated conversion function.
+func Convert_v1alpha3_OpenStackMachineTemplateSpec_To_v1alpha2_OpenStackMachineTemplateSpec(in *v1alpha3.OpenStackMachineTemplateSpec, out *OpenStackMachineTemplateSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha3_OpenStackMachineTemplateSpec_To_v1alpha2_OpenStackMachineTemplateSpec(in, out, s)
+}
+
+func autoConvert_v1alpha2_PortOpts_To_v1alpha3_PortOpts(in *PortOpts, out *v1alpha3.PortOpts, s conversion.Scope) error {
+	out.NetworkID = in.NetworkID
+	out.NameSuffix = in.NameSuffix
+	out.Description = in.Description
+	out.MACAddress = in.MACAddress
+	out.FixedIPs = *(*[]v1alpha3.FixedIP)(unsafe.Pointer(&in.FixedIPs))
+	out.VNICType = in.VNICType
+	out.Profile = *(*map[string]string)(unsafe.Pointer(&in.Profile))
+	out.PortSecurity = (*bool)(unsafe.Pointer(in.PortSecurity))
+	out.AllowedAddressPairs = *(*[]v1alpha3.AddressPair)(unsafe.Pointer(&in.AllowedAddressPairs))
+	out.QoSPolicyID = in.QoSPolicyID
+	return nil
+}
+
+// Convert_v1alpha2_PortOpts_To_v1alpha3_PortOpts is an autogenerated conversion function.
+func Convert_v1alpha2_PortOpts_To_v1alpha3_PortOpts(in *PortOpts, out *v1alpha3.PortOpts, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PortOpts_To_v1alpha3_PortOpts(in, out, s)
+}
+
+func autoConvert_v1alpha3_PortOpts_To_v1alpha2_PortOpts(in *v1alpha3.PortOpts, out *PortOpts, s conversion.Scope) error {
+	out.NetworkID = in.NetworkID
+	out.NameSuffix = in.NameSuffix
+	out.Description = in.Description
+	out.MACAddress = in.MACAddress
+	out.FixedIPs = *(*[]FixedIP)(unsafe.Pointer(&in.FixedIPs))
+	out.VNICType = in.VNICType
+	out.Profile = *(*map[string]string)(unsafe.Pointer(&in.Profile))
+	out.PortSecurity = (*bool)(unsafe.Pointer(in.PortSecurity))
+	out.AllowedAddressPairs = *(*[]AddressPair)(unsafe.Pointer(&in.AllowedAddressPairs))
+	out.QoSPolicyID = in.QoSPolicyID
+	// WARNING: in.Tag requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha2_RootVolume_To_v1alpha3_RootVolume(in *RootVolume, out *v1alpha3.RootVolume, s conversion.Scope) error {
+	out.SourceType = in.SourceType
+	out.SourceUUID = in.SourceUUID
+	out.DeviceType = in.DeviceType
+	out.Size = in.Size
+	return nil
+}
+
+// Convert_v1alpha2_RootVolume_To_v1alpha3_RootVolume is an autogenerated conversion function.
+func Convert_v1alpha2_RootVolume_To_v1alpha3_RootVolume(in *RootVolume, out *v1alpha3.RootVolume, s conversion.Scope) error {
+	return autoConvert_v1alpha2_RootVolume_To_v1alpha3_RootVolume(in, out, s)
+}
+
+func autoConvert_v1alpha3_RootVolume_To_v1alpha2_RootVolume(in *v1alpha3.RootVolume, out *RootVolume, s conversion.Scope) error {
+	out.SourceType = in.SourceType
+	out.SourceUUID = in.SourceUUID
+	out.DeviceType = in.DeviceType
+	out.Size = in.Size
+	return nil
+}
+
+// Convert_v1alpha3_RootVolume_To_v1alpha2_RootVolume is an autogenerated conversion function.
+func Convert_v1alpha3_RootVolume_To_v1alpha2_RootVolume(in *v1alpha3.RootVolume, out *RootVolume, s conversion.Scope) error {
+	return autoConvert_v1alpha3_RootVolume_To_v1alpha2_RootVolume(in, out, s)
+}
+
+func autoConvert_v1alpha2_Router_To_v1alpha3_Router(in *Router, out *v1alpha3.Router, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	return nil
+}
+
+// Convert_v1alpha2_Router_To_v1alpha3_Router is an autogenerated conversion function.
+func Convert_v1alpha2_Router_To_v1alpha3_Router(in *Router, out *v1alpha3.Router, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Router_To_v1alpha3_Router(in, out, s)
+}
+
+func autoConvert_v1alpha3_Router_To_v1alpha2_Router(in *v1alpha3.Router, out *Router, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	return nil
+}
+
+// Convert_v1alpha3_Router_To_v1alpha2_Router is an autogenerated conversion function.
+func Convert_v1alpha3_Router_To_v1alpha2_Router(in *v1alpha3.Router, out *Router, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Router_To_v1alpha2_Router(in, out, s)
+}
+
+func autoConvert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in *SecurityGroup, out *v1alpha3.SecurityGroup, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.Rules = *(*[]v1alpha3.SecurityGroupRule)(unsafe.Pointer(&in.Rules))
+	return nil
+}
+
+// Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup is an autogenerated conversion function.
+func Convert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in *SecurityGroup, out *v1alpha3.SecurityGroup, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SecurityGroup_To_v1alpha3_SecurityGroup(in, out, s)
+}
+
+func autoConvert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in *v1alpha3.SecurityGroup, out *SecurityGroup, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.Rules = *(*[]SecurityGroupRule)(unsafe.Pointer(&in.Rules))
+	return nil
+}
+
+// Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup is an autogenerated conversion function.
+func Convert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in *v1alpha3.SecurityGroup, out *SecurityGroup, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SecurityGroup_To_v1alpha2_SecurityGroup(in, out, s)
+}
+
+func autoConvert_v1alpha2_SecurityGroupFilter_To_v1alpha3_SecurityGroupFilter(in *SecurityGroupFilter, out *v1alpha3.SecurityGroupFilter, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Name = in.Name
+	out.Description = in.Description
+	out.TenantID = in.TenantID
+	out.ProjectID = in.ProjectID
+	out.Limit = in.Limit
+	out.Marker = in.Marker
+	out.SortKey = in.SortKey
+	out.SortDir = in.SortDir
+	out.Tags = in.Tags
+	out.TagsAny = in.TagsAny
+	out.NotTags = in.NotTags
+	out.NotTagsAny = in.NotTagsAny
+	return nil
+}
+
+// Convert_v1alpha2_SecurityGroupFilter_To_v1alpha3_SecurityGroupFilter is an autogenerated conversion function.
+func Convert_v1alpha2_SecurityGroupFilter_To_v1alpha3_SecurityGroupFilter(in *SecurityGroupFilter, out *v1alpha3.SecurityGroupFilter, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SecurityGroupFilter_To_v1alpha3_SecurityGroupFilter(in, out, s)
+}
+
+func autoConvert_v1alpha3_SecurityGroupFilter_To_v1alpha2_SecurityGroupFilter(in *v1alpha3.SecurityGroupFilter, out *SecurityGroupFilter, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Name = in.Name
+	out.Description = in.Description
+	out.TenantID = in.TenantID
+	out.ProjectID = in.ProjectID
+	out.Limit = in.Limit
+	out.Marker = in.Marker
+	out.SortKey = in.SortKey
+	out.SortDir = in.SortDir
+	out.Tags = in.Tags
+	out.TagsAny = in.TagsAny
+	out.NotTags = in.NotTags
+	out.NotTagsAny = in.NotTagsAny
+	return nil
+}
+
+// Convert_v1alpha3_SecurityGroupFilter_To_v1alpha2_SecurityGroupFilter is an autogenerated conversion function.
+func Convert_v1alpha3_SecurityGroupFilter_To_v1alpha2_SecurityGroupFilter(in *v1alpha3.SecurityGroupFilter, out *SecurityGroupFilter, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SecurityGroupFilter_To_v1alpha2_SecurityGroupFilter(in, out, s)
+}
+
+func autoConvert_v1alpha2_SecurityGroupParam_To_v1alpha3_SecurityGroupParam(in *SecurityGroupParam, out *v1alpha3.SecurityGroupParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	out.Name = in.Name
+	if err := Convert_v1alpha2_SecurityGroupFilter_To_v1alpha3_SecurityGroupFilter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_SecurityGroupParam_To_v1alpha3_SecurityGroupParam is an autogenerated conversion function.
+func Convert_v1alpha2_SecurityGroupParam_To_v1alpha3_SecurityGroupParam(in *SecurityGroupParam, out *v1alpha3.SecurityGroupParam, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SecurityGroupParam_To_v1alpha3_SecurityGroupParam(in, out, s)
+}
+
+func autoConvert_v1alpha3_SecurityGroupParam_To_v1alpha2_SecurityGroupParam(in *v1alpha3.SecurityGroupParam, out *SecurityGroupParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	out.Name = in.Name
+	if err := Convert_v1alpha3_SecurityGroupFilter_To_v1alpha2_SecurityGroupFilter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_SecurityGroupParam_To_v1alpha2_SecurityGroupParam is an autogenerated conversion function.
+func Convert_v1alpha3_SecurityGroupParam_To_v1alpha2_SecurityGroupParam(in *v1alpha3.SecurityGroupParam, out *SecurityGroupParam, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SecurityGroupParam_To_v1alpha2_SecurityGroupParam(in, out, s)
+}
+
+func autoConvert_v1alpha2_SecurityGroupRule_To_v1alpha3_SecurityGroupRule(in *SecurityGroupRule, out *v1alpha3.SecurityGroupRule, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Direction = in.Direction
+	out.EtherType = in.EtherType
+	out.SecurityGroupID = in.SecurityGroupID
+	out.PortRangeMin = in.PortRangeMin
+	out.PortRangeMax = in.PortRangeMax
+	out.Protocol = in.Protocol
+	out.RemoteGroupID = in.RemoteGroupID
+	out.RemoteIPPrefix = in.RemoteIPPrefix
+	return nil
+}
+
+// Convert_v1alpha2_SecurityGroupRule_To_v1alpha3_SecurityGroupRule is an autogenerated conversion function.
+func Convert_v1alpha2_SecurityGroupRule_To_v1alpha3_SecurityGroupRule(in *SecurityGroupRule, out *v1alpha3.SecurityGroupRule, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SecurityGroupRule_To_v1alpha3_SecurityGroupRule(in, out, s)
+}
+
+func autoConvert_v1alpha3_SecurityGroupRule_To_v1alpha2_SecurityGroupRule(in *v1alpha3.SecurityGroupRule, out *SecurityGroupRule, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Direction = in.Direction
+	out.EtherType = in.EtherType
+	out.SecurityGroupID = in.SecurityGroupID
+	out.PortRangeMin = in.PortRangeMin
+	out.PortRangeMax = in.PortRangeMax
+	out.Protocol = in.Protocol
+	out.RemoteGroupID = in.RemoteGroupID
+	out.RemoteIPPrefix = in.RemoteIPPrefix
+	return nil
+}
+
+// Convert_v1alpha3_SecurityGroupRule_To_v1alpha2_SecurityGroupRule is an autogenerated conversion function.
+func Convert_v1alpha3_SecurityGroupRule_To_v1alpha2_SecurityGroupRule(in *v1alpha3.SecurityGroupRule, out *SecurityGroupRule, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SecurityGroupRule_To_v1alpha2_SecurityGroupRule(in, out, s)
+}
+
+func autoConvert_v1alpha2_Subnet_To_v1alpha3_Subnet(in *Subnet, out *v1alpha3.Subnet, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.CIDR = in.CIDR
+	return nil
+}
+
+// Convert_v1alpha2_Subnet_To_v1alpha3_Subnet is an autogenerated conversion function.
+func Convert_v1alpha2_Subnet_To_v1alpha3_Subnet(in *Subnet, out *v1alpha3.Subnet, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Subnet_To_v1alpha3_Subnet(in, out, s)
+}
+
+func autoConvert_v1alpha3_Subnet_To_v1alpha2_Subnet(in *v1alpha3.Subnet, out *Subnet, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = in.ID
+	out.CIDR = in.CIDR
+	return nil
+}
+
+// Convert_v1alpha3_Subnet_To_v1alpha2_Subnet is an autogenerated conversion function.
+func Convert_v1alpha3_Subnet_To_v1alpha2_Subnet(in *v1alpha3.Subnet, out *Subnet, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Subnet_To_v1alpha2_Subnet(in, out, s)
+}
+
+func autoConvert_v1alpha2_SubnetFilter_To_v1alpha3_SubnetFilter(in *SubnetFilter, out *v1alpha3.SubnetFilter, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Description = in.Description
+	out.EnableDHCP = (*bool)(unsafe.Pointer(in.EnableDHCP))
+	out.NetworkID = in.NetworkID
+	out.TenantID = in.TenantID
+	out.ProjectID = in.ProjectID
+	out.IPVersion = in.IPVersion
+	out.GatewayIP = in.GatewayIP
+	out.CIDR = in.CIDR
+	out.IPv6AddressMode = in.IPv6AddressMode
+	out.IPv6RAMode = in.IPv6RAMode
+	out.ID = in.ID
+	out.SubnetPoolID = in.SubnetPoolID
+	out.Limit = in.Limit
+	out.Marker = in.Marker
+	out.SortKey = in.SortKey
+	out.SortDir = in.SortDir
+	out.Tags = in.Tags
+	out.TagsAny = in.TagsAny
+	out.NotTags = in.NotTags
+	out.NotTagsAny = in.NotTagsAny
+	return nil
+}
+
+// Convert_v1alpha2_SubnetFilter_To_v1alpha3_SubnetFilter is an autogenerated conversion function.
+func Convert_v1alpha2_SubnetFilter_To_v1alpha3_SubnetFilter(in *SubnetFilter, out *v1alpha3.SubnetFilter, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SubnetFilter_To_v1alpha3_SubnetFilter(in, out, s)
+}
+
+func autoConvert_v1alpha3_SubnetFilter_To_v1alpha2_SubnetFilter(in *v1alpha3.SubnetFilter, out *SubnetFilter, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Description = in.Description
+	out.EnableDHCP = (*bool)(unsafe.Pointer(in.EnableDHCP))
+	out.NetworkID = in.NetworkID
+	out.TenantID = in.TenantID
+	out.ProjectID = in.ProjectID
+	out.IPVersion = in.IPVersion
+	out.GatewayIP = in.GatewayIP
+	out.CIDR = in.CIDR
+	out.IPv6AddressMode = in.IPv6AddressMode
+	out.IPv6RAMode = in.IPv6RAMode
+	out.ID = in.ID
+	out.SubnetPoolID = in.SubnetPoolID
+	out.Limit = in.Limit
+	out.Marker = in.Marker
+	out.SortKey = in.SortKey
+	out.SortDir = in.SortDir
+	out.Tags = in.Tags
+	out.TagsAny = in.TagsAny
+	out.NotTags = in.NotTags
+	out.NotTagsAny = in.NotTagsAny
+	return nil
+}
+
+// Convert_v1alpha3_SubnetFilter_To_v1alpha2_SubnetFilter is an autogenerated conversion function.
+func Convert_v1alpha3_SubnetFilter_To_v1alpha2_SubnetFilter(in *v1alpha3.SubnetFilter, out *SubnetFilter, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SubnetFilter_To_v1alpha2_SubnetFilter(in, out, s)
+}
+
+func autoConvert_v1alpha2_SubnetParam_To_v1alpha3_SubnetParam(in *SubnetParam, out *v1alpha3.SubnetParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	if err := Convert_v1alpha2_SubnetFilter_To_v1alpha3_SubnetFilter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_SubnetParam_To_v1alpha3_SubnetParam is an autogenerated conversion function.
+func Convert_v1alpha2_SubnetParam_To_v1alpha3_SubnetParam(in *SubnetParam, out *v1alpha3.SubnetParam, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SubnetParam_To_v1alpha3_SubnetParam(in, out, s)
+}
+
+func autoConvert_v1alpha3_SubnetParam_To_v1alpha2_SubnetParam(in *v1alpha3.SubnetParam, out *SubnetParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	if err := Convert_v1alpha3_SubnetFilter_To_v1alpha2_SubnetFilter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha3_SubnetParam_To_v1alpha2_SubnetParam is an autogenerated conversion function.
+func Convert_v1alpha3_SubnetParam_To_v1alpha2_SubnetParam(in *v1alpha3.SubnetParam, out *SubnetParam, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SubnetParam_To_v1alpha2_SubnetParam(in, out, s)
+}
+
+func autoConvert_v1alpha2_Subport_To_v1alpha3_Subport(in *Subport, out *v1alpha3.Subport, s conversion.Scope) error {
+	out.PortID = in.PortID
+	out.NetworkID = in.NetworkID
+	out.TrunkID = in.TrunkID
+	out.SegmentationType = in.SegmentationType
+	out.SegmentationID = in.SegmentationID
+	return nil
+}
+
+// Convert_v1alpha2_Subport_To_v1alpha3_Subport is an autogenerated conversion function.
+func Convert_v1alpha2_Subport_To_v1alpha3_Subport(in *Subport, out *v1alpha3.Subport, s conversion.Scope) error {
+	return autoConvert_v1alpha2_Subport_To_v1alpha3_Subport(in, out, s)
+}
+
+func autoConvert_v1alpha3_Subport_To_v1alpha2_Subport(in *v1alpha3.Subport, out *Subport, s conversion.Scope) error {
+	out.PortID = in.PortID
+	out.NetworkID = in.NetworkID
+	out.TrunkID = in.TrunkID
+	out.SegmentationType = in.SegmentationType
+	out.SegmentationID = in.SegmentationID
+	return nil
+}
+
+// Convert_v1alpha3_Subport_To_v1alpha2_Subport is an autogenerated conversion function.
+func Convert_v1alpha3_Subport_To_v1alpha2_Subport(in *v1alpha3.Subport, out *Subport, s conversion.Scope) error {
+	return autoConvert_v1alpha3_Subport_To_v1alpha2_Subport(in, out, s)
+}
+
+func autoConvert_v1alpha2_SubportParam_To_v1alpha3_SubportParam(in *SubportParam, out *v1alpha3.SubportParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	if err := Convert_v1alpha2_Filter_To_v1alpha3_Filter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	out.SegmentationType = in.SegmentationType
+	out.SegmentationID = in.SegmentationID
+	return nil
+}
+
+// Convert_v1alpha2_SubportParam_To_v1alpha3_SubportParam is an autogenerated conversion function.
+func Convert_v1alpha2_SubportParam_To_v1alpha3_SubportParam(in *SubportParam, out *v1alpha3.SubportParam, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SubportParam_To_v1alpha3_SubportParam(in, out, s)
+}
+
+func autoConvert_v1alpha3_SubportParam_To_v1alpha2_SubportParam(in *v1alpha3.SubportParam, out *SubportParam, s conversion.Scope) error {
+	out.UUID = in.UUID
+	if err := Convert_v1alpha3_Filter_To_v1alpha2_Filter(&in.Filter, &out.Filter, s); err != nil {
+		return err
+	}
+	out.SegmentationType = in.SegmentationType
+	out.SegmentationID = in.SegmentationID
+	return nil
+}
+
+// Convert_v1alpha3_SubportParam_To_v1alpha2_SubportParam is an autogenerated conversion function.
+func Convert_v1alpha3_SubportParam_To_v1alpha2_SubportParam(in *v1alpha3.SubportParam, out *SubportParam, s conversion.Scope) error {
+	return autoConvert_v1alpha3_SubportParam_To_v1alpha2_SubportParam(in, out, s)
+}
@@ -0,0 +1,188 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterFinalizer allows ReconcileOpenStackCluster to clean up OpenStack resources associated with OpenStackCluster before
+	// removing it from the apiserver.
+	ClusterFinalizer = "openstackcluster.infrastructure.cluster.x-k8s.io"
+)
+
+// OpenStackClusterSpec defines the desired state of OpenStackCluster
+type OpenStackClusterSpec struct {
+
+	// IdentityRef references the OpenStackClusterIdentity holding the openstack credentials.
+	// It takes precedence over CloudsSecret and CloudName.
+	// +optional
+	IdentityRef *OpenStackIdentityReference `json:"identityRef,omitempty"`
+
+	// The name of the secret containing the openstack credentials
+	// +optional
+	CloudsSecret *corev1.SecretReference `json:"cloudsSecret"`
+
+	// The name of the cloud to use from the clouds secret
+	// +optional
+	CloudName string `json:"cloudName"`
+
+	// NodeCIDR is the OpenStack Subnet to be created. Cluster actuator will create a
+	// network, a subnet with NodeCIDR, and a router connected to this subnet.
+	// If you leave this empty, no network will be created.
+	NodeCIDR string `json:"nodeCidr,omitempty"`
+	// DNSNameservers is the list of nameservers for OpenStack Subnet being created.
+	DNSNameservers []string `json:"dnsNameservers,omitempty"`
+	// ExternalRouterIPs is an array of externalIPs on the respective subnets.
+	// This is necessary if the router needs a fixed ip in a specific subnet.
+	ExternalRouterIPs []ExternalRouterIPParam `json:"externalRouterIPs,omitempty"`
+	// ExternalNetworkID is the ID of an external OpenStack Network. This is necessary
+	// to get public internet to the VMs.
+	ExternalNetworkID string `json:"externalNetworkId,omitempty"`
+
+	// UseOctavia is weather LoadBalancer Service is Octavia or not
+	// +optional
+	UseOctavia bool `json:"useOctavia,omitempty"`
+
+	// ManagedAPIServerLoadBalancer defines whether a LoadBalancer for the
+	// APIServer should be created. If set to true the following property is
+	// mandatory: APIServerLoadBalancerPort
+	// +optional
+	ManagedAPIServerLoadBalancer bool `json:"managedAPIServerLoadBalancer"`
+
+	// APIServerLoadBalancerFloatingIP is the floatingIP which will be associated
+	// to the APIServer loadbalancer. The floatingIP will be created if it not
+	// already exists. If it is empty, a floating IP is allocated from the external
+	// network, which is released when the cluster is deleted.
+	APIServerLoadBalancerFloatingIP string `json:"apiServerLoadBalancerFloatingIP,omitempty"`
+
+	// APIServerLoadBalancerPort is the port on which the listener on the APIServer
+	// loadbalancer will be created
+	APIServerLoadBalancerPort int `json:"apiServerLoadBalancerPort,omitempty"`
+
+	// APIServerLoadBalancerAdditionalPorts adds additional ports to the APIServerLoadBalancer
+	APIServerLoadBalancerAdditionalPorts []int `json:"apiServerLoadBalancerAdditionalPorts,omitempty"`
+
+	// APIServerLoadBalancer configures the Octavia provider, flavor, availability zone
+	// and VIP of the APIServer loadbalancer.
+	// +optional
+	APIServerLoadBalancer APIServerLoadBalancer `json:"apiServerLoadBalancer,omitempty"`
+
+	// DisableAPIServerFloatingIP disables the floating IPs of the APIServer, e.g. for
+	// air-gapped clusters without an ExternalNetworkID. The control plane endpoint is then
+	// the VIP of the APIServer loadbalancer, or the fixed IP of the first control plane
+	// machine without a managed loadbalancer.
+	// +optional
+	DisableAPIServerFloatingIP bool `json:"disableAPIServerFloatingIP,omitempty"`
+
+	// APIServerVirtualIP reserves a port for a virtual IP of the APIServer, which is
+	// added as an allowed address pair to the ports of the control plane machines. It
+	// can't be used with ManagedAPIServerLoadBalancer.
+	// +optional
+	APIServerVirtualIP *APIServerVirtualIP `json:"apiServerVirtualIP,omitempty"`
+
+	// ManagedSecurityGroups defines that kubernetes manages the OpenStack security groups
+	// for now, that means that we'll create two security groups, one allowing SSH
+	// and API access from everywhere, and another one that allows all traffic to/from
+	// machines belonging to that group. In the future, we could make this more flexible.
+	// +optional
+	ManagedSecurityGroups bool `json:"managedSecurityGroups"`
+
+	// DisablePortSecurity disables the port security of the network created for the
+	// Kubernetes cluster, which also disables SecurityGroups
+	DisablePortSecurity bool `json:"disablePortSecurity,omitempty"`
+
+	// Tags for all resources in cluster
+	Tags []string `json:"tags,omitempty"`
+
+	// ControlPlaneEndpoint represents the endpoint used to communicate with the control plane.
+	// It is set to the API server load balancer or the first control plane machine if empty,
+	// using their floating IP unless DisableAPIServerFloatingIP is set.
+	// +optional
+	ControlPlaneEndpoint APIEndpoint `json:"controlPlaneEndpoint"`
+}
+
+// OpenStackClusterStatus defines the observed state of OpenStackCluster
+type OpenStackClusterStatus struct {
+	Ready bool `json:"ready"`
+
+	// Network contains all information about the created OpenStack Network.
+	// It includes Subnets and Router.
+	Network *Network `json:"network,omitempty"`
+
+	// ControlPlaneSecurityGroups contains all the information about the OpenStack
+	// Security Group that needs to be applied to control plane nodes.
+	// TODO: Maybe instead of two properties, we add a property to the group?
+	ControlPlaneSecurityGroup *SecurityGroup `json:"controlPlaneSecurityGroup,omitempty"`
+
+	// GlobalSecurityGroup contains all the information about the OpenStack Security
+	// Group that needs to be applied to all nodes, both control plane and worker nodes.
+	GlobalSecurityGroup *SecurityGroup `json:"globalSecurityGroup,omitempty"`
+
+	// APIEndpoints are the endpoints to communicate with the control plane. Without an APIServer
+	// loadbalancer or virtual IP, these are the endpoints of the ready control plane machines.
+	// +optional
+	APIEndpoints []APIEndpoint `json:"apiEndpoints,omitempty"`
+
+	// ControlPlaneMachine is the name of the OpenStackMachine selected to serve the control
+	// plane endpoint. It fails over to another ready control plane machine if this machine is
+	// deleted or unhealthy, whose endpoint is then listed first in the APIEndpoints. The control
+	// plane endpoint in the spec isn't changed.
+	// +optional
+	ControlPlaneMachine string `json:"controlPlaneMachine,omitempty"`
+
+	// ApplicationCredentialExpiresAt is when the application credential used to manage
+	// the cluster expires. It is only set if the cluster uses an expiring application credential.
+	// +optional
+	ApplicationCredentialExpiresAt *metav1.Time `json:"applicationCredentialExpiresAt,omitempty"`
+
+	// FailureDomains are the availability zones of the compute service machines can be created in.
+	// +optional
+	FailureDomains FailureDomains `json:"failureDomains,omitempty"`
+
+	// Conditions describe the observed state of the credentials and resources.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=openstackclusters,scope=Namespaced
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+
+// OpenStackCluster is the Schema for the openstackclusters API
+type OpenStackCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenStackClusterSpec   `json:"spec,omitempty"`
+	Status OpenStackClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenStackClusterList contains a list of OpenStackCluster
+type OpenStackClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenStackCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenStackCluster{}, &OpenStackClusterList{})
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"net"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// DefaultAPIServerLoadBalancerPort is the port of the API server load balancer if none is set.
+const DefaultAPIServerLoadBalancerPort = 6443
+
+// DefaultDNSNameservers are the nameservers of the subnets created for clusters which don't set any.
+// They are empty unless configured by the manager.
+var DefaultDNSNameservers []string
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha3-openstackcluster,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackclusters,versions=v1alpha3,name=validation.v1alpha3.openstackcluster.infrastructure.cluster.x-k8s.io
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha3-openstackcluster,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackclusters,versions=v1alpha3,name=default.v1alpha3.openstackcluster.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Defaulter = &OpenStackCluster{}
+var _ webhook.Validator = &OpenStackCluster{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of OpenStackClusters.
+func (r *OpenStackCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// Default sets the defaults of the OpenStackCluster.
+func (r *OpenStackCluster) Default() {
+	if r.Spec.ManagedAPIServerLoadBalancer && r.Spec.APIServerLoadBalancerPort == 0 {
+		r.Spec.APIServerLoadBalancerPort = DefaultAPIServerLoadBalancerPort
+	}
+	if r.Spec.NodeCIDR != "" && len(r.Spec.DNSNameservers) == 0 && len(DefaultDNSNameservers) > 0 {
+		r.Spec.DNSNameservers = append([]string{}, DefaultDNSNameservers...)
+	}
+}
+
+// ValidateCreate validates the OpenStackCluster on creation.
+func (r *OpenStackCluster) ValidateCreate() error {
+	return r.validate(nil)
+}
+
+// ValidateUpdate validates the OpenStackCluster on update. The fields determining the
+// network and load balancer of the cluster can't be changed once they are created, and
+// the control plane endpoint can't be changed once it is set, unless it is the endpoint of a
+// control plane machine, which fails over to another control plane machine.
+func (r *OpenStackCluster) ValidateUpdate(old runtime.Object) error {
+	return r.validate(old.(*OpenStackCluster))
+}
+
+// ValidateDelete allows deleting all OpenStackClusters.
+func (r *OpenStackCluster) ValidateDelete() error {
+	return nil
+}
+
+func (r *OpenStackCluster) validate(old *OpenStackCluster) error {
+	var allErrs field.ErrorList
+	spec := field.NewPath("spec")
+
+	if r.Spec.CloudsSecret != nil && r.Spec.CloudName == "" {
+		allErrs = append(allErrs, field.Required(spec.Child("cloudName"), "must be set when cloudsSecret is set"))
+	}
+	if r.Spec.NodeCIDR != "" {
+		if _, _, err := net.ParseCIDR(r.Spec.NodeCIDR); err != nil {
+			allErrs = append(allErrs, field.Invalid(spec.Child("nodeCidr"), r.Spec.NodeCIDR, "must be a CIDR"))
+		}
+	}
+	for i, nameserver := range r.Spec.DNSNameservers {
+		allErrs = append(allErrs, validateIP(spec.Child("dnsNameservers").Index(i), nameserver)...)
+	}
+	for i, routerIP := range r.Spec.ExternalRouterIPs {
+		if routerIP.FixedIP != "" {
+			allErrs = append(allErrs, validateIP(spec.Child("externalRouterIPs").Index(i).Child("fixedIP"), routerIP.FixedIP)...)
+		}
+	}
+
+	if r.Spec.ManagedAPIServerLoadBalancer {
+		if r.Spec.APIServerLoadBalancerPort == 0 {
+			allErrs = append(allErrs, field.Required(spec.Child("apiServerLoadBalancerPort"), "must be set when managedAPIServerLoadBalancer is true"))
+		}
+	}
+	if r.Spec.APIServerLoadBalancerFloatingIP != "" {
+		allErrs = append(allErrs, validateIP(spec.Child("apiServerLoadBalancerFloatingIP"), r.Spec.APIServerLoadBalancerFloatingIP)...)
+		if r.Spec.DisableAPIServerFloatingIP {
+			allErrs = append(allErrs, field.Forbidden(spec.Child("apiServerLoadBalancerFloatingIP"), "must not be set when disableAPIServerFloatingIP is true"))
+		}
+	}
+	if r.Spec.APIServerLoadBalancerPort != 0 {
+		allErrs = append(allErrs, validatePort(spec.Child("apiServerLoadBalancerPort"), r.Spec.APIServerLoadBalancerPort)...)
+	}
+	lb := r.Spec.APIServerLoadBalancer
+	if !r.Spec.UseOctavia {
+		if lb.Provider != "" {
+			allErrs = append(allErrs, field.Forbidden(spec.Child("apiServerLoadBalancer", "provider"), "is only supported with useOctavia"))
+		}
+		if lb.FlavorID != "" {
+			allErrs = append(allErrs, field.Forbidden(spec.Child("apiServerLoadBalancer", "flavorId"), "is only supported with useOctavia"))
+		}
+		if lb.AvailabilityZone != "" {
+			allErrs = append(allErrs, field.Forbidden(spec.Child("apiServerLoadBalancer", "availabilityZone"), "is only supported with useOctavia"))
+		}
+	}
+	if lb.VipAddress != "" {
+		allErrs = append(allErrs, validateIP(spec.Child("apiServerLoadBalancer", "vipAddress"), lb.VipAddress)...)
+	}
+	allErrs = append(allErrs, validateAPIServerLoadBalancer(spec.Child("apiServerLoadBalancer"), lb, r.Spec.UseOctavia)...)
+	if r.Spec.APIServerVirtualIP != nil {
+		allErrs = append(allErrs, r.validateAPIServerVirtualIP(spec.Child("apiServerVirtualIP"))...)
+	}
+	if !r.Spec.ControlPlaneEndpoint.IsZero() {
+		if r.Spec.ControlPlaneEndpoint.Host == "" {
+			allErrs = append(allErrs, field.Required(spec.Child("controlPlaneEndpoint", "host"), "must be set when the port is set"))
+		}
+		allErrs = append(allErrs, validatePort(spec.Child("controlPlaneEndpoint", "port"), r.Spec.ControlPlaneEndpoint.Port)...)
+	}
+	ports := map[int]bool{r.Spec.APIServerLoadBalancerPort: true}
+	for i, port := range r.Spec.APIServerLoadBalancerAdditionalPorts {
+		path := spec.Child("apiServerLoadBalancerAdditionalPorts").Index(i)
+		allErrs = append(allErrs, validatePort(path, port)...)
+		if ports[port] {
+			allErrs = append(allErrs, field.Duplicate(path, port))
+		}
+		ports[port] = true
+	}
+
+	if old != nil {
+		allErrs = append(allErrs, validateImmutable(spec.Child("nodeCidr"), r.Spec.NodeCIDR, old.Spec.NodeCIDR)...)
+		allErrs = append(allErrs, validateImmutable(spec.Child("externalNetworkId"), r.Spec.ExternalNetworkID, old.Spec.ExternalNetworkID)...)
+		allErrs = append(allErrs, validateImmutable(spec.Child("useOctavia"), r.Spec.UseOctavia, old.Spec.UseOctavia)...)
+		allErrs = append(allErrs, validateImmutable(spec.Child("managedAPIServerLoadBalancer"), r.Spec.ManagedAPIServerLoadBalancer, old.Spec.ManagedAPIServerLoadBalancer)...)
+		allErrs = append(allErrs, validateImmutable(spec.Child("apiServerLoadBalancerFloatingIP"), r.Spec.APIServerLoadBalancerFloatingIP, old.Spec.APIServerLoadBalancerFloatingIP)...)
+		allErrs = append(allErrs, validateImmutable(spec.Child("disableAPIServerFloatingIP"), r.Spec.DisableAPIServerFloatingIP, old.Spec.DisableAPIServerFloatingIP)...)
+		allErrs = append(allErrs, validateImmutable(spec.Child("apiServerLoadBalancerPort"), r.Spec.APIServerLoadBalancerPort, old.Spec.APIServerLoadBalancerPort)...)
+		lbPath, oldLB := spec.Child("apiServerLoadBalancer"), old.Spec.APIServerLoadBalancer
+		allErrs = append(allErrs, validateImmutable(lbPath.Child("provider"), lb.Provider, oldLB.Provider)...)
+		allErrs = append(allErrs, validateImmutable(lbPath.Child("flavorId"), lb.FlavorID, oldLB.FlavorID)...)
+		allErrs = append(allErrs, validateImmutable(lbPath.Child("availabilityZone"), lb.AvailabilityZone, oldLB.AvailabilityZone)...)
+		allErrs = append(allErrs, validateImmutable(lbPath.Child("vipNetworkId"), lb.VipNetworkID, oldLB.VipNetworkID)...)
+		allErrs = append(allErrs, validateImmutable(lbPath.Child("vipPortId"), lb.VipPortID, oldLB.VipPortID)...)
+		allErrs = append(allErrs, validateImmutable(lbPath.Child("vipAddress"), lb.VipAddress, oldLB.VipAddress)...)
+		vipPath, vip, oldVIP := spec.Child("apiServerVirtualIP"), r.Spec.APIServerVirtualIP, old.Spec.APIServerVirtualIP
+		if vip == nil || oldVIP == nil {
+			allErrs = append(allErrs, validateImmutable(vipPath, vip, oldVIP)...)
+		} else {
+			allErrs = append(allErrs, validateImmutable(vipPath.Child("address"), vip.Address, oldVIP.Address)...)
+			allErrs = append(allErrs, validateImmutable(vipPath.Child("floatingIP"), vip.FloatingIP, oldVIP.FloatingIP)...)
+		}
+		allErrs = append(allErrs, validateImmutable(spec.Child("disablePortSecurity"), r.Spec.DisablePortSecurity, old.Spec.DisablePortSecurity)...)
+		if !old.Spec.ControlPlaneEndpoint.IsZero() {
+			allErrs = append(allErrs, validateImmutable(spec.Child("controlPlaneEndpoint"), r.Spec.ControlPlaneEndpoint, old.Spec.ControlPlaneEndpoint)...)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("OpenStackCluster").GroupKind(), r.Name, allErrs)
+}
+
+// validateAPIServerVirtualIP validates the virtual IP of the APIServer, which replaces the
+// APIServer loadbalancer and needs port security for the allowed address pairs.
+func (r *OpenStackCluster) validateAPIServerVirtualIP(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	vip := r.Spec.APIServerVirtualIP
+	if r.Spec.ManagedAPIServerLoadBalancer {
+		allErrs = append(allErrs, field.Forbidden(path, "can't be used with managedAPIServerLoadBalancer"))
+	}
+	if r.Spec.DisablePortSecurity {
+		allErrs = append(allErrs, field.Forbidden(path, "can't be used with disablePortSecurity"))
+	}
+	if r.Spec.NodeCIDR == "" {
+		allErrs = append(allErrs, field.Forbidden(path, "requires nodeCidr, the virtual IP is reserved on the cluster subnet"))
+	}
+	if vip.Address != "" {
+		allErrs = append(allErrs, validateIP(path.Child("address"), vip.Address)...)
+		if _, cidr, err := net.ParseCIDR(r.Spec.NodeCIDR); err == nil && !cidr.Contains(net.ParseIP(vip.Address)) {
+			allErrs = append(allErrs, field.Invalid(path.Child("address"), vip.Address, "must be in the nodeCidr"))
+		}
+	}
+	if vip.FloatingIP != "" {
+		allErrs = append(allErrs, validateIP(path.Child("floatingIP"), vip.FloatingIP)...)
+		if r.Spec.DisableAPIServerFloatingIP {
+			allErrs = append(allErrs, field.Forbidden(path.Child("floatingIP"), "must not be set when disableAPIServerFloatingIP is true"))
+		}
+	}
+	if vip.Priority < 0 || vip.Priority > 254 {
+		allErrs = append(allErrs, field.Invalid(path.Child("priority"), vip.Priority, "must be between 1 and 254"))
+	}
+	return allErrs
+}
+
+// validateAPIServerLoadBalancer validates the algorithm, health monitors and listeners of
+// the API server load balancer.
+func validateAPIServerLoadBalancer(path *field.Path, lb APIServerLoadBalancer, useOctavia bool) field.ErrorList {
+	var allErrs field.ErrorList
+	ovn := lb.Provider == "ovn"
+	if ovn && lb.Algorithm != "" && lb.Algorithm != "SOURCE_IP_PORT" {
+		allErrs = append(allErrs, field.Invalid(path.Child("algorithm"), lb.Algorithm, "the ovn provider only supports SOURCE_IP_PORT"))
+	}
+	if !ovn && lb.Provider != "" && lb.Algorithm == "SOURCE_IP_PORT" {
+		allErrs = append(allErrs, field.Invalid(path.Child("algorithm"), lb.Algorithm, "is only supported by the ovn provider"))
+	}
+
+	if monitor := lb.Monitor; monitor != nil {
+		monitorPath := path.Child("monitor")
+		if ovn && (monitor.Type == "HTTP" || monitor.Type == "HTTPS") {
+			allErrs = append(allErrs, field.Invalid(monitorPath.Child("type"), monitor.Type, "the ovn provider only supports TCP monitors"))
+		}
+		if monitor.URLPath != "" && !strings.HasPrefix(monitor.URLPath, "/") {
+			allErrs = append(allErrs, field.Invalid(monitorPath.Child("urlPath"), monitor.URLPath, "must start with /"))
+		}
+		if monitor.Delay < 0 {
+			allErrs = append(allErrs, field.Invalid(monitorPath.Child("delay"), monitor.Delay, "must not be negative"))
+		}
+		if monitor.Timeout < 0 {
+			allErrs = append(allErrs, field.Invalid(monitorPath.Child("timeout"), monitor.Timeout, "must not be negative"))
+		}
+		if monitor.Delay > 0 && monitor.Timeout > monitor.Delay {
+			allErrs = append(allErrs, field.Invalid(monitorPath.Child("timeout"), monitor.Timeout, "must not be greater than the delay"))
+		}
+		if monitor.MaxRetries < 0 || monitor.MaxRetries > 10 {
+			allErrs = append(allErrs, field.Invalid(monitorPath.Child("maxRetries"), monitor.MaxRetries, "must be between 1 and 10"))
+		}
+	}
+
+	if listener := lb.Listener; listener != nil {
+		listenerPath := path.Child("listener")
+		if listener.ConnectionLimit != nil && *listener.ConnectionLimit < -1 {
+			allErrs = append(allErrs, field.Invalid(listenerPath.Child("connectionLimit"), *listener.ConnectionLimit, "must be -1 or greater"))
+		}
+		timeouts := map[string]*int{
+			"timeoutClientData":    listener.TimeoutClientData,
+			"timeoutMemberData":    listener.TimeoutMemberData,
+			"timeoutMemberConnect": listener.TimeoutMemberConnect,
+		}
+		for _, name := range []string{"timeoutClientData", "timeoutMemberData", "timeoutMemberConnect"} {
+			timeout := timeouts[name]
+			if timeout == nil {
+				continue
+			}
+			if !useOctavia {
+				allErrs = append(allErrs, field.Forbidden(listenerPath.Child(name), "is only supported with useOctavia"))
+			} else if *timeout < 0 {
+				allErrs = append(allErrs, field.Invalid(listenerPath.Child(name), *timeout, "must not be negative"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validateIP(path *field.Path, ip string) field.ErrorList {
+	if net.ParseIP(ip) == nil {
+		return field.ErrorList{field.Invalid(path, ip, "must be an IP address")}
+	}
+	return nil
+}
+
+func validatePort(path *field.Path, port int) field.ErrorList {
+	if port < 1 || port > 65535 {
+		return field.ErrorList{field.Invalid(path, port, "must be between 1 and 65535")}
+	}
+	return nil
+}
+
+func validateImmutable(path *field.Path, value, oldValue interface{}) field.ErrorList {
+	if !reflect.DeepEqual(value, oldValue) {
+		return field.ErrorList{field.Invalid(path, value, "field is immutable")}
+	}
+	return nil
+}
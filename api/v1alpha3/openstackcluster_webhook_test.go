@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newTestOpenStackCluster() *OpenStackCluster {
+	return &OpenStackCluster{
+		Spec: OpenStackClusterSpec{
+			CloudsSecret:                    &corev1.SecretReference{Name: "cloud-config"},
+			CloudName:                       "openstack",
+			NodeCIDR:                        "10.6.0.0/24",
+			DNSNameservers:                  []string{"8.8.8.8"},
+			ManagedAPIServerLoadBalancer:    true,
+			APIServerLoadBalancerFloatingIP: "172.24.4.10",
+			APIServerLoadBalancerPort:       6443,
+		},
+	}
+}
+
+func TestOpenStackClusterDefault(t *testing.T) {
+	defer func(nameservers []string) { DefaultDNSNameservers = nameservers }(DefaultDNSNameservers)
+	DefaultDNSNameservers = []string{"10.0.0.53"}
+
+	cluster := &OpenStackCluster{Spec: OpenStackClusterSpec{NodeCIDR: "10.6.0.0/24", ManagedAPIServerLoadBalancer: true}}
+	cluster.Default()
+	if cluster.Spec.APIServerLoadBalancerPort != DefaultAPIServerLoadBalancerPort {
+		t.Errorf("expected the API server port to default to %d, got %d", DefaultAPIServerLoadBalancerPort, cluster.Spec.APIServerLoadBalancerPort)
+	}
+	if len(cluster.Spec.DNSNameservers) != 1 || cluster.Spec.DNSNameservers[0] != "10.0.0.53" {
+		t.Errorf("expected the default nameservers, got %v", cluster.Spec.DNSNameservers)
+	}
+
+	cluster = newTestOpenStackCluster()
+	cluster.Default()
+	if cluster.Spec.DNSNameservers[0] != "8.8.8.8" {
+		t.Errorf("expected the nameservers to be kept, got %v", cluster.Spec.DNSNameservers)
+	}
+}
+
+func TestOpenStackClusterValidateCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*OpenStackCluster)
+		valid  bool
+	}{
+		{"valid", func(*OpenStackCluster) {}, true},
+		{"missing cloud name", func(c *OpenStackCluster) { c.Spec.CloudName = "" }, false},
+		{"invalid node CIDR", func(c *OpenStackCluster) { c.Spec.NodeCIDR = "10.6.0.0" }, false},
+		{"invalid nameserver", func(c *OpenStackCluster) { c.Spec.DNSNameservers = []string{"dns.example.com"} }, false},
+		{"allocated floating IP", func(c *OpenStackCluster) { c.Spec.APIServerLoadBalancerFloatingIP = "" }, true},
+		{"disabled floating IP", func(c *OpenStackCluster) {
+			c.Spec.DisableAPIServerFloatingIP = true
+			c.Spec.APIServerLoadBalancerFloatingIP = ""
+			c.Spec.ExternalNetworkID = ""
+		}, true},
+		{"floating IP with disabled floating IP", func(c *OpenStackCluster) { c.Spec.DisableAPIServerFloatingIP = true }, false},
+		{"virtual IP", func(c *OpenStackCluster) {
+			c.Spec.ManagedAPIServerLoadBalancer = false
+			c.Spec.APIServerVirtualIP = &APIServerVirtualIP{Address: "10.6.0.10", FloatingIP: "172.24.4.11", Priority: 150}
+		}, true},
+		{"virtual IP with load balancer", func(c *OpenStackCluster) { c.Spec.APIServerVirtualIP = &APIServerVirtualIP{} }, false},
+		{"virtual IP outside the node CIDR", func(c *OpenStackCluster) {
+			c.Spec.ManagedAPIServerLoadBalancer = false
+			c.Spec.APIServerVirtualIP = &APIServerVirtualIP{Address: "10.7.0.10"}
+		}, false},
+		{"virtual IP without node CIDR", func(c *OpenStackCluster) {
+			c.Spec.ManagedAPIServerLoadBalancer = false
+			c.Spec.NodeCIDR = ""
+			c.Spec.APIServerVirtualIP = &APIServerVirtualIP{}
+		}, false},
+		{"virtual IP priority out of range", func(c *OpenStackCluster) {
+			c.Spec.ManagedAPIServerLoadBalancer = false
+			c.Spec.APIServerVirtualIP = &APIServerVirtualIP{Priority: 255}
+		}, false},
+		{"missing port", func(c *OpenStackCluster) { c.Spec.APIServerLoadBalancerPort = 0 }, false},
+		{"port out of range", func(c *OpenStackCluster) { c.Spec.APIServerLoadBalancerPort = 70000 }, false},
+		{"duplicate additional port", func(c *OpenStackCluster) { c.Spec.APIServerLoadBalancerAdditionalPorts = []int{6443} }, false},
+		{"control plane endpoint", func(c *OpenStackCluster) { c.Spec.ControlPlaneEndpoint = APIEndpoint{Host: "172.24.4.10", Port: 6443} }, true},
+		{"control plane endpoint without host", func(c *OpenStackCluster) { c.Spec.ControlPlaneEndpoint = APIEndpoint{Port: 6443} }, false},
+		{"octavia load balancer", func(c *OpenStackCluster) {
+			c.Spec.UseOctavia = true
+			c.Spec.APIServerLoadBalancer = APIServerLoadBalancer{Provider: "ovn", FlavorID: "1", AvailabilityZone: "az1", VipAddress: "10.6.0.10"}
+		}, true},
+		{"provider without octavia", func(c *OpenStackCluster) { c.Spec.APIServerLoadBalancer.Provider = "ovn" }, false},
+		{"invalid VIP address", func(c *OpenStackCluster) { c.Spec.APIServerLoadBalancer.VipAddress = "10.6.0" }, false},
+		{"load balancer settings", func(c *OpenStackCluster) {
+			c.Spec.APIServerLoadBalancer.Algorithm = "LEAST_CONNECTIONS"
+			c.Spec.APIServerLoadBalancer.Monitor = &LoadBalancerMonitor{Type: "HTTPS", URLPath: "/readyz", Delay: 5, Timeout: 3, MaxRetries: 2}
+		}, true},
+		{"OVN round robin", func(c *OpenStackCluster) {
+			c.Spec.UseOctavia = true
+			c.Spec.APIServerLoadBalancer = APIServerLoadBalancer{Provider: "ovn", Algorithm: "ROUND_ROBIN"}
+		}, false},
+		{"OVN HTTPS monitor", func(c *OpenStackCluster) {
+			c.Spec.UseOctavia = true
+			c.Spec.APIServerLoadBalancer = APIServerLoadBalancer{Provider: "ovn", Monitor: &LoadBalancerMonitor{Type: "HTTPS"}}
+		}, false},
+		{"monitor timeout greater than delay", func(c *OpenStackCluster) {
+			c.Spec.APIServerLoadBalancer.Monitor = &LoadBalancerMonitor{Delay: 5, Timeout: 10}
+		}, false},
+		{"relative monitor URL path", func(c *OpenStackCluster) {
+			c.Spec.APIServerLoadBalancer.Monitor = &LoadBalancerMonitor{URLPath: "healthz"}
+		}, false},
+		{"listener timeout without octavia", func(c *OpenStackCluster) {
+			timeout := 10000
+			c.Spec.APIServerLoadBalancer.Listener = &LoadBalancerListener{TimeoutClientData: &timeout}
+		}, false},
+		{"invalid connection limit", func(c *OpenStackCluster) {
+			limit := -2
+			c.Spec.APIServerLoadBalancer.Listener = &LoadBalancerListener{ConnectionLimit: &limit}
+		}, false},
+		{"unmanaged load balancer", func(c *OpenStackCluster) {
+			c.Spec.ManagedAPIServerLoadBalancer = false
+			c.Spec.APIServerLoadBalancerFloatingIP = ""
+			c.Spec.APIServerLoadBalancerPort = 0
+		}, true},
+	}
+	for _, tt := range tests {
+		cluster := newTestOpenStackCluster()
+		tt.modify(cluster)
+		if err := cluster.ValidateCreate(); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %t, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestOpenStackClusterValidateUpdate(t *testing.T) {
+	old := newTestOpenStackCluster()
+
+	cluster := newTestOpenStackCluster()
+	cluster.Spec.CloudName = "other"
+	cluster.Spec.Tags = []string{"tag"}
+	if err := cluster.ValidateUpdate(old); err != nil {
+		t.Errorf("expected credentials and tags to be mutable, got %v", err)
+	}
+
+	cluster = newTestOpenStackCluster()
+	cluster.Spec.NodeCIDR = "10.7.0.0/24"
+	if err := cluster.ValidateUpdate(old); err == nil {
+		t.Errorf("expected the node CIDR to be immutable")
+	}
+
+	cluster = newTestOpenStackCluster()
+	cluster.Spec.APIServerLoadBalancer.VipAddress = "10.6.0.10"
+	if err := cluster.ValidateUpdate(old); err == nil {
+		t.Errorf("expected the VIP of the API server load balancer to be immutable")
+	}
+
+	cluster = newTestOpenStackCluster()
+	cluster.Spec.APIServerLoadBalancer.Monitor = &LoadBalancerMonitor{Type: "HTTPS"}
+	if err := cluster.ValidateUpdate(old); err != nil {
+		t.Errorf("expected the API server load balancer settings to be mutable, got %v", err)
+	}
+
+	cluster = newTestOpenStackCluster()
+	cluster.Spec.ControlPlaneEndpoint = APIEndpoint{Host: "172.24.4.10", Port: 6443}
+	if err := cluster.ValidateUpdate(old); err != nil {
+		t.Errorf("expected the control plane endpoint to be set once, got %v", err)
+	}
+	old = cluster.DeepCopy()
+	cluster.Spec.ControlPlaneEndpoint.Port = 443
+	if err := cluster.ValidateUpdate(old); err == nil {
+		t.Errorf("expected the control plane endpoint to be immutable once set")
+	}
+
+	cluster = newTestOpenStackCluster()
+	cluster.Spec.ManagedAPIServerLoadBalancer = false
+	cluster.Spec.APIServerLoadBalancerFloatingIP = ""
+	cluster.Spec.ControlPlaneEndpoint = APIEndpoint{Host: "10.6.0.5", Port: 6443}
+	old = cluster.DeepCopy()
+	cluster.Spec.ControlPlaneEndpoint.Host = "10.6.0.6"
+	if err := cluster.ValidateUpdate(old); err == nil {
+		t.Errorf("expected the control plane endpoint of a control plane machine to be immutable once set")
+	}
+}
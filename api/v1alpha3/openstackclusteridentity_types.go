@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenStackClusterIdentitySpec defines the credentials OpenStackClusters can reference.
+type OpenStackClusterIdentitySpec struct {
+	// SecretRef references the clouds secret containing the OpenStack credentials.
+	// The secret has the same format as the CloudsSecret of the OpenStackCluster.
+	SecretRef OpenStackIdentitySecretReference `json:"secretRef"`
+
+	// CloudName is the name of the cloud to use from the clouds secret.
+	CloudName string `json:"cloudName"`
+
+	// AllowedNamespaces selects the namespaces of the OpenStackClusters allowed to use this identity.
+	// Namespaces can be selected by name or with a label selector.
+	// An empty allowedNamespaces allows all namespaces, while no OpenStackCluster may use the identity
+	// if allowedNamespaces is not set.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// OpenStackIdentitySecretReference references the clouds secret of an identity.
+type OpenStackIdentitySecretReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+	// Namespace of the secret.
+	Namespace string `json:"namespace"`
+}
+
+// AllowedNamespaces selects namespaces by name or labels.
+type AllowedNamespaces struct {
+	// NamespaceList contains the names of the allowed namespaces.
+	// +optional
+	NamespaceList []string `json:"list,omitempty"`
+
+	// Selector selects the allowed namespaces by their labels.
+	// An empty selector matches all namespaces.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// OpenStackIdentityReference references an OpenStackClusterIdentity.
+type OpenStackIdentityReference struct {
+	// Name of the OpenStackClusterIdentity.
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=openstackclusteridentities,scope=Cluster
+// +kubebuilder:storageversion
+
+// OpenStackClusterIdentity is the Schema for the openstackclusteridentities API
+type OpenStackClusterIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OpenStackClusterIdentitySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenStackClusterIdentityList contains a list of OpenStackClusterIdentity
+type OpenStackClusterIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenStackClusterIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenStackClusterIdentity{}, &OpenStackClusterIdentityList{})
+}
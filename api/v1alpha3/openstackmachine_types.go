@@ -0,0 +1,187 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/errors"
+)
+
+const (
+	// MachineFinalizer allows ReconcileOpenStackMachine to clean up OpenStack resources associated with OpenStackMachine before
+	// removing it from the apiserver.
+	MachineFinalizer = "openstackmachine.infrastructure.cluster.x-k8s.io"
+)
+
+// OpenStackMachineSpec defines the desired state of OpenStackMachine
+type OpenStackMachineSpec struct {
+
+	// ProviderID is the unique identifier as specified by the cloud provider.
+	ProviderID *string `json:"providerID,omitempty"`
+
+	// The name of the secret containing the openstack credentials.
+	// Deprecated: machines use the credentials of their OpenStackCluster. This is only
+	// used if the OpenStackCluster has no credentials configured.
+	// +optional
+	CloudsSecret *corev1.SecretReference `json:"cloudsSecret"`
+
+	// The name of the cloud to use from the clouds secret.
+	// Deprecated: machines use the credentials of their OpenStackCluster.
+	// +optional
+	CloudName string `json:"cloudName"`
+
+	// The flavor reference for the flavor for your server instance.
+	Flavor string `json:"flavor"`
+
+	// The name of the image to use for your server instance.
+	// If the RootVolume is specified, this will be ignored and use rootVolume directly.
+	Image string `json:"image"`
+
+	// The ssh key to inject in the instance
+	KeyName string `json:"keyName,omitempty"`
+
+	// A networks object. Required parameter when there are multiple networks defined for the tenant.
+	// When you do not specify the networks parameter, the server attaches to the only network created for the current tenant.
+	Networks []NetworkParam `json:"networks,omitempty"`
+
+	// Ports to be attached to the server instance, in addition to the ports created for Networks.
+	// They allow to configure e.g. SR-IOV ports or allowed address pairs per port.
+	Ports []PortOpts `json:"ports,omitempty"`
+
+	// The floatingIP which will be associated to the machine, only used for master.
+	// The floatingIP should have been created and haven't been associated.
+	// If it is empty, a floating IP is allocated from the external network for control
+	// plane machines of clusters without a managed APIServer loadbalancer, which is
+	// released when the machine is deleted.
+	FloatingIP string `json:"floatingIP,omitempty"`
+
+	// The availability zone from which to launch the server.
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+
+	// The names of the security groups to assign to the instance
+	SecurityGroups []SecurityGroupParam `json:"securityGroups,omitempty"`
+
+	// The name of the secret containing the user data (startup script in most cases)
+	UserDataSecret *corev1.SecretReference `json:"userDataSecret,omitempty"`
+
+	// Whether the server instance is created on a trunk port or not.
+	// Subports of the trunks are configured per network.
+	Trunk bool `json:"trunk,omitempty"`
+
+	// Machine tags
+	// Servers are only tagged if the compute API supports microversion 2.52,
+	// other resources are always tagged.
+	Tags []string `json:"tags,omitempty"`
+
+	// Metadata mapping. Allows you to create a map of key value pairs to add to the server instance.
+	ServerMetadata map[string]string `json:"serverMetadata,omitempty"`
+
+	// Config Drive support
+	ConfigDrive *bool `json:"configDrive,omitempty"`
+
+	// The volume metadata to boot from
+	RootVolume *RootVolume `json:"rootVolume,omitempty"`
+
+	// IDs of the trusted certificates which verify the signature of the image.
+	// Requires the compute API to support microversion 2.63.
+	TrustedImageCertificates []string `json:"trustedImageCertificates,omitempty"`
+}
+
+// OpenStackMachineStatus defines the observed state of OpenStackMachine
+type OpenStackMachineStatus struct {
+
+	// Ready is true when the provider resource is ready.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// Addresses contains the OpenStack instance associated addresses.
+	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
+
+	// InstanceID is the ID of the OpenStack instance for this machine. The instance is
+	// adopted by its ID, e.g. after the machine was moved to another management cluster.
+	// +optional
+	InstanceID string `json:"instanceID,omitempty"`
+
+	// InstanceState is the state of the OpenStack instance for this machine.
+	// +optional
+	InstanceState *InstanceState `json:"instanceState,omitempty"`
+
+	// FloatingIP is the floating IP associated with the instance, which is either
+	// the FloatingIP of the spec or a floating IP allocated for the machine.
+	// +optional
+	FloatingIP string `json:"floatingIP,omitempty"`
+
+	// Subports contains the trunk subports created for this machine.
+	// +optional
+	Subports []Subport `json:"subports,omitempty"`
+
+	// Conditions describe the observed state of the credentials and resources.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// FailureReason will be set in the event that there is a terminal problem
+	// reconciling the Machine and will contain a succinct value suitable
+	// for machine interpretation.
+	// +optional
+	FailureReason *errors.MachineStatusError `json:"failureReason,omitempty"`
+
+	// FailureMessage will be set in the event that there is a terminal problem
+	// reconciling the Machine and will contain a more verbose string suitable
+	// for logging and human consumption.
+	//
+	// This field should not be set for transitive errors that a controller
+	// faces that are expected to be fixed automatically over
+	// time (like service outages), but instead indicate that something is
+	// fundamentally wrong with the Machine's spec or the configuration of
+	// the controller, and that manual intervention is required. Examples
+	// of terminal errors would be invalid combinations of settings in the
+	// spec, values that are unsupported by the controller, or the
+	// responsible controller itself being critically misconfigured.
+	//
+	// Any transient errors that occur during the reconciliation of Machines
+	// can be added as events to the Machine object and/or logged in the
+	// controller's output.
+	// +optional
+	FailureMessage *string `json:"failureMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=openstackmachines,scope=Namespaced
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+
+// OpenStackMachine is the Schema for the openstackmachines API
+type OpenStackMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenStackMachineSpec   `json:"spec,omitempty"`
+	Status OpenStackMachineStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenStackMachineList contains a list of OpenStackMachine
+type OpenStackMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenStackMachine `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenStackMachine{}, &OpenStackMachineList{})
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// DefaultSegmentationType is the segmentation type of trunk subports if none is set.
+const DefaultSegmentationType = "vlan"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha3-openstackmachine,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachines,versions=v1alpha3,name=validation.v1alpha3.openstackmachine.infrastructure.cluster.x-k8s.io
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha3-openstackmachine,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachines,versions=v1alpha3,name=default.v1alpha3.openstackmachine.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Defaulter = &OpenStackMachine{}
+var _ webhook.Validator = &OpenStackMachine{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of OpenStackMachines.
+func (r *OpenStackMachine) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// Default sets the defaults of the OpenStackMachine.
+func (r *OpenStackMachine) Default() {
+	defaultOpenStackMachineSpec(&r.Spec)
+}
+
+func defaultOpenStackMachineSpec(spec *OpenStackMachineSpec) {
+	for i := range spec.Networks {
+		for j := range spec.Networks[i].Subports {
+			if spec.Networks[i].Subports[j].SegmentationType == "" {
+				spec.Networks[i].Subports[j].SegmentationType = DefaultSegmentationType
+			}
+		}
+	}
+}
+
+// ValidateCreate validates the OpenStackMachine on creation.
+func (r *OpenStackMachine) ValidateCreate() error {
+	return r.validate(nil)
+}
+
+// ValidateUpdate validates the OpenStackMachine on update. The fields determining the
+// server can't be changed once it is created.
+func (r *OpenStackMachine) ValidateUpdate(old runtime.Object) error {
+	return r.validate(old.(*OpenStackMachine))
+}
+
+// ValidateDelete allows deleting all OpenStackMachines.
+func (r *OpenStackMachine) ValidateDelete() error {
+	return nil
+}
+
+func (r *OpenStackMachine) validate(old *OpenStackMachine) error {
+	var oldSpec *OpenStackMachineSpec
+	if old != nil {
+		oldSpec = &old.Spec
+	}
+	allErrs := validateOpenStackMachineSpec(field.NewPath("spec"), &r.Spec, oldSpec)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("OpenStackMachine").GroupKind(), r.Name, allErrs)
+}
+
+// validateOpenStackMachineSpec validates the spec of an OpenStackMachine, and that the
+// immutable fields weren't changed if the spec is updated.
+func validateOpenStackMachineSpec(path *field.Path, spec, old *OpenStackMachineSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.CloudsSecret != nil && spec.CloudName == "" {
+		allErrs = append(allErrs, field.Required(path.Child("cloudName"), "must be set when cloudsSecret is set"))
+	}
+	if spec.Flavor == "" {
+		allErrs = append(allErrs, field.Required(path.Child("flavor"), ""))
+	}
+	if spec.Image == "" && spec.RootVolume == nil {
+		allErrs = append(allErrs, field.Required(path.Child("image"), "must be set unless rootVolume is set"))
+	}
+	if spec.FloatingIP != "" {
+		allErrs = append(allErrs, validateIP(path.Child("floatingIP"), spec.FloatingIP)...)
+	}
+	for i, network := range spec.Networks {
+		networkPath := path.Child("networks").Index(i)
+		if network.FixedIp != "" {
+			allErrs = append(allErrs, validateIP(networkPath.Child("fixedIp"), network.FixedIp)...)
+		}
+		for j, subport := range network.Subports {
+			if (subport.SegmentationType == "" || subport.SegmentationType == DefaultSegmentationType) && (subport.SegmentationID < 1 || subport.SegmentationID > 4094) {
+				allErrs = append(allErrs, field.Invalid(networkPath.Child("subports").Index(j).Child("segmentationID"), subport.SegmentationID, "must be a VLAN ID between 1 and 4094"))
+			}
+		}
+	}
+	for i, port := range spec.Ports {
+		portPath := path.Child("ports").Index(i)
+		for j, fixedIP := range port.FixedIPs {
+			if fixedIP.IPAddress != "" {
+				allErrs = append(allErrs, validateIP(portPath.Child("fixedIPs").Index(j).Child("ipAddress"), fixedIP.IPAddress)...)
+			}
+		}
+		for j, pair := range port.AllowedAddressPairs {
+			if net.ParseIP(pair.IPAddress) == nil {
+				if _, _, err := net.ParseCIDR(pair.IPAddress); err != nil {
+					allErrs = append(allErrs, field.Invalid(portPath.Child("allowedAddressPairs").Index(j).Child("ipAddress"), pair.IPAddress, "must be an IP address or CIDR"))
+				}
+			}
+		}
+	}
+
+	if old != nil {
+		if old.ProviderID != nil {
+			allErrs = append(allErrs, validateImmutable(path.Child("providerID"), spec.ProviderID, old.ProviderID)...)
+		}
+		allErrs = append(allErrs, validateImmutable(path.Child("flavor"), spec.Flavor, old.Flavor)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("image"), spec.Image, old.Image)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("keyName"), spec.KeyName, old.KeyName)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("availabilityZone"), spec.AvailabilityZone, old.AvailabilityZone)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("networks"), spec.Networks, old.Networks)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("ports"), spec.Ports, old.Ports)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("trunk"), spec.Trunk, old.Trunk)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("rootVolume"), spec.RootVolume, old.RootVolume)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("configDrive"), spec.ConfigDrive, old.ConfigDrive)...)
+		allErrs = append(allErrs, validateImmutable(path.Child("trustedImageCertificates"), spec.TrustedImageCertificates, old.TrustedImageCertificates)...)
+	}
+
+	return allErrs
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+)
+
+func newTestOpenStackMachine() *OpenStackMachine {
+	return &OpenStackMachine{
+		Spec: OpenStackMachineSpec{
+			Flavor:   "m1.medium",
+			Image:    "ubuntu",
+			Networks: []NetworkParam{{UUID: "network", Subports: []SubportParam{{UUID: "vlan", SegmentationID: 100}}}},
+		},
+	}
+}
+
+func TestOpenStackMachineDefault(t *testing.T) {
+	machine := newTestOpenStackMachine()
+	machine.Default()
+	if segmentationType := machine.Spec.Networks[0].Subports[0].SegmentationType; segmentationType != DefaultSegmentationType {
+		t.Errorf("expected the segmentation type to default to %s, got %s", DefaultSegmentationType, segmentationType)
+	}
+}
+
+func TestOpenStackMachineValidateCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*OpenStackMachine)
+		valid  bool
+	}{
+		{"valid", func(*OpenStackMachine) {}, true},
+		{"missing flavor", func(m *OpenStackMachine) { m.Spec.Flavor = "" }, false},
+		{"missing image", func(m *OpenStackMachine) { m.Spec.Image = "" }, false},
+		{"root volume without image", func(m *OpenStackMachine) {
+			m.Spec.Image = ""
+			m.Spec.RootVolume = &RootVolume{SourceType: "image", SourceUUID: "image", Size: 20}
+		}, true},
+		{"invalid floating IP", func(m *OpenStackMachine) { m.Spec.FloatingIP = "172.24.4" }, false},
+		{"invalid VLAN ID", func(m *OpenStackMachine) { m.Spec.Networks[0].Subports[0].SegmentationID = 4095 }, false},
+		{"allowed address pair CIDR", func(m *OpenStackMachine) {
+			m.Spec.Ports = []PortOpts{{NetworkID: "network", AllowedAddressPairs: []AddressPair{{IPAddress: "10.6.0.0/24"}}}}
+		}, true},
+	}
+	for _, tt := range tests {
+		machine := newTestOpenStackMachine()
+		tt.modify(machine)
+		if err := machine.ValidateCreate(); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid %t, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestOpenStackMachineValidateUpdate(t *testing.T) {
+	old := newTestOpenStackMachine()
+
+	machine := newTestOpenStackMachine()
+	machine.Spec.ProviderID = pointer.StringPtr("openstack:////id")
+	if err := machine.ValidateUpdate(old); err != nil {
+		t.Errorf("expected the provider ID to be settable, got %v", err)
+	}
+
+	old = machine.DeepCopy()
+	machine.Spec.ProviderID = pointer.StringPtr("openstack:////other")
+	if err := machine.ValidateUpdate(old); err == nil {
+		t.Errorf("expected the provider ID to be immutable once set")
+	}
+
+	machine = newTestOpenStackMachine()
+	machine.Spec.Flavor = "m1.large"
+	if err := machine.ValidateUpdate(newTestOpenStackMachine()); err == nil {
+		t.Errorf("expected the flavor to be immutable")
+	}
+}
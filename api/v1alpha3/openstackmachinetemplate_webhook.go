@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1alpha3-openstackmachinetemplate,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachinetemplates,versions=v1alpha3,name=validation.v1alpha3.openstackmachinetemplate.infrastructure.cluster.x-k8s.io
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha3-openstackmachinetemplate,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=openstackmachinetemplates,versions=v1alpha3,name=default.v1alpha3.openstackmachinetemplate.infrastructure.cluster.x-k8s.io
+
+var _ webhook.Defaulter = &OpenStackMachineTemplate{}
+var _ webhook.Validator = &OpenStackMachineTemplate{}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of OpenStackMachineTemplates.
+func (r *OpenStackMachineTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// Default sets the defaults of the OpenStackMachines created from the template.
+func (r *OpenStackMachineTemplate) Default() {
+	defaultOpenStackMachineSpec(&r.Spec.Template.Spec)
+}
+
+// ValidateCreate validates the template like the OpenStackMachines created from it.
+func (r *OpenStackMachineTemplate) ValidateCreate() error {
+	return r.validate(nil)
+}
+
+// ValidateUpdate validates the OpenStackMachineTemplate on update. The template can't be
+// changed, as the Machines created from it wouldn't be updated. Machines are rolled out
+// with a new template instead.
+func (r *OpenStackMachineTemplate) ValidateUpdate(old runtime.Object) error {
+	return r.validate(old.(*OpenStackMachineTemplate))
+}
+
+// ValidateDelete allows deleting all OpenStackMachineTemplates.
+func (r *OpenStackMachineTemplate) ValidateDelete() error {
+	return nil
+}
+
+func (r *OpenStackMachineTemplate) validate(old *OpenStackMachineTemplate) error {
+	templateSpec := field.NewPath("spec", "template", "spec")
+	allErrs := validateOpenStackMachineSpec(templateSpec, &r.Spec.Template.Spec, nil)
+
+	if r.Spec.Template.Spec.ProviderID != nil {
+		allErrs = append(allErrs, field.Forbidden(templateSpec.Child("providerID"), "must not be set in a template"))
+	}
+	if old != nil {
+		allErrs = append(allErrs, validateImmutable(templateSpec, r.Spec.Template.Spec, old.Spec.Template.Spec)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("OpenStackMachineTemplate").GroupKind(), r.Name, allErrs)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+)
+
+func newTestOpenStackMachineTemplate() *OpenStackMachineTemplate {
+	return &OpenStackMachineTemplate{
+		Spec: OpenStackMachineTemplateSpec{
+			Template: OpenStackMachineTemplateResource{Spec: newTestOpenStackMachine().Spec},
+		},
+	}
+}
+
+func TestOpenStackMachineTemplateValidate(t *testing.T) {
+	template := newTestOpenStackMachineTemplate()
+	template.Default()
+	if err := template.ValidateCreate(); err != nil {
+		t.Fatalf("expected the template to be valid, got %v", err)
+	}
+
+	template.Spec.Template.Spec.Flavor = ""
+	template.Spec.Template.Spec.ProviderID = pointer.StringPtr("openstack:////id")
+	if err := template.ValidateCreate(); err == nil {
+		t.Errorf("expected a template without flavor and with a provider ID to be invalid")
+	}
+
+	template = newTestOpenStackMachineTemplate()
+	template.Spec.Template.Spec.KeyName = "other"
+	if err := template.ValidateUpdate(newTestOpenStackMachineTemplate()); err == nil {
+		t.Errorf("expected the template to be immutable")
+	}
+}
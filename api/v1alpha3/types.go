@@ -0,0 +1,509 @@
+package v1alpha3
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ExternalRouterIPParam struct {
+	// The FixedIP in the corresponding subnet
+	FixedIP string `json:"fixedIP,omitempty"`
+	// The subnet in which the FixedIP is used for the Gateway of this router
+	Subnet SubnetParam `json:"subnet"`
+}
+
+type SecurityGroupParam struct {
+	// Security Group UID
+	UUID string `json:"uuid,omitempty"`
+	// Security Group name
+	Name string `json:"name,omitempty"`
+	// Filters used to query security groups in openstack
+	Filter SecurityGroupFilter `json:"filter,omitempty"`
+}
+
+type SecurityGroupFilter struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	TenantID    string `json:"tenantId,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	Marker      string `json:"marker,omitempty"`
+	SortKey     string `json:"sortKey,omitempty"`
+	SortDir     string `json:"sortDir,omitempty"`
+	Tags        string `json:"tags,omitempty"`
+	TagsAny     string `json:"tagsAny,omitempty"`
+	NotTags     string `json:"notTags,omitempty"`
+	NotTagsAny  string `json:"notTagsAny,omitempty"`
+}
+
+type NetworkParam struct {
+	// The UUID of the network. Required if you omit the port attribute.
+	UUID string `json:"uuid,omitempty"`
+	// A fixed IPv4 address for the NIC.
+	FixedIp string `json:"fixedIp,omitempty"`
+	// Filters for optional network query
+	Filter Filter `json:"filter,omitempty"`
+	// Subnet within a network to use
+	Subnets []SubnetParam `json:"subnets,omitempty"`
+	// Subports to attach to the trunk of the port on this network.
+	// Only used if trunk is enabled for the machine.
+	Subports []SubportParam `json:"subports,omitempty"`
+}
+
+// SubportParam selects the network of a subport, which is attached with its segmentation to the
+// trunk of the port of the machine on the network.
+type SubportParam struct {
+	// The UUID of the network the subport is created on.
+	UUID string `json:"uuid,omitempty"`
+	// Filters for optional network query
+	Filter Filter `json:"filter,omitempty"`
+	// SegmentationType is the segmentation type of the subport. Defaults to vlan.
+	SegmentationType string `json:"segmentationType,omitempty"`
+	// SegmentationID is the segmentation ID of the subport, e.g. the VLAN ID.
+	SegmentationID int `json:"segmentationID"`
+}
+
+type Filter struct {
+	Status       string `json:"status,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	AdminStateUp *bool  `json:"adminStateUp,omitempty"`
+	TenantID     string `json:"tenantId,omitempty"`
+	ProjectID    string `json:"projectId,omitempty"`
+	Shared       *bool  `json:"shared,omitempty"`
+	ID           string `json:"id,omitempty"`
+	Marker       string `json:"marker,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	SortKey      string `json:"sortKey,omitempty"`
+	SortDir      string `json:"sortDir,omitempty"`
+	Tags         string `json:"tags,omitempty"`
+	TagsAny      string `json:"tagsAny,omitempty"`
+	NotTags      string `json:"notTags,omitempty"`
+	NotTagsAny   string `json:"notTagsAny,omitempty"`
+}
+
+// PortOpts configures a port of the machine, which is created on the network before the server.
+type PortOpts struct {
+	// ID of the OpenStack network on which to create the port.
+	NetworkID string `json:"networkId"`
+	// Used to make the name of the port unique. If unspecified, instead the 0-based index of the port in the list is used.
+	NameSuffix string `json:"nameSuffix,omitempty"`
+	// Description of the port.
+	Description string `json:"description,omitempty"`
+	// MACAddress of the port. Only used on creation.
+	MACAddress string `json:"macAddress,omitempty"`
+	// Specify pairs of subnet and/or IP address. These should be subnets of the network with the given NetworkID.
+	// Only used on creation.
+	FixedIPs []FixedIP `json:"fixedIPs,omitempty"`
+	// The virtual network interface card (vNIC) type that is bound to the neutron port, e.g. normal, direct or macvtap.
+	// Only used on creation.
+	VNICType string `json:"vnicType,omitempty"`
+	// A dictionary that enables the application running on the specified
+	// host to pass and receive virtual network interface (VIF) port-specific
+	// information to the plug-in. Only used on creation.
+	Profile map[string]string `json:"profile,omitempty"`
+	// Enables or disables port security of the port. When disabled, no security groups are applied.
+	PortSecurity *bool `json:"portSecurity,omitempty"`
+	// AllowedAddressPairs are the IP/MAC address pairs the port accepts in addition to its own.
+	AllowedAddressPairs []AddressPair `json:"allowedAddressPairs,omitempty"`
+	// ID of the QoS policy applied to the port.
+	QoSPolicyID string `json:"qosPolicyId,omitempty"`
+	// Device tag of the network interface of the port, which identifies it in the metadata of the server.
+	// Only used on creation, and only if the compute API supports microversion 2.42.
+	Tag string `json:"tag,omitempty"`
+}
+
+// FixedIP selects the subnet and optionally the IP address of a fixed IP of a port.
+type FixedIP struct {
+	// The ID of the subnet to get the IP address from.
+	SubnetID string `json:"subnetId"`
+	// The IP address to use. If unspecified, an address is allocated from the subnet.
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// AddressPair is an IP address, and optionally a MAC address, which a port accepts traffic for
+// in addition to its own fixed IPs and MAC address.
+type AddressPair struct {
+	// IPAddress is the IP address or CIDR of the address pair.
+	IPAddress string `json:"ipAddress"`
+	// MACAddress of the address pair. Defaults to the MAC address of the port.
+	MACAddress string `json:"macAddress,omitempty"`
+}
+
+type SubnetParam struct {
+	// The UUID of the network. Required if you omit the port attribute.
+	UUID string `json:"uuid,omitempty"`
+
+	// Filters for optional network query
+	Filter SubnetFilter `json:"filter,omitempty"`
+}
+
+type SubnetFilter struct {
+	Name            string `json:"name,omitempty"`
+	Description     string `json:"description,omitempty"`
+	EnableDHCP      *bool  `json:"enableDhcp,omitempty"`
+	NetworkID       string `json:"networkId,omitempty"`
+	TenantID        string `json:"tenantId,omitempty"`
+	ProjectID       string `json:"projectId,omitempty"`
+	IPVersion       int    `json:"ipVersion,omitempty"`
+	GatewayIP       string `json:"gateway_ip,omitempty"`
+	CIDR            string `json:"cidr,omitempty"`
+	IPv6AddressMode string `json:"ipv6AddressMode,omitempty"`
+	IPv6RAMode      string `json:"ipv6RaMode,omitempty"`
+	ID              string `json:"id,omitempty"`
+	SubnetPoolID    string `json:"subnetpoolId,omitempty"`
+	Limit           int    `json:"limit,omitempty"`
+	Marker          string `json:"marker,omitempty"`
+	SortKey         string `json:"sortKey,omitempty"`
+	SortDir         string `json:"sortDir,omitempty"`
+	Tags            string `json:"tags,omitempty"`
+	TagsAny         string `json:"tagsAny,omitempty"`
+	NotTags         string `json:"notTags,omitempty"`
+	NotTagsAny      string `json:"notTagsAny,omitempty"`
+}
+
+// APIEndpoint represents a reachable Kubernetes API endpoint.
+type APIEndpoint struct {
+	// The hostname on which the API server is serving.
+	Host string `json:"host"`
+
+	// The port on which the API server is serving.
+	Port int `json:"port"`
+}
+
+// IsZero returns true if neither the host nor the port of the endpoint are set.
+func (v APIEndpoint) IsZero() bool {
+	return v.Host == "" && v.Port == 0
+}
+
+// FailureDomains is a map of failure domains by their name.
+type FailureDomains map[string]FailureDomainSpec
+
+// FailureDomainSpec is the specification of a failure domain.
+type FailureDomainSpec struct {
+	// ControlPlane determines if this failure domain is suitable for use by control plane machines.
+	// +optional
+	ControlPlane bool `json:"controlPlane"`
+
+	// Attributes is a free form map of attributes a provider can use to further describe the failure domain.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// APIServerLoadBalancer configures the load balancer of the API server, which is
+// created if ManagedAPIServerLoadBalancer is true.
+type APIServerLoadBalancer struct {
+	// Provider is the Octavia provider of the load balancer, e.g. amphora or ovn.
+	// The default provider of the cloud is used if it is empty.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// FlavorID is the ID of the Octavia flavor of the load balancer.
+	// +optional
+	FlavorID string `json:"flavorId,omitempty"`
+
+	// AvailabilityZone is the Octavia availability zone of the load balancer.
+	// +optional
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+
+	// VipNetworkID is the network the VIP of the load balancer is allocated from.
+	// It defaults to the network of the cluster.
+	// +optional
+	VipNetworkID string `json:"vipNetworkId,omitempty"`
+
+	// VipPortID is an existing port used as the VIP of the load balancer. It takes
+	// precedence over VipNetworkID and VipAddress.
+	// +optional
+	VipPortID string `json:"vipPortId,omitempty"`
+
+	// VipAddress is the fixed IP address of the VIP of the load balancer.
+	// +optional
+	VipAddress string `json:"vipAddress,omitempty"`
+
+	// Algorithm is the load balancing algorithm of the pools. It defaults to
+	// ROUND_ROBIN, or SOURCE_IP_PORT with the OVN provider.
+	// +kubebuilder:validation:Enum=ROUND_ROBIN;LEAST_CONNECTIONS;SOURCE_IP;SOURCE_IP_PORT
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Monitor configures the health monitors of the pools.
+	// +optional
+	Monitor *LoadBalancerMonitor `json:"monitor,omitempty"`
+
+	// Listener configures the listeners of the load balancer.
+	// +optional
+	Listener *LoadBalancerListener `json:"listener,omitempty"`
+}
+
+// LoadBalancerMonitor configures the health monitors of the API server load balancer.
+// Unset fields keep their defaults.
+type LoadBalancerMonitor struct {
+	// Type is the type of the health monitors. HTTP and HTTPS monitors request the
+	// URLPath of the API server. It defaults to TCP.
+	// +kubebuilder:validation:Enum=TCP;HTTP;HTTPS
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// URLPath is the path requested by HTTP and HTTPS monitors. It defaults to /healthz.
+	// +optional
+	URLPath string `json:"urlPath,omitempty"`
+
+	// Delay is the time in seconds between the health checks. It defaults to 30.
+	// +optional
+	Delay int `json:"delay,omitempty"`
+
+	// Timeout is the time in seconds a health check waits for a reply. It defaults to 5.
+	// +optional
+	Timeout int `json:"timeout,omitempty"`
+
+	// MaxRetries is the number of successful health checks before a member is
+	// considered healthy again. It defaults to 3.
+	// +optional
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+// LoadBalancerListener configures the listeners of the API server load balancer.
+// Unset fields keep the defaults of the load balancer service.
+type LoadBalancerListener struct {
+	// ConnectionLimit is the maximum number of connections of a listener, -1 means unlimited.
+	// +optional
+	ConnectionLimit *int `json:"connectionLimit,omitempty"`
+
+	// TimeoutClientData is the inactivity timeout of the client connections in milliseconds.
+	// +optional
+	TimeoutClientData *int `json:"timeoutClientData,omitempty"`
+
+	// TimeoutMemberData is the inactivity timeout of the member connections in milliseconds.
+	// +optional
+	TimeoutMemberData *int `json:"timeoutMemberData,omitempty"`
+
+	// TimeoutMemberConnect is the timeout of connecting to a member in milliseconds.
+	// +optional
+	TimeoutMemberConnect *int `json:"timeoutMemberConnect,omitempty"`
+}
+
+// APIServerVirtualIP configures a virtual IP of the APIServer, which is floated between the
+// control plane machines by keepalived or kube-vip instead of an APIServer loadbalancer.
+type APIServerVirtualIP struct {
+	// Address is the fixed IP address of the virtual IP on the cluster subnet.
+	// It is allocated from the subnet if empty.
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// FloatingIP is the floating IP associated with the virtual IP. The floating IP will
+	// be created if it doesn't exist, or allocated from the external network if empty.
+	// +optional
+	FloatingIP string `json:"floatingIP,omitempty"`
+
+	// Priority is the highest VRRP priority passed to the control plane machines in the
+	// server metadata. Every machine gets a priority between 1 and Priority derived from
+	// its name. It defaults to 100.
+	// +optional
+	Priority int `json:"priority,omitempty"`
+}
+
+type RootVolume struct {
+	SourceType string `json:"sourceType,omitempty"`
+	SourceUUID string `json:"sourceUUID,omitempty"`
+	DeviceType string `json:"deviceType,omitempty"`
+	Size       int    `json:"diskSize,omitempty"`
+}
+
+// Network represents basic information about the associated OpenStach Neutron Network
+type Network struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+
+	Subnet *Subnet `json:"subnet,omitempty"`
+	Router *Router `json:"router,omitempty"`
+
+	// Be careful when using APIServerLoadBalancer, because this field is optional and therefore not
+	// set in all cases
+	APIServerLoadBalancer *LoadBalancer `json:"apiServerLoadBalancer,omitempty"`
+
+	// APIServerVirtualIP is only set if the APIServerVirtualIP of the spec is set
+	APIServerVirtualIP *VirtualIP `json:"apiServerVirtualIP,omitempty"`
+}
+
+// Subnet represents basic information about the associated OpenStack Neutron Subnet
+type Subnet struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+
+	CIDR string `json:"cidr"`
+}
+
+// Router represents basic information about the associated OpenStack Neutron Router
+type Router struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// Subport represents basic information about a trunk subport created for a machine
+type Subport struct {
+	PortID    string `json:"portID"`
+	NetworkID string `json:"networkID"`
+	TrunkID   string `json:"trunkID"`
+
+	SegmentationType string `json:"segmentationType"`
+	SegmentationID   int    `json:"segmentationID"`
+}
+
+// LoadBalancer represents basic information about the associated OpenStack LoadBalancer
+type LoadBalancer struct {
+	Name       string `json:"name"`
+	ID         string `json:"id"`
+	IP         string `json:"ip"`
+	InternalIP string `json:"internalIP"`
+}
+
+// VirtualIP represents basic information about the port reserved for a virtual IP
+type VirtualIP struct {
+	Name       string `json:"name"`
+	PortID     string `json:"portID"`
+	IP         string `json:"ip,omitempty"`
+	InternalIP string `json:"internalIP"`
+}
+
+// SecurityGroup represents the basic information of the associated
+// OpenStack Neutron Security Group.
+type SecurityGroup struct {
+	Name  string              `json:"name"`
+	ID    string              `json:"id"`
+	Rules []SecurityGroupRule `json:"rules"`
+}
+
+// SecurityGroupRule represent the basic information of the associated OpenStack
+// Security Group Role.
+type SecurityGroupRule struct {
+	ID              string `json:"name"`
+	Direction       string `json:"direction"`
+	EtherType       string `json:"etherType"`
+	SecurityGroupID string `json:"securityGroupID"`
+	PortRangeMin    int    `json:"portRangeMin"`
+	PortRangeMax    int    `json:"portRangeMax"`
+	Protocol        string `json:"protocol"`
+	RemoteGroupID   string `json:"remoteGroupID"`
+	RemoteIPPrefix  string `json:"remoteIPPrefix"`
+}
+
+// Equal checks if two SecurityGroupRules are the same.
+func (r SecurityGroupRule) Equal(x SecurityGroupRule) bool {
+	return (r.Direction == x.Direction &&
+		r.EtherType == x.EtherType &&
+		r.PortRangeMin == x.PortRangeMin &&
+		r.PortRangeMax == x.PortRangeMax &&
+		r.Protocol == x.Protocol &&
+		r.RemoteGroupID == x.RemoteGroupID &&
+		r.RemoteIPPrefix == x.RemoteIPPrefix)
+
+}
+
+// InstanceState describes the state of an OpenStack instance.
+type InstanceState string
+
+var (
+	InstanceStateBuilding = InstanceState("BUILDING")
+
+	InstanceStateActive = InstanceState("ACTIVE")
+
+	InstanceStateError = InstanceState("ERROR")
+
+	InstanceStateStopped = InstanceState("STOPPED")
+
+	InstanceStateShutoff = InstanceState("SHUTOFF")
+)
+
+// ConditionType is the type of a Condition.
+type ConditionType string
+
+const (
+	// AuthenticatedCondition reports whether the credentials in the clouds secret
+	// authenticate against the OpenStack cloud.
+	AuthenticatedCondition ConditionType = "Authenticated"
+)
+
+// Condition describes the state of an aspect of an OpenStack resource.
+type Condition struct {
+	// Type of the condition.
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the condition changed from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a one-word CamelCase reason for the last transition of the condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable message with details about the last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GetCondition returns the condition of the given type, or nil if it isn't set.
+func GetCondition(conditions []Condition, conditionType ConditionType) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition. The LastTransitionTime is only
+// updated if the status changes.
+func SetCondition(conditions *[]Condition, condition Condition) {
+	existing := GetCondition(*conditions, condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, condition)
+		return
+	}
+	if existing.Status != condition.Status {
+		existing.Status = condition.Status
+		existing.LastTransitionTime = metav1.Now()
+	}
+	existing.Reason = condition.Reason
+	existing.Message = condition.Message
+}
+
+const (
+	// PausedAnnotation pauses the reconciliation of an OpenStackCluster or OpenStackMachine
+	// carrying it, or of all OpenStackClusters and OpenStackMachines of a Cluster carrying it.
+	PausedAnnotation = "cluster.x-k8s.io/paused"
+
+	// ExternallyManagedAnnotation is a comma-separated list of the IDs of OpenStack resources
+	// used by an OpenStackCluster or OpenStackMachine which are managed outside of CAPO.
+	// CAPO uses these resources, but never modifies or deletes them.
+	ExternallyManagedAnnotation = "infrastructure.cluster.x-k8s.io/externally-managed"
+)
+
+// IsPaused returns whether the reconciliation of an object is paused, because either the
+// object or the Cluster it belongs to has the PausedAnnotation.
+func IsPaused(cluster, o metav1.Object) bool {
+	for _, obj := range []metav1.Object{cluster, o} {
+		if _, ok := obj.GetAnnotations()[PausedAnnotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExternallyManaged returns whether the OpenStack resource with the ID is listed in the
+// ExternallyManagedAnnotation of an object.
+func IsExternallyManaged(o metav1.Object, id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, managed := range strings.Split(o.GetAnnotations()[ExternallyManagedAnnotation], ",") {
+		if strings.TrimSpace(managed) == id {
+			return true
+		}
+	}
+	return false
+}
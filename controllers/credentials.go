@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// waitForCredentialsDuration is how long to wait before retrying when authentication failed.
+	// Changes of the clouds secret trigger a reconcile immediately.
+	waitForCredentialsDuration = time.Minute
+
+	authenticationFailedReason    = "AuthenticationFailed"
+	authenticationSucceededReason = "AuthenticationSucceeded"
+)
+
+// setAuthenticatedCondition reports the result of authenticating with the credentials
+// of the clouds secret in the Authenticated condition, and records an event on failure.
+func setAuthenticatedCondition(object runtime.Object, conditions *[]infrav1.Condition, err error) {
+	if err != nil {
+		record.Warnf(object, authenticationFailedReason, "Failed to authenticate with the clouds secret: %v", err)
+		infrav1.SetCondition(conditions, infrav1.Condition{
+			Type:    infrav1.AuthenticatedCondition,
+			Status:  corev1.ConditionFalse,
+			Reason:  authenticationFailedReason,
+			Message: err.Error(),
+		})
+		return
+	}
+	infrav1.SetCondition(conditions, infrav1.Condition{
+		Type:   infrav1.AuthenticatedCondition,
+		Status: corev1.ConditionTrue,
+		Reason: authenticationSucceededReason,
+	})
+}
+
+// cloudsSecretPredicate filters the events of Secrets which aren't clouds secrets, so the OpenStackClusters
+// aren't listed for every change of an unrelated Secret.
+var cloudsSecretPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool { return isCloudsSecret(e.Object) },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		// A secret which isn't a clouds secret anymore breaks the clusters using it.
+		return isCloudsSecret(e.ObjectOld) || isCloudsSecret(e.ObjectNew)
+	},
+	DeleteFunc:  func(e event.DeleteEvent) bool { return isCloudsSecret(e.Object) },
+	GenericFunc: func(e event.GenericEvent) bool { return isCloudsSecret(e.Object) },
+}
+
+// isCloudsSecret returns whether the object is a Secret containing a clouds.yaml.
+func isCloudsSecret(o runtime.Object) bool {
+	secret, ok := o.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	_, ok = secret.Data[provider.CloudsSecretKey]
+	return ok
+}
+
+// invalidateDeletedSecret evicts the OpenStack clients created from a deleted secret from the client cache.
+func invalidateDeletedSecret(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+	if e.Meta == nil {
+		return
+	}
+	provider.InvalidateSecret(e.Meta.GetNamespace(), e.Meta.GetName())
+}
+
+// referencesSecret returns whether the clouds secret reference of an object in the given namespace points to the secret.
+func referencesSecret(ref *corev1.SecretReference, namespace string, secret *corev1.Secret) bool {
+	if ref == nil || ref.Name != secret.Name {
+		return false
+	}
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return namespace == secret.Namespace
+}
+
+// openStackClustersUsingSecret returns the OpenStackClusters using the secret as clouds secret,
+// directly or through their OpenStackClusterIdentity.
+func openStackClustersUsingSecret(ctx context.Context, c client.Client, secret *corev1.Secret) ([]infrav1.OpenStackCluster, error) {
+	identityList := &infrav1.OpenStackClusterIdentityList{}
+	if err := c.List(ctx, identityList); err != nil {
+		return nil, err
+	}
+	identities := map[string]bool{}
+	for _, identity := range identityList.Items {
+		if identity.Spec.SecretRef.Name == secret.Name && identity.Spec.SecretRef.Namespace == secret.Namespace {
+			identities[identity.Name] = true
+		}
+	}
+
+	clusterList := &infrav1.OpenStackClusterList{}
+	if err := c.List(ctx, clusterList); err != nil {
+		return nil, err
+	}
+	var result []infrav1.OpenStackCluster
+	for _, cluster := range clusterList.Items {
+		if cluster.Spec.IdentityRef != nil {
+			if identities[cluster.Spec.IdentityRef.Name] {
+				result = append(result, cluster)
+			}
+			continue
+		}
+		if referencesSecret(cluster.Spec.CloudsSecret, cluster.Namespace, secret) {
+			result = append(result, cluster)
+		}
+	}
+	return result, nil
+}
@@ -19,24 +19,38 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/gophercloud/gophercloud"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	"reflect"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/compute"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/loadbalancer"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/metrics"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/record"
 	"sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
+	"time"
 )
 
 const (
 	clusterControllerName = "openstackcluster-controller"
+	defaultAPIServerPort  = 6443
 )
 
 // OpenStackClusterReconciler reconciles a OpenStackCluster object
@@ -48,6 +62,9 @@ type OpenStackClusterReconciler struct {
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=openstackclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=openstackclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=openstackclusteridentities,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func (r *OpenStackClusterReconciler) Reconcile(request ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx := context.TODO()
@@ -79,6 +96,11 @@ func (r *OpenStackClusterReconciler) Reconcile(request ctrl.Request) (_ ctrl.Res
 
 	logger = logger.WithName(fmt.Sprintf("cluster=%s", cluster.Name))
 
+	if infrav1.IsPaused(cluster, openStackCluster) {
+		logger.Info("OpenStackCluster or linked Cluster is marked as paused, not reconciling")
+		return reconcile.Result{}, nil
+	}
+
 	patchHelper, err := patch.NewHelper(openStackCluster, r)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -111,10 +133,13 @@ func (r *OpenStackClusterReconciler) reconcileCluster(logger logr.Logger, cluste
 	}
 
 	osProviderClient, clientOpts, err := provider.NewClientFromCluster(r.Client, openStackCluster)
+	setAuthenticatedCondition(openStackCluster, &openStackCluster.Status.Conditions, err)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{RequeueAfter: waitForCredentialsDuration}, nil
 	}
 
+	reconcileApplicationCredentialExpiry(osProviderClient, openStackCluster)
+
 	networkingService, err := networking.NewService(osProviderClient, clientOpts)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -129,53 +154,101 @@ func (r *OpenStackClusterReconciler) reconcileCluster(logger logr.Logger, cluste
 	if openStackCluster.Spec.NodeCIDR == "" {
 		klog.V(4).Infof("No need to reconcile network for cluster %s", clusterName)
 	} else {
-		err := networkingService.ReconcileNetwork(clusterName, openStackCluster)
+		err := metrics.ObservePhase(clusterControllerName, "network", func() error {
+			return networkingService.ReconcileNetwork(clusterName, openStackCluster)
+		})
 		if err != nil {
 			return reconcile.Result{}, errors.Errorf("failed to reconcile network: %v", err)
 		}
-		err = networkingService.ReconcileSubnet(clusterName, openStackCluster)
+		err = metrics.ObservePhase(clusterControllerName, "subnet", func() error {
+			return networkingService.ReconcileSubnet(clusterName, openStackCluster)
+		})
 		if err != nil {
 			return reconcile.Result{}, errors.Errorf("failed to reconcile subnets: %v", err)
 		}
-		err = networkingService.ReconcileRouter(clusterName, openStackCluster)
+		err = metrics.ObservePhase(clusterControllerName, "router", func() error {
+			return networkingService.ReconcileRouter(clusterName, openStackCluster)
+		})
 		if err != nil {
 			return reconcile.Result{}, errors.Errorf("failed to reconcile router: %v", err)
 		}
+		if openStackCluster.Spec.APIServerVirtualIP != nil {
+			err = metrics.ObservePhase(clusterControllerName, "virtualip", func() error {
+				return networkingService.ReconcileVirtualIP(clusterName, openStackCluster)
+			})
+			if err != nil {
+				return reconcile.Result{}, errors.Errorf("failed to reconcile virtual IP: %v", err)
+			}
+		}
 		if openStackCluster.Spec.ManagedAPIServerLoadBalancer {
-			err = loadbalancerService.ReconcileLoadBalancer(clusterName, openStackCluster)
+			err = metrics.ObservePhase(clusterControllerName, "loadbalancer", func() error {
+				return loadbalancerService.ReconcileLoadBalancer(clusterName, openStackCluster)
+			})
 			if err != nil {
 				return reconcile.Result{}, errors.Errorf("failed to reconcile load balancer: %v", err)
 			}
+			err = metrics.ObservePhase(clusterControllerName, "loadbalancermembers", func() error {
+				openStackMachineNames, err := r.getControlPlaneOpenStackMachineNames(cluster)
+				if err != nil {
+					return err
+				}
+				return loadbalancerService.DeleteStaleLoadBalancerMembers(clusterName, openStackCluster, openStackMachineNames)
+			})
+			if err != nil {
+				return reconcile.Result{}, errors.Errorf("failed to reconcile load balancer members: %v", err)
+			}
 		}
 	}
 
-	err = networkingService.ReconcileSecurityGroups(clusterName, openStackCluster)
+	err = metrics.ObservePhase(clusterControllerName, "securitygroups", func() error {
+		return networkingService.ReconcileSecurityGroups(clusterName, openStackCluster)
+	})
 	if err != nil {
 		return reconcile.Result{}, errors.Errorf("failed to reconcile security groups: %v", err)
 	}
 
-	// Set APIEndpoints so the Cluster API Cluster Controller can pull them
-	if openStackCluster.Spec.ManagedAPIServerLoadBalancer {
-		openStackCluster.Status.APIEndpoints = []infrav1.APIEndpoint{
-			{
-				Host: openStackCluster.Spec.APIServerLoadBalancerFloatingIP,
-				Port: openStackCluster.Spec.APIServerLoadBalancerPort,
-			},
-		}
-	} else {
-		controlPlaneMachine, err := r.getControlPlaneMachine()
+	computeService, err := compute.NewService(osProviderClient, clientOpts)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	err = metrics.ObservePhase(clusterControllerName, "failuredomains", func() error {
+		return computeService.ReconcileFailureDomains(openStackCluster)
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Errorf("failed to reconcile failure domains: %v", err)
+	}
+
+	// Set the ControlPlaneEndpoint so the Cluster API Cluster Controller can pull it
+	if !openStackCluster.Spec.ManagedAPIServerLoadBalancer && openStackCluster.Spec.APIServerVirtualIP == nil {
+		err = r.reconcileControlPlaneMachineEndpoint(cluster, openStackCluster)
 		if err != nil {
-			return reconcile.Result{}, errors.Errorf("failed to get control plane machine: %v", err)
+			return reconcile.Result{}, errors.Errorf("failed to reconcile control plane machine endpoint: %v", err)
 		}
-		if controlPlaneMachine != nil {
-			openStackCluster.Status.APIEndpoints = []infrav1.APIEndpoint{
-				{
-					Host: controlPlaneMachine.Spec.FloatingIP,
-					Port: int(*cluster.Spec.ClusterNetwork.APIServerPort),
-				},
+	} else {
+		if openStackCluster.Spec.ControlPlaneEndpoint.IsZero() {
+			if openStackCluster.Spec.ManagedAPIServerLoadBalancer {
+				// The floating IP of the loadbalancer may have been allocated automatically
+				if host := loadBalancerHost(openStackCluster); host != "" {
+					openStackCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{
+						Host: host,
+						Port: openStackCluster.Spec.APIServerLoadBalancerPort,
+					}
+				} else {
+					klog.Info("No address of the APIServer loadbalancer found yet, could not write OpenStackCluster.Spec.ControlPlaneEndpoint")
+				}
+			} else {
+				if host := virtualIPHost(openStackCluster); host != "" {
+					openStackCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{
+						Host: host,
+						Port: apiServerPort(cluster),
+					}
+				} else {
+					klog.Info("No address of the APIServer virtual IP found yet, could not write OpenStackCluster.Spec.ControlPlaneEndpoint")
+				}
 			}
-		} else {
-			klog.Info("No control plane node found yet, could not write OpenStackCluster.Status.APIEndpoints")
+		}
+		if !openStackCluster.Spec.ControlPlaneEndpoint.IsZero() {
+			openStackCluster.Status.APIEndpoints = []infrav1.APIEndpoint{openStackCluster.Spec.ControlPlaneEndpoint}
 		}
 	}
 
@@ -186,13 +259,33 @@ func (r *OpenStackClusterReconciler) reconcileCluster(logger logr.Logger, cluste
 	return reconcile.Result{}, nil
 }
 
+// reconcileApplicationCredentialExpiry records when the application credential of the cluster
+// expires, and warns when it is about to expire. Failing to look up the expiry doesn't block
+// the reconciliation, as the application credential may not be allowed to read itself.
+func reconcileApplicationCredentialExpiry(osProviderClient *gophercloud.ProviderClient, openStackCluster *infrav1.OpenStackCluster) {
+	expiresAt, err := provider.GetApplicationCredentialExpiry(osProviderClient)
+	if err != nil {
+		klog.Warningf("Failed to get application credential expiry of OpenStackCluster %s/%s: %v", openStackCluster.Namespace, openStackCluster.Name, err)
+		return
+	}
+	if expiresAt == nil {
+		openStackCluster.Status.ApplicationCredentialExpiresAt = nil
+		return
+	}
+	openStackCluster.Status.ApplicationCredentialExpiresAt = &metav1.Time{Time: *expiresAt}
+	if time.Until(*expiresAt) < provider.ApplicationCredentialExpiryWarningPeriod {
+		record.Warnf(openStackCluster, "ApplicationCredentialExpiring", "Application credential expires at %s, rotate the credentials in the clouds secret", expiresAt.Format(time.RFC3339))
+	}
+}
+
 func (r *OpenStackClusterReconciler) reconcileClusterDelete(logger logr.Logger, cluster *v1alpha2.Cluster, openStackCluster *infrav1.OpenStackCluster) (ctrl.Result, error) {
 
 	klog.Infof("Reconcile Cluster delete %s/%s", cluster.Namespace, cluster.Name)
 	clusterName := fmt.Sprintf("%s-%s", cluster.Namespace, cluster.Name)
 	osProviderClient, clientOpts, err := provider.NewClientFromCluster(r.Client, openStackCluster)
+	setAuthenticatedCondition(openStackCluster, &openStackCluster.Status.Conditions, err)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{RequeueAfter: waitForCredentialsDuration}, nil
 	}
 
 	networkingService, err := networking.NewService(osProviderClient, clientOpts)
@@ -212,10 +305,17 @@ func (r *OpenStackClusterReconciler) reconcileClusterDelete(logger logr.Logger,
 		}
 	}
 
+	if openStackCluster.Spec.APIServerVirtualIP != nil {
+		err = networkingService.DeleteVirtualIP(clusterName, openStackCluster)
+		if err != nil {
+			return reconcile.Result{}, errors.Errorf("failed to delete virtual IP: %v", err)
+		}
+	}
+
 	// Delete other things
 	if openStackCluster.Status.GlobalSecurityGroup != nil {
 		klog.Infof("Deleting global security group %q", openStackCluster.Status.GlobalSecurityGroup.Name)
-		err := networkingService.DeleteSecurityGroups(openStackCluster.Status.GlobalSecurityGroup)
+		err := networkingService.DeleteSecurityGroups(openStackCluster, openStackCluster.Status.GlobalSecurityGroup)
 		if err != nil {
 			return reconcile.Result{}, errors.Errorf("failed to delete security group: %v", err)
 		}
@@ -223,7 +323,7 @@ func (r *OpenStackClusterReconciler) reconcileClusterDelete(logger logr.Logger,
 
 	if openStackCluster.Status.ControlPlaneSecurityGroup != nil {
 		klog.Infof("Deleting control plane security group %q", openStackCluster.Status.ControlPlaneSecurityGroup.Name)
-		err := networkingService.DeleteSecurityGroups(openStackCluster.Status.ControlPlaneSecurityGroup)
+		err := networkingService.DeleteSecurityGroups(openStackCluster, openStackCluster.Status.ControlPlaneSecurityGroup)
 		if err != nil {
 			return reconcile.Result{}, errors.Errorf("failed to delete security group: %v", err)
 		}
@@ -238,36 +338,320 @@ func (r *OpenStackClusterReconciler) reconcileClusterDelete(logger logr.Logger,
 }
 
 func (r *OpenStackClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.OpenStackCluster{}).
-		Complete(r)
+		Watches(
+			&source.Kind{Type: &v1alpha2.Cluster{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: util.ClusterToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("OpenStackCluster")),
+			},
+		).
+		Watches(
+			&source.Kind{Type: &infrav1.OpenStackClusterIdentity{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.OpenStackClusterIdentityToOpenStackClusters)},
+		).
+		Watches(
+			&source.Kind{Type: &infrav1.OpenStackMachine{}},
+			handler.Funcs{
+				UpdateFunc: r.enqueueOpenStackClusterOfUpdatedOpenStackMachine,
+				DeleteFunc: r.enqueueOpenStackClusterOfDeletedOpenStackMachine,
+			},
+		).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	// The builder doesn't support predicates for a single watch.
+	err = c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.SecretToOpenStackClusters)},
+		cloudsSecretPredicate,
+	)
+	if err != nil {
+		return err
+	}
+	return c.Watch(&source.Kind{Type: &corev1.Secret{}}, handler.Funcs{DeleteFunc: invalidateDeletedSecret}, cloudsSecretPredicate)
+}
+
+// enqueueOpenStackClusterOfUpdatedOpenStackMachine reconciles the OpenStackCluster of an
+// OpenStackMachine whose readiness, health or addresses changed, so the APIEndpoints of the
+// cluster are updated and the control plane endpoint fails over if its machine is unhealthy.
+func (r *OpenStackClusterReconciler) enqueueOpenStackClusterOfUpdatedOpenStackMachine(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldMachine, ok := e.ObjectOld.(*infrav1.OpenStackMachine)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a OpenStackMachine but got a %T", e.ObjectOld), "failed to get OpenStackCluster for OpenStackMachine")
+		return
+	}
+	newMachine, ok := e.ObjectNew.(*infrav1.OpenStackMachine)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a OpenStackMachine but got a %T", e.ObjectNew), "failed to get OpenStackCluster for OpenStackMachine")
+		return
+	}
+	if oldMachine.Status.Ready == newMachine.Status.Ready &&
+		isHealthy(oldMachine) == isHealthy(newMachine) &&
+		oldMachine.Status.FloatingIP == newMachine.Status.FloatingIP &&
+		reflect.DeepEqual(oldMachine.Status.Addresses, newMachine.Status.Addresses) &&
+		oldMachine.DeletionTimestamp.IsZero() == newMachine.DeletionTimestamp.IsZero() {
+		return
+	}
+	r.enqueueOpenStackClusterOfOpenStackMachine(newMachine, q)
+}
+
+// enqueueOpenStackClusterOfDeletedOpenStackMachine reconciles the OpenStackCluster of a deleted
+// OpenStackMachine, so the load balancer members of the machine are removed even if the
+// OpenStackMachine was deleted without its finalizer.
+func (r *OpenStackClusterReconciler) enqueueOpenStackClusterOfDeletedOpenStackMachine(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	openStackMachine, ok := e.Object.(*infrav1.OpenStackMachine)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a OpenStackMachine but got a %T", e.Object), "failed to get OpenStackCluster for OpenStackMachine")
+		return
+	}
+	r.enqueueOpenStackClusterOfOpenStackMachine(openStackMachine, q)
+}
+
+func (r *OpenStackClusterReconciler) enqueueOpenStackClusterOfOpenStackMachine(openStackMachine *infrav1.OpenStackMachine, q workqueue.RateLimitingInterface) {
+	// OpenStackMachines which don't belong to a cluster don't affect any OpenStackCluster
+	if openStackMachine.Labels[v1alpha2.MachineClusterLabelName] == "" {
+		return
+	}
+	cluster, err := util.GetClusterFromMetadata(context.Background(), r.Client, openStackMachine.ObjectMeta)
+	if err != nil {
+		r.Log.Error(err, "failed to get Cluster", "OpenStackMachine", openStackMachine.Name, "Namespace", openStackMachine.Namespace)
+		return
+	}
+	if cluster.Spec.InfrastructureRef == nil {
+		return
+	}
+	q.Add(ctrl.Request{NamespacedName: client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.InfrastructureRef.Name}})
+}
+
+// getControlPlaneOpenStackMachineNames returns the names of the OpenStackMachines of the
+// control plane machines of the cluster which exist and aren't being deleted.
+func (r *OpenStackClusterReconciler) getControlPlaneOpenStackMachineNames(cluster *v1alpha2.Cluster) ([]string, error) {
+	openStackMachines, err := r.getControlPlaneOpenStackMachines(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, openStackMachine := range openStackMachines {
+		names = append(names, openStackMachine.Name)
+	}
+	return names, nil
 }
 
-func (r *OpenStackClusterReconciler) getControlPlaneMachine() (*infrav1.OpenStackMachine, error) {
+// getControlPlaneOpenStackMachines returns the OpenStackMachines of the control plane machines
+// of the cluster which exist and aren't being deleted, ordered by their creation.
+func (r *OpenStackClusterReconciler) getControlPlaneOpenStackMachines(cluster *v1alpha2.Cluster) ([]infrav1.OpenStackMachine, error) {
+	labels := map[string]string{v1alpha2.MachineClusterLabelName: cluster.Name}
 	machines := &v1alpha2.MachineList{}
-	if err := r.Client.List(context.Background(), machines); err != nil {
+	if err := r.Client.List(context.Background(), machines, client.InNamespace(cluster.Namespace), client.MatchingLabels(labels)); err != nil {
 		return nil, err
 	}
 	openStackMachines := &infrav1.OpenStackMachineList{}
-	if err := r.Client.List(context.Background(), openStackMachines); err != nil {
+	if err := r.Client.List(context.Background(), openStackMachines, client.InNamespace(cluster.Namespace)); err != nil {
 		return nil, err
 	}
 
-	var controlPlaneMachine *v1alpha2.Machine
-	for _, machine := range machines.Items {
-		if util.IsControlPlaneMachine(&machine) {
-			controlPlaneMachine = &machine
-			break
+	live := map[string]infrav1.OpenStackMachine{}
+	for _, openStackMachine := range openStackMachines.Items {
+		if openStackMachine.DeletionTimestamp.IsZero() {
+			live[openStackMachine.Name] = openStackMachine
 		}
 	}
-	if controlPlaneMachine == nil {
-		return nil, nil
+	var result []infrav1.OpenStackMachine
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if !util.IsControlPlaneMachine(machine) || !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if openStackMachine, ok := live[machine.Spec.InfrastructureRef.Name]; ok {
+			result = append(result, openStackMachine)
+		}
 	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreationTimestamp.Equal(&result[j].CreationTimestamp) {
+			return result[i].CreationTimestamp.Before(&result[j].CreationTimestamp)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
 
-	for _, openStackMachine := range openStackMachines.Items {
-		if openStackMachine.Name == controlPlaneMachine.Name {
-			return &openStackMachine, nil
+// SecretToOpenStackClusters maps a clouds secret to the OpenStackClusters referencing it,
+// so they are reconciled with the new credentials when the secret is rotated.
+func (r *OpenStackClusterReconciler) SecretToOpenStackClusters(o handler.MapObject) []ctrl.Request {
+	var result []ctrl.Request
+
+	s, ok := o.Object.(*corev1.Secret)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Secret but got a %T", o.Object), "failed to get OpenStackClusters for Secret")
+		return nil
+	}
+
+	clusters, err := openStackClustersUsingSecret(context.Background(), r.Client, s)
+	if err != nil {
+		r.Log.Error(err, "failed to list OpenStackClusters", "Secret", s.Name, "Namespace", s.Namespace)
+		return nil
+	}
+	for _, c := range clusters {
+		name := client.ObjectKey{Namespace: c.Namespace, Name: c.Name}
+		result = append(result, ctrl.Request{NamespacedName: name})
+	}
+
+	return result
+}
+
+// OpenStackClusterIdentityToOpenStackClusters maps an OpenStackClusterIdentity to the OpenStackClusters referencing it.
+func (r *OpenStackClusterReconciler) OpenStackClusterIdentityToOpenStackClusters(o handler.MapObject) []ctrl.Request {
+	var result []ctrl.Request
+
+	identity, ok := o.Object.(*infrav1.OpenStackClusterIdentity)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a OpenStackClusterIdentity but got a %T", o.Object), "failed to get OpenStackClusters for OpenStackClusterIdentity")
+		return nil
+	}
+
+	clusterList := &infrav1.OpenStackClusterList{}
+	if err := r.List(context.Background(), clusterList); err != nil {
+		r.Log.Error(err, "failed to list OpenStackClusters", "OpenStackClusterIdentity", identity.Name)
+		return nil
+	}
+	for _, c := range clusterList.Items {
+		if c.Spec.IdentityRef != nil && c.Spec.IdentityRef.Name == identity.Name {
+			name := client.ObjectKey{Namespace: c.Namespace, Name: c.Name}
+			result = append(result, ctrl.Request{NamespacedName: name})
 		}
 	}
-	return nil, nil
+
+	return result
+}
+
+// loadBalancerHost returns the floating IP of the APIServer loadbalancer, or its VIP
+// if the floating IP is disabled.
+func loadBalancerHost(openStackCluster *infrav1.OpenStackCluster) string {
+	if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.APIServerLoadBalancer == nil {
+		return ""
+	}
+	if openStackCluster.Spec.DisableAPIServerFloatingIP {
+		return openStackCluster.Status.Network.APIServerLoadBalancer.InternalIP
+	}
+	return openStackCluster.Status.Network.APIServerLoadBalancer.IP
+}
+
+// virtualIPHost returns the floating IP of the virtual IP of the APIServer, or the virtual
+// IP itself if the floating IP is disabled or there is no external network.
+func virtualIPHost(openStackCluster *infrav1.OpenStackCluster) string {
+	if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.APIServerVirtualIP == nil {
+		return ""
+	}
+	if openStackCluster.Status.Network.APIServerVirtualIP.IP != "" {
+		return openStackCluster.Status.Network.APIServerVirtualIP.IP
+	}
+	return openStackCluster.Status.Network.APIServerVirtualIP.InternalIP
+}
+
+// machineHost returns the floating IP of the control plane machine, or its fixed IP if
+// the floating IP is disabled.
+func machineHost(openStackCluster *infrav1.OpenStackCluster, openStackMachine *infrav1.OpenStackMachine) string {
+	if openStackMachine == nil {
+		return ""
+	}
+	if openStackCluster.Spec.DisableAPIServerFloatingIP {
+		for _, address := range openStackMachine.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				return address.Address
+			}
+		}
+		return ""
+	}
+	if openStackMachine.Status.FloatingIP != "" {
+		return openStackMachine.Status.FloatingIP
+	}
+	return openStackMachine.Spec.FloatingIP
+}
+
+// reconcileControlPlaneMachineEndpoint publishes the endpoints of the ready control plane
+// machines of a cluster without an APIServer loadbalancer or virtual IP, and selects the
+// control plane endpoint from them once. The selected machine fails over to another ready
+// machine if it is deleted or unhealthy, which only changes the status: the control plane
+// endpoint in the spec is immutable, and the endpoint of the selected machine is published
+// first in the API endpoints.
+func (r *OpenStackClusterReconciler) reconcileControlPlaneMachineEndpoint(cluster *v1alpha2.Cluster, openStackCluster *infrav1.OpenStackCluster) error {
+	openStackMachines, err := r.getControlPlaneOpenStackMachines(cluster)
+	if err != nil {
+		return err
+	}
+	port := apiServerPort(cluster)
+
+	var endpoints []infrav1.APIEndpoint
+	var selected, first, firstReady *infrav1.OpenStackMachine
+	for i := range openStackMachines {
+		openStackMachine := &openStackMachines[i]
+		host := machineHost(openStackCluster, openStackMachine)
+		if host == "" || !isHealthy(openStackMachine) {
+			continue
+		}
+		endpoint := infrav1.APIEndpoint{Host: host, Port: port}
+		if first == nil {
+			first = openStackMachine
+		}
+		if openStackMachine.Status.Ready {
+			endpoints = append(endpoints, endpoint)
+			if firstReady == nil {
+				firstReady = openStackMachine
+			}
+		}
+		if openStackMachine.Name == openStackCluster.Status.ControlPlaneMachine ||
+			(openStackCluster.Status.ControlPlaneMachine == "" && endpoint == openStackCluster.Spec.ControlPlaneEndpoint) {
+			selected = openStackMachine
+		}
+	}
+	openStackCluster.Status.APIEndpoints = endpoints
+
+	switch {
+	case openStackCluster.Spec.ControlPlaneEndpoint.IsZero():
+		// The first control plane machine isn't ready before it's bootstrapped with the endpoint
+		if first == nil {
+			klog.Info("No control plane node with an address found yet, could not write OpenStackCluster.Spec.ControlPlaneEndpoint")
+			return nil
+		}
+		selected = first
+		openStackCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: machineHost(openStackCluster, selected), Port: port}
+	case selected == nil && openStackCluster.Status.ControlPlaneMachine != "":
+		if firstReady == nil {
+			klog.Infof("Control plane node %s is unavailable, but no other control plane node is ready", openStackCluster.Status.ControlPlaneMachine)
+			return nil
+		}
+		klog.Infof("Control plane node %s is unavailable, failing over to %s, the control plane endpoint %s:%d is kept",
+			openStackCluster.Status.ControlPlaneMachine, firstReady.Name, openStackCluster.Spec.ControlPlaneEndpoint.Host, openStackCluster.Spec.ControlPlaneEndpoint.Port)
+		selected = firstReady
+	case selected == nil:
+		// The control plane endpoint wasn't selected from the control plane machines
+		return nil
+	}
+
+	openStackCluster.Status.ControlPlaneMachine = selected.Name
+	selectedEndpoint := infrav1.APIEndpoint{Host: machineHost(openStackCluster, selected), Port: port}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i] == selectedEndpoint && endpoints[j] != selectedEndpoint
+	})
+	return nil
+}
+
+// isHealthy returns whether the instance of an OpenStackMachine is usable, which includes
+// instances which are still being built.
+func isHealthy(openStackMachine *infrav1.OpenStackMachine) bool {
+	if openStackMachine.Status.FailureReason != nil {
+		return false
+	}
+	state := openStackMachine.Status.InstanceState
+	return state == nil || *state == infrav1.InstanceStateActive || *state == infrav1.InstanceStateBuilding
+}
+
+// apiServerPort returns the port of the APIServer of the cluster, which defaults to 6443.
+func apiServerPort(cluster *v1alpha2.Cluster) int {
+	if cluster.Spec.ClusterNetwork == nil || cluster.Spec.ClusterNetwork.APIServerPort == nil {
+		return defaultAPIServerPort
+	}
+	return int(*cluster.Spec.ClusterNetwork.APIServerPort)
 }
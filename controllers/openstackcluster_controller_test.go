@@ -0,0 +1,444 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/pointer"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const testCloudName = "openstack"
+
+// testEnvironment is a namespace with a clouds secret for a fake cloud, and a Cluster
+// with its OpenStackCluster using the secret.
+type testEnvironment struct {
+	ctx              context.Context
+	cloud            *fake.Cloud
+	namespace        string
+	cluster          *clusterv1.Cluster
+	openStackCluster *infrav1.OpenStackCluster
+}
+
+func newTestEnvironment() *testEnvironment {
+	e := &testEnvironment{ctx: context.Background(), cloud: fake.NewCloud()}
+	externalNetworkID := e.cloud.AddNetwork("public", true)
+	e.cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-"}}
+	Expect(k8sClient.Create(e.ctx, namespace)).To(Succeed())
+	e.namespace = namespace.Name
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: "cloud-config"},
+		Data:       map[string][]byte{"clouds.yaml": e.cloud.CloudsYAML(testCloudName)},
+	}
+	Expect(k8sClient.Create(e.ctx, secret)).To(Succeed())
+
+	e.cluster = &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: "cluster"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "OpenStackCluster",
+				Name:       "cluster",
+			},
+		},
+	}
+	Expect(k8sClient.Create(e.ctx, e.cluster)).To(Succeed())
+
+	e.openStackCluster = &infrav1.OpenStackCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: e.namespace,
+			Name:      "cluster",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       e.cluster.Name,
+				UID:        e.cluster.UID,
+			}},
+		},
+		Spec: infrav1.OpenStackClusterSpec{
+			CloudsSecret:                    &corev1.SecretReference{Name: secret.Name},
+			CloudName:                       testCloudName,
+			NodeCIDR:                        "10.6.0.0/24",
+			ExternalNetworkID:               externalNetworkID,
+			ManagedSecurityGroups:           true,
+			ManagedAPIServerLoadBalancer:    true,
+			UseOctavia:                      true,
+			APIServerLoadBalancerFloatingIP: "172.24.4.10",
+			APIServerLoadBalancerPort:       6443,
+		},
+	}
+	return e
+}
+
+func (e *testEnvironment) close() {
+	e.cloud.Close()
+}
+
+func (e *testEnvironment) reconcileCluster() (ctrl.Result, error) {
+	r := &OpenStackClusterReconciler{Client: k8sClient, Log: log.Log}
+	return r.Reconcile(ctrl.Request{NamespacedName: client.ObjectKey{Namespace: e.namespace, Name: e.openStackCluster.Name}})
+}
+
+// getCluster refreshes the OpenStackCluster of the environment.
+func (e *testEnvironment) getCluster() *infrav1.OpenStackCluster {
+	Expect(k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: e.openStackCluster.Name}, e.openStackCluster)).To(Succeed())
+	return e.openStackCluster
+}
+
+// requestIndex returns the index of the first request to the cloud matching the service, method and path prefix, or -1.
+func (e *testEnvironment) requestIndex(service, method, pathPrefix string) int {
+	for i, r := range e.cloud.Requests() {
+		if r.Service == service && r.Method == method && strings.HasPrefix(r.Path, pathPrefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+var _ = Describe("OpenStackClusterReconciler", func() {
+	var e *testEnvironment
+
+	BeforeEach(func() {
+		e = newTestEnvironment()
+	})
+
+	AfterEach(func() {
+		e.close()
+	})
+
+	It("waits for the owner reference of the Cluster", func() {
+		e.openStackCluster.OwnerReferences = nil
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e.getCluster().Finalizers).To(BeEmpty())
+		Expect(e.cloud.Requests()).To(BeEmpty())
+	})
+
+	It("doesn't reconcile the cluster of a paused Cluster", func() {
+		e.cluster.Annotations = map[string]string{infrav1.PausedAnnotation: "true"}
+		Expect(k8sClient.Update(e.ctx, e.cluster)).To(Succeed())
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e.getCluster().Finalizers).To(BeEmpty())
+		Expect(e.cloud.Requests()).To(BeEmpty())
+	})
+
+	It("creates the cluster infrastructure and becomes ready", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		openStackCluster := e.getCluster()
+		Expect(openStackCluster.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+		Expect(openStackCluster.Status.Ready).To(BeTrue())
+		Expect(infrav1.GetCondition(openStackCluster.Status.Conditions, infrav1.AuthenticatedCondition).Status).To(Equal(corev1.ConditionTrue))
+
+		network := openStackCluster.Status.Network
+		Expect(network).NotTo(BeNil())
+		Expect(network.ID).NotTo(BeEmpty())
+		Expect(network.Subnet.CIDR).To(Equal("10.6.0.0/24"))
+		Expect(network.Router.ID).NotTo(BeEmpty())
+		Expect(network.APIServerLoadBalancer.IP).To(Equal("172.24.4.10"))
+		Expect(openStackCluster.Status.ControlPlaneSecurityGroup.ID).NotTo(BeEmpty())
+		Expect(openStackCluster.Status.GlobalSecurityGroup.ID).NotTo(BeEmpty())
+		Expect(openStackCluster.Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: "172.24.4.10", Port: 6443}))
+		Expect(openStackCluster.Status.FailureDomains).To(HaveKey("nova"))
+
+		Expect(e.cloud.Resources("loadbalancers")).To(HaveLen(1))
+		Expect(e.cloud.Resources("routers")).To(HaveLen(1))
+	})
+
+	It("uses the load balancer VIP as the endpoint of a private cluster", func() {
+		e.openStackCluster.Spec.ExternalNetworkID = ""
+		e.openStackCluster.Spec.APIServerLoadBalancerFloatingIP = ""
+		e.openStackCluster.Spec.DisableAPIServerFloatingIP = true
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		openStackCluster := e.getCluster()
+		Expect(openStackCluster.Status.Ready).To(BeTrue())
+		lb := openStackCluster.Status.Network.APIServerLoadBalancer
+		Expect(lb.IP).To(BeEmpty())
+		Expect(lb.InternalIP).NotTo(BeEmpty())
+		Expect(openStackCluster.Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: lb.InternalIP, Port: 6443}))
+		Expect(e.cloud.Resources("routers")).To(BeEmpty())
+		Expect(e.cloud.Resources("floatingips")).To(BeEmpty())
+	})
+
+	It("uses the floating IP of the virtual IP as the endpoint without a load balancer", func() {
+		e.cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{APIServerPort: pointer.Int32Ptr(6443)}
+		Expect(k8sClient.Update(e.ctx, e.cluster)).To(Succeed())
+		e.openStackCluster.Spec.ManagedAPIServerLoadBalancer = false
+		e.openStackCluster.Spec.APIServerVirtualIP = &infrav1.APIServerVirtualIP{Address: "10.6.0.10", FloatingIP: "172.24.4.10"}
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		openStackCluster := e.getCluster()
+		vip := openStackCluster.Status.Network.APIServerVirtualIP
+		Expect(vip).NotTo(BeNil())
+		Expect(vip.InternalIP).To(Equal("10.6.0.10"))
+		Expect(openStackCluster.Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: "172.24.4.10", Port: 6443}))
+		Expect(e.cloud.Resources("loadbalancers")).To(BeEmpty())
+
+		Expect(k8sClient.Delete(e.ctx, openStackCluster)).To(Succeed())
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Resources("floatingips")).To(BeEmpty())
+	})
+
+	It("fails over to another ready control plane machine in the status", func() {
+		e.cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{APIServerPort: pointer.Int32Ptr(6443)}
+		Expect(k8sClient.Update(e.ctx, e.cluster)).To(Succeed())
+		e.openStackCluster.Spec.ManagedAPIServerLoadBalancer = false
+		e.openStackCluster.Spec.APIServerLoadBalancerFloatingIP = ""
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		first := e.createControlPlaneMachine("control-plane-0", "172.24.4.20", false)
+		e.createControlPlaneMachine("control-plane-1", "172.24.4.21", true)
+
+		// The first control plane machine is selected before it is ready.
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		openStackCluster := e.getCluster()
+		Expect(openStackCluster.Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: "172.24.4.20", Port: 6443}))
+		Expect(openStackCluster.Status.ControlPlaneMachine).To(Equal("control-plane-0"))
+		Expect(openStackCluster.Status.APIEndpoints).To(Equal([]infrav1.APIEndpoint{{Host: "172.24.4.21", Port: 6443}}))
+
+		reason := capierrors.CreateMachineError
+		first.Status.FailureReason = &reason
+		Expect(k8sClient.Status().Update(e.ctx, first)).To(Succeed())
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		openStackCluster = e.getCluster()
+		Expect(openStackCluster.Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: "172.24.4.20", Port: 6443}))
+		Expect(openStackCluster.Status.ControlPlaneMachine).To(Equal("control-plane-1"))
+		Expect(openStackCluster.Status.APIEndpoints).To(Equal([]infrav1.APIEndpoint{{Host: "172.24.4.21", Port: 6443}}))
+	})
+
+	It("reuses the existing infrastructure when reconciling again", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		status := e.getCluster().Status.DeepCopy()
+
+		e.cloud.ResetRequests()
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e.cloud.CountRequests(fake.ServiceNetwork, http.MethodPost, ".")).To(BeZero())
+		Expect(e.cloud.CountRequests(fake.ServiceLoadBalancer, http.MethodPost, ".")).To(BeZero())
+		openStackCluster := e.getCluster()
+		Expect(openStackCluster.Status.Network).To(Equal(status.Network))
+		Expect(openStackCluster.Status.Ready).To(BeTrue())
+	})
+
+	It("reports authentication failures and recovers when the secret is fixed", func() {
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: "cloud-config"}, secret)).To(Succeed())
+		secret.Data["clouds.yaml"] = []byte(strings.Replace(string(secret.Data["clouds.yaml"]), fake.Password, "wrong", 1))
+		Expect(k8sClient.Update(e.ctx, secret)).To(Succeed())
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+
+		result, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(waitForCredentialsDuration))
+		openStackCluster := e.getCluster()
+		Expect(openStackCluster.Status.Ready).To(BeFalse())
+		Expect(infrav1.GetCondition(openStackCluster.Status.Conditions, infrav1.AuthenticatedCondition).Status).To(Equal(corev1.ConditionFalse))
+
+		secret.Data["clouds.yaml"] = e.cloud.CloudsYAML(testCloudName)
+		Expect(k8sClient.Update(e.ctx, secret)).To(Succeed())
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		openStackCluster = e.getCluster()
+		Expect(openStackCluster.Status.Ready).To(BeTrue())
+		Expect(infrav1.GetCondition(openStackCluster.Status.Conditions, infrav1.AuthenticatedCondition).Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("maps only clouds secrets to the clusters using them", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: "cloud-config"}, secret)).To(Succeed())
+		other := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: "other"},
+			Data:       map[string][]byte{"token": []byte("token")},
+		}
+
+		Expect(cloudsSecretPredicate.Create(event.CreateEvent{Meta: secret, Object: secret})).To(BeTrue())
+		Expect(cloudsSecretPredicate.Create(event.CreateEvent{Meta: other, Object: other})).To(BeFalse())
+		Expect(cloudsSecretPredicate.Update(event.UpdateEvent{MetaOld: secret, ObjectOld: secret, MetaNew: other, ObjectNew: other})).To(BeTrue())
+
+		r := &OpenStackClusterReconciler{Client: k8sClient, Log: log.Log}
+		Expect(r.SecretToOpenStackClusters(handler.MapObject{Meta: secret, Object: secret})).To(ConsistOf(
+			ctrl.Request{NamespacedName: client.ObjectKey{Namespace: e.namespace, Name: e.openStackCluster.Name}},
+		))
+	})
+
+	It("enqueues the cluster of deleted OpenStackMachines with the cluster label", func() {
+		r := &OpenStackClusterReconciler{Client: k8sClient, Log: log.Log}
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		openStackMachine := &infrav1.OpenStackMachine{ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: "machine"}}
+
+		r.enqueueOpenStackClusterOfDeletedOpenStackMachine(event.DeleteEvent{Meta: openStackMachine, Object: openStackMachine}, q)
+		Expect(q.Len()).To(Equal(0))
+
+		openStackMachine.Labels = map[string]string{clusterv1.MachineClusterLabelName: e.cluster.Name}
+		r.enqueueOpenStackClusterOfDeletedOpenStackMachine(event.DeleteEvent{Meta: openStackMachine, Object: openStackMachine}, q)
+		Expect(q.Len()).To(Equal(1))
+		item, _ := q.Get()
+		Expect(item).To(Equal(ctrl.Request{NamespacedName: client.ObjectKey{Namespace: e.namespace, Name: e.openStackCluster.Name}}))
+	})
+
+	It("recovers from API faults", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		e.cloud.InjectFault(fake.Fault{Service: fake.ServiceNetwork, Method: http.MethodPost, Path: "^routers$", StatusCode: http.StatusInternalServerError, Times: 1})
+		e.cloud.InjectFault(fake.Fault{Service: fake.ServiceLoadBalancer, Method: http.MethodPost, Path: "^lbaas/listeners$", StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+		_, err := e.reconcileCluster()
+		Expect(err).To(HaveOccurred())
+		Expect(e.getCluster().Status.Ready).To(BeFalse())
+
+		_, err = e.reconcileCluster()
+		Expect(err).To(HaveOccurred())
+		Expect(e.getCluster().Status.Ready).To(BeFalse())
+
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.getCluster().Status.Ready).To(BeTrue())
+		Expect(e.cloud.Resources("networks")).To(HaveLen(2))
+		Expect(e.cloud.Resources("routers")).To(HaveLen(1))
+		Expect(e.cloud.Resources("loadbalancers")).To(HaveLen(1))
+		Expect(e.cloud.Resources("listeners")).To(HaveLen(1))
+	})
+
+	It("deletes the load balancer before the security groups and removes the finalizer", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		e.cloud.ResetRequests()
+		Expect(k8sClient.Delete(e.ctx, e.getCluster())).To(Succeed())
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: e.openStackCluster.Name}, &infrav1.OpenStackCluster{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(e.cloud.Resources("loadbalancers")).To(BeEmpty())
+		for _, group := range e.cloud.Resources("security-groups") {
+			Expect(group["name"]).To(Equal("default"))
+		}
+		loadBalancerDelete := e.requestIndex(fake.ServiceLoadBalancer, http.MethodDelete, "lbaas/loadbalancers/")
+		securityGroupDelete := e.requestIndex(fake.ServiceNetwork, http.MethodDelete, "security-groups/")
+		Expect(loadBalancerDelete).To(BeNumerically(">=", 0))
+		Expect(securityGroupDelete).To(BeNumerically(">", loadBalancerDelete))
+	})
+
+	It("keeps the finalizer when the deletion fails", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(k8sClient.Delete(e.ctx, e.getCluster())).To(Succeed())
+		e.cloud.InjectFault(fake.Fault{Service: fake.ServiceNetwork, Method: http.MethodDelete, Path: "^security-groups/", StatusCode: http.StatusConflict, Times: 1})
+		_, err = e.reconcileCluster()
+		Expect(err).To(HaveOccurred())
+		Expect(e.getCluster().Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: e.openStackCluster.Name}, &infrav1.OpenStackCluster{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+// createControlPlaneMachine creates a control plane Machine of the Cluster with its OpenStackMachine,
+// which has the floating IP and readiness in its status.
+func (e *testEnvironment) createControlPlaneMachine(name, floatingIP string, ready bool) *infrav1.OpenStackMachine {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: e.namespace,
+			Name:      name,
+			Labels: map[string]string{
+				clusterv1.MachineClusterLabelName:      e.cluster.Name,
+				clusterv1.MachineControlPlaneLabelName: "true",
+			},
+		},
+		Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: infrav1.GroupVersion.String(),
+				Kind:       "OpenStackMachine",
+				Name:       name,
+			},
+		},
+	}
+	Expect(k8sClient.Create(e.ctx, machine)).To(Succeed())
+	openStackMachine := &infrav1.OpenStackMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: e.namespace,
+			Name:      name,
+			Labels:    map[string]string{clusterv1.MachineClusterLabelName: e.cluster.Name},
+		},
+		Spec: infrav1.OpenStackMachineSpec{Flavor: "m1.medium", Image: "ubuntu"},
+	}
+	Expect(k8sClient.Create(e.ctx, openStackMachine)).To(Succeed())
+	openStackMachine.Status.Ready = ready
+	openStackMachine.Status.FloatingIP = floatingIP
+	Expect(k8sClient.Status().Update(e.ctx, openStackMachine)).To(Succeed())
+	return openStackMachine
+}
+
+// setInfrastructureReady marks the infrastructure of the Cluster ready, like the Cluster API cluster controller does.
+func (e *testEnvironment) setInfrastructureReady() {
+	cluster := &clusterv1.Cluster{}
+	Expect(k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: e.cluster.Name}, cluster)).To(Succeed())
+	cluster.Status.InfrastructureReady = true
+	cluster.Status.APIEndpoints = []clusterv1.APIEndpoint{{Host: "172.24.4.10", Port: 6443}}
+	Expect(k8sClient.Status().Update(e.ctx, cluster)).To(Succeed())
+	e.cluster = cluster
+}
@@ -22,16 +22,19 @@ import (
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/utils/openstack/clientconfig"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
 	"k8s.io/utils/pointer"
 	"net"
 	"os"
+	"sort"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/compute"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/loadbalancer"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/metrics"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -46,7 +49,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 )
@@ -67,6 +70,7 @@ type OpenStackMachineReconciler struct {
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=openstackmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=openstackmachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=openstackmachinetemplates,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machines,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
 
@@ -110,6 +114,11 @@ func (r *OpenStackMachineReconciler) Reconcile(request ctrl.Request) (_ ctrl.Res
 
 	logger = logger.WithName(fmt.Sprintf("cluster=%s", cluster.Name))
 
+	if infrav1.IsPaused(cluster, openStackMachine) {
+		logger.Info("OpenStackMachine or linked Cluster is marked as paused, not reconciling")
+		return reconcile.Result{}, nil
+	}
+
 	openStackCluster := &infrav1.OpenStackCluster{}
 	openStackClusterName := types.NamespacedName{
 		Namespace: openStackMachine.Namespace,
@@ -147,7 +156,7 @@ func (r *OpenStackMachineReconciler) Reconcile(request ctrl.Request) (_ ctrl.Res
 
 func (r *OpenStackMachineReconciler) reconcileMachine(logger logr.Logger, machine *clusterv1.Machine, openStackMachine *infrav1.OpenStackMachine, cluster *clusterv1.Cluster, openStackCluster *infrav1.OpenStackCluster) (_ ctrl.Result, reterr error) {
 	// If the OpenStackMachine is in an error state, return early.
-	if openStackMachine.Status.ErrorReason != nil || openStackMachine.Status.ErrorMessage != nil {
+	if openStackMachine.Status.FailureReason != nil || openStackMachine.Status.FailureMessage != nil {
 		logger.Info("Error state detected, skipping reconciliation")
 		return reconcile.Result{}, nil
 	}
@@ -172,9 +181,10 @@ func (r *OpenStackMachineReconciler) reconcileMachine(logger logr.Logger, machin
 
 	clusterName := fmt.Sprintf("%s-%s", cluster.ObjectMeta.Namespace, cluster.Name)
 
-	osProviderClient, clientOpts, err := provider.NewClientFromMachine(r.Client, openStackMachine)
+	osProviderClient, clientOpts, err := provider.NewClientFromMachine(r.Client, openStackCluster, openStackMachine)
+	setAuthenticatedCondition(openStackMachine, &openStackMachine.Status.Conditions, err)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{RequeueAfter: waitForCredentialsDuration}, nil
 	}
 
 	computeService, err := compute.NewService(osProviderClient, clientOpts)
@@ -187,7 +197,11 @@ func (r *OpenStackMachineReconciler) reconcileMachine(logger logr.Logger, machin
 		return reconcile.Result{}, err
 	}
 
-	instance, err := r.getOrCreate(computeService, machine, openStackMachine, cluster, openStackCluster)
+	var instance *compute.Instance
+	err = metrics.ObservePhase(machineControllerName, "instance", func() (err error) {
+		instance, err = r.getOrCreate(computeService, machine, openStackMachine, cluster, openStackCluster)
+		return err
+	})
 	if err != nil {
 		handleMachineError(openStackMachine, capierrors.UpdateMachineError, errors.Errorf("OpenStack instance cannot be created: %v", err))
 		return reconcile.Result{}, err
@@ -199,12 +213,17 @@ func (r *OpenStackMachineReconciler) reconcileMachine(logger logr.Logger, machin
 		return reconcile.Result{}, nil
 	}
 
-	// TODO(sbueringer) From CAPA: TODO(ncdc): move this validation logic into a validating webhook (for us: create validation logic in webhook)
-
 	openStackMachine.Spec.ProviderID = pointer.StringPtr(fmt.Sprintf("openstack:////%s", instance.ID))
 
+	openStackMachine.Status.InstanceID = instance.ID
 	openStackMachine.Status.InstanceState = &instance.State
 
+	addresses, err := getAddressesFromInstance(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	openStackMachine.Status.Addresses = addresses
+
 	// TODO(sbueringer) From CAPA: TODO(vincepri): Remove this annotation when clusterctl is no longer relevant.
 	if openStackMachine.Annotations == nil {
 		openStackMachine.Annotations = map[string]string{}
@@ -222,16 +241,38 @@ func (r *OpenStackMachineReconciler) reconcileMachine(logger logr.Logger, machin
 		return reconcile.Result{}, nil
 	}
 
-	if openStackMachine.Spec.FloatingIP != "" {
-		err = r.reconcileFloatingIP(computeService, networkingService, instance, openStackMachine, openStackCluster)
+	err = computeService.ReconcilePorts(openStackMachine)
+	if err != nil {
+		return reconcile.Result{}, errors.Errorf("failed to reconcile ports: %v", err)
+	}
+
+	// The floating IP and the virtual IP aren't added to externally managed instances.
+	instanceManaged := !infrav1.IsExternallyManaged(openStackMachine, instance.ID)
+
+	if instanceManaged && (openStackMachine.Spec.FloatingIP != "" || needsFloatingIP(machine, openStackCluster)) {
+		err = metrics.ObservePhase(machineControllerName, "floatingip", func() error {
+			return r.reconcileFloatingIP(computeService, networkingService, instance, clusterName, openStackMachine, openStackCluster)
+		})
 		if err != nil {
 			handleMachineError(openStackMachine, capierrors.UpdateMachineError, errors.Errorf("FloatingIP cannot be reconciled: %v", err))
 			return reconcile.Result{}, nil
 		}
 	}
 
+	if instanceManaged && openStackCluster.Spec.APIServerVirtualIP != nil && util.IsControlPlaneMachine(machine) {
+		err = metrics.ObservePhase(machineControllerName, "virtualip", func() error {
+			return networkingService.ReconcileVirtualIPAddressPairs(instance.ID, openStackCluster)
+		})
+		if err != nil {
+			handleMachineError(openStackMachine, capierrors.UpdateMachineError, errors.Errorf("VirtualIP cannot be reconciled: %v", err))
+			return reconcile.Result{}, nil
+		}
+	}
+
 	if openStackCluster.Spec.ManagedAPIServerLoadBalancer {
-		err = r.reconcileLoadBalancerMember(osProviderClient, clientOpts, instance, clusterName, machine, openStackMachine, openStackCluster)
+		err = metrics.ObservePhase(machineControllerName, "loadbalancermember", func() error {
+			return r.reconcileLoadBalancerMember(osProviderClient, clientOpts, instance, clusterName, machine, openStackMachine, openStackCluster)
+		})
 		if err != nil {
 			handleMachineError(openStackMachine, capierrors.UpdateMachineError, errors.Errorf("LoadBalancerMember cannot be reconciled: %v", err))
 			return reconcile.Result{}, nil
@@ -248,9 +289,10 @@ func (r *OpenStackMachineReconciler) reconcileMachineDelete(logger logr.Logger,
 
 	clusterName := fmt.Sprintf("%s-%s", cluster.ObjectMeta.Namespace, cluster.Name)
 
-	osProviderClient, clientOpts, err := provider.NewClientFromMachine(r.Client, openStackMachine)
+	osProviderClient, clientOpts, err := provider.NewClientFromMachine(r.Client, openStackCluster, openStackMachine)
+	setAuthenticatedCondition(openStackMachine, &openStackMachine.Status.Conditions, err)
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{RequeueAfter: waitForCredentialsDuration}, nil
 	}
 
 	computeService, err := compute.NewService(osProviderClient, clientOpts)
@@ -269,22 +311,33 @@ func (r *OpenStackMachineReconciler) reconcileMachineDelete(logger logr.Logger,
 		}
 	}
 
-	instance, err := computeService.InstanceExists(openStackMachine)
+	instance, err := computeService.InstanceExists(cluster.Name, openStackMachine)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
 	if instance == nil {
 		klog.Infof("Skipped deleting %s that is already deleted.\n", machine.Name)
-		openStackMachine.Finalizers = util.Filter(openStackMachine.Finalizers, infrav1.MachineFinalizer)
-		return reconcile.Result{}, nil
+	} else if infrav1.IsExternallyManaged(openStackMachine, instance.ID) {
+		klog.Infof("Skipped deleting instance %s of %s that is externally managed", instance.ID, machine.Name)
+	} else {
+		// TODO(sbueringer) wait for instance deleted
+		err = metrics.ObservePhase(machineControllerName, "instancedelete", func() error {
+			return computeService.InstanceDelete(machine, openStackMachine)
+		})
+		if err != nil {
+			handleMachineError(openStackMachine, capierrors.UpdateMachineError, errors.Errorf("error deleting Openstack instance: %v", err))
+			return reconcile.Result{}, nil
+		}
 	}
 
-	// TODO(sbueringer) wait for instance deleted
-	err = computeService.InstanceDelete(machine)
+	networkingService, err := networking.NewService(osProviderClient, clientOpts)
 	if err != nil {
-		handleMachineError(openStackMachine, capierrors.UpdateMachineError, errors.Errorf("error deleting Openstack instance: %v", err))
-		return reconcile.Result{}, nil
+		return reconcile.Result{}, err
+	}
+	err = networkingService.DeleteFloatingIP(openStackMachine.Status.FloatingIP, floatingIPOwner(clusterName, openStackMachine))
+	if err != nil {
+		return reconcile.Result{}, errors.Errorf("failed to release floating IP: %v", err)
 	}
 
 	klog.Infof("Reconciled Machine delete %s/%s: %s successfully", cluster.Namespace, cluster.Name, machine.Name)
@@ -295,7 +348,7 @@ func (r *OpenStackMachineReconciler) reconcileMachineDelete(logger logr.Logger,
 
 func (r *OpenStackMachineReconciler) getOrCreate(computeService *compute.Service, machine *clusterv1.Machine, openStackMachine *infrav1.OpenStackMachine, cluster *clusterv1.Cluster, openStackCluster *infrav1.OpenStackCluster) (*compute.Instance, error) {
 
-	instance, err := computeService.InstanceExists(openStackMachine)
+	instance, err := computeService.InstanceExists(cluster.Name, openStackMachine)
 	if err != nil {
 		return nil, err
 	}
@@ -325,8 +378,8 @@ func (r *OpenStackMachineReconciler) getOrCreate(computeService *compute.Service
 }
 
 func handleMachineError(openstackMachine *infrav1.OpenStackMachine, reason capierrors.MachineStatusError, message error) {
-	openstackMachine.Status.ErrorReason = &reason
-	openstackMachine.Status.ErrorMessage = pointer.StringPtr(message.Error())
+	openstackMachine.Status.FailureReason = &reason
+	openstackMachine.Status.FailureMessage = pointer.StringPtr(message.Error())
 	// TODO remove if this error is logged redundantly
 	klog.Errorf("Machine error %s: %v", openstackMachine.Name, message.Error())
 }
@@ -342,7 +395,7 @@ func getTimeout(name string, timeout int) time.Duration {
 }
 
 func (r *OpenStackMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.OpenStackMachine{}).Watches(
 		&source.Kind{Type: &clusterv1.Machine{}},
 		&handler.EnqueueRequestsFromMapFunc{
@@ -352,25 +405,64 @@ func (r *OpenStackMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				Kind:    "OpenStackMachine",
 			}),
 		},
+	).Watches(
+		&source.Kind{Type: &clusterv1.Cluster{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.ClusterToOpenStackMachines)},
 	).Watches(
 		&source.Kind{Type: &infrav1.OpenStackCluster{}},
 		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.OpenStackClusterToOpenStackMachines)},
-	).Complete(r)
+	).Build(r)
+	if err != nil {
+		return err
+	}
+	// The builder doesn't support predicates for a single watch.
+	return c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.SecretToOpenStackMachines)},
+		cloudsSecretPredicate,
+	)
+}
+
+// needsFloatingIP returns whether a floating IP is allocated for a machine without a FloatingIP,
+// which is the case for the control plane machines of a cluster with an external network and
+// without a managed APIServer loadbalancer or virtual IP, as their floating IPs are the API endpoint.
+func needsFloatingIP(machine *clusterv1.Machine, openStackCluster *infrav1.OpenStackCluster) bool {
+	return util.IsControlPlaneMachine(machine) && !openStackCluster.Spec.ManagedAPIServerLoadBalancer &&
+		openStackCluster.Spec.APIServerVirtualIP == nil && openStackCluster.Spec.ExternalNetworkID != "" &&
+		!openStackCluster.Spec.DisableAPIServerFloatingIP
+}
+
+// floatingIPOwner returns the owner of the floating IP allocated for the machine.
+func floatingIPOwner(clusterName string, openStackMachine *infrav1.OpenStackMachine) string {
+	return fmt.Sprintf("%s-%s", clusterName, openStackMachine.Name)
 }
 
-func (r *OpenStackMachineReconciler) reconcileFloatingIP(computeService *compute.Service, networkingService *networking.Service, instance *compute.Instance, openStackMachine *infrav1.OpenStackMachine, openStackCluster *infrav1.OpenStackCluster) error {
-	err := networkingService.GetOrCreateFloatingIP(openStackCluster, openStackMachine.Spec.FloatingIP)
+func (r *OpenStackMachineReconciler) reconcileFloatingIP(computeService *compute.Service, networkingService *networking.Service, instance *compute.Instance, clusterName string, openStackMachine *infrav1.OpenStackMachine, openStackCluster *infrav1.OpenStackCluster) error {
+	fp, err := networkingService.GetOrCreateFloatingIP(openStackCluster, openStackMachine.Spec.FloatingIP, floatingIPOwner(clusterName, openStackMachine))
 	if err != nil {
 		return fmt.Errorf("error creating floatingIP: %v", err)
 	}
+	openStackMachine.Status.FloatingIP = fp.FloatingIP
+	if hasAddress(openStackMachine.Status.Addresses, corev1.NodeExternalIP, fp.FloatingIP) {
+		return nil
+	}
 
-	err = computeService.AssociateFloatingIP(instance.ID, openStackMachine.Spec.FloatingIP)
+	err = computeService.AssociateFloatingIP(instance.ID, fp.FloatingIP)
 	if err != nil {
 		return fmt.Errorf("error associationg floatingIP: %v", err)
 	}
 	return nil
 }
 
+func hasAddress(addresses []corev1.NodeAddress, addressType corev1.NodeAddressType, address string) bool {
+	for _, a := range addresses {
+		if a.Type == addressType && a.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *OpenStackMachineReconciler) reconcileLoadBalancerMember(osProviderClient *gophercloud.ProviderClient, clientOpts *clientconfig.ClientOpts, instance *compute.Instance, clusterName string, machine *clusterv1.Machine, openStackMachine *infrav1.OpenStackMachine, openStackCluster *infrav1.OpenStackCluster) error {
 	ip, err := getIPFromInstance(instance)
 	if err != nil {
@@ -429,6 +521,88 @@ func getIPFromInstance(instance *compute.Instance) (string, error) {
 	return "", fmt.Errorf("extract IP from instance err")
 }
 
+// getAddressesFromInstance returns the fixed IPs of the instance as internal and its
+// floating IPs as external addresses, ordered by the names of their networks.
+func getAddressesFromInstance(instance *compute.Instance) ([]corev1.NodeAddress, error) {
+	type networkInterface struct {
+		Address string `json:"addr"`
+		Type    string `json:"OS-EXT-IPS:type"`
+	}
+	var networkNames []string
+	for name := range instance.Addresses {
+		networkNames = append(networkNames, name)
+	}
+	sort.Strings(networkNames)
+
+	var addresses []corev1.NodeAddress
+	for _, name := range networkNames {
+		list, err := json.Marshal(instance.Addresses[name])
+		if err != nil {
+			return nil, fmt.Errorf("extract addresses from instance err: %v", err)
+		}
+		var networkInterfaces []networkInterface
+		if err := json.Unmarshal(list, &networkInterfaces); err != nil {
+			return nil, fmt.Errorf("extract addresses from instance err: %v", err)
+		}
+		for _, netInterface := range networkInterfaces {
+			addressType := corev1.NodeInternalIP
+			if netInterface.Type == "floating" {
+				addressType = corev1.NodeExternalIP
+			}
+			addresses = append(addresses, corev1.NodeAddress{Type: addressType, Address: netInterface.Address})
+		}
+	}
+	return addresses, nil
+}
+
+// SecretToOpenStackMachines maps a clouds secret to the OpenStackMachines of the OpenStackClusters using it,
+// so they are reconciled with the new credentials when the secret is rotated.
+func (r *OpenStackMachineReconciler) SecretToOpenStackMachines(o handler.MapObject) []ctrl.Request {
+	var result []ctrl.Request
+
+	s, ok := o.Object.(*corev1.Secret)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Secret but got a %T", o.Object), "failed to get OpenStackMachines for Secret")
+		return nil
+	}
+
+	clusters, err := openStackClustersUsingSecret(context.Background(), r.Client, s)
+	if err != nil {
+		r.Log.Error(err, "failed to list OpenStackClusters", "Secret", s.Name, "Namespace", s.Namespace)
+		return nil
+	}
+	for i := range clusters {
+		result = append(result, r.OpenStackClusterToOpenStackMachines(handler.MapObject{Object: &clusters[i]})...)
+	}
+
+	return result
+}
+
+// ClusterToOpenStackMachines reconciles the OpenStackMachines of a Cluster when it changes,
+// e.g. when its reconciliation is resumed.
+func (r *OpenStackMachineReconciler) ClusterToOpenStackMachines(o handler.MapObject) []ctrl.Request {
+	var result []ctrl.Request
+
+	c, ok := o.Object.(*clusterv1.Cluster)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Cluster but got a %T", o.Object), "failed to get OpenStackMachine for Cluster")
+		return nil
+	}
+
+	labels := map[string]string{clusterv1.MachineClusterLabelName: c.Name}
+	machineList := &infrav1.OpenStackMachineList{}
+	if err := r.List(context.Background(), machineList, client.InNamespace(c.Namespace), client.MatchingLabels(labels)); err != nil {
+		r.Log.Error(err, "failed to list OpenStackMachines", "Cluster", c.Name, "Namespace", c.Namespace)
+		return nil
+	}
+	for _, m := range machineList.Items {
+		name := client.ObjectKey{Namespace: m.Namespace, Name: m.Name}
+		result = append(result, ctrl.Request{NamespacedName: name})
+	}
+
+	return result
+}
+
 // OpenStackClusterToOpenStackMachine is a handler.ToRequestsFunc to be used to enqeue requests for reconciliation
 // of OpenStackMachines.
 func (r *OpenStackMachineReconciler) OpenStackClusterToOpenStackMachines(o handler.MapObject) []ctrl.Request {
@@ -0,0 +1,312 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("OpenStackMachineReconciler", func() {
+	var (
+		e                *testEnvironment
+		machine          *clusterv1.Machine
+		openStackMachine *infrav1.OpenStackMachine
+	)
+
+	reconcileMachine := func() (ctrl.Result, error) {
+		r := &OpenStackMachineReconciler{Client: k8sClient, Log: log.Log}
+		return r.Reconcile(ctrl.Request{NamespacedName: client.ObjectKey{Namespace: e.namespace, Name: openStackMachine.Name}})
+	}
+
+	getMachine := func() *infrav1.OpenStackMachine {
+		Expect(k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: openStackMachine.Name}, openStackMachine)).To(Succeed())
+		return openStackMachine
+	}
+
+	// createMachines creates the control plane Machine and its OpenStackMachine.
+	createMachines := func() {
+		Expect(k8sClient.Create(e.ctx, machine)).To(Succeed())
+		openStackMachine.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+			Name:       machine.Name,
+			UID:        machine.UID,
+		}}
+		Expect(k8sClient.Create(e.ctx, openStackMachine)).To(Succeed())
+	}
+
+	// createReadyCluster reconciles the OpenStackCluster and marks the Cluster infrastructure ready.
+	createReadyCluster := func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		_, err := e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.getCluster().Status.Ready).To(BeTrue())
+		e.setInfrastructureReady()
+		openStackMachine.Spec.Networks = []infrav1.NetworkParam{{UUID: e.openStackCluster.Status.Network.ID}}
+	}
+
+	// deleteMachine deletes the OpenStackMachine after the Cluster API machine controller
+	// copied its provider ID to the Machine.
+	deleteMachine := func() {
+		Expect(k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: machine.Name}, machine)).To(Succeed())
+		machine.Spec.ProviderID = getMachine().Spec.ProviderID
+		Expect(k8sClient.Update(e.ctx, machine)).To(Succeed())
+		Expect(k8sClient.Delete(e.ctx, openStackMachine)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		e = newTestEnvironment()
+		e.cloud.AddFlavor("m1.medium", 2, 4096, 40)
+		e.cloud.AddImage("ubuntu")
+		e.cloud.AddKeyPair("default")
+
+		machine = &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: e.namespace,
+				Name:      "control-plane-0",
+				Labels: map[string]string{
+					clusterv1.MachineClusterLabelName:      e.cluster.Name,
+					clusterv1.MachineControlPlaneLabelName: "true",
+				},
+			},
+			Spec: clusterv1.MachineSpec{
+				Bootstrap: clusterv1.Bootstrap{Data: pointer.StringPtr("#cloud-config")},
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "OpenStackMachine",
+					Name:       "control-plane-0",
+				},
+			},
+		}
+		openStackMachine = &infrav1.OpenStackMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: e.namespace,
+				Name:      "control-plane-0",
+				Labels:    map[string]string{clusterv1.MachineClusterLabelName: e.cluster.Name},
+			},
+			Spec: infrav1.OpenStackMachineSpec{
+				CloudsSecret: &corev1.SecretReference{Name: "cloud-config"},
+				CloudName:    testCloudName,
+				Flavor:       "m1.medium",
+				Image:        "ubuntu",
+				KeyName:      "default",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		e.close()
+	})
+
+	It("waits for the cluster infrastructure", func() {
+		Expect(k8sClient.Create(e.ctx, e.openStackCluster)).To(Succeed())
+		createMachines()
+
+		result, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(waitForClusterInfrastructureReadyDuration))
+		Expect(getMachine().Finalizers).To(ContainElement(infrav1.MachineFinalizer))
+		Expect(e.cloud.Resources("servers")).To(BeEmpty())
+	})
+
+	It("waits for the bootstrap data", func() {
+		createReadyCluster()
+		machine.Spec.Bootstrap.Data = nil
+		createMachines()
+
+		result, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).NotTo(BeZero())
+		Expect(getMachine().Status.Ready).To(BeFalse())
+		Expect(e.cloud.Resources("servers")).To(BeEmpty())
+	})
+
+	It("doesn't reconcile a paused machine until it is resumed", func() {
+		createReadyCluster()
+		openStackMachine.Annotations = map[string]string{infrav1.PausedAnnotation: ""}
+		createMachines()
+
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getMachine().Finalizers).To(BeEmpty())
+		Expect(e.cloud.Resources("servers")).To(BeEmpty())
+
+		delete(openStackMachine.Annotations, infrav1.PausedAnnotation)
+		Expect(k8sClient.Update(e.ctx, openStackMachine)).To(Succeed())
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Resources("servers")).To(HaveLen(1))
+	})
+
+	It("creates the instance and adds it to the load balancer", func() {
+		createReadyCluster()
+		createMachines()
+
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+
+		openStackMachine := getMachine()
+		Expect(openStackMachine.Status.Ready).To(BeTrue())
+		Expect(*openStackMachine.Status.InstanceState).To(Equal(infrav1.InstanceStateActive))
+		servers := e.cloud.Resources("servers")
+		Expect(servers).To(HaveLen(1))
+		Expect(*openStackMachine.Spec.ProviderID).To(Equal("openstack:////" + servers[0]["id"].(string)))
+		Expect(e.cloud.Resources("members")).To(HaveLen(1))
+	})
+
+	It("reuses the existing instance when reconciling again", func() {
+		createReadyCluster()
+		createMachines()
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		providerID := *getMachine().Spec.ProviderID
+
+		e.cloud.ResetRequests()
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e.cloud.CountRequests(fake.ServiceCompute, http.MethodPost, "^servers$")).To(BeZero())
+		Expect(e.cloud.CountRequests(fake.ServiceNetwork, http.MethodPost, "^ports$")).To(BeZero())
+		Expect(e.cloud.CountRequests(fake.ServiceLoadBalancer, http.MethodPost, ".")).To(BeZero())
+		Expect(*getMachine().Spec.ProviderID).To(Equal(providerID))
+		Expect(e.cloud.Resources("servers")).To(HaveLen(1))
+		Expect(e.cloud.Resources("members")).To(HaveLen(1))
+	})
+
+	It("sets the error reason when the instance cannot be created", func() {
+		createReadyCluster()
+		createMachines()
+		e.cloud.InjectFault(fake.Fault{Service: fake.ServiceCompute, Method: http.MethodPost, Path: "^servers$", StatusCode: http.StatusInternalServerError})
+
+		_, err := reconcileMachine()
+		Expect(err).To(HaveOccurred())
+		openStackMachine := getMachine()
+		Expect(openStackMachine.Status.Ready).To(BeFalse())
+		Expect(openStackMachine.Status.FailureReason).NotTo(BeNil())
+		Expect(*openStackMachine.Status.FailureReason).To(Equal(capierrors.UpdateMachineError))
+
+		// Machines in the error state are not reconciled anymore.
+		e.cloud.ResetRequests()
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Requests()).To(BeEmpty())
+	})
+
+	It("removes the load balancer member before the instance and removes the finalizer", func() {
+		createReadyCluster()
+		createMachines()
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+
+		e.cloud.ResetRequests()
+		deleteMachine()
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: openStackMachine.Name}, &infrav1.OpenStackMachine{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(e.cloud.Resources("servers")).To(BeEmpty())
+		Expect(e.cloud.Resources("members")).To(BeEmpty())
+		memberDelete := e.requestIndex(fake.ServiceLoadBalancer, http.MethodDelete, "lbaas/pools/")
+		serverDelete := e.requestIndex(fake.ServiceCompute, http.MethodDelete, "servers/")
+		Expect(memberDelete).To(BeNumerically(">=", 0))
+		Expect(serverDelete).To(BeNumerically(">", memberDelete))
+	})
+
+	It("removes the load balancer member of a force-deleted machine when the cluster is reconciled", func() {
+		createReadyCluster()
+		createMachines()
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Resources("members")).To(HaveLen(1))
+
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Resources("members")).To(HaveLen(1))
+
+		openStackMachine := getMachine()
+		openStackMachine.Finalizers = nil
+		Expect(k8sClient.Update(e.ctx, openStackMachine)).To(Succeed())
+		Expect(k8sClient.Delete(e.ctx, openStackMachine)).To(Succeed())
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Resources("members")).To(BeEmpty())
+	})
+
+	It("allocates a floating IP for the control plane endpoint without a load balancer and releases it", func() {
+		e.openStackCluster.Spec.ManagedAPIServerLoadBalancer = false
+		e.openStackCluster.Spec.APIServerLoadBalancerFloatingIP = ""
+		e.cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{APIServerPort: pointer.Int32Ptr(6443)}
+		Expect(k8sClient.Update(e.ctx, e.cluster)).To(Succeed())
+		createReadyCluster()
+		createMachines()
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+
+		floatingIP := getMachine().Status.FloatingIP
+		Expect(floatingIP).NotTo(BeEmpty())
+		fips := e.cloud.Resources("floatingips")
+		Expect(fips).To(HaveLen(1))
+		Expect(fips[0]["port_id"]).NotTo(BeNil())
+
+		_, err = e.reconcileCluster()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.getCluster().Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: floatingIP, Port: 6443}))
+
+		deleteMachine()
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.cloud.Resources("floatingips")).To(BeEmpty())
+	})
+
+	It("keeps the finalizer until the instance is deleted", func() {
+		createReadyCluster()
+		createMachines()
+		_, err := reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+
+		deleteMachine()
+		e.cloud.InjectFault(fake.Fault{Service: fake.ServiceCompute, Method: http.MethodDelete, Path: "^servers/[^/]+$", StatusCode: http.StatusConflict, Times: 1})
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		openStackMachine := getMachine()
+		Expect(openStackMachine.Finalizers).To(ContainElement(infrav1.MachineFinalizer))
+		Expect(openStackMachine.Status.FailureReason).NotTo(BeNil())
+		Expect(e.cloud.Resources("servers")).To(HaveLen(1))
+
+		_, err = reconcileMachine()
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.Get(e.ctx, client.ObjectKey{Namespace: e.namespace, Name: openStackMachine.Name}, &infrav1.OpenStackMachine{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(e.cloud.Resources("servers")).To(BeEmpty())
+	})
+})
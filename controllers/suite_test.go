@@ -16,7 +16,10 @@ limitations under the License.
 package controllers
 
 import (
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
@@ -24,7 +27,8 @@ import (
 
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -40,9 +44,13 @@ var k8sClient client.Client
 var testEnv *envtest.Environment
 
 func TestAPIs(t *testing.T) {
-	//TODO(sbueringer) controller don't work yet because kubebuilder is not installed correctly
-	//and therefore no etcd is available inside the path
-	t.Skip()
+	if !envtestAvailable() {
+		if os.Getenv("SKIP_ENVTEST") != "" {
+			t.Skip("SKIP_ENVTEST is set, skipping the envtest integration tests")
+		}
+		t.Fatal("the envtest binaries are not installed, run `make envtest` and set KUBEBUILDER_ASSETS to bin/kubebuilder, " +
+			"or set SKIP_ENVTEST to skip the integration tests")
+	}
 
 	RegisterFailHandler(Fail)
 
@@ -51,12 +59,36 @@ func TestAPIs(t *testing.T) {
 		[]Reporter{envtest.NewlineReporter{}})
 }
 
+// envtestAvailable returns whether the etcd and kube-apiserver binaries envtest starts are installed.
+func envtestAvailable() bool {
+	assets := os.Getenv("KUBEBUILDER_ASSETS")
+	if assets == "" {
+		assets = "/usr/local/kubebuilder/bin"
+	}
+	for _, binary := range []string{"etcd", "kube-apiserver"} {
+		if _, err := os.Stat(filepath.Join(assets, binary)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// clusterAPICRDPath returns the directory of the Cluster API CRDs in the module cache.
+func clusterAPICRDPath() string {
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "sigs.k8s.io/cluster-api").Output()
+	Expect(err).NotTo(HaveOccurred())
+	return filepath.Join(strings.TrimSpace(string(out)), "config", "crd", "bases")
+}
+
 var _ = BeforeSuite(func(done Done) {
 	logf.SetLogger(zap.LoggerTo(GinkgoWriter, true))
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "config", "crd", "bases"),
+			clusterAPICRDPath(),
+		},
 	}
 
 	var err error
@@ -67,7 +99,7 @@ var _ = BeforeSuite(func(done Done) {
 	err = infrav1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
-	err = infrav1.AddToScheme(scheme.Scheme)
+	err = clusterv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
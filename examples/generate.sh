@@ -191,9 +191,9 @@ echo "Generated ${CLUSTER_GENERATED_FILE}"
 kustomize build "${SOURCE_DIR}/controlplane" --reorder=none | envsubst > "${CONTROLPLANE_GENERATED_FILE}"
 echo "Generated ${CONTROLPLANE_GENERATED_FILE}"
 
-# Generate machinedeployment resources. (TODO(sbueringer) Have to implement OpenStackMachineTemplate first)
-#kustomize build "${SOURCE_DIR}/machinedeployment" --reorder=none | envsubst >> "${MACHINEDEPLOYMENT_GENERATED_FILE}"
-#echo "Generated ${MACHINEDEPLOYMENT_GENERATED_FILE}"
+# Generate machinedeployment resources.
+kustomize build "${SOURCE_DIR}/machinedeployment" --reorder=none | envsubst > "${MACHINEDEPLOYMENT_GENERATED_FILE}"
+echo "Generated ${MACHINEDEPLOYMENT_GENERATED_FILE}"
 
 # Generate machines resources.
 kustomize build "${SOURCE_DIR}/machines" --reorder=none | envsubst > "${WORKER_GENERATED_FILE}"
@@ -201,7 +201,7 @@ echo "Generated ${WORKER_GENERATED_FILE}"
 
 cat ${CONTROLPLANE_GENERATED_FILE} > ${MACHINES_GENERATED_FILE}
 echo "---" >> ${MACHINES_GENERATED_FILE}
-#cat ${MACHINEDEPLOYMENT_GENERATED_FILE} >> ${MACHINES_GENERATED_FILE}
+cat ${MACHINEDEPLOYMENT_GENERATED_FILE} >> ${MACHINES_GENERATED_FILE}
 echo "---" >> ${MACHINES_GENERATED_FILE}
 cat ${WORKER_GENERATED_FILE} >> ${MACHINES_GENERATED_FILE}
 echo "---" >> ${MACHINES_GENERATED_FILE}
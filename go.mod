@@ -4,11 +4,14 @@ go 1.12
 
 require (
 	github.com/go-logr/logr v0.1.0
+	github.com/google/gofuzz v0.0.0-20170612174753-24818f796faf
 	github.com/gophercloud/gophercloud v0.3.0
 	github.com/gophercloud/utils v0.0.0-20190527093828-25f1b77b8c03
 	github.com/onsi/ginkgo v1.8.0
 	github.com/onsi/gomega v1.5.0
 	github.com/pkg/errors v0.8.1
+	github.com/prometheus/client_golang v0.9.3-0.20190127221311-3c4408c8b829
+	golang.org/x/time v0.0.0-20180412165947-fbb02b2291d2
 	gopkg.in/yaml.v2 v2.2.2
 	k8s.io/api v0.0.0-20190711103429-37c3b8b1ca65
 	k8s.io/apimachinery v0.0.0-20190711103026-7bf792636534
@@ -21,6 +24,7 @@ require (
 	sigs.k8s.io/controller-runtime v0.2.0
 	sigs.k8s.io/controller-tools v0.2.0
 	sigs.k8s.io/testing_frameworks v0.1.2-0.20190130140139-57f07443c2d4
+	sigs.k8s.io/yaml v1.1.0
 )
 
 replace (
@@ -0,0 +1,41 @@
+#!/usr/bin/env bash
+
+# Copyright 2019 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Installs the etcd and kube-apiserver binaries of the envtest integration tests into the given directory.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+if [[ $# -ne 1 ]]; then
+  echo "usage: $0 <directory>" >&2
+  exit 1
+fi
+ASSETS_DIR="$1"
+ENVTEST_K8S_VERSION="${ENVTEST_K8S_VERSION:-1.16.4}"
+GOOS="$(go env GOOS)"
+GOARCH="$(go env GOARCH)"
+
+if [[ -x "${ASSETS_DIR}/etcd" && -x "${ASSETS_DIR}/kube-apiserver" ]]; then
+  exit 0
+fi
+
+TMP_DIR="$(mktemp -d)"
+trap 'rm -rf "${TMP_DIR}"' EXIT
+curl -sSLf "https://storage.googleapis.com/kubebuilder-tools/kubebuilder-tools-${ENVTEST_K8S_VERSION}-${GOOS}-${GOARCH}.tar.gz" |
+  tar -xz -C "${TMP_DIR}"
+mkdir -p "${ASSETS_DIR}"
+cp "${TMP_DIR}/kubebuilder/bin/etcd" "${TMP_DIR}/kubebuilder/bin/kube-apiserver" "${ASSETS_DIR}/"
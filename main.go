@@ -20,6 +20,7 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"strings"
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"time"
@@ -30,8 +31,11 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog"
 	"k8s.io/klog/klogr"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1alpha2 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 	"sigs.k8s.io/cluster-api-provider-openstack/controllers"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/metrics"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	// +kubebuilder:scaffold:imports
@@ -44,6 +48,7 @@ var (
 
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
+	_ = infrav1alpha2.AddToScheme(scheme)
 	_ = infrav1.AddToScheme(scheme)
 	_ = clusterv1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
@@ -61,6 +66,14 @@ func main() {
 	watchNamespace := flag.String("namespace", "",
 		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 	profilerAddress := flag.String("profiler-address", "", "Bind address to expose the pprof profiler (e.g. localhost:6060)")
+	defaultIdentityPath := flag.String("default-identity-path", "",
+		"Directory containing the clouds.yaml and certificates used by OpenStackClusters without credentials, e.g. a mounted clouds secret.")
+	defaultIdentityCloudName := flag.String("default-identity-cloud-name", "openstack",
+		"Name of the cloud in the clouds.yaml of the default identity.")
+	webhookPort := flag.Int("webhook-port", 9443,
+		"Port the admission and conversion webhook server listens on. The webhooks are disabled if it is 0, e.g. when running the manager locally without a serving certificate.")
+	defaultDNSNameservers := flag.String("default-dns-nameservers", "",
+		"Comma-separated nameservers set on the subnets of OpenStackClusters which don't configure dnsNameservers.")
 	flag.Parse()
 
 	if *watchNamespace != "" {
@@ -74,6 +87,15 @@ func main() {
 		}()
 	}
 
+	if *defaultIdentityPath != "" {
+		setupLog.Info("Using default identity for OpenStackClusters without credentials", "path", *defaultIdentityPath, "cloud", *defaultIdentityCloudName)
+		provider.SetDefaultIdentity(*defaultIdentityPath, *defaultIdentityCloudName)
+	}
+
+	if *defaultDNSNameservers != "" {
+		infrav1.DefaultDNSNameservers = strings.Split(*defaultDNSNameservers, ",")
+	}
+
 	syncPeriod := 10 * time.Minute
 
 	ctrl.SetLogger(klogr.New())
@@ -89,6 +111,7 @@ func main() {
 		LeaderElection:     enableLeaderElection,
 		SyncPeriod:         &syncPeriod,
 		Namespace:          *watchNamespace,
+		Port:               *webhookPort,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -114,8 +137,39 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "OpenStackCluster")
 		os.Exit(1)
 	}
+	if *webhookPort != 0 {
+		if err = (&infrav1.OpenStackCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackCluster")
+			os.Exit(1)
+		}
+		if err = (&infrav1.OpenStackMachine{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackMachine")
+			os.Exit(1)
+		}
+		if err = (&infrav1.OpenStackMachineTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackMachineTemplate")
+			os.Exit(1)
+		}
+		if err = (&infrav1alpha2.OpenStackCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackCluster")
+			os.Exit(1)
+		}
+		if err = (&infrav1alpha2.OpenStackMachine{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackMachine")
+			os.Exit(1)
+		}
+		if err = (&infrav1alpha2.OpenStackMachineTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenStackMachineTemplate")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err := metrics.RegisterMachineCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register metrics")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+)
+
+// ReconcileFailureDomains sets the failure domains of the cluster to the available
+// availability zones of the compute service.
+func (is *Service) ReconcileFailureDomains(openStackCluster *infrav1.OpenStackCluster) error {
+	allPages, err := availabilityzones.List(is.computeClient).AllPages()
+	if err != nil {
+		return fmt.Errorf("failed to list availability zones: %v", err)
+	}
+	zones, err := availabilityzones.ExtractAvailabilityZones(allPages)
+	if err != nil {
+		return fmt.Errorf("failed to extract availability zones: %v", err)
+	}
+
+	failureDomains := infrav1.FailureDomains{}
+	for _, zone := range zones {
+		if zone.ZoneState.Available {
+			failureDomains[zone.ZoneName] = infrav1.FailureDomainSpec{ControlPlane: true}
+		}
+	}
+	openStackCluster.Status.FailureDomains = failureDomains
+	return nil
+}
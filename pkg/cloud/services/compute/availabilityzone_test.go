@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"reflect"
+	"testing"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestReconcileFailureDomains(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	cloud.SetAvailabilityZones("az1", "az2")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{}
+	if err := s.ReconcileFailureDomains(openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile failure domains: %v", err)
+	}
+	expected := infrav1.FailureDomains{
+		"az1": {ControlPlane: true},
+		"az2": {ControlPlane: true},
+	}
+	if !reflect.DeepEqual(openStackCluster.Status.FailureDomains, expected) {
+		t.Errorf("expected failure domains %v, got %v", expected, openStackCluster.Status.FailureDomains)
+	}
+}
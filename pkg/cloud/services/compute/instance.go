@@ -23,26 +23,24 @@ import (
 	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
 	"sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"strconv"
 	"time"
 
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
 
 	"github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack/common/extensions"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/attachinterfaces"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/bootfromvolume"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/floatingips"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
-	netext "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions"
-	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/trunks"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/gophercloud/gophercloud/pagination"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
 )
 
@@ -54,6 +52,15 @@ const (
 	RetryIntervalPortDelete = 5 * time.Second
 )
 
+// The server metadata of control plane machines contains the virtual IP of the APIServer
+// and its VRRP priority, so keepalived or kube-vip can float it between the machines.
+const (
+	MetadataAPIServerVirtualIP         = "apiserver-vip"
+	MetadataAPIServerVirtualIPPriority = "apiserver-vip-priority"
+
+	defaultVirtualIPPriority = 100
+)
+
 // TODO(sbueringer) We should probably wrap the OpenStack object completely (see CAPA)
 type Instance struct {
 	servers.Server
@@ -63,6 +70,7 @@ type Instance struct {
 type ServerNetwork struct {
 	networkID string
 	subnetID  string
+	subports  []infrav1.SubportParam
 }
 
 // InstanceCreate creates a compute instance
@@ -72,26 +80,12 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 		return nil, fmt.Errorf("create Options need be specified to create instace")
 	}
 	if openStackMachine.Spec.Trunk == true {
-		trunkSupport, err := getTrunkSupport(is)
-		if err != nil {
-			return nil, fmt.Errorf("there was an issue verifying whether trunk support is available, please disable it: %v", err)
-		}
-		if trunkSupport == false {
-			return nil, fmt.Errorf("there is no trunk support. Please disable it")
+		if err := networking.RequireExtension(is.networkClient, networking.ExtensionTrunk, "trunk ports"); err != nil {
+			return nil, fmt.Errorf("%v. Please disable trunk", err)
 		}
 	}
 
-	// Set default Tags
-	machineTags := []string{
-		"cluster-api-provider-openstack",
-		clusterName,
-	}
-
-	// Append machine specific tags
-	machineTags = append(machineTags, openStackMachine.Spec.Tags...)
-
-	// Append cluster scope tags
-	machineTags = append(machineTags, openStackCluster.Spec.Tags...)
+	machineTags := getMachineTags(clusterName, openStackMachine, openStackCluster)
 
 	// Get security groups
 	securityGroups, err := getSecurityGroups(is, openStackMachine.Spec.SecurityGroups)
@@ -107,11 +101,18 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 		if err != nil {
 			return nil, err
 		}
+		if len(net.Subports) > 0 && !openStackMachine.Spec.Trunk {
+			return nil, fmt.Errorf("subports can only be configured if trunk is enabled")
+		}
+		// Subports are only attached to the trunk of the first port created for this network
+		subports := net.Subports
 		for _, netID := range ids {
 			if net.Subnets == nil {
 				nets = append(nets, ServerNetwork{
 					networkID: netID,
+					subports:  subports,
 				})
+				subports = nil
 			}
 
 			for _, subnet := range net.Subnets {
@@ -126,12 +127,15 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 					nets = append(nets, ServerNetwork{
 						networkID: subnetByFilter.NetworkID,
 						subnetID:  subnetByFilter.ID,
+						subports:  subports,
 					})
+					subports = nil
 				}
 			}
 		}
 	}
 	var portsList []servers.Network
+	var observedSubports []infrav1.Subport
 	for _, net := range nets {
 		if net.networkID == "" {
 			return nil, fmt.Errorf("no network was found or provided. Please check your machine configuration and try again")
@@ -158,8 +162,7 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 			port = portList[0]
 		}
 
-		_, err = attributestags.ReplaceAll(is.networkClient, "ports", port.ID, attributestags.ReplaceAllOpts{
-			Tags: machineTags}).Extract()
+		err = networking.ReplaceAllAttributesTags(is.networkClient, "ports", port.ID, machineTags)
 		if err != nil {
 			return nil, fmt.Errorf("tagging port for server err: %v", err)
 		}
@@ -195,28 +198,86 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 				trunk = trunkList[0]
 			}
 
-			_, err = attributestags.ReplaceAll(is.networkClient, "trunks", trunk.ID, attributestags.ReplaceAllOpts{
-				Tags: machineTags}).Extract()
+			err = networking.ReplaceAllAttributesTags(is.networkClient, "trunks", trunk.ID, machineTags)
 			if err != nil {
 				return nil, fmt.Errorf("tagging trunk for server err: %v", err)
 			}
+
+			subports, err := is.reconcileSubports(openStackMachine.Name, &trunk, net.subports, &securityGroups, machineTags)
+			if err != nil {
+				return nil, err
+			}
+			observedSubports = append(observedSubports, subports...)
+		}
+	}
+	openStackMachine.Status.Subports = observedSubports
+
+	deviceTags := map[string]string{}
+	for i, portOpts := range openStackMachine.Spec.Ports {
+		port, err := is.getOrCreatePort(openStackMachine.Name, i, portOpts, securityGroups, machineTags)
+		if err != nil {
+			return nil, err
+		}
+		portsList = append(portsList, servers.Network{
+			Port: port.ID,
+		})
+		if portOpts.Tag != "" {
+			deviceTags[port.ID] = portOpts.Tag
 		}
 	}
 
+	// Every optional feature raises the microversion of the request to the one it needs if the
+	// compute endpoint supports it, and is skipped otherwise unless the server can't do without it.
+	microversions := is.GetMicroversionRange()
+	versions := []string{MicroversionServerTags}
+
 	var serverTags []string
-	if openStackCluster.Spec.DisableServerTags == false {
+	if microversions.Supports(MicroversionServerTags) {
 		serverTags = machineTags
-		// NOTE(flaper87): This is the minimum required version
-		// to use tags.
-		is.computeClient.Microversion = "2.52"
+	} else {
+		klog.Infof("Compute API microversions %s do not support server tags, creating server %s without tags", microversions, openStackMachine.Name)
+	}
+
+	if len(deviceTags) > 0 {
+		if microversions.Supports(MicroversionDeviceTagging) {
+			versions = append(versions, MicroversionDeviceTagging)
+		} else {
+			klog.Infof("Compute API microversions %s do not support device tags, creating server %s without device tags", microversions, openStackMachine.Name)
+			deviceTags = nil
+		}
+	}
+
+	// The image of the server must not be used unverified.
+	if len(openStackMachine.Spec.TrustedImageCertificates) > 0 {
+		if !microversions.Supports(MicroversionTrustedCerts) {
+			return nil, fmt.Errorf("create new server err: compute API microversions %s do not support trusted image certificates", microversions)
+		}
+		versions = append(versions, MicroversionTrustedCerts)
+	}
+
+	// Without an explicit hostname Nova derives it from the server name.
+	var hostname string
+	if microversions.Supports(MicroversionHostname) && hostnameRegexp.MatchString(openStackMachine.Name) {
+		hostname = openStackMachine.Name
+		versions = append(versions, MicroversionHostname)
 	}
 
+	// Work on a copy of the compute client so the microversion picked for this
+	// request never leaks into other calls.
+	computeClient := *is.computeClient
+	computeClient.Microversion = microversions.Highest(versions...)
+
 	// Get image ID
 	imageID, err := getImageID(is, openStackMachine.Spec.Image)
 	if err != nil {
 		return nil, fmt.Errorf("create new server err: %v", err)
 	}
 
+	metadata, err := is.getServerMetadata(clusterName, machine, openStackMachine, openStackCluster)
+	if err != nil {
+		return nil, fmt.Errorf("create new server err: %v", err)
+	}
+
 	serverCreateOpts := servers.CreateOpts{
 		Name:             openStackMachine.Name,
 		ImageRef:         imageID,
@@ -225,9 +286,9 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 		Networks:         portsList,
 		UserData:         []byte(*machine.Spec.Bootstrap.Data),
 		SecurityGroups:   securityGroups,
-		ServiceClient:    is.computeClient,
+		ServiceClient:    &computeClient,
 		Tags:             serverTags,
-		Metadata:         openStackMachine.Spec.ServerMetadata,
+		Metadata:         metadata,
 		ConfigDrive:      openStackMachine.Spec.ConfigDrive,
 	}
 
@@ -252,34 +313,137 @@ func (is *Service) InstanceCreate(clusterName string, machine *v1alpha2.Machine,
 		}
 	}
 
-	server, err := servers.Create(is.computeClient, keypairs.CreateOptsExt{
-		CreateOptsBuilder: serverCreateOpts,
-		KeyName:           openStackMachine.Spec.KeyName,
+	server, err := servers.Create(&computeClient, serverCreateOptsExt{
+		CreateOptsBuilder: keypairs.CreateOptsExt{
+			CreateOptsBuilder: serverCreateOpts,
+			KeyName:           openStackMachine.Spec.KeyName,
+		},
+		Hostname:                 hostname,
+		TrustedImageCertificates: openStackMachine.Spec.TrustedImageCertificates,
+		DeviceTags:               deviceTags,
 	}).Extract()
 	if err != nil {
 		return nil, fmt.Errorf("create new server err: %v", err)
 	}
-	is.computeClient.Microversion = ""
 	return &Instance{Server: *server, State: infrav1.InstanceState(server.Status)}, nil
 }
 
-func getTrunkSupport(is *Service) (bool, error) {
-	allPages, err := netext.List(is.networkClient).AllPages()
+// serverCreateOptsExt adds the fields of newer compute microversions, which gophercloud doesn't
+// support yet, to the request creating a server.
+type serverCreateOptsExt struct {
+	servers.CreateOptsBuilder
+	// Hostname of the server, requires MicroversionHostname.
+	Hostname string
+	// TrustedImageCertificates verify the image of the server, require MicroversionTrustedCerts.
+	TrustedImageCertificates []string
+	// DeviceTags are the device tags of the network interfaces by port ID, require MicroversionDeviceTagging.
+	DeviceTags map[string]string
+}
+
+func (opts serverCreateOptsExt) ToServerCreateMap() (map[string]interface{}, error) {
+	base, err := opts.CreateOptsBuilder.ToServerCreateMap()
+	if err != nil {
+		return nil, err
+	}
+	server := base["server"].(map[string]interface{})
+	if opts.Hostname != "" {
+		server["hostname"] = opts.Hostname
+	}
+	if len(opts.TrustedImageCertificates) > 0 {
+		server["trusted_image_certificates"] = opts.TrustedImageCertificates
+	}
+	if networks, ok := server["networks"].([]map[string]interface{}); ok {
+		for _, network := range networks {
+			if port, ok := network["port"].(string); ok && opts.DeviceTags[port] != "" {
+				network["tag"] = opts.DeviceTags[port]
+			}
+		}
+	}
+	return base, nil
+}
+
+func getMachineTags(clusterName string, openStackMachine *infrav1.OpenStackMachine, openStackCluster *infrav1.OpenStackCluster) []string {
+	// Set default Tags
+	machineTags := networking.OwnershipTags(clusterName)
+
+	// Append machine specific tags
+	machineTags = append(machineTags, openStackMachine.Spec.Tags...)
+
+	// Append cluster scope tags
+	machineTags = append(machineTags, openStackCluster.Spec.Tags...)
+	return machineTags
+}
+
+// getServerMetadata returns the ServerMetadata of the machine, which is extended by the
+// virtual IP of the APIServer for control plane machines.
+func (is *Service) getServerMetadata(clusterName string, machine *v1alpha2.Machine, openStackMachine *infrav1.OpenStackMachine, openStackCluster *infrav1.OpenStackCluster) (map[string]string, error) {
+	vip := openStackCluster.Spec.APIServerVirtualIP
+	if vip == nil || !util.IsControlPlaneMachine(machine) ||
+		openStackCluster.Status.Network == nil || openStackCluster.Status.Network.APIServerVirtualIP == nil {
+		return openStackMachine.Spec.ServerMetadata, nil
+	}
+
+	address := openStackCluster.Status.Network.APIServerVirtualIP.InternalIP
+	priority, err := is.virtualIPPriority(clusterName, address, vip.Priority)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	metadata := map[string]string{}
+	for k, v := range openStackMachine.Spec.ServerMetadata {
+		metadata[k] = v
+	}
+	metadata[MetadataAPIServerVirtualIP] = address
+	metadata[MetadataAPIServerVirtualIPPriority] = strconv.Itoa(priority)
+	return metadata, nil
+}
+
+// virtualIPPriority returns the VRRP priority of a new control plane machine: the highest priority
+// between 1 and the priority of the cluster which no other server of the cluster with the same
+// virtual IP uses, so the machines don't compete with the same priority. If all of them are used,
+// the machine gets priority 1 and VRRP prefers the machine with the highest IP among the machines
+// with the same priority.
+func (is *Service) virtualIPPriority(clusterName, address string, priority int) (int, error) {
+	if priority == 0 {
+		priority = defaultVirtualIPPriority
 	}
 
-	allExts, err := extensions.ExtractExtensions(allPages)
+	// Server tags are only returned by microversions which support them.
+	computeClient := *is.computeClient
+	computeClient.Microversion = is.GetMicroversionRange().Highest(MicroversionServerTags)
+	allPages, err := servers.List(&computeClient, servers.ListOpts{}).AllPages()
 	if err != nil {
-		return false, err
+		return 0, fmt.Errorf("failed to list servers: %v", err)
+	}
+	var serverList []struct {
+		ID       string            `json:"id"`
+		Metadata map[string]string `json:"metadata"`
+		Tags     *[]string         `json:"tags"`
+	}
+	if err := servers.ExtractServersInto(allPages, &serverList); err != nil {
+		return 0, fmt.Errorf("failed to extract servers: %v", err)
 	}
 
-	for _, ext := range allExts {
-		if ext.Alias == "trunk" {
-			return true, nil
+	used := map[int]bool{}
+	for _, server := range serverList {
+		if server.Metadata[MetadataAPIServerVirtualIP] != address {
+			continue
 		}
+		if server.Tags != nil && !networking.HasOwnershipTags(*server.Tags, clusterName) {
+			continue
+		}
+		if p, err := strconv.Atoi(server.Metadata[MetadataAPIServerVirtualIPPriority]); err == nil {
+			used[p] = true
+		}
+	}
+	for p := priority; p > 1; p-- {
+		if !used[p] {
+			return p, nil
+		}
+	}
+	if used[1] {
+		klog.Warningf("All VRRP priorities of virtual IP %s are in use, the priority of the new machine is 1", address)
 	}
-	return false, nil
+	return 1, nil
 }
 
 func getSecurityGroups(is *Service, securityGroupParams []infrav1.SecurityGroupParam) ([]string, error) {
@@ -398,7 +562,7 @@ func (is *Service) AssociateFloatingIP(instanceID, floatingIP string) error {
 	return floatingips.AssociateInstance(is.computeClient, instanceID, opts).ExtractErr()
 }
 
-func (is *Service) InstanceDelete(machine *v1alpha2.Machine) error {
+func (is *Service) InstanceDelete(machine *v1alpha2.Machine, openStackMachine *infrav1.OpenStackMachine) error {
 
 	parsed, err := noderefutil.NewProviderID(*machine.Spec.ProviderID)
 	if err != nil {
@@ -418,39 +582,54 @@ func (is *Service) InstanceDelete(machine *v1alpha2.Machine) error {
 		return servers.Delete(is.computeClient, parsed.ID()).ExtractErr()
 	}
 
-	trunkSupport, err := getTrunkSupport(is)
+	trunkSupport, err := networking.HasExtension(is.networkClient, networking.ExtensionTrunk)
 	if err != nil {
 		return fmt.Errorf("obtaining network extensions: %v", err)
 	}
+	// Remove the subports from the trunks and delete their ports before the trunks. The subport
+	// ports recorded in the status are deleted as well, so they are found again if their deletion
+	// fails after they were removed from their trunk.
+	portTrunks := map[string]*trunks.Trunk{}
+	var subports []trunks.Subport
+	for _, subport := range openStackMachine.Status.Subports {
+		subports = append(subports, trunks.Subport{PortID: subport.PortID})
+	}
+	if trunkSupport {
+		for _, port := range instanceInterfaces {
+			trunk, err := is.getTrunkOfPort(port.PortID)
+			if err != nil {
+				return err
+			}
+			if trunk == nil {
+				continue
+			}
+			portTrunks[port.PortID] = trunk
+			if err := is.removeSubports(trunk); err != nil {
+				return err
+			}
+			subports = append(subports, trunk.Subports...)
+		}
+	}
+	if err := is.deleteSubportPorts(subports); err != nil {
+		return err
+	}
+
 	// get and delete trunks
 	for _, port := range instanceInterfaces {
 		err := attachinterfaces.Delete(is.computeClient, parsed.ID(), port.PortID).ExtractErr()
 		if err != nil {
 			return err
 		}
-		if trunkSupport {
-			listOpts := trunks.ListOpts{
-				PortID: port.PortID,
-			}
-			allTrunks, err := trunks.List(is.networkClient, listOpts).AllPages()
-			if err != nil {
-				return err
-			}
-			trunkInfo, err := trunks.ExtractTrunks(allTrunks)
-			if err != nil {
-				return err
-			}
-			if len(trunkInfo) == 1 {
-				err = util.PollImmediate(RetryIntervalTrunkDelete, TimeoutTrunkDelete, func() (bool, error) {
-					err := trunks.Delete(is.networkClient, trunkInfo[0].ID).ExtractErr()
-					if err != nil {
-						return false, nil
-					}
-					return true, nil
-				})
+		if trunk, ok := portTrunks[port.PortID]; ok {
+			err = util.PollImmediate(RetryIntervalTrunkDelete, TimeoutTrunkDelete, func() (bool, error) {
+				err := trunks.Delete(is.networkClient, trunk.ID).ExtractErr()
 				if err != nil {
-					return fmt.Errorf("error deleting the trunk %v", trunkInfo[0].ID)
+					return false, nil
 				}
+				return true, nil
+			})
+			if err != nil {
+				return fmt.Errorf("error deleting the trunk %v", trunk.ID)
 			}
 		}
 
@@ -524,7 +703,33 @@ func (is *Service) GetInstance(resourceId string) (instance *Instance, err error
 	return &Instance{Server: *server, State: infrav1.InstanceState(server.Status)}, err
 }
 
-func (is *Service) InstanceExists(openStackMachine *infrav1.OpenStackMachine) (instance *Instance, err error) {
+// InstanceExists returns the instance recorded in the status of the machine if it is owned by the
+// cluster, or the instance with the name of the machine otherwise, e.g. because the status was lost
+// when the machine was moved.
+func (is *Service) InstanceExists(clusterName string, openStackMachine *infrav1.OpenStackMachine) (instance *Instance, err error) {
+	if id := openStackMachine.Status.InstanceID; id != "" {
+		// Server tags are only returned by microversions which support them.
+		computeClient := *is.computeClient
+		computeClient.Microversion = is.GetMicroversionRange().Highest(MicroversionServerTags)
+		result := servers.Get(&computeClient, id)
+		server, err := result.Extract()
+		if err != nil && !networking.IsNotFound(err) {
+			return nil, fmt.Errorf("get server %q detail failed: %v", id, err)
+		}
+		if err == nil {
+			var tags struct {
+				Tags []string `json:"tags"`
+			}
+			if err := result.ExtractInto(&tags); err != nil {
+				return nil, fmt.Errorf("get server %q tags failed: %v", id, err)
+			}
+			if networking.HasOwnershipTags(tags.Tags, clusterName) || infrav1.IsExternallyManaged(openStackMachine, id) {
+				return &Instance{Server: *server, State: infrav1.InstanceState(server.Status)}, nil
+			}
+			klog.V(3).Infof("Instance %s of the status isn't owned by cluster %s, looking it up by name", id, clusterName)
+		}
+	}
+
 	opts := &InstanceListOpts{
 		Name:   openStackMachine.Name,
 		Image:  openStackMachine.Spec.Image,
@@ -0,0 +1,412 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/trunks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+	"sigs.k8s.io/cluster-api/api/v1alpha2"
+)
+
+func newTestService(t *testing.T, cloud *fake.Cloud) *Service {
+	client, clientOpts, err := cloud.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(client, clientOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newTestMachines(networkID string) (*v1alpha2.Machine, *infrav1.OpenStackMachine) {
+	bootstrapData := "#cloud-config"
+	machine := &v1alpha2.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine"},
+		Spec: v1alpha2.MachineSpec{
+			Bootstrap: v1alpha2.Bootstrap{Data: &bootstrapData},
+		},
+	}
+	openStackMachine := &infrav1.OpenStackMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine"},
+		Spec: infrav1.OpenStackMachineSpec{
+			Flavor:   "m1.medium",
+			Image:    "ubuntu",
+			KeyName:  "default",
+			Networks: []infrav1.NetworkParam{{UUID: networkID}},
+		},
+	}
+	return machine, openStackMachine
+}
+
+func TestInstanceLifecycle(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	cloud.AddFlavor("m1.medium", 2, 4096, 40)
+	cloud.AddImage("ubuntu")
+	cloud.AddKeyPair("default")
+	cloud.SetBuildPolls(2)
+	s := newTestService(t, cloud)
+
+	machine, openStackMachine := newTestMachines(networkID)
+	openStackMachine.Spec.Trunk = true
+	openStackCluster := &infrav1.OpenStackCluster{}
+	instance, err := s.InstanceCreate("test", machine, openStackMachine, openStackCluster)
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	for i := 0; instance.State != infrav1.InstanceStateActive; i++ {
+		if i == 3 {
+			t.Fatalf("expected instance to become active, got %s", instance.State)
+		}
+		if instance, err = s.GetInstance(instance.ID); err != nil {
+			t.Fatalf("failed to get instance: %v", err)
+		}
+	}
+	if servers := cloud.Resources("servers"); len(servers[0]["tags"].([]interface{})) != 2 {
+		t.Errorf("expected the instance to be tagged, got %v", servers[0]["tags"])
+	}
+	if _, ok := instance.Addresses["cluster"]; !ok {
+		t.Errorf("expected the instance to have an address on the cluster network, got %v", instance.Addresses)
+	}
+	if n := len(cloud.Resources("trunks")); n != 1 {
+		t.Errorf("expected 1 trunk, got %d", n)
+	}
+
+	existing, err := s.InstanceExists("test", openStackMachine)
+	if err != nil || existing == nil || existing.ID != instance.ID {
+		t.Fatalf("expected the instance to exist, got %v: %v", existing, err)
+	}
+
+	providerID := fmt.Sprintf("openstack:///%s", instance.ID)
+	machine.Spec.ProviderID = &providerID
+	if err := s.InstanceDelete(machine, openStackMachine); err != nil {
+		t.Fatalf("failed to delete instance: %v", err)
+	}
+	for _, collection := range []string{"servers", "ports", "trunks"} {
+		if n := len(cloud.Resources(collection)); n != 0 {
+			t.Errorf("expected no %s after deleting the instance, got %d", collection, n)
+		}
+	}
+}
+
+func TestInstanceCreateWithSubports(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	vlanNetworkID := cloud.AddNetwork("vlan", false)
+	cloud.AddSubnet(vlanNetworkID, "vlan", "10.7.0.0/24")
+	cloud.AddFlavor("m1.medium", 2, 4096, 40)
+	cloud.AddImage("ubuntu")
+	cloud.AddKeyPair("default")
+	s := newTestService(t, cloud)
+
+	machine, openStackMachine := newTestMachines(networkID)
+	openStackMachine.Spec.Trunk = true
+	openStackMachine.Spec.Networks[0].Subports = []infrav1.SubportParam{
+		{UUID: vlanNetworkID, SegmentationID: 100},
+		{Filter: infrav1.Filter{Name: "vlan"}, SegmentationType: "inherit", SegmentationID: 101},
+	}
+	instance, err := s.InstanceCreate("test", machine, openStackMachine, &infrav1.OpenStackCluster{})
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	subports := openStackMachine.Status.Subports
+	if len(subports) != 2 {
+		t.Fatalf("expected 2 subports in the status, got %+v", subports)
+	}
+	for i, expected := range []string{infrav1.DefaultSegmentationType, "inherit"} {
+		if subports[i].NetworkID != vlanNetworkID || subports[i].SegmentationType != expected {
+			t.Errorf("expected subport %d on the vlan network with segmentation type %s, got %+v", i, expected, subports[i])
+		}
+	}
+	trunkList := cloud.Resources("trunks")
+	if len(trunkList) != 1 || len(trunkList[0]["sub_ports"].([]interface{})) != 2 {
+		t.Fatalf("expected 1 trunk with 2 subports, got %v", trunkList)
+	}
+
+	// A subport port which is already deleted doesn't fail the deletion of the instance.
+	if err := ports.Delete(s.networkClient, subports[0].PortID).ExtractErr(); err == nil {
+		t.Fatalf("expected the port of an attached subport not to be deletable")
+	}
+	if err := s.deleteSubportPorts([]trunks.Subport{{PortID: "deleted"}}); err != nil {
+		t.Errorf("expected an already deleted subport port to be skipped, got %v", err)
+	}
+
+	// The subport ports of a deletion which failed after removing them from the trunk are found
+	// again through the status.
+	trunk, err := s.getTrunkOfPort(trunkList[0]["port_id"].(string))
+	if err != nil || trunk == nil {
+		t.Fatalf("expected the trunk of the instance, got %v: %v", trunk, err)
+	}
+	if err := s.removeSubports(trunk); err != nil {
+		t.Fatalf("failed to remove subports: %v", err)
+	}
+	providerID := fmt.Sprintf("openstack:///%s", instance.ID)
+	machine.Spec.ProviderID = &providerID
+	if err := s.InstanceDelete(machine, openStackMachine); err != nil {
+		t.Fatalf("failed to delete instance: %v", err)
+	}
+	for _, collection := range []string{"servers", "ports", "trunks"} {
+		if n := len(cloud.Resources(collection)); n != 0 {
+			t.Errorf("expected no %s after deleting the instance, got %d", collection, n)
+		}
+	}
+}
+
+func TestInstanceExistsByID(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	cloud.AddFlavor("m1.medium", 2, 4096, 40)
+	cloud.AddImage("ubuntu")
+	cloud.AddKeyPair("default")
+	s := newTestService(t, cloud)
+
+	machine, openStackMachine := newTestMachines(networkID)
+	instance, err := s.InstanceCreate("test", machine, openStackMachine, &infrav1.OpenStackCluster{})
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+
+	// The instance in the status is adopted if it is owned by the cluster, even if it can't be
+	// found by the name of the machine.
+	openStackMachine.Name = "renamed"
+	openStackMachine.Status.InstanceID = instance.ID
+	existing, err := s.InstanceExists("test", openStackMachine)
+	if err != nil || existing == nil || existing.ID != instance.ID {
+		t.Fatalf("expected the instance in the status to exist, got %v: %v", existing, err)
+	}
+
+	// An instance which isn't owned by the cluster falls back to the name of the machine.
+	existing, err = s.InstanceExists("other", openStackMachine)
+	if err != nil || existing != nil {
+		t.Fatalf("expected the instance of another cluster not to be adopted, got %v: %v", existing, err)
+	}
+
+	// An instance which doesn't exist anymore falls back to the name of the machine.
+	openStackMachine.Name = "machine"
+	openStackMachine.Status.InstanceID = "deleted"
+	existing, err = s.InstanceExists("test", openStackMachine)
+	if err != nil || existing == nil || existing.ID != instance.ID {
+		t.Fatalf("expected the instance of the machine to exist, got %v: %v", existing, err)
+	}
+}
+
+func TestInstanceCreateWithoutServerTags(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	cloud.AddFlavor("m1.medium", 2, 4096, 40)
+	cloud.AddImage("ubuntu")
+	cloud.AddKeyPair("default")
+	cloud.SetMicroversions("2.1", "2.38")
+	s := newTestService(t, cloud)
+
+	machine, openStackMachine := newTestMachines(networkID)
+	if _, err := s.InstanceCreate("test", machine, openStackMachine, &infrav1.OpenStackCluster{}); err != nil {
+		t.Fatalf("failed to create instance on a cloud without server tags: %v", err)
+	}
+	servers := cloud.Resources("servers")
+	if len(servers) != 1 || len(servers[0]["tags"].([]interface{})) != 0 {
+		t.Errorf("expected 1 server without tags, got %v", servers)
+	}
+}
+
+func TestInstanceCreateMicroversionFeatures(t *testing.T) {
+	tests := []struct {
+		name              string
+		maxMicroversion   string
+		trustedCerts      []string
+		wantErr           bool
+		expectedHostname  string
+		expectedDeviceTag string
+	}{
+		{name: "without microversions", maxMicroversion: "2.1", expectedHostname: "machine-0"},
+		{name: "without microversions with trusted certificates", maxMicroversion: "2.1", trustedCerts: []string{"cert"}, wantErr: true},
+		{name: "device tagging", maxMicroversion: "2.42", expectedHostname: "machine-0", expectedDeviceTag: "data"},
+		{name: "trusted certificates", maxMicroversion: "2.63", trustedCerts: []string{"cert"}, expectedHostname: "machine-0", expectedDeviceTag: "data"},
+		{name: "hostname", maxMicroversion: "2.90", trustedCerts: []string{"cert"}, expectedHostname: "Machine-0", expectedDeviceTag: "data"},
+	}
+	for _, tt := range tests {
+		cloud := fake.NewCloud()
+		networkID := cloud.AddNetwork("cluster", false)
+		cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+		cloud.AddFlavor("m1.medium", 2, 4096, 40)
+		cloud.AddImage("ubuntu")
+		cloud.AddKeyPair("default")
+		cloud.SetMicroversions("2.1", tt.maxMicroversion)
+		s := newTestService(t, cloud)
+
+		machine, openStackMachine := newTestMachines(networkID)
+		// Nova derives the hostname in lower case from the name unless it is set explicitly.
+		openStackMachine.Name = "Machine-0"
+		openStackMachine.Spec.Ports = []infrav1.PortOpts{{NetworkID: networkID, Tag: "data"}}
+		openStackMachine.Spec.TrustedImageCertificates = tt.trustedCerts
+		instance, err := s.InstanceCreate("test", machine, openStackMachine, &infrav1.OpenStackCluster{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.wantErr, err)
+		}
+		if err != nil {
+			cloud.Close()
+			continue
+		}
+
+		server := cloud.Resources("servers")[0]
+		if hostname := server["OS-EXT-SRV-ATTR:hostname"]; hostname != tt.expectedHostname {
+			t.Errorf("%s: expected hostname %s, got %v", tt.name, tt.expectedHostname, hostname)
+		}
+		if certs := fmt.Sprint(server["trusted_image_certificates"]); len(tt.trustedCerts) > 0 && certs != fmt.Sprint(tt.trustedCerts) {
+			t.Errorf("%s: expected trusted image certificates %v, got %s", tt.name, tt.trustedCerts, certs)
+		}
+
+		var body struct {
+			InterfaceAttachments []struct {
+				PortID string `json:"port_id"`
+				Tag    string `json:"tag"`
+			} `json:"interfaceAttachments"`
+		}
+		client := *s.computeClient
+		client.Microversion = "2.70"
+		cloud.SetMicroversions("2.1", "2.90")
+		if _, err := client.Get(client.ServiceURL("servers", instance.ID, "os-interface"), &body, nil); err != nil {
+			t.Fatalf("%s: failed to list the interfaces: %v", tt.name, err)
+		}
+		tags := map[string]bool{}
+		for _, attachment := range body.InterfaceAttachments {
+			tags[attachment.Tag] = true
+		}
+		if tt.expectedDeviceTag != "" && !tags[tt.expectedDeviceTag] || tt.expectedDeviceTag == "" && tags["data"] {
+			t.Errorf("%s: expected device tag %q, got %v", tt.name, tt.expectedDeviceTag, body.InterfaceAttachments)
+		}
+		cloud.Close()
+	}
+}
+
+func TestInstanceCreateUnknownImage(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	cloud.AddFlavor("m1.medium", 2, 4096, 40)
+	s := newTestService(t, cloud)
+
+	machine, openStackMachine := newTestMachines(networkID)
+	if _, err := s.InstanceCreate("test", machine, openStackMachine, &infrav1.OpenStackCluster{}); err == nil {
+		t.Fatalf("expected instance creation to fail without image")
+	}
+	if n := len(cloud.Resources("servers")); n != 0 {
+		t.Errorf("expected no server, got %d", n)
+	}
+}
+
+func TestInstanceCreateWithVirtualIP(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	cloud.AddFlavor("m1.medium", 2, 4096, 40)
+	cloud.AddImage("ubuntu")
+	cloud.AddKeyPair("default")
+	s := newTestService(t, cloud)
+
+	machine, openStackMachine := newTestMachines(networkID)
+	machine.Labels = map[string]string{v1alpha2.MachineControlPlaneLabelName: "true"}
+	openStackMachine.Spec.ServerMetadata = map[string]string{"role": "control-plane"}
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{APIServerVirtualIP: &infrav1.APIServerVirtualIP{}},
+		Status: infrav1.OpenStackClusterStatus{
+			Network: &infrav1.Network{ID: networkID, APIServerVirtualIP: &infrav1.VirtualIP{InternalIP: "10.6.0.10"}},
+		},
+	}
+	if _, err := s.InstanceCreate("test", machine, openStackMachine, openStackCluster); err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	metadata := cloud.Resources("servers")[0]["metadata"]
+	expected := map[string]interface{}{
+		"role":                             "control-plane",
+		MetadataAPIServerVirtualIP:         "10.6.0.10",
+		MetadataAPIServerVirtualIPPriority: strconv.Itoa(defaultVirtualIPPriority),
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("expected the virtual IP in the server metadata, got %v", metadata)
+	}
+	if len(openStackMachine.Spec.ServerMetadata) != 1 {
+		t.Errorf("expected the server metadata of the machine not to be modified, got %v", openStackMachine.Spec.ServerMetadata)
+	}
+}
+
+func TestVirtualIPPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority int
+		// clusters are the clusters of the control plane machines, which are created in order.
+		clusters []string
+		expected []string
+	}{
+		{name: "default priority", clusters: []string{"test", "test", "test"}, expected: []string{"100", "99", "98"}},
+		{name: "priority", priority: 10, clusters: []string{"test", "test"}, expected: []string{"10", "9"}},
+		{name: "other cluster", clusters: []string{"other", "test", "test"}, expected: []string{"100", "100", "99"}},
+		{name: "all priorities used", priority: 2, clusters: []string{"test", "test", "test"}, expected: []string{"2", "1", "1"}},
+	}
+	for _, tt := range tests {
+		cloud := fake.NewCloud()
+		networkID := cloud.AddNetwork("cluster", false)
+		cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+		cloud.AddFlavor("m1.medium", 2, 4096, 40)
+		cloud.AddImage("ubuntu")
+		cloud.AddKeyPair("default")
+		s := newTestService(t, cloud)
+
+		openStackCluster := &infrav1.OpenStackCluster{
+			Spec: infrav1.OpenStackClusterSpec{APIServerVirtualIP: &infrav1.APIServerVirtualIP{Priority: tt.priority}},
+			Status: infrav1.OpenStackClusterStatus{
+				Network: &infrav1.Network{ID: networkID, APIServerVirtualIP: &infrav1.VirtualIP{InternalIP: "10.6.0.10"}},
+			},
+		}
+		for i, clusterName := range tt.clusters {
+			machine, openStackMachine := newTestMachines(networkID)
+			machine.Name = fmt.Sprintf("%s-controlplane-%d", clusterName, i)
+			openStackMachine.Name = machine.Name
+			machine.Labels = map[string]string{v1alpha2.MachineControlPlaneLabelName: "true"}
+			if _, err := s.InstanceCreate(clusterName, machine, openStackMachine, openStackCluster); err != nil {
+				t.Fatalf("%s: failed to create instance %s: %v", tt.name, machine.Name, err)
+			}
+		}
+		for i, server := range cloud.Resources("servers") {
+			metadata := server["metadata"].(map[string]interface{})
+			if priority := metadata[MetadataAPIServerVirtualIPPriority]; priority != tt.expected[i] {
+				t.Errorf("%s: expected server %s to have priority %s, got %v", tt.name, server["name"], tt.expected[i], priority)
+			}
+		}
+		cloud.Close()
+	}
+}
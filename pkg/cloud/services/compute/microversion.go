@@ -0,0 +1,188 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gophercloud/gophercloud"
+	"k8s.io/klog"
+)
+
+// The minimum Nova microversions which support the optional features of servers.
+const (
+	// MicroversionDeviceTagging supports device tags of the network interfaces of a server.
+	MicroversionDeviceTagging = "2.42"
+	// MicroversionServerTags supports server tags.
+	MicroversionServerTags = "2.52"
+	// MicroversionTrustedCerts supports the trusted certificates which verify the image of a server.
+	MicroversionTrustedCerts = "2.63"
+	// MicroversionHostname supports setting the hostname of a server.
+	MicroversionHostname = "2.90"
+)
+
+// hostnameRegexp matches the hostnames MicroversionHostname accepts, which are not fully qualified.
+var hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// versionURLRegexp matches the versioned part of a compute endpoint, e.g.
+// https://nova.example.com:8774/v2.1/ in https://nova.example.com:8774/v2.1/<project-id>/
+var versionURLRegexp = regexp.MustCompile(`^(.*/v\d+(\.\d+)?/)`)
+
+// microversion is a parsed Nova API microversion such as 2.52.
+type microversion struct {
+	major int
+	minor int
+}
+
+func parseMicroversion(s string) (microversion, error) {
+	parts := strings.SplitN(strings.TrimPrefix(s, "v"), ".", 2)
+	if len(parts) != 2 {
+		return microversion{}, fmt.Errorf("invalid microversion %q", s)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return microversion{}, fmt.Errorf("invalid microversion %q: %v", s, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return microversion{}, fmt.Errorf("invalid microversion %q: %v", s, err)
+	}
+	return microversion{major: major, minor: minor}, nil
+}
+
+func (m microversion) less(o microversion) bool {
+	if m.major != o.major {
+		return m.major < o.major
+	}
+	return m.minor < o.minor
+}
+
+func (m microversion) String() string {
+	return fmt.Sprintf("%d.%d", m.major, m.minor)
+}
+
+// MicroversionRange is the range of microversions supported by a compute endpoint.
+// A zero MicroversionRange means the endpoint does not support microversions at all.
+type MicroversionRange struct {
+	min microversion
+	max microversion
+}
+
+// Supports returns whether the given microversion is within the range.
+func (r MicroversionRange) Supports(version string) bool {
+	v, err := parseMicroversion(version)
+	if err != nil {
+		return false
+	}
+	return !v.less(r.min) && !r.max.less(v)
+}
+
+// Highest returns the highest of the given microversions supported by the range,
+// or an empty string if none of them is supported.
+func (r MicroversionRange) Highest(versions ...string) string {
+	var highest *microversion
+	for _, version := range versions {
+		if !r.Supports(version) {
+			continue
+		}
+		v, _ := parseMicroversion(version)
+		if highest == nil || highest.less(v) {
+			highest = &v
+		}
+	}
+	if highest == nil {
+		return ""
+	}
+	return highest.String()
+}
+
+func (r MicroversionRange) String() string {
+	return fmt.Sprintf("%s-%s", r.min, r.max)
+}
+
+// microversionCache caches the microversion range of every compute endpoint
+// we talked to, so discovery happens only once per endpoint.
+var microversionCache = struct {
+	sync.Mutex
+	ranges map[string]MicroversionRange
+}{ranges: map[string]MicroversionRange{}}
+
+// GetMicroversionRange returns the microversion range supported by the compute endpoint,
+// discovering it on first use. If the discovery fails, the zero range is returned so the
+// base microversion is used, and the discovery is retried on the next call.
+func (is *Service) GetMicroversionRange() MicroversionRange {
+	versionURL := is.computeClient.Endpoint
+	if match := versionURLRegexp.FindStringSubmatch(versionURL); match != nil {
+		versionURL = match[1]
+	}
+
+	microversionCache.Lock()
+	r, ok := microversionCache.ranges[versionURL]
+	microversionCache.Unlock()
+	if ok {
+		return r
+	}
+
+	// The lock isn't held during the discovery, so a slow endpoint doesn't block the
+	// other clouds. Concurrent discoveries of the same endpoint store the same range.
+	r, err := discoverMicroversionRange(is.computeClient, versionURL)
+	if err != nil {
+		klog.Warningf("Using the base compute microversion for %s: %v", versionURL, err)
+		return MicroversionRange{}
+	}
+	klog.V(3).Infof("Compute endpoint %s supports microversions %s", versionURL, r)
+	microversionCache.Lock()
+	microversionCache.ranges[versionURL] = r
+	microversionCache.Unlock()
+	return r
+}
+
+func discoverMicroversionRange(client *gophercloud.ServiceClient, versionURL string) (MicroversionRange, error) {
+	var body struct {
+		Version struct {
+			MinVersion string `json:"min_version"`
+			Version    string `json:"version"`
+		} `json:"version"`
+	}
+	// Copy the client so the version document is requested without a microversion header.
+	versionClient := *client
+	versionClient.Microversion = ""
+	_, err := versionClient.Get(versionURL, &body, &gophercloud.RequestOpts{
+		OkCodes: []int{200},
+	})
+	if err != nil {
+		return MicroversionRange{}, fmt.Errorf("failed to discover compute microversions: %v", err)
+	}
+
+	// Endpoints without microversion support report empty versions.
+	if body.Version.Version == "" {
+		return MicroversionRange{}, nil
+	}
+	min, err := parseMicroversion(body.Version.MinVersion)
+	if err != nil {
+		return MicroversionRange{}, err
+	}
+	max, err := parseMicroversion(body.Version.Version)
+	if err != nil {
+		return MicroversionRange{}, err
+	}
+	return MicroversionRange{min: min, max: max}, nil
+}
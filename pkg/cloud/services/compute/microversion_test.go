@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"net/http"
+	"testing"
+
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestParseMicroversion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected microversion
+		wantErr  bool
+	}{
+		{version: "2.52", expected: microversion{major: 2, minor: 52}},
+		{version: "v2.1", expected: microversion{major: 2, minor: 1}},
+		{version: "2", wantErr: true},
+		{version: "2.x", wantErr: true},
+		{version: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		v, err := parseMicroversion(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMicroversion(%q): expected error %t, got %v", tt.version, tt.wantErr, err)
+			continue
+		}
+		if v != tt.expected {
+			t.Errorf("parseMicroversion(%q): expected %v, got %v", tt.version, tt.expected, v)
+		}
+	}
+}
+
+func TestMicroversionRange(t *testing.T) {
+	r := MicroversionRange{min: microversion{2, 1}, max: microversion{2, 60}}
+	tests := []struct {
+		r        MicroversionRange
+		versions []string
+		highest  string
+	}{
+		{r: r, versions: []string{"2.52"}, highest: "2.52"},
+		{r: r, versions: []string{"2.1", "2.52", "2.10"}, highest: "2.52"},
+		{r: r, versions: []string{"2.61", "2.90"}, highest: ""},
+		{r: r, versions: []string{"2.0", "invalid"}, highest: ""},
+		{r: MicroversionRange{}, versions: []string{"2.1"}, highest: ""},
+	}
+	for _, tt := range tests {
+		if highest := tt.r.Highest(tt.versions...); highest != tt.highest {
+			t.Errorf("%s.Highest(%v): expected %q, got %q", tt.r, tt.versions, tt.highest, highest)
+		}
+	}
+}
+
+func TestGetMicroversionRange(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	cloud.SetMicroversions("2.1", "2.38")
+	s := newTestService(t, cloud)
+
+	// The range isn't cached if the discovery fails, and the base microversion is used.
+	cloud.InjectFault(fake.Fault{Service: fake.ServiceCompute, Method: http.MethodGet, Path: "^$", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if r := s.GetMicroversionRange(); r != (MicroversionRange{}) {
+		t.Errorf("expected the base microversion if the discovery fails, got %s", r)
+	}
+
+	// The range is discovered once per endpoint.
+	cloud.ResetRequests()
+	for i := 0; i < 2; i++ {
+		r := s.GetMicroversionRange()
+		if !r.Supports("2.38") || r.Supports(MicroversionServerTags) {
+			t.Errorf("expected microversions 2.1-2.38, got %s", r)
+		}
+	}
+	if n := cloud.CountRequests(fake.ServiceCompute, http.MethodGet, "^$"); n != 1 {
+		t.Errorf("expected the microversions to be discovered once, got %d requests", n)
+	}
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsbinding"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsecurity"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/qos/policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
+)
+
+// portWithExtensions is a port including the attributes of the extensions we configure.
+// All fields have to be embedded structs, so lists of ports can be extracted into it.
+type portWithExtensions struct {
+	ports.Port
+	portsecurity.PortSecurityExt
+	policies.QoSPolicyExt
+}
+
+// ReconcilePorts updates the existing ports configured in the Ports of the OpenStackMachine
+// with the attributes Neutron allows to update after creation.
+func (is *Service) ReconcilePorts(openStackMachine *infrav1.OpenStackMachine) error {
+	if len(openStackMachine.Spec.Ports) == 0 {
+		return nil
+	}
+	securityGroups, err := getSecurityGroups(is, openStackMachine.Spec.SecurityGroups)
+	if err != nil {
+		return err
+	}
+	for i, portOpts := range openStackMachine.Spec.Ports {
+		if err := is.requirePortExtensions(portOpts); err != nil {
+			return err
+		}
+		port, err := is.getPort(getPortName(openStackMachine.Name, i, portOpts), portOpts.NetworkID)
+		if err != nil {
+			return err
+		}
+		if port == nil {
+			// Ports are only attached when the server is created.
+			continue
+		}
+		if infrav1.IsExternallyManaged(openStackMachine, port.ID) {
+			klog.V(4).Infof("Skipped updating port %s that is externally managed", port.Name)
+			continue
+		}
+		if _, err := is.updatePortWithOpts(port, portOpts, getPortSecurityGroups(portOpts, securityGroups)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOrCreatePort creates the port for the given PortOpts or updates the existing one.
+func (is *Service) getOrCreatePort(name string, index int, portOpts infrav1.PortOpts, securityGroups []string, tags []string) (*portWithExtensions, error) {
+	if err := is.requirePortExtensions(portOpts); err != nil {
+		return nil, err
+	}
+
+	portName := getPortName(name, index, portOpts)
+	securityGroups = getPortSecurityGroups(portOpts, securityGroups)
+
+	port, err := is.getPort(portName, portOpts.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if port == nil {
+		port, err = is.createPortWithOpts(portName, portOpts, securityGroups)
+	} else {
+		port, err = is.updatePortWithOpts(port, portOpts, securityGroups)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = networking.ReplaceAllAttributesTags(is.networkClient, "ports", port.ID, tags)
+	if err != nil {
+		return nil, fmt.Errorf("tagging port %s err: %v", portName, err)
+	}
+	return port, nil
+}
+
+func getPortName(name string, index int, portOpts infrav1.PortOpts) string {
+	suffix := portOpts.NameSuffix
+	if suffix == "" {
+		suffix = fmt.Sprintf("%d", index)
+	}
+	return fmt.Sprintf("%s-%s", name, suffix)
+}
+
+// getPortSecurityGroups returns the security groups of the port.
+// Security groups can't be set on ports without port security.
+func getPortSecurityGroups(portOpts infrav1.PortOpts, securityGroups []string) []string {
+	if portOpts.PortSecurity != nil && !*portOpts.PortSecurity {
+		return []string{}
+	}
+	return securityGroups
+}
+
+func (is *Service) getPort(portName, networkID string) (*portWithExtensions, error) {
+	allPages, err := ports.List(is.networkClient, ports.ListOpts{
+		Name:      portName,
+		NetworkID: networkID,
+	}).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("searching for existing port %s: %v", portName, err)
+	}
+	var portList []portWithExtensions
+	if err := ports.ExtractPortsInto(allPages, &portList); err != nil {
+		return nil, fmt.Errorf("searching for existing port %s: %v", portName, err)
+	}
+	if len(portList) == 0 {
+		return nil, nil
+	}
+	return &portList[0], nil
+}
+
+func (is *Service) requirePortExtensions(portOpts infrav1.PortOpts) error {
+	if portOpts.VNICType != "" || len(portOpts.Profile) > 0 {
+		if err := networking.RequireExtension(is.networkClient, networking.ExtensionPortBinding, "setting the vnicType or profile of a port"); err != nil {
+			return err
+		}
+	}
+	if portOpts.PortSecurity != nil {
+		if err := networking.RequireExtension(is.networkClient, networking.ExtensionPortSecurity, "setting the port security of a port"); err != nil {
+			return err
+		}
+	}
+	if len(portOpts.AllowedAddressPairs) > 0 {
+		if err := networking.RequireExtension(is.networkClient, networking.ExtensionAllowedAddressPairs, "setting allowed address pairs of a port"); err != nil {
+			return err
+		}
+	}
+	if portOpts.QoSPolicyID != "" {
+		if err := networking.RequireExtension(is.networkClient, networking.ExtensionQoS, "setting the QoS policy of a port"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (is *Service) createPortWithOpts(portName string, portOpts infrav1.PortOpts, securityGroups []string) (*portWithExtensions, error) {
+	baseOpts := ports.CreateOpts{
+		Name:                portName,
+		NetworkID:           portOpts.NetworkID,
+		Description:         portOpts.Description,
+		MACAddress:          portOpts.MACAddress,
+		SecurityGroups:      &securityGroups,
+		AllowedAddressPairs: getAddressPairs(portOpts.AllowedAddressPairs),
+	}
+	if len(portOpts.FixedIPs) > 0 {
+		fixedIPs := make([]ports.IP, 0, len(portOpts.FixedIPs))
+		for _, fixedIP := range portOpts.FixedIPs {
+			fixedIPs = append(fixedIPs, ports.IP{
+				SubnetID:  fixedIP.SubnetID,
+				IPAddress: fixedIP.IPAddress,
+			})
+		}
+		baseOpts.FixedIPs = fixedIPs
+	}
+
+	var createOpts ports.CreateOptsBuilder = baseOpts
+	if portOpts.VNICType != "" || len(portOpts.Profile) > 0 {
+		var profile map[string]interface{}
+		if len(portOpts.Profile) > 0 {
+			profile = make(map[string]interface{}, len(portOpts.Profile))
+			for k, v := range portOpts.Profile {
+				profile[k] = v
+			}
+		}
+		createOpts = portsbinding.CreateOptsExt{
+			CreateOptsBuilder: createOpts,
+			VNICType:          portOpts.VNICType,
+			Profile:           profile,
+		}
+	}
+	if portOpts.PortSecurity != nil {
+		createOpts = portsecurity.PortCreateOptsExt{
+			CreateOptsBuilder:   createOpts,
+			PortSecurityEnabled: portOpts.PortSecurity,
+		}
+	}
+	if portOpts.QoSPolicyID != "" {
+		createOpts = policies.PortCreateOptsExt{
+			CreateOptsBuilder: createOpts,
+			QoSPolicyID:       portOpts.QoSPolicyID,
+		}
+	}
+
+	klog.Infof("Creating port %s", portName)
+	var port portWithExtensions
+	err := ports.Create(is.networkClient, createOpts).ExtractInto(&port)
+	if err != nil {
+		return nil, fmt.Errorf("create port %s: %v", portName, err)
+	}
+	return &port, nil
+}
+
+// updatePortWithOpts updates the attributes of an existing port which Neutron allows to update.
+func (is *Service) updatePortWithOpts(port *portWithExtensions, portOpts infrav1.PortOpts, securityGroups []string) (*portWithExtensions, error) {
+	var updateOpts ports.UpdateOpts
+	needsUpdate := false
+	if port.Description != portOpts.Description {
+		updateOpts.Description = &portOpts.Description
+		needsUpdate = true
+	}
+	addressPairs := getAddressPairs(portOpts.AllowedAddressPairs)
+	if !addressPairsEqual(port.AllowedAddressPairs, addressPairs) {
+		if addressPairs == nil {
+			addressPairs = []ports.AddressPair{}
+		}
+		updateOpts.AllowedAddressPairs = &addressPairs
+		needsUpdate = true
+	}
+
+	// Security groups can only be set on ports with port security, e.g. not on networks with port
+	// security disabled. They are removed in the same request which disables port security, and
+	// re-added when it is enabled again. Without the extension all ports have port security.
+	hasPortSecurity, err := networking.HasExtension(is.networkClient, networking.ExtensionPortSecurity)
+	if err != nil {
+		return nil, err
+	}
+	portSecurityEnabled := port.PortSecurityEnabled || !hasPortSecurity
+	if (portSecurityEnabled || portOpts.PortSecurity != nil && *portOpts.PortSecurity) && !securityGroupsEqual(port.SecurityGroups, securityGroups) {
+		updateOpts.SecurityGroups = &securityGroups
+		needsUpdate = true
+	}
+
+	var opts ports.UpdateOptsBuilder = updateOpts
+	if portOpts.PortSecurity != nil && portSecurityEnabled != *portOpts.PortSecurity {
+		opts = portsecurity.PortUpdateOptsExt{
+			UpdateOptsBuilder:   updateOpts,
+			PortSecurityEnabled: portOpts.PortSecurity,
+		}
+		needsUpdate = true
+	}
+	if port.QoSPolicyID != portOpts.QoSPolicyID {
+		opts = policies.PortUpdateOptsExt{
+			UpdateOptsBuilder: opts,
+			QoSPolicyID:       &portOpts.QoSPolicyID,
+		}
+		needsUpdate = true
+	}
+
+	if !needsUpdate {
+		return port, nil
+	}
+
+	klog.Infof("Updating port %s", port.Name)
+	var updatedPort portWithExtensions
+	err = ports.Update(is.networkClient, port.ID, opts).ExtractInto(&updatedPort)
+	if err != nil {
+		return nil, fmt.Errorf("update port %s: %v", port.Name, err)
+	}
+	return &updatedPort, nil
+}
+
+// securityGroupsEqual returns whether the observed and desired security groups are the same, in any order.
+func securityGroupsEqual(observed, desired []string) bool {
+	if len(observed) != len(desired) {
+		return false
+	}
+	for _, d := range desired {
+		found := false
+		for _, o := range observed {
+			if o == d {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func getAddressPairs(addressPairs []infrav1.AddressPair) []ports.AddressPair {
+	var result []ports.AddressPair
+	for _, pair := range addressPairs {
+		result = append(result, ports.AddressPair{
+			IPAddress:  pair.IPAddress,
+			MACAddress: pair.MACAddress,
+		})
+	}
+	return result
+}
+
+// addressPairsEqual compares the observed with the desired address pairs.
+// Neutron defaults the MAC address of an address pair to the MAC address of the port,
+// so desired pairs without MAC address match observed pairs with any MAC address.
+func addressPairsEqual(observed, desired []ports.AddressPair) bool {
+	if len(observed) != len(desired) {
+		return false
+	}
+	for _, d := range desired {
+		found := false
+		for _, o := range observed {
+			if o.IPAddress == d.IPAddress && (d.MACAddress == "" || o.MACAddress == d.MACAddress) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/utils/pointer"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestGetOrCreatePort(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("cluster", false)
+	subnetID := cloud.AddSubnet(networkID, "cluster", "10.6.0.0/24")
+	groupA := cloud.AddSecurityGroup("a")
+	groupB := cloud.AddSecurityGroup("b")
+	s := newTestService(t, cloud)
+
+	tests := []struct {
+		name           string
+		portOpts       infrav1.PortOpts
+		securityGroups []string
+		expected       func(*testing.T, *portWithExtensions)
+	}{
+		{
+			name: "name suffix, description and fixed IP",
+			portOpts: infrav1.PortOpts{
+				NetworkID:   networkID,
+				NameSuffix:  "data",
+				Description: "data port",
+				FixedIPs:    []infrav1.FixedIP{{SubnetID: subnetID, IPAddress: "10.6.0.20"}},
+			},
+			securityGroups: []string{groupA},
+			expected: func(t *testing.T, port *portWithExtensions) {
+				if port.Name != "machine-data" || port.Description != "data port" {
+					t.Errorf("expected port machine-data with its description, got %s %q", port.Name, port.Description)
+				}
+				if len(port.FixedIPs) != 1 || port.FixedIPs[0].IPAddress != "10.6.0.20" {
+					t.Errorf("expected fixed IP 10.6.0.20, got %+v", port.FixedIPs)
+				}
+				if !reflect.DeepEqual(port.SecurityGroups, []string{groupA}) {
+					t.Errorf("expected security group %s, got %v", groupA, port.SecurityGroups)
+				}
+			},
+		},
+		{
+			name: "without port security",
+			portOpts: infrav1.PortOpts{
+				NetworkID:    networkID,
+				PortSecurity: pointer.BoolPtr(false),
+			},
+			securityGroups: []string{groupA},
+			expected: func(t *testing.T, port *portWithExtensions) {
+				if port.Name != "machine-1" || port.PortSecurityEnabled || len(port.SecurityGroups) != 0 {
+					t.Errorf("expected port machine-1 without port security and security groups, got %+v", port)
+				}
+			},
+		},
+		{
+			name: "allowed address pairs and QoS policy",
+			portOpts: infrav1.PortOpts{
+				NetworkID:           networkID,
+				AllowedAddressPairs: []infrav1.AddressPair{{IPAddress: "10.6.0.100"}},
+				QoSPolicyID:         "qos",
+			},
+			expected: func(t *testing.T, port *portWithExtensions) {
+				if len(port.AllowedAddressPairs) != 1 || port.AllowedAddressPairs[0].IPAddress != "10.6.0.100" {
+					t.Errorf("expected allowed address pair 10.6.0.100, got %+v", port.AllowedAddressPairs)
+				}
+				if port.QoSPolicyID != "qos" {
+					t.Errorf("expected QoS policy qos, got %q", port.QoSPolicyID)
+				}
+			},
+		},
+	}
+	for i, tt := range tests {
+		port, err := s.getOrCreatePort("machine", i, tt.portOpts, tt.securityGroups, []string{"test"})
+		if err != nil {
+			t.Errorf("%s: failed to create port: %v", tt.name, err)
+			continue
+		}
+		tt.expected(t, port)
+	}
+
+	// The security groups of an existing port are updated, and nothing else changes.
+	portOpts := tests[0].portOpts
+	cloud.ResetRequests()
+	port, err := s.getOrCreatePort("machine", 0, portOpts, []string{groupB, groupA}, []string{"test"})
+	if err != nil {
+		t.Fatalf("failed to update port: %v", err)
+	}
+	groups := append([]string(nil), port.SecurityGroups...)
+	sort.Strings(groups)
+	expected := []string{groupA, groupB}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expected security groups %v, got %v", expected, groups)
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPut, "^ports/[^/]+$"); n != 1 {
+		t.Errorf("expected the port to be updated once, got %d requests", n)
+	}
+	cloud.ResetRequests()
+	if _, err := s.getOrCreatePort("machine", 0, portOpts, []string{groupA, groupB}, []string{"test"}); err != nil {
+		t.Fatalf("failed to reconcile port: %v", err)
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPut, "^ports/[^/]+$"); n != 0 {
+		t.Errorf("expected a port with the same security groups in another order not to be updated, got %d requests", n)
+	}
+
+	// Disabling port security removes the security groups in the same request.
+	portOpts.PortSecurity = pointer.BoolPtr(false)
+	port, err = s.getOrCreatePort("machine", 0, portOpts, []string{groupA}, []string{"test"})
+	if err != nil {
+		t.Fatalf("failed to disable port security: %v", err)
+	}
+	if port.PortSecurityEnabled || len(port.SecurityGroups) != 0 {
+		t.Errorf("expected port security to be disabled without security groups, got %+v", port)
+	}
+
+	// The security groups of a port without port security, e.g. on a network with port security
+	// disabled, aren't updated when the port security isn't specified.
+	portOpts.PortSecurity = nil
+	cloud.ResetRequests()
+	if _, err := s.getOrCreatePort("machine", 0, portOpts, []string{groupA}, []string{"test"}); err != nil {
+		t.Fatalf("failed to reconcile port without port security: %v", err)
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPut, "^ports/[^/]+$"); n != 0 {
+		t.Errorf("expected a port without port security not to be updated, got %d requests", n)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/gophercloud/utils/openstack/clientconfig"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
 )
 
 type Service struct {
@@ -32,30 +33,30 @@ type Service struct {
 }
 
 func NewService(client *gophercloud.ProviderClient, clientOpts *clientconfig.ClientOpts) (*Service, error) {
-	identityClient, err := openstack.NewIdentityV3(client, gophercloud.EndpointOpts{
+	identityClient, err := provider.NewServiceClient(client, "identity", gophercloud.EndpointOpts{
 		Region: "",
-	})
+	}, openstack.NewIdentityV3)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create identity service client: %v", err)
 	}
 
-	computeClient, err := openstack.NewComputeV2(client, gophercloud.EndpointOpts{
+	computeClient, err := provider.NewServiceClient(client, "compute", gophercloud.EndpointOpts{
 		Region: clientOpts.RegionName,
-	})
+	}, openstack.NewComputeV2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compute service client: %v", err)
 	}
 
-	networkingClient, err := openstack.NewNetworkV2(client, gophercloud.EndpointOpts{
+	networkingClient, err := provider.NewServiceClient(client, "network", gophercloud.EndpointOpts{
 		Region: clientOpts.RegionName,
-	})
+	}, openstack.NewNetworkV2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create networking service client: %v", err)
 	}
 
-	imagesClient, err := openstack.NewImageServiceV2(client, gophercloud.EndpointOpts{
+	imagesClient, err := provider.NewServiceClient(client, "image", gophercloud.EndpointOpts{
 		Region: clientOpts.RegionName,
-	})
+	}, openstack.NewImageServiceV2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create image service client: %v", err)
 	}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/trunks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// reconcileSubports creates the ports for the given subports, tags them and attaches them to the trunk.
+func (is *Service) reconcileSubports(name string, trunk *trunks.Trunk, subportParams []infrav1.SubportParam, securityGroups *[]string, tags []string) ([]infrav1.Subport, error) {
+	var observedSubports []infrav1.Subport
+	var missingSubports []trunks.Subport
+	for _, subportParam := range subportParams {
+		segmentationType := subportParam.SegmentationType
+		if segmentationType == "" {
+			segmentationType = infrav1.DefaultSegmentationType
+		}
+
+		opts := networks.ListOpts(subportParam.Filter)
+		opts.ID = subportParam.UUID
+		ids, err := getNetworkIDsByFilter(is.networkClient, &opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) != 1 {
+			return nil, fmt.Errorf("subport with segmentation ID %d must match exactly one network, but matched %d", subportParam.SegmentationID, len(ids))
+		}
+		networkID := ids[0]
+
+		portName := fmt.Sprintf("%s-subport-%d", name, subportParam.SegmentationID)
+		allPages, err := ports.List(is.networkClient, ports.ListOpts{
+			Name:      portName,
+			NetworkID: networkID,
+		}).AllPages()
+		if err != nil {
+			return nil, fmt.Errorf("searching for existing subport port: %v", err)
+		}
+		portList, err := ports.ExtractPorts(allPages)
+		if err != nil {
+			return nil, fmt.Errorf("searching for existing subport port: %v", err)
+		}
+		var port ports.Port
+		if len(portList) == 0 {
+			klog.Infof("Creating subport port %s", portName)
+			port, err = createPort(is, portName, ServerNetwork{networkID: networkID}, securityGroups)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create subport port: %v", err)
+			}
+		} else {
+			port = portList[0]
+		}
+
+		err = networking.ReplaceAllAttributesTags(is.networkClient, "ports", port.ID, tags)
+		if err != nil {
+			return nil, fmt.Errorf("tagging subport port err: %v", err)
+		}
+
+		if !hasSubport(trunk, port.ID) {
+			missingSubports = append(missingSubports, trunks.Subport{
+				PortID:           port.ID,
+				SegmentationType: segmentationType,
+				SegmentationID:   subportParam.SegmentationID,
+			})
+		}
+
+		observedSubports = append(observedSubports, infrav1.Subport{
+			PortID:           port.ID,
+			NetworkID:        networkID,
+			TrunkID:          trunk.ID,
+			SegmentationType: segmentationType,
+			SegmentationID:   subportParam.SegmentationID,
+		})
+	}
+
+	if len(missingSubports) > 0 {
+		klog.Infof("Adding %d subports to trunk %s", len(missingSubports), trunk.ID)
+		_, err := trunks.AddSubports(is.networkClient, trunk.ID, trunks.AddSubportsOpts{
+			Subports: missingSubports,
+		}).Extract()
+		if err != nil {
+			return nil, fmt.Errorf("adding subports to trunk %s err: %v", trunk.ID, err)
+		}
+	}
+	return observedSubports, nil
+}
+
+func hasSubport(trunk *trunks.Trunk, portID string) bool {
+	for _, subport := range trunk.Subports {
+		if subport.PortID == portID {
+			return true
+		}
+	}
+	return false
+}
+
+// getTrunkOfPort returns the trunk of the given parent port, or nil if the port has no trunk.
+func (is *Service) getTrunkOfPort(portID string) (*trunks.Trunk, error) {
+	allTrunks, err := trunks.List(is.networkClient, trunks.ListOpts{
+		PortID: portID,
+	}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	trunkList, err := trunks.ExtractTrunks(allTrunks)
+	if err != nil {
+		return nil, err
+	}
+	if len(trunkList) != 1 {
+		return nil, nil
+	}
+	return &trunkList[0], nil
+}
+
+// removeSubports removes all subports from the trunk, so their ports can be deleted.
+func (is *Service) removeSubports(trunk *trunks.Trunk) error {
+	if len(trunk.Subports) == 0 {
+		return nil
+	}
+	opts := trunks.RemoveSubportsOpts{}
+	for _, subport := range trunk.Subports {
+		opts.Subports = append(opts.Subports, trunks.RemoveSubport{PortID: subport.PortID})
+	}
+	klog.Infof("Removing %d subports from trunk %s", len(opts.Subports), trunk.ID)
+	if _, err := trunks.RemoveSubports(is.networkClient, trunk.ID, opts).Extract(); err != nil {
+		return fmt.Errorf("removing subports from trunk %s err: %v", trunk.ID, err)
+	}
+	return nil
+}
+
+// deleteSubportPorts deletes the ports of the given subports, which have to be removed from their
+// trunk before. Ports which are already deleted or listed twice are skipped.
+func (is *Service) deleteSubportPorts(subports []trunks.Subport) error {
+	deleted := map[string]bool{}
+	for _, subport := range subports {
+		if deleted[subport.PortID] {
+			continue
+		}
+		deleted[subport.PortID] = true
+		klog.Infof("Deleting subport port %s", subport.PortID)
+		var lastErr error
+		err := util.PollImmediate(RetryIntervalPortDelete, TimeoutPortDelete, func() (bool, error) {
+			lastErr = ports.Delete(is.networkClient, subport.PortID).ExtractErr()
+			if lastErr != nil && !networking.IsNotFound(lastErr) {
+				return false, nil
+			}
+			return true, nil
+		})
+		if err != nil {
+			return fmt.Errorf("error deleting the subport port %v: %v", subport.PortID, lastErr)
+		}
+	}
+	return nil
+}
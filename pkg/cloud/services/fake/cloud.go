@@ -0,0 +1,716 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake implements an in-memory OpenStack cloud for tests. It serves the subsets of
+// Keystone v3, Nova, Neutron, Glance and Octavia used by the provider over HTTP, keeps the
+// state of the resources created through them and can inject faults into the requests.
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/utils/openstack/clientconfig"
+)
+
+const (
+	// Username, Password, ProjectName and DomainName are the credentials accepted by the cloud.
+	Username    = "admin"
+	Password    = "secret"
+	ProjectName = "admin"
+	DomainName  = "Default"
+	// RegionName is the region of all endpoints in the service catalog.
+	RegionName = "RegionOne"
+
+	// DefaultMinMicroversion and DefaultMaxMicroversion are the compute API microversions
+	// supported by the cloud unless configured otherwise.
+	DefaultMinMicroversion = "2.1"
+	DefaultMaxMicroversion = "2.79"
+
+	// DefaultTokenTTL is how long the tokens issued by the cloud are valid.
+	DefaultTokenTTL = time.Hour
+)
+
+// Service types and the root paths the services are served on.
+const (
+	ServiceIdentity     = "identity"
+	ServiceCompute      = "compute"
+	ServiceNetwork      = "network"
+	ServiceImage        = "image"
+	ServiceLoadBalancer = "load-balancer"
+)
+
+var servicePaths = map[string]string{
+	ServiceIdentity:     "/identity/",
+	ServiceCompute:      "/compute/v2.1/",
+	ServiceNetwork:      "/network/v2.0/",
+	ServiceImage:        "/image/v2/",
+	ServiceLoadBalancer: "/load-balancer/v2.0/",
+}
+
+// DefaultExtensions are the Neutron extensions available unless configured otherwise.
+var DefaultExtensions = []string{
+	"allowed-address-pairs",
+	"binding",
+	"dns-integration",
+	"external-net",
+	"lbaasv2",
+	"port-security",
+	"qos",
+	"router",
+	"security-group",
+	"standard-attr-tag",
+	"trunk",
+}
+
+// Fault makes the requests matching it fail.
+type Fault struct {
+	// Service is the service type of the requests that fail, or empty for all services.
+	Service string
+	// Method is the HTTP method of the requests that fail, or empty for all methods.
+	Method string
+	// Path is a regular expression matched against the path of the requests relative to the
+	// root of their service, e.g. "servers" or "lbaas/loadbalancers/[^/]+$". Empty matches all paths.
+	Path string
+	// StatusCode is returned for the failing requests. If it is 0 the connection is closed
+	// without response.
+	StatusCode int
+	// Times is how many requests fail, 0 means all requests until the faults are cleared.
+	Times int
+
+	path  *regexp.Regexp
+	count int
+}
+
+// Request is a request received by the cloud.
+type Request struct {
+	Service string
+	Method  string
+	// Path is the path of the request relative to the root of its service.
+	Path string
+}
+
+// object is an OpenStack resource as it is serialized in the API.
+type object map[string]interface{}
+
+// Cloud is an in-memory OpenStack cloud served by an HTTP test server.
+type Cloud struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+	// resources maps the collections, e.g. "networks", to their resources in order of creation.
+	resources map[string][]object
+	// autoPorts are the ports Nova created for servers, which are deleted with them.
+	autoPorts map[string]bool
+	// deviceTags are the device tags of the network interfaces of servers by port ID.
+	deviceTags map[string]string
+	// pending counts the remaining GETs a server is building or a load balancer is provisioning.
+	pending map[string]int
+	tokens  map[string]time.Time
+
+	extensions      []string
+	minMicroversion string
+	maxMicroversion string
+	tokenTTL        time.Duration
+	buildPolls      int
+	provisionPolls  int
+	faults          []*Fault
+	requests        []Request
+
+	user    object
+	project object
+}
+
+// NewCloud starts a new cloud. It must be closed when it's no longer used.
+func NewCloud() *Cloud {
+	c := &Cloud{
+		resources:       map[string][]object{},
+		autoPorts:       map[string]bool{},
+		deviceTags:      map[string]string{},
+		pending:         map[string]int{},
+		tokens:          map[string]time.Time{},
+		extensions:      DefaultExtensions,
+		minMicroversion: DefaultMinMicroversion,
+		maxMicroversion: DefaultMaxMicroversion,
+		tokenTTL:        DefaultTokenTTL,
+	}
+	c.user = object{"id": newID(), "name": Username, "domain": object{"id": "default", "name": DomainName}}
+	c.project = object{"id": newID(), "name": ProjectName, "domain": object{"id": "default", "name": DomainName}}
+	c.server = httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+	return c
+}
+
+// Close shuts the cloud down.
+func (c *Cloud) Close() {
+	c.server.Close()
+}
+
+// URL returns the base URL of the cloud.
+func (c *Cloud) URL() string {
+	return c.server.URL
+}
+
+// AuthURL returns the Keystone v3 endpoint of the cloud.
+func (c *Cloud) AuthURL() string {
+	return c.server.URL + "/identity/v3"
+}
+
+// ProjectID returns the ID of the project the credentials are scoped to.
+func (c *Cloud) ProjectID() string {
+	return c.project["id"].(string)
+}
+
+// CloudsYAML returns a clouds.yaml containing the cloud with the given name.
+func (c *Cloud) CloudsYAML(cloudName string) []byte {
+	return []byte(fmt.Sprintf(`clouds:
+  %s:
+    auth:
+      auth_url: %s
+      username: %s
+      password: %s
+      project_name: %s
+      user_domain_name: %s
+      project_domain_name: %s
+    region_name: %s
+`, cloudName, c.AuthURL(), Username, Password, ProjectName, DomainName, DomainName, RegionName))
+}
+
+// NewClient returns a provider client authenticated against the cloud and the client options of the cloud.
+func (c *Cloud) NewClient() (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
+	client, err := openstack.AuthenticatedClient(gophercloud.AuthOptions{
+		IdentityEndpoint: c.AuthURL(),
+		Username:         Username,
+		Password:         Password,
+		TenantName:       ProjectName,
+		DomainName:       DomainName,
+		AllowReauth:      true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to authenticate to the fake cloud: %v", err)
+	}
+	return client, &clientconfig.ClientOpts{RegionName: RegionName}, nil
+}
+
+// SetExtensions configures the Neutron extensions available in the cloud.
+func (c *Cloud) SetExtensions(aliases ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.extensions = aliases
+}
+
+// SetMicroversions configures the range of compute API microversions supported by the cloud.
+func (c *Cloud) SetMicroversions(min, max string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.minMicroversion = min
+	c.maxMicroversion = max
+}
+
+// SetTokenTTL configures how long the tokens issued from now on are valid.
+func (c *Cloud) SetTokenTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenTTL = ttl
+}
+
+// SetBuildPolls configures how many times a new server is returned as BUILD before it becomes ACTIVE.
+func (c *Cloud) SetBuildPolls(polls int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buildPolls = polls
+}
+
+// SetProvisioningPolls configures how many times a load balancer is returned as pending
+// after it or one of its children changed, before it becomes ACTIVE again.
+func (c *Cloud) SetProvisioningPolls(polls int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.provisionPolls = polls
+}
+
+// RevokeTokens revokes all issued tokens, so clients have to authenticate again.
+func (c *Cloud) RevokeTokens() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = map[string]time.Time{}
+}
+
+// InjectFault makes the requests matching the fault fail. Faults are matched in the order they were injected.
+func (c *Cloud) InjectFault(fault Fault) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f := fault
+	f.path = regexp.MustCompile(f.Path)
+	c.faults = append(c.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (c *Cloud) ClearFaults() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = nil
+}
+
+// Requests returns the requests received by the cloud in order.
+func (c *Cloud) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Request(nil), c.requests...)
+}
+
+// CountRequests returns the number of requests received for the service and method
+// whose path matches the regular expression.
+func (c *Cloud) CountRequests(service, method, path string) int {
+	re := regexp.MustCompile(path)
+	count := 0
+	for _, r := range c.Requests() {
+		if r.Service == service && r.Method == method && re.MatchString(r.Path) {
+			count++
+		}
+	}
+	return count
+}
+
+// ResetRequests forgets the requests received so far.
+func (c *Cloud) ResetRequests() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = nil
+}
+
+func (c *Cloud) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	service, path := splitPath(r.URL.Path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, Request{Service: service, Method: r.Method, Path: path})
+	if fault := c.matchFault(service, r.Method, path); fault != nil {
+		if fault.StatusCode == 0 {
+			closeConnection(w)
+			return
+		}
+		writeError(w, service, fault.StatusCode, "injected fault")
+		return
+	}
+
+	var body object
+	if r.Body != nil {
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, service, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(content) > 0 {
+			if err := json.Unmarshal(content, &body); err != nil {
+				writeError(w, service, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+				return
+			}
+		}
+	}
+
+	req := &request{Request: r, service: service, path: path, segments: strings.Split(strings.Trim(path, "/"), "/"), body: body}
+	if service != ServiceIdentity && !c.authorized(r) {
+		writeError(w, service, http.StatusUnauthorized, "The request you have made requires authentication.")
+		return
+	}
+
+	var status int
+	var response interface{}
+	switch service {
+	case ServiceIdentity:
+		status, response = c.serveIdentity(w, req)
+	case ServiceCompute:
+		status, response = c.serveCompute(req)
+	case ServiceNetwork:
+		status, response = c.serveNetwork(req)
+	case ServiceImage:
+		status, response = c.serveImage(req)
+	case ServiceLoadBalancer:
+		status, response = c.serveLoadBalancer(req)
+	default:
+		status, response = notFound(service, "the resource could not be found")
+	}
+	writeResponse(w, service, status, response)
+}
+
+// request is a request dispatched to the handlers of a service.
+type request struct {
+	*http.Request
+	service  string
+	path     string
+	segments []string
+	body     object
+}
+
+// match returns whether the method and the segments of the path match the pattern,
+// in which "*" matches any segment.
+func (r *request) match(method string, pattern ...string) bool {
+	if r.Method != method || len(r.segments) != len(pattern) {
+		return false
+	}
+	for i, p := range pattern {
+		if p != "*" && p != r.segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath returns the service of the path and the path relative to the root of the service.
+func splitPath(path string) (string, string) {
+	for service, root := range servicePaths {
+		if strings.HasPrefix(path+"/", root) {
+			return service, strings.Trim(strings.TrimPrefix(path, strings.TrimSuffix(root, "/")), "/")
+		}
+	}
+	return "", path
+}
+
+func (c *Cloud) matchFault(service, method, path string) *Fault {
+	for i, f := range c.faults {
+		if (f.Service != "" && f.Service != service) || (f.Method != "" && f.Method != method) || !f.path.MatchString(path) {
+			continue
+		}
+		f.count++
+		if f.Times > 0 && f.count >= f.Times {
+			c.faults = append(c.faults[:i:i], c.faults[i+1:]...)
+		}
+		return f
+	}
+	return nil
+}
+
+func (c *Cloud) authorized(r *http.Request) bool {
+	expiry, ok := c.tokens[r.Header.Get("X-Auth-Token")]
+	return ok && time.Now().Before(expiry)
+}
+
+// closeConnection closes the connection of the request without response.
+func closeConnection(w http.ResponseWriter) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			conn.Close()
+			return
+		}
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func writeResponse(w http.ResponseWriter, service string, status int, response interface{}) {
+	if response == nil {
+		w.WriteHeader(status)
+		return
+	}
+	content, err := json.Marshal(response)
+	if err != nil {
+		writeError(w, service, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(content)
+}
+
+func writeError(w http.ResponseWriter, service string, status int, message string) {
+	status, response := errorResponse(service, status, message)
+	writeResponse(w, service, status, response)
+}
+
+// errorResponse returns an error in the format of the service.
+func errorResponse(service string, status int, message string) (int, interface{}) {
+	switch service {
+	case ServiceIdentity:
+		return status, object{"error": object{"code": status, "message": message, "title": http.StatusText(status)}}
+	case ServiceCompute:
+		key := map[int]string{
+			http.StatusBadRequest:   "badRequest",
+			http.StatusUnauthorized: "unauthorized",
+			http.StatusForbidden:    "forbidden",
+			http.StatusNotFound:     "itemNotFound",
+			http.StatusConflict:     "conflictingRequest",
+		}[status]
+		if key == "" {
+			key = "computeFault"
+		}
+		return status, object{key: object{"code": status, "message": message}}
+	case ServiceNetwork:
+		return status, object{"NeutronError": object{"type": strings.Replace(http.StatusText(status), " ", "", -1), "message": message, "detail": ""}}
+	case ServiceLoadBalancer:
+		return status, object{"faultcode": "Client", "faultstring": message, "debuginfo": nil}
+	}
+	return status, object{"message": message, "code": status}
+}
+
+func notFound(service, format string, args ...interface{}) (int, interface{}) {
+	return errorResponse(service, http.StatusNotFound, fmt.Sprintf(format, args...))
+}
+
+func badRequest(service, format string, args ...interface{}) (int, interface{}) {
+	return errorResponse(service, http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+func conflict(service, format string, args ...interface{}) (int, interface{}) {
+	return errorResponse(service, http.StatusConflict, fmt.Sprintf(format, args...))
+}
+
+// add stores a resource in a collection, assigning an ID if it has none.
+func (c *Cloud) add(collection string, obj object) object {
+	if _, ok := obj["id"]; !ok {
+		obj["id"] = newID()
+	}
+	c.resources[collection] = append(c.resources[collection], obj)
+	return obj
+}
+
+// get returns the resource of the collection with the given ID, or nil.
+func (c *Cloud) get(collection, id string) object {
+	for _, obj := range c.resources[collection] {
+		if obj["id"] == id {
+			return obj
+		}
+	}
+	return nil
+}
+
+// remove deletes the resource of the collection with the given ID.
+func (c *Cloud) remove(collection, id string) {
+	objs := c.resources[collection]
+	for i, obj := range objs {
+		if obj["id"] == id {
+			c.resources[collection] = append(objs[:i:i], objs[i+1:]...)
+			return
+		}
+	}
+}
+
+// list returns the resources of the collection matching the filter.
+func (c *Cloud) list(collection string, filter func(object) bool) []object {
+	result := []object{}
+	for _, obj := range c.resources[collection] {
+		if filter == nil || filter(obj) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// ignoredQueryParameters are the query parameters which don't filter lists.
+var ignoredQueryParameters = map[string]bool{
+	"limit": true, "marker": true, "page_reverse": true, "sort_key": true, "sort_dir": true, "fields": true, "cascade": true,
+}
+
+// queryFilter returns a filter matching resources whose fields equal the query parameters, the way Neutron,
+// Octavia and Glance filter lists. "tags" and "tags-any" match the tags of the resource, and "<resource>_id"
+// matches the IDs in the "<resource>s" field, e.g. loadbalancer_id matches the loadbalancers of a listener.
+func queryFilter(r *request) func(object) bool {
+	query := r.URL.Query()
+	return func(obj object) bool {
+		for key, values := range query {
+			if ignoredQueryParameters[key] || len(values) == 0 {
+				continue
+			}
+			value := values[0]
+			switch key {
+			case "tags":
+				for _, tag := range strings.Split(value, ",") {
+					if !hasString(obj["tags"], tag) {
+						return false
+					}
+				}
+				continue
+			case "tags-any":
+				found := false
+				for _, tag := range strings.Split(value, ",") {
+					found = found || hasString(obj["tags"], tag)
+				}
+				if !found {
+					return false
+				}
+				continue
+			}
+
+			field, ok := obj[key]
+			if plural := strings.TrimSuffix(key, "_id") + "s"; !ok && strings.HasSuffix(key, "_id") && obj[plural] != nil {
+				if !hasRef(objectList(obj, plural), value) {
+					return false
+				}
+				continue
+			}
+			if !ok || fieldString(field) != value {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func fieldString(field interface{}) string {
+	if field == nil {
+		return ""
+	}
+	return fmt.Sprint(field)
+}
+
+func hasString(list interface{}, s string) bool {
+	switch l := list.(type) {
+	case []string:
+		for _, e := range l {
+			if e == s {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range l {
+			if e == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasRef(refs []object, id string) bool {
+	for _, ref := range refs {
+		if ref["id"] == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeRef(refs []object, id string) []object {
+	result := []object{}
+	for _, ref := range refs {
+		if ref["id"] != id {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// copyObject returns a deep copy of the resource, so it can be serialized without holding the lock.
+func copyObject(obj object) object {
+	content, _ := json.Marshal(obj)
+	var result object
+	_ = json.Unmarshal(content, &result)
+	return result
+}
+
+func copyObjects(objs []object) []object {
+	result := make([]object, 0, len(objs))
+	for _, obj := range objs {
+		result = append(result, copyObject(obj))
+	}
+	return result
+}
+
+// merge copies the fields of the request body into the resource, except the read-only ones.
+func merge(obj, body object, readOnly ...string) {
+	for key, value := range body {
+		skip := false
+		for _, r := range readOnly {
+			skip = skip || r == key
+		}
+		if !skip {
+			obj[key] = value
+		}
+	}
+}
+
+func stringField(obj object, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
+
+func intField(obj object, key string) int {
+	switch v := obj[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+func objectField(obj object, key string) object {
+	switch v := obj[key].(type) {
+	case object:
+		return v
+	case map[string]interface{}:
+		return object(v)
+	}
+	return nil
+}
+
+func objectList(obj object, key string) []object {
+	var result []object
+	switch v := obj[key].(type) {
+	case []object:
+		return v
+	case []interface{}:
+		for _, e := range v {
+			if m, ok := e.(map[string]interface{}); ok {
+				result = append(result, object(m))
+			}
+		}
+	}
+	return result
+}
+
+func stringList(obj object, key string) []string {
+	result := []string{}
+	switch v := obj[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// newID returns a random UUID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// newMAC returns a random MAC address with the OpenStack prefix.
+func newMAC() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("fa:16:3e:%02x:%02x:%02x", b[0], b[1], b[2])
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/imageservice/v2/images"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/loadbalancers"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/monitors"
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+)
+
+func newServiceClients(t *testing.T, c *Cloud) (*gophercloud.ServiceClient, *gophercloud.ServiceClient) {
+	client, _, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	computeClient, err := openstack.NewComputeV2(client, gophercloud.EndpointOpts{Region: RegionName})
+	if err != nil {
+		t.Fatalf("failed to create compute client: %v", err)
+	}
+	networkClient, err := openstack.NewNetworkV2(client, gophercloud.EndpointOpts{Region: RegionName})
+	if err != nil {
+		t.Fatalf("failed to create network client: %v", err)
+	}
+	return computeClient, networkClient
+}
+
+func TestServerLifecycle(t *testing.T) {
+	c := NewCloud()
+	defer c.Close()
+	networkID := c.AddNetwork("private", false)
+	c.AddSubnet(networkID, "private", "10.0.0.0/24")
+	flavorID := c.AddFlavor("m1.small", 1, 2048, 20)
+	imageID := c.AddImage("ubuntu")
+	c.SetBuildPolls(1)
+	computeClient, networkClient := newServiceClients(t, c)
+
+	server, err := servers.Create(computeClient, servers.CreateOpts{
+		Name:      "server",
+		FlavorRef: flavorID,
+		ImageRef:  imageID,
+		Networks:  []servers.Network{{UUID: networkID}},
+	}).Extract()
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	server, err = servers.Get(computeClient, server.ID).Extract()
+	if err != nil {
+		t.Fatalf("failed to get server: %v", err)
+	}
+	if server.Status != "ACTIVE" {
+		t.Errorf("expected server to be ACTIVE after one poll, got %s", server.Status)
+	}
+	addresses, ok := server.Addresses["private"].([]interface{})
+	if !ok || len(addresses) != 1 || addresses[0].(map[string]interface{})["addr"] != "10.0.0.2" {
+		t.Errorf("expected server to have address 10.0.0.2 on network private, got %v", server.Addresses)
+	}
+
+	if err := servers.Delete(computeClient, server.ID).ExtractErr(); err != nil {
+		t.Fatalf("failed to delete server: %v", err)
+	}
+	if _, err := servers.Get(computeClient, server.ID).Extract(); !isNotFound(err) {
+		t.Errorf("expected deleted server to be not found, got %v", err)
+	}
+	allPages, err := ports.List(networkClient, ports.ListOpts{}).AllPages()
+	if err != nil {
+		t.Fatalf("failed to list ports: %v", err)
+	}
+	portList, _ := ports.ExtractPorts(allPages)
+	if len(portList) != 0 {
+		t.Errorf("expected the port of the server to be deleted with it, got %v", portList)
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	c := NewCloud()
+	defer c.Close()
+	_, networkClient := newServiceClients(t, c)
+
+	c.InjectFault(Fault{Service: ServiceNetwork, Method: http.MethodGet, Path: "^networks$", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if _, err := networks.List(networkClient, networks.ListOpts{}).AllPages(); err == nil {
+		t.Errorf("expected the injected fault to fail the request")
+	}
+	if _, err := networks.List(networkClient, networks.ListOpts{}).AllPages(); err != nil {
+		t.Errorf("expected the fault to fail only once, got %v", err)
+	}
+
+	c.InjectFault(Fault{Service: ServiceNetwork, Path: "^networks"})
+	if _, err := networks.Create(networkClient, networks.CreateOpts{Name: "net"}).Extract(); err == nil {
+		t.Errorf("expected the connection to be closed")
+	}
+	c.ClearFaults()
+	if _, err := networks.Create(networkClient, networks.CreateOpts{Name: "net"}).Extract(); err != nil {
+		t.Errorf("expected request to succeed after clearing the faults, got %v", err)
+	}
+	if n := c.CountRequests(ServiceNetwork, http.MethodPost, "^networks$"); n != 2 {
+		t.Errorf("expected 2 network creations, got %d", n)
+	}
+}
+
+func TestReauthentication(t *testing.T) {
+	c := NewCloud()
+	defer c.Close()
+	client, _, err := c.NewClient()
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	imageClient, err := openstack.NewImageServiceV2(client, gophercloud.EndpointOpts{Region: RegionName})
+	if err != nil {
+		t.Fatalf("failed to create image client: %v", err)
+	}
+	c.AddImage("ubuntu")
+
+	c.RevokeTokens()
+	allPages, err := images.List(imageClient, images.ListOpts{Name: "ubuntu"}).AllPages()
+	if err != nil {
+		t.Fatalf("expected the client to reauthenticate, got %v", err)
+	}
+	imageList, err := images.ExtractImages(allPages)
+	if err != nil || len(imageList) != 1 {
+		t.Errorf("expected one image, got %v: %v", imageList, err)
+	}
+	if n := c.CountRequests(ServiceIdentity, http.MethodPost, "auth/tokens"); n != 2 {
+		t.Errorf("expected 2 authentications, got %d", n)
+	}
+}
+
+func isNotFound(err error) bool {
+	_, ok := err.(gophercloud.ErrDefault404)
+	return ok
+}
+
+func TestLoadBalancerProviders(t *testing.T) {
+	cloud := NewCloud()
+	defer cloud.Close()
+	networkID := cloud.AddNetwork("network", false)
+	cloud.AddSubnet(networkID, "subnet", "10.0.0.0/24")
+	client, _, err := cloud.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lbClient, err := openstack.NewLoadBalancerV2(client, gophercloud.EndpointOpts{Region: RegionName})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadbalancers.Create(lbClient, loadbalancers.CreateOpts{VipNetworkID: networkID, Provider: "unknown"}).Extract(); err == nil {
+		t.Errorf("expected an unknown provider to be rejected")
+	}
+	lb, err := loadbalancers.Create(lbClient, loadbalancers.CreateOpts{VipNetworkID: networkID, Provider: "ovn"}).Extract()
+	if err != nil {
+		t.Fatalf("failed to create load balancer: %v", err)
+	}
+	if _, err := pools.Create(lbClient, pools.CreateOpts{LoadbalancerID: lb.ID, Protocol: pools.ProtocolTCP, LBMethod: pools.LBMethodRoundRobin}).Extract(); err == nil {
+		t.Errorf("expected the OVN provider to reject ROUND_ROBIN pools")
+	}
+	pool, err := pools.Create(lbClient, pools.CreateOpts{LoadbalancerID: lb.ID, Protocol: pools.ProtocolTCP, LBMethod: "SOURCE_IP_PORT"}).Extract()
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	if _, err := monitors.Create(lbClient, monitors.CreateOpts{PoolID: pool.ID, Type: "HTTPS", Delay: 5, Timeout: 5, MaxRetries: 3}).Extract(); err == nil {
+		t.Errorf("expected the OVN provider to reject HTTPS monitors")
+	}
+}
@@ -0,0 +1,596 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AddFlavor creates a flavor and returns its ID.
+func (c *Cloud) AddFlavor(name string, vcpus, ramMB, diskGB int) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.add("flavors", object{
+		"name":                       name,
+		"vcpus":                      vcpus,
+		"ram":                        ramMB,
+		"disk":                       diskGB,
+		"swap":                       "",
+		"OS-FLV-EXT-DATA:ephemeral":  0,
+		"OS-FLV-DISABLED:disabled":   false,
+		"os-flavor-access:is_public": true,
+		"rxtx_factor":                1.0,
+	})["id"].(string)
+}
+
+// AddKeyPair creates a key pair.
+func (c *Cloud) AddKeyPair(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add("keypairs", object{"id": name, "name": name, "fingerprint": "", "public_key": "ssh-rsa AAAA"})
+}
+
+// SetAvailabilityZones configures the compute availability zones of the cloud. Servers can be created
+// in any availability zone if none are configured, and the default "nova" zone is listed.
+func (c *Cloud) SetAvailabilityZones(zones ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resources["availability-zones"] = nil
+	for _, zone := range zones {
+		c.add("availability-zones", object{"id": zone})
+	}
+}
+
+// microversion is a compute API microversion, e.g. [2, 52].
+type microversion [2]int
+
+func parseMicroversion(s string) (microversion, bool) {
+	parts := strings.SplitN(s, ".", 2)
+	if len(parts) != 2 {
+		return microversion{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return microversion{}, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return microversion{}, false
+	}
+	return microversion{major, minor}, true
+}
+
+func (m microversion) less(o microversion) bool {
+	return m[0] < o[0] || (m[0] == o[0] && m[1] < o[1])
+}
+
+// requestMicroversion returns the microversion requested by the request, the minimum if it requested none.
+func (c *Cloud) requestMicroversion(r *request) (microversion, bool) {
+	min, _ := parseMicroversion(c.minMicroversion)
+	max, _ := parseMicroversion(c.maxMicroversion)
+	requested := r.Header.Get("X-OpenStack-Nova-API-Version")
+	switch requested {
+	case "":
+		return min, true
+	case "latest":
+		return max, true
+	}
+	v, ok := parseMicroversion(requested)
+	if !ok || v.less(min) || max.less(v) {
+		return microversion{}, false
+	}
+	return v, true
+}
+
+func (c *Cloud) serveCompute(r *request) (int, interface{}) {
+	if r.Method == http.MethodGet && r.path == "" {
+		return http.StatusOK, object{"version": object{
+			"id":          "v2.1",
+			"status":      "CURRENT",
+			"min_version": c.minMicroversion,
+			"version":     c.maxMicroversion,
+			"updated":     "2013-07-23T11:33:21Z",
+			"links":       []object{{"href": c.URL() + servicePaths[ServiceCompute], "rel": "self"}},
+		}}
+	}
+	version, ok := c.requestMicroversion(r)
+	if !ok {
+		return errorResponse(ServiceCompute, http.StatusNotAcceptable, fmt.Sprintf("Version %s is not supported by the API. Minimum is %s and maximum is %s.",
+			r.Header.Get("X-OpenStack-Nova-API-Version"), c.minMicroversion, c.maxMicroversion))
+	}
+
+	switch {
+	case r.match(http.MethodGet, "flavors"), r.match(http.MethodGet, "flavors", "detail"):
+		return http.StatusOK, object{"flavors": copyObjects(c.resources["flavors"])}
+	case r.match(http.MethodGet, "flavors", "*"):
+		flavor := c.get("flavors", r.segments[1])
+		if flavor == nil {
+			return notFound(ServiceCompute, "Flavor %s could not be found.", r.segments[1])
+		}
+		return http.StatusOK, object{"flavor": copyObject(flavor)}
+	case r.match(http.MethodGet, "os-availability-zone"):
+		return http.StatusOK, object{"availabilityZoneInfo": c.availabilityZones()}
+	case r.match(http.MethodPost, "servers"):
+		return c.createServer(r, version)
+	case r.match(http.MethodGet, "servers"), r.match(http.MethodGet, "servers", "detail"):
+		return c.listServers(r, version)
+	}
+
+	if len(r.segments) < 2 || r.segments[0] != "servers" {
+		return notFound(ServiceCompute, "The resource could not be found.")
+	}
+	server := c.get("servers", r.segments[1])
+	if server == nil {
+		return notFound(ServiceCompute, "Instance %s could not be found.", r.segments[1])
+	}
+	switch {
+	case r.match(http.MethodGet, "servers", "*"):
+		c.pollServer(server)
+		return http.StatusOK, object{"server": renderServerVersion(c.renderServer(server), version)}
+	case r.match(http.MethodDelete, "servers", "*"):
+		for _, port := range c.serverPorts(server) {
+			c.detachPort(port)
+		}
+		c.remove("servers", server["id"].(string))
+		delete(c.pending, server["id"].(string))
+		return http.StatusNoContent, nil
+	case r.match(http.MethodGet, "servers", "*", "os-interface"):
+		attachments := []object{}
+		for _, port := range c.serverPorts(server) {
+			attachment := interfaceAttachment(port)
+			if !version.less(microversion{2, 70}) {
+				attachment["tag"] = c.deviceTags[port["id"].(string)]
+			}
+			attachments = append(attachments, attachment)
+		}
+		return http.StatusOK, object{"interfaceAttachments": attachments}
+	case r.match(http.MethodPost, "servers", "*", "os-interface"):
+		attachment := objectField(r.body, "interfaceAttachment")
+		port, status, response := c.serverPort(server, object{"port": attachment["port_id"], "uuid": attachment["net_id"]}, nil)
+		if port == nil {
+			return status, response
+		}
+		return http.StatusOK, object{"interfaceAttachment": interfaceAttachment(port)}
+	case r.match(http.MethodDelete, "servers", "*", "os-interface", "*"):
+		for _, port := range c.serverPorts(server) {
+			if port["id"] == r.segments[3] {
+				c.detachPort(port)
+				return http.StatusAccepted, nil
+			}
+		}
+		return notFound(ServiceCompute, "Port %s is not attached", r.segments[3])
+	case r.match(http.MethodPost, "servers", "*", "action"):
+		return c.serverAction(server, r.body, version)
+	}
+	return notFound(ServiceCompute, "The resource could not be found.")
+}
+
+func (c *Cloud) availabilityZones() []object {
+	names := []string{"nova"}
+	if zones := c.resources["availability-zones"]; len(zones) > 0 {
+		names = nil
+		for _, zone := range zones {
+			names = append(names, zone["id"].(string))
+		}
+	}
+	info := []object{}
+	for _, name := range names {
+		info = append(info, object{"zoneName": name, "zoneState": object{"available": true}, "hosts": nil})
+	}
+	return info
+}
+
+func (c *Cloud) createServer(r *request, version microversion) (int, interface{}) {
+	body := objectField(r.body, "server")
+	if body == nil || stringField(body, "name") == "" {
+		return badRequest(ServiceCompute, "Invalid input for field/attribute server.")
+	}
+	if c.get("flavors", stringField(body, "flavorRef")) == nil {
+		return badRequest(ServiceCompute, "Flavor %s could not be found.", stringField(body, "flavorRef"))
+	}
+	bootFromVolume := false
+	for _, bdm := range objectList(body, "block_device_mapping_v2") {
+		if intField(bdm, "boot_index") == 0 {
+			bootFromVolume = true
+			if bdm["source_type"] == "image" && c.get("images", stringField(bdm, "uuid")) == nil {
+				return badRequest(ServiceCompute, "Image %s could not be found.", stringField(bdm, "uuid"))
+			}
+		}
+	}
+	image := interface{}("")
+	if !bootFromVolume {
+		if c.get("images", stringField(body, "imageRef")) == nil {
+			return badRequest(ServiceCompute, "Can not find requested image")
+		}
+		image = object{"id": body["imageRef"]}
+	}
+	if keyName := stringField(body, "key_name"); keyName != "" && c.get("keypairs", keyName) == nil {
+		return badRequest(ServiceCompute, "Invalid key_name provided.")
+	}
+	for field, min := range map[string]microversion{"tags": {2, 52}, "trusted_image_certificates": {2, 63}, "hostname": {2, 90}} {
+		if _, ok := body[field]; ok && version.less(min) {
+			return badRequest(ServiceCompute, "Invalid input for field/attribute server. Value: %v. Additional properties are not allowed ('%s' was unexpected)", body, field)
+		}
+	}
+	zone := stringField(body, "availability_zone")
+	if zones := c.resources["availability-zones"]; len(zones) > 0 {
+		if zone == "" {
+			zone = stringField(zones[0], "id")
+		} else if c.get("availability-zones", zone) == nil {
+			return badRequest(ServiceCompute, "The requested availability zone is not available")
+		}
+	}
+	if zone == "" {
+		zone = "nova"
+	}
+
+	var securityGroups []string
+	for _, sg := range objectList(body, "security_groups") {
+		name := stringField(sg, "name")
+		var found object
+		for _, group := range c.resources["security-groups"] {
+			if group["id"] == name || group["name"] == name {
+				found = group
+			}
+		}
+		if found == nil {
+			return badRequest(ServiceCompute, "Security group %s not found for project %s.", name, c.project["id"])
+		}
+		securityGroups = append(securityGroups, found["id"].(string))
+	}
+
+	networks := objectList(body, "networks")
+	if _, ok := body["networks"]; !ok {
+		candidates := c.list("networks", func(n object) bool { return n["router:external"] != true })
+		if len(candidates) > 1 {
+			return conflict(ServiceCompute, "Multiple possible networks found, use a Network ID to be more specific.")
+		}
+		for _, network := range candidates {
+			networks = append(networks, object{"uuid": network["id"]})
+		}
+	}
+	for _, network := range networks {
+		if _, ok := network["tag"]; ok && version.less(microversion{2, 42}) {
+			return badRequest(ServiceCompute, "Invalid input for field/attribute networks. Value: %v. Additional properties are not allowed ('tag' was unexpected)", network)
+		}
+		if portID := stringField(network, "port"); portID != "" {
+			port := c.get("ports", portID)
+			if port == nil {
+				return badRequest(ServiceCompute, "Port id %s could not be found.", portID)
+			}
+			if stringField(port, "device_id") != "" {
+				return conflict(ServiceCompute, "Port %s is still in use.", portID)
+			}
+		} else if c.get("networks", stringField(network, "uuid")) == nil {
+			return badRequest(ServiceCompute, "Network %s could not be found.", stringField(network, "uuid"))
+		}
+	}
+
+	server := object{
+		"name":                                 body["name"],
+		"status":                               "ACTIVE",
+		"tenant_id":                            c.project["id"],
+		"user_id":                              c.user["id"],
+		"created":                              now(),
+		"updated":                              now(),
+		"hostId":                               newID(),
+		"flavor":                               object{"id": body["flavorRef"]},
+		"image":                                image,
+		"key_name":                             body["key_name"],
+		"metadata":                             object{},
+		"accessIPv4":                           "",
+		"accessIPv6":                           "",
+		"config_drive":                         "",
+		"progress":                             0,
+		"tags":                                 []string{},
+		"trusted_image_certificates":           body["trusted_image_certificates"],
+		"OS-EXT-SRV-ATTR:hostname":             sanitizeHostname(stringField(body, "name")),
+		"OS-EXT-AZ:availability_zone":          zone,
+		"OS-EXT-STS:vm_state":                  "active",
+		"OS-EXT-STS:task_state":                nil,
+		"OS-EXT-STS:power_state":               1,
+		"OS-DCF:diskConfig":                    "MANUAL",
+		"os-extended-volumes:volumes_attached": []object{},
+	}
+	if metadata := objectField(body, "metadata"); metadata != nil {
+		server["metadata"] = metadata
+	}
+	if tags, ok := body["tags"]; ok {
+		server["tags"] = tags
+	}
+	if hostname, ok := body["hostname"]; ok {
+		server["OS-EXT-SRV-ATTR:hostname"] = hostname
+	}
+	if body["config_drive"] == true {
+		server["config_drive"] = "True"
+	}
+	if c.buildPolls > 0 {
+		server["status"] = "BUILD"
+		server["OS-EXT-STS:vm_state"] = "building"
+	}
+	c.add("servers", server)
+	if c.buildPolls > 0 {
+		c.pending[server["id"].(string)] = c.buildPolls
+	}
+
+	for _, network := range networks {
+		if port, status, response := c.serverPort(server, network, securityGroups); port == nil {
+			for _, p := range c.serverPorts(server) {
+				c.detachPort(p)
+			}
+			c.remove("servers", server["id"].(string))
+			return status, response
+		}
+	}
+
+	return http.StatusAccepted, object{"server": object{
+		"id":                server["id"],
+		"links":             []object{{"href": c.URL() + servicePaths[ServiceCompute] + "servers/" + server["id"].(string), "rel": "self"}},
+		"adminPass":         "password",
+		"OS-DCF:diskConfig": "MANUAL",
+		"security_groups":   c.renderServer(server)["security_groups"],
+	}}
+}
+
+// serverPort binds the port of the network to the server, creating a port on the network if no port is given.
+func (c *Cloud) serverPort(server, network object, securityGroups []string) (object, int, interface{}) {
+	var port object
+	if portID := stringField(network, "port"); portID != "" {
+		port = c.get("ports", portID)
+		if port == nil {
+			status, response := notFound(ServiceCompute, "Port id %s could not be found.", portID)
+			return nil, status, response
+		}
+		if stringField(port, "device_id") != "" {
+			status, response := conflict(ServiceCompute, "Port %s is still in use.", portID)
+			return nil, status, response
+		}
+	} else {
+		body := object{"network_id": network["uuid"]}
+		if fixedIP := stringField(network, "fixed_ip"); fixedIP != "" {
+			body["fixed_ips"] = []interface{}{map[string]interface{}{"ip_address": fixedIP}}
+		}
+		if securityGroups != nil {
+			body["security_groups"] = securityGroups
+		}
+		status, response := c.createPort(body)
+		if status != http.StatusCreated {
+			return nil, http.StatusBadRequest, object{"badRequest": object{"code": http.StatusBadRequest, "message": fmt.Sprintf("Failed to allocate the network(s): %v", response)}}
+		}
+		port = c.get("ports", response.(object)["port"].(object)["id"].(string))
+		c.autoPorts[port["id"].(string)] = true
+	}
+	port["device_id"] = server["id"]
+	port["device_owner"] = "compute:" + stringField(server, "OS-EXT-AZ:availability_zone")
+	if tag := stringField(network, "tag"); tag != "" {
+		c.deviceTags[port["id"].(string)] = tag
+	}
+	port["status"] = "ACTIVE"
+	if c.hasExtension("binding") {
+		port["binding:host_id"] = "compute-0"
+		port["binding:vif_type"] = "ovs"
+	}
+	return port, 0, nil
+}
+
+// detachPort unbinds the port from its server. Ports Nova created for the server are deleted.
+func (c *Cloud) detachPort(port object) {
+	delete(c.deviceTags, port["id"].(string))
+	if c.autoPorts[port["id"].(string)] {
+		c.deletePort(port)
+		return
+	}
+	port["device_id"] = ""
+	port["device_owner"] = ""
+	port["status"] = "DOWN"
+	if c.hasExtension("binding") {
+		port["binding:host_id"] = ""
+		port["binding:vif_type"] = "unbound"
+	}
+}
+
+func (c *Cloud) serverPorts(server object) []object {
+	return c.list("ports", func(p object) bool {
+		return p["device_id"] == server["id"] && strings.HasPrefix(stringField(p, "device_owner"), "compute:")
+	})
+}
+
+// hostnameInvalidRegexp matches the characters Nova replaces in hostnames.
+var hostnameInvalidRegexp = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// sanitizeHostname derives the hostname of a server from its name, as Nova does without an explicit hostname.
+func sanitizeHostname(name string) string {
+	return strings.Trim(hostnameInvalidRegexp.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+}
+
+func interfaceAttachment(port object) object {
+	var fixedIPs []object
+	for _, ip := range objectList(port, "fixed_ips") {
+		fixedIPs = append(fixedIPs, object{"subnet_id": ip["subnet_id"], "ip_address": ip["ip_address"]})
+	}
+	return object{
+		"port_id":    port["id"],
+		"net_id":     port["network_id"],
+		"mac_addr":   port["mac_address"],
+		"port_state": port["status"],
+		"fixed_ips":  fixedIPs,
+	}
+}
+
+// pollServer counts a GET of a building server, which becomes ACTIVE after the configured number of polls.
+func (c *Cloud) pollServer(server object) {
+	id := server["id"].(string)
+	if c.pending[id] <= 0 {
+		return
+	}
+	c.pending[id]--
+	if c.pending[id] == 0 {
+		delete(c.pending, id)
+		server["status"] = "ACTIVE"
+		server["OS-EXT-STS:vm_state"] = "active"
+	}
+}
+
+func (c *Cloud) listServers(r *request, version microversion) (int, interface{}) {
+	query := r.URL.Query()
+	var name *regexp.Regexp
+	if n := query.Get("name"); n != "" {
+		var err error
+		if name, err = regexp.Compile(n); err != nil {
+			return badRequest(ServiceCompute, "Invalid regular expression %s", n)
+		}
+	}
+	servers := []object{}
+	for _, server := range c.resources["servers"] {
+		// Nova matches the name as a regular expression, so "bob" also matches "bobb".
+		if name != nil && !name.MatchString(stringField(server, "name")) {
+			continue
+		}
+		if status := query.Get("status"); status != "" && status != server["status"] {
+			continue
+		}
+		c.pollServer(server)
+		if r.match(http.MethodGet, "servers") {
+			servers = append(servers, object{"id": server["id"], "name": server["name"]})
+			continue
+		}
+		servers = append(servers, renderServerVersion(c.renderServer(server), version))
+	}
+	return http.StatusOK, object{"servers": servers}
+}
+
+// renderServer returns the server as returned by the API, with the addresses and security groups of its ports.
+func (c *Cloud) renderServer(server object) object {
+	result := copyObject(server)
+	addresses := object{}
+	securityGroups := []object{}
+	for _, port := range c.serverPorts(server) {
+		networkName := port["network_id"]
+		if network := c.get("networks", stringField(port, "network_id")); network != nil {
+			networkName = network["name"]
+		}
+		key := fieldString(networkName)
+		var networkAddresses []interface{}
+		if existing, ok := addresses[key].([]interface{}); ok {
+			networkAddresses = existing
+		}
+		for _, ip := range objectList(port, "fixed_ips") {
+			ipVersion := 4
+			if strings.Contains(stringField(ip, "ip_address"), ":") {
+				ipVersion = 6
+			}
+			networkAddresses = append(networkAddresses, object{
+				"addr":                    ip["ip_address"],
+				"version":                 ipVersion,
+				"OS-EXT-IPS:type":         "fixed",
+				"OS-EXT-IPS-MAC:mac_addr": port["mac_address"],
+			})
+		}
+		for _, fip := range c.list("floatingips", func(f object) bool { return f["port_id"] == port["id"] }) {
+			networkAddresses = append(networkAddresses, object{
+				"addr":                    fip["floating_ip_address"],
+				"version":                 4,
+				"OS-EXT-IPS:type":         "floating",
+				"OS-EXT-IPS-MAC:mac_addr": port["mac_address"],
+			})
+		}
+		addresses[key] = networkAddresses
+		for _, sg := range stringList(port, "security_groups") {
+			if group := c.get("security-groups", sg); group != nil && !hasRefName(securityGroups, group["name"]) {
+				securityGroups = append(securityGroups, object{"name": group["name"]})
+			}
+		}
+	}
+	result["addresses"] = addresses
+	result["security_groups"] = securityGroups
+	return copyObject(result)
+}
+
+func hasRefName(refs []object, name interface{}) bool {
+	for _, ref := range refs {
+		if ref["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renderServerVersion removes the fields of the server the requested microversion doesn't return.
+func renderServerVersion(server object, version microversion) object {
+	if version.less(microversion{2, 26}) {
+		delete(server, "tags")
+	}
+	if version.less(microversion{2, 63}) {
+		delete(server, "trusted_image_certificates")
+	}
+	return server
+}
+
+func (c *Cloud) serverAction(server, body object, version microversion) (int, interface{}) {
+	switch {
+	case body["addFloatingIp"] != nil || body["removeFloatingIp"] != nil:
+		if !version.less(microversion{2, 44}) {
+			return notFound(ServiceCompute, "The resource could not be found.")
+		}
+		if action := objectField(body, "removeFloatingIp"); action != nil {
+			for _, fip := range c.resources["floatingips"] {
+				if fip["floating_ip_address"] == action["address"] {
+					disassociateFloatingIP(fip)
+					return http.StatusAccepted, nil
+				}
+			}
+			return notFound(ServiceCompute, "floating IP not found")
+		}
+		action := objectField(body, "addFloatingIp")
+		var fip object
+		for _, f := range c.resources["floatingips"] {
+			if f["floating_ip_address"] == action["address"] {
+				fip = f
+			}
+		}
+		if fip == nil {
+			return notFound(ServiceCompute, "floating IP not found")
+		}
+		fixedAddress := stringField(action, "fixed_address")
+		for _, port := range c.serverPorts(server) {
+			for _, ip := range objectList(port, "fixed_ips") {
+				if fixedAddress != "" && ip["ip_address"] != fixedAddress {
+					continue
+				}
+				if status, response := c.associateFloatingIP(fip, port["id"].(string), stringField(ip, "ip_address")); status != 0 {
+					return badRequest(ServiceCompute, "Unable to associate floating IP %s to fixed IP %s for instance %s. Error: %v",
+						action["address"], ip["ip_address"], server["id"], response)
+				}
+				return http.StatusAccepted, nil
+			}
+		}
+		return badRequest(ServiceCompute, "Instance %s has no fixed IP %s", server["id"], fixedAddress)
+	case body["os-stop"] != nil:
+		server["status"] = "SHUTOFF"
+		server["OS-EXT-STS:vm_state"] = "stopped"
+		return http.StatusAccepted, nil
+	case body["os-start"] != nil, body["reboot"] != nil:
+		server["status"] = "ACTIVE"
+		server["OS-EXT-STS:vm_state"] = "active"
+		return http.StatusAccepted, nil
+	}
+	return badRequest(ServiceCompute, "There is no such action: %v", body)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"net/http"
+	"time"
+)
+
+// AddApplicationCredential creates an application credential of the user with the given name and secret,
+// and returns its ID. It doesn't expire if expiresAt is nil.
+func (c *Cloud) AddApplicationCredential(name, secret string, expiresAt *time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	appCred := object{
+		"name":         name,
+		"secret":       secret,
+		"user_id":      c.user["id"],
+		"project_id":   c.project["id"],
+		"description":  "",
+		"unrestricted": false,
+		"roles":        []object{{"id": newID(), "name": "member"}},
+		"expires_at":   nil,
+	}
+	if expiresAt != nil {
+		appCred["expires_at"] = expiresAt.UTC().Format("2006-01-02T15:04:05.000000")
+	}
+	return c.add("application_credentials", appCred)["id"].(string)
+}
+
+func (c *Cloud) serveIdentity(w http.ResponseWriter, r *request) (int, interface{}) {
+	switch {
+	case r.Method == http.MethodGet && (r.path == "" || r.path == "v3"):
+		return c.identityVersions()
+	case r.match(http.MethodPost, "v3", "auth", "tokens"):
+		return c.createToken(w, r)
+	case r.match(http.MethodGet, "v3", "auth", "tokens"), r.match(http.MethodHead, "v3", "auth", "tokens"):
+		if !c.authorized(r.Request) {
+			return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+		}
+		if _, ok := c.tokens[r.Header.Get("X-Subject-Token")]; !ok {
+			return notFound(ServiceIdentity, "Could not find token.")
+		}
+		return http.StatusOK, nil
+	case r.match(http.MethodGet, "v3", "users", "*", "application_credentials", "*"):
+		if !c.authorized(r.Request) {
+			return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+		}
+		appCred := c.get("application_credentials", r.segments[4])
+		if appCred == nil || appCred["user_id"] != r.segments[2] {
+			return notFound(ServiceIdentity, "Could not find application credential: %s.", r.segments[4])
+		}
+		result := copyObject(appCred)
+		delete(result, "secret")
+		return http.StatusOK, object{"application_credential": result}
+	}
+	return notFound(ServiceIdentity, "The resource could not be found.")
+}
+
+func (c *Cloud) identityVersions() (int, interface{}) {
+	return http.StatusOK, object{"versions": object{"values": []object{{
+		"id":     "v3.14",
+		"status": "stable",
+		"links":  []object{{"href": c.AuthURL() + "/", "rel": "self"}},
+	}}}}
+}
+
+// createToken authenticates with a password, an application credential or a token.
+func (c *Cloud) createToken(w http.ResponseWriter, r *request) (int, interface{}) {
+	auth := objectField(r.body, "auth")
+	identity := objectField(auth, "identity")
+	methods := stringList(identity, "methods")
+	if len(methods) != 1 {
+		return badRequest(ServiceIdentity, "Exactly one authentication method is supported.")
+	}
+
+	var appCred object
+	switch methods[0] {
+	case "password":
+		user := objectField(objectField(identity, "password"), "user")
+		if !c.isUser(user) || stringField(user, "password") != Password {
+			return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+		}
+	case "application_credential":
+		credential := objectField(identity, "application_credential")
+		for _, ac := range c.resources["application_credentials"] {
+			if (ac["id"] == credential["id"] || (ac["name"] == credential["name"] && c.isUser(objectField(credential, "user")))) &&
+				ac["secret"] == credential["secret"] {
+				appCred = ac
+			}
+		}
+		if appCred == nil {
+			return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+		}
+		if expiresAt, ok := appCred["expires_at"].(string); ok {
+			expiry, err := time.Parse("2006-01-02T15:04:05.000000", expiresAt)
+			if err == nil && time.Now().After(expiry) {
+				return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+			}
+		}
+	case "token":
+		expiry, ok := c.tokens[stringField(objectField(identity, "token"), "id")]
+		if !ok || time.Now().After(expiry) {
+			return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+		}
+	default:
+		return errorResponse(ServiceIdentity, http.StatusUnauthorized, "Unsupported authentication method "+methods[0])
+	}
+
+	id := newID()
+	issuedAt := time.Now().UTC()
+	expiresAt := issuedAt.Add(c.tokenTTL)
+	c.tokens[id] = expiresAt
+
+	token := object{
+		"methods":    methods,
+		"user":       c.user,
+		"issued_at":  issuedAt.Format("2006-01-02T15:04:05.000000Z"),
+		"expires_at": expiresAt.Format("2006-01-02T15:04:05.000000Z"),
+		"audit_ids":  []string{newID()},
+	}
+	scope := objectField(auth, "scope")
+	if appCred != nil {
+		token["application_credential"] = object{"id": appCred["id"], "name": appCred["name"], "restricted": !appCred["unrestricted"].(bool)}
+		scope = object{"project": object{"id": appCred["project_id"]}}
+	}
+	if project := objectField(scope, "project"); project != nil {
+		if project["id"] != c.project["id"] && (project["name"] != c.project["name"] || !isDomain(objectField(project, "domain"))) {
+			return errorResponse(ServiceIdentity, http.StatusUnauthorized, "The request you have made requires authentication.")
+		}
+		token["project"] = c.project
+		token["roles"] = []object{{"id": newID(), "name": "member"}}
+		token["catalog"] = c.catalog()
+	}
+
+	w.Header().Set("X-Subject-Token", id)
+	return http.StatusCreated, object{"token": token}
+}
+
+// isUser returns whether the user of an authentication request is the user of the cloud.
+func (c *Cloud) isUser(user object) bool {
+	if user == nil {
+		return false
+	}
+	if id, ok := user["id"]; ok {
+		return id == c.user["id"]
+	}
+	return user["name"] == c.user["name"] && isDomain(objectField(user, "domain"))
+}
+
+func isDomain(domain object) bool {
+	return domain != nil && (domain["id"] == "default" || domain["name"] == DomainName)
+}
+
+// catalog returns the service catalog of the cloud.
+func (c *Cloud) catalog() []object {
+	endpoints := []struct {
+		serviceType string
+		name        string
+		url         string
+	}{
+		{ServiceIdentity, "keystone", c.AuthURL()},
+		{ServiceCompute, "nova", c.URL() + servicePaths[ServiceCompute]},
+		{ServiceNetwork, "neutron", c.URL() + "/network/"},
+		{ServiceImage, "glance", c.URL() + "/image/"},
+		{ServiceLoadBalancer, "octavia", c.URL() + "/load-balancer/"},
+	}
+	var catalog []object
+	for _, e := range endpoints {
+		var serviceEndpoints []object
+		for _, iface := range []string{"public", "internal", "admin"} {
+			serviceEndpoints = append(serviceEndpoints, object{
+				"id":        newID(),
+				"interface": iface,
+				"region":    RegionName,
+				"region_id": RegionName,
+				"url":       e.url,
+			})
+		}
+		catalog = append(catalog, object{
+			"id":        newID(),
+			"type":      e.serviceType,
+			"name":      e.name,
+			"endpoints": serviceEndpoints,
+		})
+	}
+	return catalog
+}
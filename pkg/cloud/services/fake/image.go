@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"net/http"
+)
+
+// AddImage creates an active image and returns its ID.
+func (c *Cloud) AddImage(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	image := c.add("images", object{
+		"name":             name,
+		"status":           "active",
+		"visibility":       "public",
+		"protected":        false,
+		"owner":            c.project["id"],
+		"tags":             []string{},
+		"container_format": "bare",
+		"disk_format":      "qcow2",
+		"min_disk":         0,
+		"min_ram":          0,
+		"size":             1073741824,
+		"checksum":         "",
+		"created_at":       now(),
+		"updated_at":       now(),
+	})
+	image["self"] = "/v2/images/" + image["id"].(string)
+	image["file"] = "/v2/images/" + image["id"].(string) + "/file"
+	image["schema"] = "/v2/schemas/image"
+	return image["id"].(string)
+}
+
+func (c *Cloud) serveImage(r *request) (int, interface{}) {
+	switch {
+	case r.match(http.MethodGet, "images"):
+		return http.StatusOK, object{
+			"images": copyObjects(c.list("images", queryFilter(r))),
+			"first":  "/v2/images",
+			"schema": "/v2/schemas/images",
+		}
+	case r.match(http.MethodGet, "images", "*"):
+		image := c.get("images", r.segments[1])
+		if image == nil {
+			return notFound(ServiceImage, "No image found with ID %s", r.segments[1])
+		}
+		return http.StatusOK, copyObject(image)
+	case r.match(http.MethodDelete, "images", "*"):
+		if c.get("images", r.segments[1]) == nil {
+			return notFound(ServiceImage, "No image found with ID %s", r.segments[1])
+		}
+		c.remove("images", r.segments[1])
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceImage, "The resource could not be found.")
+}
@@ -0,0 +1,527 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"net/http"
+	"time"
+)
+
+// lbProviders are the Octavia providers enabled in the cloud.
+var lbProviders = map[string]bool{"octavia": true, "amphora": true, "ovn": true}
+
+// lbaasCollections maps the load balancer collections to the key of their resources in requests and responses.
+var lbaasCollections = map[string]struct{ singular, plural string }{
+	"loadbalancers":  {"loadbalancer", "loadbalancers"},
+	"listeners":      {"listener", "listeners"},
+	"pools":          {"pool", "pools"},
+	"healthmonitors": {"healthmonitor", "healthmonitors"},
+}
+
+func (c *Cloud) serveLoadBalancer(r *request) (int, interface{}) {
+	if r.path == "" {
+		return http.StatusOK, object{"versions": []object{{
+			"id":     "v2.0",
+			"status": "CURRENT",
+			"links":  []object{{"href": c.URL() + servicePaths[ServiceLoadBalancer], "rel": "self"}},
+		}}}
+	}
+	if r.segments[0] != "lbaas" {
+		return notFound(ServiceLoadBalancer, "The resource could not be found.")
+	}
+	return c.serveLBaaS(r)
+}
+
+// serveLBaaS serves the load balancer API, which Octavia and the Neutron LBaaS v2 extension share.
+func (c *Cloud) serveLBaaS(r *request) (int, interface{}) {
+	if len(r.segments) < 2 {
+		return notFound(r.service, "The resource could not be found.")
+	}
+	collection := r.segments[1]
+	info, ok := lbaasCollections[collection]
+	if !ok {
+		return notFound(r.service, "The resource could not be found.")
+	}
+	if len(r.segments) >= 4 && collection == "pools" && r.segments[3] == "members" {
+		return c.serveMembers(r)
+	}
+	body := objectField(r.body, info.singular)
+	if (r.Method == http.MethodPost || r.Method == http.MethodPut) && body == nil {
+		return badRequest(r.service, "Missing mandatory field %s.", info.singular)
+	}
+
+	switch {
+	case r.match(http.MethodGet, "lbaas", collection):
+		return http.StatusOK, object{info.plural: copyObjects(c.list(collection, queryFilter(r)))}
+	case r.match(http.MethodPost, "lbaas", collection):
+		switch collection {
+		case "loadbalancers":
+			return c.createLoadBalancer(r, body)
+		case "listeners":
+			return c.createListener(r, body)
+		case "pools":
+			return c.createPool(r, body)
+		case "healthmonitors":
+			return c.createHealthMonitor(r, body)
+		}
+	}
+
+	if len(r.segments) != 3 {
+		return notFound(r.service, "The resource could not be found.")
+	}
+	obj := c.get(collection, r.segments[2])
+	if obj == nil {
+		return notFound(r.service, "%s %s not found.", info.singular, r.segments[2])
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if collection == "loadbalancers" {
+			c.pollLoadBalancer(obj)
+		}
+		return http.StatusOK, object{info.singular: copyObject(obj)}
+	case http.MethodPut:
+		lb := c.parentLoadBalancer(obj)
+		if status, response := c.checkMutable(r, lb); status != 0 {
+			return status, response
+		}
+		updated := copyObject(obj)
+		merge(updated, body)
+		if status, response := c.checkProvider(r, lb, collection, updated); status != 0 {
+			return status, response
+		}
+		merge(obj, body, "id", "project_id", "tenant_id", "provisioning_status", "operating_status", "loadbalancers",
+			"listeners", "pools", "members", "vip_address", "vip_port_id", "vip_subnet_id", "vip_network_id", "loadbalancer_id",
+			"listener_id", "pool_id", "protocol", "protocol_port", "type")
+		obj["updated_at"] = now()
+		c.provision(lb, "PENDING_UPDATE")
+		return http.StatusOK, object{info.singular: copyObject(obj)}
+	case http.MethodDelete:
+		return c.deleteLBaaSResource(r, collection, obj)
+	}
+	return notFound(r.service, "The resource could not be found.")
+}
+
+// checkMutable returns a conflict if the load balancer is being provisioned.
+func (c *Cloud) checkMutable(r *request, lb object) (int, interface{}) {
+	if lb == nil {
+		return 0, nil
+	}
+	if status := stringField(lb, "provisioning_status"); status != "ACTIVE" {
+		return conflict(r.service, "Load Balancer %s is immutable and cannot be updated.", lb["id"])
+	}
+	return 0, nil
+}
+
+// provision marks the load balancer as being provisioned for the configured number of polls.
+func (c *Cloud) provision(lb object, status string) {
+	if lb == nil || c.provisionPolls <= 0 {
+		return
+	}
+	lb["provisioning_status"] = status
+	c.pending[lb["id"].(string)] = c.provisionPolls
+}
+
+// pollLoadBalancer counts a GET of a provisioning load balancer, which becomes ACTIVE after the configured number of polls.
+func (c *Cloud) pollLoadBalancer(lb object) {
+	id := lb["id"].(string)
+	if c.pending[id] <= 0 {
+		return
+	}
+	c.pending[id]--
+	if c.pending[id] == 0 {
+		delete(c.pending, id)
+		if lb["provisioning_status"] == "PENDING_DELETE" {
+			c.removeLoadBalancer(lb)
+			return
+		}
+		lb["provisioning_status"] = "ACTIVE"
+		lb["operating_status"] = "ONLINE"
+	}
+}
+
+// parentLoadBalancer returns the load balancer of a load balancer resource.
+func (c *Cloud) parentLoadBalancer(obj object) object {
+	if _, ok := obj["vip_port_id"]; ok {
+		return obj
+	}
+	for _, lb := range objectList(obj, "loadbalancers") {
+		return c.get("loadbalancers", stringField(lb, "id"))
+	}
+	for _, pool := range objectList(obj, "pools") {
+		if p := c.get("pools", stringField(pool, "id")); p != nil {
+			return c.parentLoadBalancer(p)
+		}
+	}
+	return nil
+}
+
+// lbaasFields returns the fields all load balancer resources have.
+func (c *Cloud) lbaasFields(body object) object {
+	obj := object{
+		"name":                "",
+		"description":         "",
+		"project_id":          c.project["id"],
+		"tenant_id":           c.project["id"],
+		"admin_state_up":      true,
+		"provisioning_status": "ACTIVE",
+		"operating_status":    "ONLINE",
+		"created_at":          time.Now().UTC().Format("2006-01-02T15:04:05"),
+		"updated_at":          time.Now().UTC().Format("2006-01-02T15:04:05"),
+	}
+	merge(obj, body, "id", "project_id", "tenant_id", "provisioning_status", "operating_status", "created_at", "updated_at")
+	return obj
+}
+
+func (c *Cloud) createLoadBalancer(r *request, body object) (int, interface{}) {
+	setDefault(body, "provider", "octavia")
+	if provider := stringField(body, "provider"); !lbProviders[provider] {
+		return badRequest(r.service, "Provider '%s' is not enabled.", provider)
+	}
+	lb := c.lbaasFields(body)
+	lb["id"] = newID()
+	port, status, response := c.createVIPPort(r, lb["id"].(string), body)
+	if port == nil {
+		return status, response
+	}
+	port["status"] = "ACTIVE"
+	setDefault(lb, "flavor_id", "")
+	setDefault(lb, "availability_zone", nil)
+	setDefault(lb, "tags", []string{})
+	lb["vip_subnet_id"] = objectList(port, "fixed_ips")[0]["subnet_id"]
+	lb["vip_network_id"] = port["network_id"]
+	lb["vip_port_id"] = port["id"]
+	lb["vip_address"] = objectList(port, "fixed_ips")[0]["ip_address"]
+	lb["listeners"] = []object{}
+	lb["pools"] = []object{}
+	c.add("loadbalancers", lb)
+	if c.provisionPolls > 0 {
+		lb["operating_status"] = "OFFLINE"
+	}
+	c.provision(lb, "PENDING_CREATE")
+	return http.StatusCreated, object{"loadbalancer": copyObject(lb)}
+}
+
+// createVIPPort returns the VIP port of a new load balancer, which is either the requested
+// port or a new port on the requested subnet or network.
+func (c *Cloud) createVIPPort(r *request, lbID string, body object) (object, int, interface{}) {
+	if id := stringField(body, "vip_port_id"); id != "" {
+		port := c.get("ports", id)
+		if port == nil {
+			status, response := badRequest(r.service, "Validation failure: Port %s not found.", id)
+			return nil, status, response
+		}
+		if len(objectList(port, "fixed_ips")) == 0 {
+			status, response := badRequest(r.service, "Validation failure: Port %s has no fixed IPs.", id)
+			return nil, status, response
+		}
+		return port, 0, nil
+	}
+
+	var networkID string
+	requested := object{}
+	if id := stringField(body, "vip_subnet_id"); id != "" {
+		subnet := c.get("subnets", id)
+		if subnet == nil {
+			status, response := badRequest(r.service, "Validation failure: Subnet %s not found.", id)
+			return nil, status, response
+		}
+		networkID = subnet["network_id"].(string)
+		requested["subnet_id"] = subnet["id"]
+	} else if id := stringField(body, "vip_network_id"); id != "" {
+		if c.get("networks", id) == nil {
+			status, response := badRequest(r.service, "Validation failure: Network %s not found.", id)
+			return nil, status, response
+		}
+		networkID = id
+	} else {
+		status, response := badRequest(r.service, "Validation failure: VIP must contain one of: vip_port_id, vip_network_id, vip_subnet_id.")
+		return nil, status, response
+	}
+	if address := stringField(body, "vip_address"); address != "" {
+		requested["ip_address"] = address
+	}
+	port := object{
+		"network_id":   networkID,
+		"name":         "octavia-lb-" + lbID,
+		"device_id":    "lb-" + lbID,
+		"device_owner": "Octavia",
+	}
+	if len(requested) > 0 {
+		port["fixed_ips"] = []object{requested}
+	}
+	status, response := c.createPort(port)
+	if status != http.StatusCreated {
+		return nil, status, response
+	}
+	port = c.get("ports", response.(object)["port"].(object)["id"].(string))
+	if len(objectList(port, "fixed_ips")) == 0 {
+		c.deletePort(port)
+		status, response := badRequest(r.service, "Validation failure: Network %s has no subnets.", networkID)
+		return nil, status, response
+	}
+	return port, 0, nil
+}
+
+// checkProvider returns a bad request if the provider of the load balancer doesn't support
+// the algorithm of a pool or the type of a health monitor.
+func (c *Cloud) checkProvider(r *request, lb object, collection string, obj object) (int, interface{}) {
+	provider := stringField(lb, "provider")
+	switch {
+	case collection == "pools" && provider == "ovn" && stringField(obj, "lb_algorithm") != "SOURCE_IP_PORT":
+		return badRequest(r.service, "Provider 'ovn' does not support a requested option: OVN provider does not support %s algorithm", stringField(obj, "lb_algorithm"))
+	case collection == "pools" && provider != "ovn" && stringField(obj, "lb_algorithm") == "SOURCE_IP_PORT":
+		return badRequest(r.service, "Provider '%s' does not support a requested option: SOURCE_IP_PORT algorithm is not supported", provider)
+	case collection == "healthmonitors" && provider == "ovn" && (obj["type"] == "HTTP" || obj["type"] == "HTTPS"):
+		return badRequest(r.service, "Provider 'ovn' does not support a requested option: OVN provider does not support %s health monitor type", obj["type"])
+	}
+	return 0, nil
+}
+
+func (c *Cloud) createListener(r *request, body object) (int, interface{}) {
+	lb := c.get("loadbalancers", stringField(body, "loadbalancer_id"))
+	if lb == nil {
+		return badRequest(r.service, "Validation failure: Load Balancer %s not found.", stringField(body, "loadbalancer_id"))
+	}
+	if status, response := c.checkMutable(r, lb); status != 0 {
+		return status, response
+	}
+	for _, l := range objectList(lb, "listeners") {
+		if listener := c.get("listeners", stringField(l, "id")); listener != nil && intField(listener, "protocol_port") == intField(body, "protocol_port") {
+			return conflict(r.service, "Another Listener on this Load Balancer is already using protocol_port %d", intField(body, "protocol_port"))
+		}
+	}
+	listener := c.lbaasFields(body)
+	delete(listener, "loadbalancer_id")
+	setDefault(listener, "connection_limit", -1)
+	setDefault(listener, "default_pool_id", nil)
+	setDefault(listener, "insert_headers", object{})
+	setDefault(listener, "timeout_client_data", 50000)
+	setDefault(listener, "timeout_member_data", 50000)
+	setDefault(listener, "timeout_member_connect", 5000)
+	setDefault(listener, "timeout_tcp_inspect", 0)
+	setDefault(listener, "tags", []string{})
+	listener["loadbalancers"] = []object{{"id": lb["id"]}}
+	listener["pools"] = []object{}
+	listener["l7policies"] = []object{}
+	c.add("listeners", listener)
+	lb["listeners"] = append(objectList(lb, "listeners"), object{"id": listener["id"]})
+	c.provision(lb, "PENDING_UPDATE")
+	return http.StatusCreated, object{"listener": copyObject(listener)}
+}
+
+func (c *Cloud) createPool(r *request, body object) (int, interface{}) {
+	var lb, listener object
+	if id := stringField(body, "listener_id"); id != "" {
+		if listener = c.get("listeners", id); listener == nil {
+			return badRequest(r.service, "Validation failure: Listener %s not found.", id)
+		}
+		if stringField(listener, "default_pool_id") != "" {
+			return conflict(r.service, "Listener %s is already using default pool %s.", id, listener["default_pool_id"])
+		}
+		lb = c.parentLoadBalancer(listener)
+	} else if lb = c.get("loadbalancers", stringField(body, "loadbalancer_id")); lb == nil {
+		return badRequest(r.service, "Validation failure: Load Balancer %s not found.", stringField(body, "loadbalancer_id"))
+	}
+	if status, response := c.checkMutable(r, lb); status != 0 {
+		return status, response
+	}
+	if status, response := c.checkProvider(r, lb, "pools", body); status != 0 {
+		return status, response
+	}
+	pool := c.lbaasFields(body)
+	delete(pool, "listener_id")
+	delete(pool, "loadbalancer_id")
+	setDefault(pool, "session_persistence", nil)
+	setDefault(pool, "tags", []string{})
+	pool["healthmonitor_id"] = nil
+	pool["loadbalancers"] = []object{{"id": lb["id"]}}
+	pool["listeners"] = []object{}
+	pool["members"] = []object{}
+	c.add("pools", pool)
+	if listener != nil {
+		pool["listeners"] = []object{{"id": listener["id"]}}
+		listener["default_pool_id"] = pool["id"]
+		listener["pools"] = append(objectList(listener, "pools"), object{"id": pool["id"]})
+	}
+	lb["pools"] = append(objectList(lb, "pools"), object{"id": pool["id"]})
+	c.provision(lb, "PENDING_UPDATE")
+	return http.StatusCreated, object{"pool": copyObject(pool)}
+}
+
+func (c *Cloud) createHealthMonitor(r *request, body object) (int, interface{}) {
+	pool := c.get("pools", stringField(body, "pool_id"))
+	if pool == nil {
+		return badRequest(r.service, "Validation failure: Pool %s not found.", stringField(body, "pool_id"))
+	}
+	if stringField(pool, "healthmonitor_id") != "" {
+		return conflict(r.service, "Validation failure: Pool %s already has a health monitor.", pool["id"])
+	}
+	lb := c.parentLoadBalancer(pool)
+	if status, response := c.checkMutable(r, lb); status != 0 {
+		return status, response
+	}
+	if status, response := c.checkProvider(r, lb, "healthmonitors", body); status != 0 {
+		return status, response
+	}
+	monitor := c.lbaasFields(body)
+	delete(monitor, "pool_id")
+	setDefault(monitor, "tags", []string{})
+	monitor["pools"] = []object{{"id": pool["id"]}}
+	c.add("healthmonitors", monitor)
+	pool["healthmonitor_id"] = monitor["id"]
+	c.provision(lb, "PENDING_UPDATE")
+	return http.StatusCreated, object{"healthmonitor": copyObject(monitor)}
+}
+
+func (c *Cloud) deleteLBaaSResource(r *request, collection string, obj object) (int, interface{}) {
+	lb := c.parentLoadBalancer(obj)
+	if status, response := c.checkMutable(r, lb); status != 0 {
+		return status, response
+	}
+	id := obj["id"].(string)
+	switch collection {
+	case "loadbalancers":
+		if r.URL.Query().Get("cascade") != "true" && (len(objectList(obj, "listeners")) > 0 || len(objectList(obj, "pools")) > 0) {
+			return badRequest(r.service, "Cannot delete Load Balancer %s - it has children", id)
+		}
+		if c.provisionPolls > 0 {
+			c.provision(obj, "PENDING_DELETE")
+			return http.StatusNoContent, nil
+		}
+		c.removeLoadBalancer(obj)
+		return http.StatusNoContent, nil
+	case "listeners":
+		for _, pool := range c.list("pools", func(p object) bool { return hasRef(objectList(p, "listeners"), id) }) {
+			pool["listeners"] = removeRef(objectList(pool, "listeners"), id)
+		}
+		if lb != nil {
+			lb["listeners"] = removeRef(objectList(lb, "listeners"), id)
+		}
+	case "pools":
+		if monitorID := stringField(obj, "healthmonitor_id"); monitorID != "" {
+			c.remove("healthmonitors", monitorID)
+		}
+		for _, listener := range c.list("listeners", func(l object) bool { return hasRef(objectList(l, "pools"), id) }) {
+			listener["pools"] = removeRef(objectList(listener, "pools"), id)
+			if listener["default_pool_id"] == id {
+				listener["default_pool_id"] = nil
+			}
+		}
+		for _, member := range objectList(obj, "members") {
+			c.remove("members", stringField(member, "id"))
+		}
+		if lb != nil {
+			lb["pools"] = removeRef(objectList(lb, "pools"), id)
+		}
+	case "healthmonitors":
+		for _, pool := range c.list("pools", func(p object) bool { return p["healthmonitor_id"] == id }) {
+			pool["healthmonitor_id"] = nil
+		}
+	}
+	c.remove(collection, id)
+	c.provision(lb, "PENDING_UPDATE")
+	return http.StatusNoContent, nil
+}
+
+// removeLoadBalancer deletes a load balancer with its children and its VIP port.
+func (c *Cloud) removeLoadBalancer(lb object) {
+	for _, pool := range objectList(lb, "pools") {
+		if p := c.get("pools", stringField(pool, "id")); p != nil {
+			if monitorID := stringField(p, "healthmonitor_id"); monitorID != "" {
+				c.remove("healthmonitors", monitorID)
+			}
+			for _, member := range objectList(p, "members") {
+				c.remove("members", stringField(member, "id"))
+			}
+		}
+		c.remove("pools", stringField(pool, "id"))
+	}
+	for _, listener := range objectList(lb, "listeners") {
+		c.remove("listeners", stringField(listener, "id"))
+	}
+	if port := c.get("ports", stringField(lb, "vip_port_id")); port != nil && port["device_owner"] == "Octavia" {
+		c.deletePort(port)
+	}
+	c.remove("loadbalancers", lb["id"].(string))
+}
+
+func (c *Cloud) serveMembers(r *request) (int, interface{}) {
+	pool := c.get("pools", r.segments[2])
+	if pool == nil {
+		return notFound(r.service, "pool %s not found.", r.segments[2])
+	}
+	lb := c.parentLoadBalancer(pool)
+	inPool := func(m object) bool { return m["pool_id"] == pool["id"] }
+
+	switch {
+	case r.match(http.MethodGet, "lbaas", "pools", "*", "members"):
+		filter := queryFilter(r)
+		return http.StatusOK, object{"members": copyObjects(c.list("members", func(m object) bool { return inPool(m) && filter(m) }))}
+	case r.match(http.MethodPost, "lbaas", "pools", "*", "members"):
+		body := objectField(r.body, "member")
+		if body == nil {
+			return badRequest(r.service, "Missing mandatory field member.")
+		}
+		if status, response := c.checkMutable(r, lb); status != 0 {
+			return status, response
+		}
+		for _, m := range c.list("members", inPool) {
+			if m["address"] == body["address"] && intField(m, "protocol_port") == intField(body, "protocol_port") {
+				return conflict(r.service, "Duplicate member with address %v and protocol_port %d in pool %s.", body["address"], intField(body, "protocol_port"), pool["id"])
+			}
+		}
+		member := c.lbaasFields(body)
+		setDefault(member, "weight", 1)
+		setDefault(member, "backup", false)
+		setDefault(member, "subnet_id", "")
+		setDefault(member, "tags", []string{})
+		member["pool_id"] = pool["id"]
+		c.add("members", member)
+		pool["members"] = append(objectList(pool, "members"), object{"id": member["id"]})
+		c.provision(lb, "PENDING_UPDATE")
+		return http.StatusCreated, object{"member": copyObject(member)}
+	}
+
+	if len(r.segments) != 5 {
+		return notFound(r.service, "The resource could not be found.")
+	}
+	member := c.get("members", r.segments[4])
+	if member == nil || !inPool(member) {
+		return notFound(r.service, "member %s not found.", r.segments[4])
+	}
+	switch r.Method {
+	case http.MethodGet:
+		return http.StatusOK, object{"member": copyObject(member)}
+	case http.MethodPut:
+		if status, response := c.checkMutable(r, lb); status != 0 {
+			return status, response
+		}
+		merge(member, objectField(r.body, "member"), "id", "project_id", "tenant_id", "pool_id", "address", "protocol_port", "subnet_id",
+			"provisioning_status", "operating_status")
+		c.provision(lb, "PENDING_UPDATE")
+		return http.StatusOK, object{"member": copyObject(member)}
+	case http.MethodDelete:
+		if status, response := c.checkMutable(r, lb); status != 0 {
+			return status, response
+		}
+		c.remove("members", member["id"].(string))
+		pool["members"] = removeRef(objectList(pool, "members"), member["id"].(string))
+		c.provision(lb, "PENDING_UPDATE")
+		return http.StatusNoContent, nil
+	}
+	return notFound(r.service, "The resource could not be found.")
+}
@@ -0,0 +1,1146 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// neutronCollections maps the collections of the Neutron API to the key of a single resource in the request
+// and response bodies, and the extension required to use them.
+var neutronCollections = map[string]struct {
+	singular  string
+	plural    string
+	extension string
+}{
+	"networks":             {"network", "networks", ""},
+	"subnets":              {"subnet", "subnets", ""},
+	"ports":                {"port", "ports", ""},
+	"trunks":               {"trunk", "trunks", "trunk"},
+	"routers":              {"router", "routers", "router"},
+	"floatingips":          {"floatingip", "floatingips", "router"},
+	"security-groups":      {"security_group", "security_groups", "security-group"},
+	"security-group-rules": {"security_group_rule", "security_group_rules", "security-group"},
+}
+
+// extensionAttributes maps the attributes of resources to the extension which adds them.
+var extensionAttributes = map[string]string{
+	"port_security_enabled": "port-security",
+	"binding:vnic_type":     "binding",
+	"binding:profile":       "binding",
+	"binding:host_id":       "binding",
+	"qos_policy_id":         "qos",
+	"allowed_address_pairs": "allowed-address-pairs",
+	"router:external":       "external-net",
+}
+
+// AddNetwork creates a network, e.g. an external network for routers and floating IPs, and returns its ID.
+func (c *Cloud) AddNetwork(name string, external bool) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	network := c.newNetwork(object{"name": name})
+	network["router:external"] = external
+	return c.add("networks", network)["id"].(string)
+}
+
+// AddSubnet creates an IPv4 subnet of the network and returns its ID.
+func (c *Cloud) AddSubnet(networkID, name, cidr string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, response := c.createSubnet(object{"network_id": networkID, "name": name, "cidr": cidr, "ip_version": 4.0})
+	if status != http.StatusCreated {
+		panic(fmt.Sprintf("failed to add subnet %s: %v", name, response))
+	}
+	return response.(object)["subnet"].(object)["id"].(string)
+}
+
+// AddSecurityGroup creates a security group and returns its ID.
+func (c *Cloud) AddSecurityGroup(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.createSecurityGroup(name, "")["id"].(string)
+}
+
+// Resources returns copies of the resources of a collection, e.g. "ports" or "servers",
+// so tests can inspect the state of the cloud.
+func (c *Cloud) Resources(collection string) []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var result []map[string]interface{}
+	for _, obj := range c.resources[collection] {
+		result = append(result, c.render(collection, obj))
+	}
+	return result
+}
+
+func (c *Cloud) hasExtension(alias string) bool {
+	for _, ext := range c.extensions {
+		if ext == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cloud) serveNetwork(r *request) (int, interface{}) {
+	if r.segments[0] == "lbaas" {
+		if !c.hasExtension("lbaasv2") {
+			return notFound(ServiceNetwork, "The resource could not be found.")
+		}
+		return c.serveLBaaS(r)
+	}
+	if r.match(http.MethodGet, "extensions") {
+		var extensions []object
+		for _, alias := range c.extensions {
+			extensions = append(extensions, object{"alias": alias, "name": alias, "description": "", "links": []object{}, "updated": "2019-01-01T00:00:00-00:00"})
+		}
+		return http.StatusOK, object{"extensions": extensions}
+	}
+
+	collection := r.segments[0]
+	info, ok := neutronCollections[collection]
+	if !ok || (info.extension != "" && !c.hasExtension(info.extension)) {
+		return notFound(ServiceNetwork, "The resource could not be found.")
+	}
+	if len(r.segments) >= 3 && r.segments[2] == "tags" {
+		return c.serveTags(r, collection)
+	}
+	body := objectField(r.body, info.singular)
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if len(r.segments) <= 2 && body == nil {
+			return badRequest(ServiceNetwork, "Resource body required.")
+		}
+		for key := range body {
+			if ext, ok := extensionAttributes[key]; ok && !c.hasExtension(ext) {
+				return badRequest(ServiceNetwork, "Unrecognized attribute(s) '%s'", key)
+			}
+		}
+	}
+
+	switch {
+	case r.match(http.MethodGet, collection):
+		var result []object
+		for _, obj := range c.resources[collection] {
+			rendered := c.render(collection, obj)
+			if queryFilter(r)(rendered) {
+				result = append(result, rendered)
+			}
+		}
+		if result == nil {
+			result = []object{}
+		}
+		return http.StatusOK, object{info.plural: result}
+	case r.match(http.MethodGet, collection, "*"):
+		obj := c.get(collection, r.segments[1])
+		if obj == nil {
+			return notFound(ServiceNetwork, "%s %s could not be found.", info.singular, r.segments[1])
+		}
+		return http.StatusOK, object{info.singular: c.render(collection, obj)}
+	case r.match(http.MethodDelete, collection, "*"):
+		if c.get(collection, r.segments[1]) == nil {
+			return notFound(ServiceNetwork, "%s %s could not be found.", info.singular, r.segments[1])
+		}
+	case r.match(http.MethodPut, collection, "*"):
+		if c.get(collection, r.segments[1]) == nil {
+			return notFound(ServiceNetwork, "%s %s could not be found.", info.singular, r.segments[1])
+		}
+	}
+
+	switch collection {
+	case "networks":
+		return c.serveNetworks(r, body)
+	case "subnets":
+		return c.serveSubnets(r, body)
+	case "ports":
+		return c.servePorts(r, body)
+	case "trunks":
+		return c.serveTrunks(r, body)
+	case "routers":
+		return c.serveRouters(r, body)
+	case "floatingips":
+		return c.serveFloatingIPs(r, body)
+	case "security-groups":
+		return c.serveSecurityGroups(r, body)
+	case "security-group-rules":
+		return c.serveSecurityGroupRules(r, body)
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+// render returns a copy of the resource as returned by the API, with the fields derived from other resources.
+func (c *Cloud) render(collection string, obj object) object {
+	result := copyObject(obj)
+	switch collection {
+	case "networks":
+		subnets := []string{}
+		for _, subnet := range c.list("subnets", func(s object) bool { return s["network_id"] == obj["id"] }) {
+			subnets = append(subnets, subnet["id"].(string))
+		}
+		result["subnets"] = subnets
+	case "security-groups":
+		result["security_group_rules"] = copyObjects(c.list("security-group-rules", func(rule object) bool {
+			return rule["security_group_id"] == obj["id"]
+		}))
+	case "trunks":
+		result["status"] = "DOWN"
+		if parent := c.get("ports", stringField(obj, "port_id")); parent != nil && stringField(parent, "device_id") != "" {
+			result["status"] = "ACTIVE"
+		}
+	case "servers":
+		return c.renderServer(obj)
+	}
+	return result
+}
+
+func (c *Cloud) serveTags(r *request, collection string) (int, interface{}) {
+	if !c.hasExtension("standard-attr-tag") {
+		return notFound(ServiceNetwork, "The resource could not be found.")
+	}
+	obj := c.get(collection, r.segments[1])
+	if obj == nil {
+		return notFound(ServiceNetwork, "%s %s could not be found.", collection, r.segments[1])
+	}
+	tags := stringList(obj, "tags")
+	switch {
+	case r.match(http.MethodGet, collection, "*", "tags"):
+		return http.StatusOK, object{"tags": tags}
+	case r.match(http.MethodPut, collection, "*", "tags"):
+		tags = unique(stringList(r.body, "tags"))
+		obj["tags"] = tags
+		return http.StatusOK, object{"tags": tags}
+	case r.match(http.MethodDelete, collection, "*", "tags"):
+		obj["tags"] = []string{}
+		return http.StatusNoContent, nil
+	case r.match(http.MethodPut, collection, "*", "tags", "*"):
+		obj["tags"] = unique(append(tags, r.segments[3]))
+		return http.StatusCreated, nil
+	case r.match(http.MethodDelete, collection, "*", "tags", "*"):
+		if !hasString(tags, r.segments[3]) {
+			return notFound(ServiceNetwork, "Tag %s could not be found.", r.segments[3])
+		}
+		var remaining []string
+		for _, tag := range tags {
+			if tag != r.segments[3] {
+				remaining = append(remaining, tag)
+			}
+		}
+		obj["tags"] = remaining
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+func unique(list []string) []string {
+	result := []string{}
+	for _, s := range list {
+		if !hasString(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// commonFields returns the fields all Neutron resources have.
+func (c *Cloud) commonFields(body object) object {
+	obj := object{
+		"name":            "",
+		"description":     "",
+		"tenant_id":       c.project["id"],
+		"project_id":      c.project["id"],
+		"tags":            []string{},
+		"revision_number": 1,
+		"created_at":      now(),
+		"updated_at":      now(),
+	}
+	merge(obj, body, "id", "tenant_id", "project_id", "tags", "revision_number", "created_at", "updated_at")
+	return obj
+}
+
+func (c *Cloud) newNetwork(body object) object {
+	network := c.commonFields(body)
+	setDefault(network, "admin_state_up", true)
+	setDefault(network, "shared", false)
+	setDefault(network, "mtu", 1450)
+	network["status"] = "ACTIVE"
+	if c.hasExtension("port-security") {
+		setDefault(network, "port_security_enabled", true)
+	}
+	if c.hasExtension("external-net") {
+		setDefault(network, "router:external", false)
+	}
+	return network
+}
+
+func setDefault(obj object, key string, value interface{}) {
+	if v, ok := obj[key]; !ok || v == nil {
+		obj[key] = value
+	}
+}
+
+func (c *Cloud) serveNetworks(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "networks"):
+		network := c.add("networks", c.newNetwork(body))
+		return http.StatusCreated, object{"network": c.render("networks", network)}
+	case r.match(http.MethodPut, "networks", "*"):
+		network := c.get("networks", r.segments[1])
+		merge(network, body, "id", "tenant_id", "project_id", "status", "subnets")
+		return http.StatusOK, object{"network": c.render("networks", network)}
+	case r.match(http.MethodDelete, "networks", "*"):
+		id := r.segments[1]
+		for _, port := range c.list("ports", func(p object) bool { return p["network_id"] == id }) {
+			if owner := stringField(port, "device_owner"); owner != "network:dhcp" {
+				return conflict(ServiceNetwork, "Unable to complete operation on network %s. There are one or more ports still in use on the network.", id)
+			}
+		}
+		for _, subnet := range c.list("subnets", func(s object) bool { return s["network_id"] == id }) {
+			c.remove("subnets", subnet["id"].(string))
+		}
+		c.remove("networks", id)
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+func (c *Cloud) createSubnet(body object) (int, interface{}) {
+	if c.get("networks", stringField(body, "network_id")) == nil {
+		return notFound(ServiceNetwork, "Network %s could not be found.", stringField(body, "network_id"))
+	}
+	_, ipNet, err := net.ParseCIDR(stringField(body, "cidr"))
+	if err != nil {
+		return badRequest(ServiceNetwork, "Invalid input for cidr. Reason: '%s' is not a valid IP subnet.", stringField(body, "cidr"))
+	}
+	ipVersion := 4
+	if ipNet.IP.To4() == nil {
+		ipVersion = 6
+	}
+	if intField(body, "ip_version") != ipVersion {
+		return badRequest(ServiceNetwork, "Invalid input for operation: cidr %s does not match ip_version %d.", ipNet, intField(body, "ip_version"))
+	}
+	for _, other := range c.list("subnets", func(s object) bool { return s["network_id"] == body["network_id"] }) {
+		_, otherNet, _ := net.ParseCIDR(stringField(other, "cidr"))
+		if otherNet.Contains(ipNet.IP) || ipNet.Contains(otherNet.IP) {
+			return badRequest(ServiceNetwork, "Invalid input for operation: Requested subnet with cidr: %s for network: %s overlaps with another subnet.", ipNet, body["network_id"])
+		}
+	}
+
+	subnet := c.commonFields(body)
+	subnet["cidr"] = ipNet.String()
+	subnet["ip_version"] = ipVersion
+	setDefault(subnet, "enable_dhcp", true)
+	setDefault(subnet, "dns_nameservers", []string{})
+	setDefault(subnet, "host_routes", []object{})
+	if _, ok := body["gateway_ip"]; !ok {
+		subnet["gateway_ip"] = nthIP(ipNet, 1).String()
+	}
+	if _, ok := body["allocation_pools"]; !ok {
+		subnet["allocation_pools"] = []object{{"start": nthIP(ipNet, 2).String(), "end": lastIP(ipNet).String()}}
+	}
+	return http.StatusCreated, object{"subnet": c.render("subnets", c.add("subnets", subnet))}
+}
+
+func (c *Cloud) serveSubnets(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "subnets"):
+		return c.createSubnet(body)
+	case r.match(http.MethodPut, "subnets", "*"):
+		subnet := c.get("subnets", r.segments[1])
+		merge(subnet, body, "id", "tenant_id", "project_id", "network_id", "cidr", "ip_version")
+		return http.StatusOK, object{"subnet": c.render("subnets", subnet)}
+	case r.match(http.MethodDelete, "subnets", "*"):
+		id := r.segments[1]
+		for _, port := range c.resources["ports"] {
+			for _, ip := range objectList(port, "fixed_ips") {
+				if ip["subnet_id"] == id {
+					return conflict(ServiceNetwork, "Unable to complete operation on subnet %s: One or more ports have an IP allocation from this subnet.", id)
+				}
+			}
+		}
+		c.remove("subnets", id)
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+// nthIP returns the n-th address of the network.
+func nthIP(ipNet *net.IPNet, n uint32) net.IP {
+	ip := ipNet.IP.To4()
+	if ip == nil {
+		ip = make(net.IP, net.IPv6len)
+		copy(ip, ipNet.IP)
+		binary.BigEndian.PutUint32(ip[12:], binary.BigEndian.Uint32(ip[12:])+n)
+		return ip
+	}
+	result := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(result, binary.BigEndian.Uint32(ip)+n)
+	return result
+}
+
+// lastIP returns the last usable address of the network.
+func lastIP(ipNet *net.IPNet) net.IP {
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones < 2 {
+		return ipNet.IP
+	}
+	size := uint32(1<<32 - 2)
+	if bits-ones < 32 {
+		size = uint32(1)<<uint(bits-ones) - 2
+	}
+	return nthIP(ipNet, size)
+}
+
+// allocateIP returns the next free address of the subnet.
+func (c *Cloud) allocateIP(subnet object) (string, error) {
+	_, ipNet, _ := net.ParseCIDR(stringField(subnet, "cidr"))
+	used := c.usedIPs(subnet["id"].(string))
+	used[stringField(subnet, "gateway_ip")] = true
+	last := lastIP(ipNet)
+	for n := uint32(2); ; n++ {
+		ip := nthIP(ipNet, n)
+		if !ipNet.Contains(ip) {
+			break
+		}
+		if !used[ip.String()] {
+			return ip.String(), nil
+		}
+		if ip.Equal(last) {
+			break
+		}
+	}
+	return "", fmt.Errorf("No more IP addresses available on network %s.", subnet["network_id"])
+}
+
+func (c *Cloud) usedIPs(subnetID string) map[string]bool {
+	used := map[string]bool{}
+	for _, port := range c.resources["ports"] {
+		for _, ip := range objectList(port, "fixed_ips") {
+			if ip["subnet_id"] == subnetID {
+				used[stringField(ip, "ip_address")] = true
+			}
+		}
+	}
+	return used
+}
+
+// allocateFixedIPs returns the fixed IPs for a port on the network. Without requested IPs one address
+// of the first subnet of the network is allocated.
+func (c *Cloud) allocateFixedIPs(networkID string, requested []object, portID string) ([]object, int, interface{}) {
+	subnets := c.list("subnets", func(s object) bool { return s["network_id"] == networkID })
+	if requested == nil {
+		if len(subnets) == 0 {
+			return []object{}, 0, nil
+		}
+		requested = []object{{"subnet_id": subnets[0]["id"]}}
+	}
+
+	fixedIPs := []object{}
+	for _, req := range requested {
+		subnetID := stringField(req, "subnet_id")
+		ipAddress := stringField(req, "ip_address")
+		var subnet object
+		for _, s := range subnets {
+			_, ipNet, _ := net.ParseCIDR(stringField(s, "cidr"))
+			if s["id"] == subnetID || (subnetID == "" && ipAddress != "" && ipNet.Contains(net.ParseIP(ipAddress))) {
+				subnet = s
+				break
+			}
+		}
+		if subnet == nil {
+			if subnetID != "" && c.get("subnets", subnetID) == nil {
+				status, response := notFound(ServiceNetwork, "Subnet %s could not be found.", subnetID)
+				return nil, status, response
+			}
+			status, response := badRequest(ServiceNetwork, "Invalid input for operation: Failed to allocate %v on network %s.", req, networkID)
+			return nil, status, response
+		}
+		if ipAddress == "" {
+			ip, err := c.allocateIP(subnet)
+			if err != nil {
+				status, response := conflict(ServiceNetwork, err.Error())
+				return nil, status, response
+			}
+			ipAddress = ip
+		} else {
+			_, ipNet, _ := net.ParseCIDR(stringField(subnet, "cidr"))
+			if !ipNet.Contains(net.ParseIP(ipAddress)) {
+				status, response := badRequest(ServiceNetwork, "IP address %s is not a valid IP for the specified subnet.", ipAddress)
+				return nil, status, response
+			}
+			for _, port := range c.resources["ports"] {
+				if port["id"] == portID {
+					continue
+				}
+				for _, ip := range objectList(port, "fixed_ips") {
+					if ip["subnet_id"] == subnet["id"] && ip["ip_address"] == ipAddress {
+						status, response := conflict(ServiceNetwork, "IP address %s already allocated in subnet %s", ipAddress, subnet["id"])
+						return nil, status, response
+					}
+				}
+			}
+		}
+		fixedIPs = append(fixedIPs, object{"subnet_id": subnet["id"], "ip_address": ipAddress})
+	}
+	return fixedIPs, 0, nil
+}
+
+// createPort creates a port. Ports without security groups get the default security group.
+func (c *Cloud) createPort(body object) (int, interface{}) {
+	network := c.get("networks", stringField(body, "network_id"))
+	if network == nil {
+		return notFound(ServiceNetwork, "Network %s could not be found.", stringField(body, "network_id"))
+	}
+	macAddress := stringField(body, "mac_address")
+	if macAddress == "" {
+		macAddress = newMAC()
+	}
+	for _, port := range c.list("ports", func(p object) bool { return p["network_id"] == network["id"] }) {
+		if port["mac_address"] == macAddress {
+			return conflict(ServiceNetwork, "Unable to complete operation for network %s. The mac address %s is in use.", network["id"], macAddress)
+		}
+	}
+	var requested []object
+	if _, ok := body["fixed_ips"]; ok {
+		requested = objectList(body, "fixed_ips")
+	}
+	id := newID()
+	fixedIPs, status, response := c.allocateFixedIPs(network["id"].(string), requested, id)
+	if fixedIPs == nil {
+		return status, response
+	}
+
+	port := c.commonFields(body)
+	port["id"] = id
+	port["mac_address"] = macAddress
+	port["fixed_ips"] = fixedIPs
+	port["status"] = "DOWN"
+	setDefault(port, "admin_state_up", true)
+	setDefault(port, "device_id", "")
+	setDefault(port, "device_owner", "")
+	if c.hasExtension("allowed-address-pairs") {
+		setDefault(port, "allowed_address_pairs", []object{})
+	}
+	if c.hasExtension("binding") {
+		setDefault(port, "binding:vnic_type", "normal")
+		setDefault(port, "binding:profile", object{})
+		setDefault(port, "binding:host_id", "")
+		port["binding:vif_type"] = "unbound"
+	}
+	if c.hasExtension("qos") {
+		setDefault(port, "qos_policy_id", nil)
+	}
+	if c.hasExtension("port-security") {
+		setDefault(port, "port_security_enabled", network["port_security_enabled"])
+	}
+	if _, ok := body["security_groups"]; !ok {
+		port["security_groups"] = []string{}
+		if port["port_security_enabled"] != false && !strings.HasPrefix(stringField(port, "device_owner"), "network:") {
+			port["security_groups"] = []string{c.defaultSecurityGroup()["id"].(string)}
+		}
+	}
+	if status, response := c.validatePort(port); status != 0 {
+		return status, response
+	}
+	return http.StatusCreated, object{"port": c.render("ports", c.add("ports", port))}
+}
+
+func (c *Cloud) validatePort(port object) (int, interface{}) {
+	securityGroups := stringList(port, "security_groups")
+	for _, sg := range securityGroups {
+		if c.get("security-groups", sg) == nil {
+			return notFound(ServiceNetwork, "Security group %s does not exist", sg)
+		}
+	}
+	if port["port_security_enabled"] == false && len(securityGroups) > 0 {
+		return conflict(ServiceNetwork, "Port has security group associated. Cannot disable port security or ip address until security group is removed")
+	}
+	if port["port_security_enabled"] == false && len(objectList(port, "allowed_address_pairs")) > 0 {
+		return conflict(ServiceNetwork, "Port Security must be enabled in order to have allowed address pairs on a port.")
+	}
+	return 0, nil
+}
+
+func (c *Cloud) servePorts(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "ports"):
+		return c.createPort(body)
+	case r.match(http.MethodPut, "ports", "*"):
+		port := c.get("ports", r.segments[1])
+		updated := copyObject(port)
+		merge(updated, body, "id", "tenant_id", "project_id", "network_id", "mac_address", "status", "fixed_ips")
+		if _, ok := body["fixed_ips"]; ok {
+			fixedIPs, status, response := c.allocateFixedIPs(stringField(port, "network_id"), objectList(body, "fixed_ips"), stringField(port, "id"))
+			if fixedIPs == nil {
+				return status, response
+			}
+			updated["fixed_ips"] = fixedIPs
+		}
+		if status, response := c.validatePort(updated); status != 0 {
+			return status, response
+		}
+		for key := range port {
+			delete(port, key)
+		}
+		merge(port, updated)
+		port["revision_number"] = intField(port, "revision_number") + 1
+		port["updated_at"] = now()
+		return http.StatusOK, object{"port": c.render("ports", port)}
+	case r.match(http.MethodDelete, "ports", "*"):
+		port := c.get("ports", r.segments[1])
+		if owner := stringField(port, "device_owner"); owner == "network:router_interface" || owner == "network:router_gateway" || owner == "network:floatingip" {
+			return conflict(ServiceNetwork, "Port %s cannot be deleted directly via the port API: has device owner %s.", port["id"], owner)
+		}
+		for _, trunk := range c.resources["trunks"] {
+			if trunk["port_id"] == port["id"] {
+				return conflict(ServiceNetwork, "Port %s is currently a parent port for trunk %s.", port["id"], trunk["id"])
+			}
+			for _, subport := range objectList(trunk, "sub_ports") {
+				if subport["port_id"] == port["id"] {
+					return conflict(ServiceNetwork, "Port %s is currently a subport for trunk %s.", port["id"], trunk["id"])
+				}
+			}
+		}
+		c.deletePort(port)
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+// deletePort deletes a port and disassociates the floating IPs associated with it.
+func (c *Cloud) deletePort(port object) {
+	for _, fip := range c.list("floatingips", func(f object) bool { return f["port_id"] == port["id"] }) {
+		disassociateFloatingIP(fip)
+	}
+	delete(c.autoPorts, port["id"].(string))
+	c.remove("ports", port["id"].(string))
+}
+
+func (c *Cloud) serveTrunks(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "trunks"):
+		portID := stringField(body, "port_id")
+		if c.get("ports", portID) == nil {
+			return notFound(ServiceNetwork, "Port %s could not be found.", portID)
+		}
+		if trunk := c.trunkOfPort(portID); trunk != nil {
+			return conflict(ServiceNetwork, "Port %s is in use by another trunk.", portID)
+		}
+		trunk := c.commonFields(body)
+		setDefault(trunk, "admin_state_up", true)
+		trunk["sub_ports"] = []object{}
+		c.add("trunks", trunk)
+		if subports := objectList(body, "sub_ports"); len(subports) > 0 {
+			if status, response := c.addSubports(trunk, subports); status != http.StatusOK {
+				c.remove("trunks", trunk["id"].(string))
+				return status, response
+			}
+		}
+		return http.StatusCreated, object{"trunk": c.render("trunks", trunk)}
+	case r.match(http.MethodPut, "trunks", "*"):
+		trunk := c.get("trunks", r.segments[1])
+		merge(trunk, body, "id", "tenant_id", "project_id", "port_id", "sub_ports", "status")
+		return http.StatusOK, object{"trunk": c.render("trunks", trunk)}
+	case r.match(http.MethodDelete, "trunks", "*"):
+		trunk := c.get("trunks", r.segments[1])
+		if c.render("trunks", trunk)["status"] == "ACTIVE" {
+			return conflict(ServiceNetwork, "Trunk %s is currently in use.", trunk["id"])
+		}
+		c.remove("trunks", trunk["id"].(string))
+		return http.StatusNoContent, nil
+	case r.match(http.MethodGet, "trunks", "*", "get_subports"):
+		trunk := c.get("trunks", r.segments[1])
+		if trunk == nil {
+			return notFound(ServiceNetwork, "Trunk %s could not be found.", r.segments[1])
+		}
+		return http.StatusOK, object{"sub_ports": trunk["sub_ports"]}
+	case r.match(http.MethodPut, "trunks", "*", "add_subports"):
+		trunk := c.get("trunks", r.segments[1])
+		if trunk == nil {
+			return notFound(ServiceNetwork, "Trunk %s could not be found.", r.segments[1])
+		}
+		return c.addSubports(trunk, objectList(r.body, "sub_ports"))
+	case r.match(http.MethodPut, "trunks", "*", "remove_subports"):
+		trunk := c.get("trunks", r.segments[1])
+		if trunk == nil {
+			return notFound(ServiceNetwork, "Trunk %s could not be found.", r.segments[1])
+		}
+		subports := objectList(trunk, "sub_ports")
+		for _, remove := range objectList(r.body, "sub_ports") {
+			found := false
+			for i, subport := range subports {
+				if subport["port_id"] == remove["port_id"] {
+					subports = append(subports[:i:i], subports[i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return notFound(ServiceNetwork, "SubPort(s) %s not found on trunk %s.", remove["port_id"], trunk["id"])
+			}
+		}
+		trunk["sub_ports"] = subports
+		return http.StatusOK, object{"trunk": c.render("trunks", trunk)}
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+func (c *Cloud) trunkOfPort(portID string) object {
+	for _, trunk := range c.resources["trunks"] {
+		if trunk["port_id"] == portID {
+			return trunk
+		}
+		for _, subport := range objectList(trunk, "sub_ports") {
+			if subport["port_id"] == portID {
+				return trunk
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cloud) addSubports(trunk object, subports []object) (int, interface{}) {
+	existing := objectList(trunk, "sub_ports")
+	for _, subport := range subports {
+		portID := stringField(subport, "port_id")
+		if c.get("ports", portID) == nil {
+			return notFound(ServiceNetwork, "Port %s could not be found.", portID)
+		}
+		if c.trunkOfPort(portID) != nil {
+			return conflict(ServiceNetwork, "Port %s is in use by another trunk.", portID)
+		}
+		segmentationType := stringField(subport, "segmentation_type")
+		if segmentationType != "vlan" && segmentationType != "inherit" {
+			return badRequest(ServiceNetwork, "Invalid input for operation: Invalid segmentation type %q.", segmentationType)
+		}
+		for _, e := range existing {
+			if intField(e, "segmentation_id") == intField(subport, "segmentation_id") {
+				return conflict(ServiceNetwork, "Segmentation ID %d is already in use on trunk %s.", intField(subport, "segmentation_id"), trunk["id"])
+			}
+		}
+		existing = append(existing, object{
+			"port_id":           portID,
+			"segmentation_type": segmentationType,
+			"segmentation_id":   intField(subport, "segmentation_id"),
+		})
+	}
+	trunk["sub_ports"] = existing
+	return http.StatusOK, object{"trunk": c.render("trunks", trunk)}
+}
+
+func (c *Cloud) serveRouters(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "routers"):
+		router := c.commonFields(body)
+		setDefault(router, "admin_state_up", true)
+		router["status"] = "ACTIVE"
+		router["routes"] = []object{}
+		router["external_gateway_info"] = nil
+		c.add("routers", router)
+		if gateway := objectField(body, "external_gateway_info"); gateway != nil {
+			if status, response := c.setRouterGateway(router, gateway); status != 0 {
+				c.remove("routers", router["id"].(string))
+				return status, response
+			}
+		}
+		return http.StatusCreated, object{"router": c.render("routers", router)}
+	case r.match(http.MethodPut, "routers", "*"):
+		router := c.get("routers", r.segments[1])
+		if _, ok := body["external_gateway_info"]; ok {
+			if status, response := c.setRouterGateway(router, objectField(body, "external_gateway_info")); status != 0 {
+				return status, response
+			}
+		}
+		merge(router, body, "id", "tenant_id", "project_id", "status", "external_gateway_info")
+		return http.StatusOK, object{"router": c.render("routers", router)}
+	case r.match(http.MethodDelete, "routers", "*"):
+		router := c.get("routers", r.segments[1])
+		if len(c.routerPorts(router, "network:router_interface")) > 0 {
+			return conflict(ServiceNetwork, "Router %s still has ports", router["id"])
+		}
+		for _, port := range c.routerPorts(router, "network:router_gateway") {
+			c.deletePort(port)
+		}
+		c.remove("routers", router["id"].(string))
+		return http.StatusNoContent, nil
+	case r.match(http.MethodPut, "routers", "*", "add_router_interface"):
+		router := c.get("routers", r.segments[1])
+		if router == nil {
+			return notFound(ServiceNetwork, "Router %s could not be found.", r.segments[1])
+		}
+		return c.addRouterInterface(router, r.body)
+	case r.match(http.MethodPut, "routers", "*", "remove_router_interface"):
+		router := c.get("routers", r.segments[1])
+		if router == nil {
+			return notFound(ServiceNetwork, "Router %s could not be found.", r.segments[1])
+		}
+		for _, port := range c.routerPorts(router, "network:router_interface") {
+			for _, ip := range objectList(port, "fixed_ips") {
+				if port["id"] == r.body["port_id"] || ip["subnet_id"] == r.body["subnet_id"] {
+					c.remove("ports", port["id"].(string))
+					return http.StatusOK, routerInterface(router, port)
+				}
+			}
+		}
+		return notFound(ServiceNetwork, "Router %s has no interface on subnet %v.", router["id"], r.body["subnet_id"])
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+func (c *Cloud) routerPorts(router object, owner string) []object {
+	return c.list("ports", func(p object) bool { return p["device_id"] == router["id"] && p["device_owner"] == owner })
+}
+
+// setRouterGateway replaces the gateway port of the router. A nil gateway removes it.
+func (c *Cloud) setRouterGateway(router, gateway object) (int, interface{}) {
+	var port object
+	if gateway != nil {
+		network := c.get("networks", stringField(gateway, "network_id"))
+		if network == nil {
+			return notFound(ServiceNetwork, "Network %s could not be found.", stringField(gateway, "network_id"))
+		}
+		if network["router:external"] != true {
+			return badRequest(ServiceNetwork, "Bad router request: Network %s is not an external network.", network["id"])
+		}
+		var requested []object
+		if _, ok := gateway["external_fixed_ips"]; ok {
+			requested = objectList(gateway, "external_fixed_ips")
+		}
+		id := newID()
+		fixedIPs, status, response := c.allocateFixedIPs(network["id"].(string), requested, id)
+		if fixedIPs == nil {
+			return status, response
+		}
+		port = c.commonFields(object{"network_id": network["id"], "device_id": router["id"], "device_owner": "network:router_gateway"})
+		port["id"] = id
+		port["mac_address"] = newMAC()
+		port["fixed_ips"] = fixedIPs
+		port["status"] = "ACTIVE"
+		port["security_groups"] = []string{}
+	}
+	for _, old := range c.routerPorts(router, "network:router_gateway") {
+		c.remove("ports", old["id"].(string))
+	}
+	if port == nil {
+		router["external_gateway_info"] = nil
+		return 0, nil
+	}
+	c.add("ports", port)
+	router["external_gateway_info"] = object{
+		"network_id":         port["network_id"],
+		"enable_snat":        true,
+		"external_fixed_ips": port["fixed_ips"],
+	}
+	return 0, nil
+}
+
+func (c *Cloud) addRouterInterface(router, body object) (int, interface{}) {
+	subnet := c.get("subnets", stringField(body, "subnet_id"))
+	if subnet == nil {
+		return notFound(ServiceNetwork, "Subnet %s could not be found.", stringField(body, "subnet_id"))
+	}
+	for _, port := range c.routerPorts(router, "network:router_interface") {
+		for _, ip := range objectList(port, "fixed_ips") {
+			if ip["subnet_id"] == subnet["id"] {
+				return badRequest(ServiceNetwork, "Bad router request: Router already has a port on subnet %s.", subnet["id"])
+			}
+		}
+	}
+	status, response := c.createPort(object{
+		"network_id":   subnet["network_id"],
+		"device_id":    router["id"],
+		"device_owner": "network:router_interface",
+		"fixed_ips":    []interface{}{map[string]interface{}{"subnet_id": subnet["id"], "ip_address": subnet["gateway_ip"]}},
+	})
+	if status != http.StatusCreated {
+		return status, response
+	}
+	port := c.get("ports", response.(object)["port"].(object)["id"].(string))
+	port["status"] = "ACTIVE"
+	return http.StatusOK, routerInterface(router, port)
+}
+
+func routerInterface(router, port object) object {
+	fixedIPs := objectList(port, "fixed_ips")
+	return object{
+		"id":         router["id"],
+		"port_id":    port["id"],
+		"subnet_id":  fixedIPs[0]["subnet_id"],
+		"subnet_ids": []interface{}{fixedIPs[0]["subnet_id"]},
+		"network_id": port["network_id"],
+		"tenant_id":  router["tenant_id"],
+		"project_id": router["project_id"],
+	}
+}
+
+func (c *Cloud) serveFloatingIPs(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "floatingips"):
+		network := c.get("networks", stringField(body, "floating_network_id"))
+		if network == nil {
+			return notFound(ServiceNetwork, "Network %s could not be found.", stringField(body, "floating_network_id"))
+		}
+		if network["router:external"] != true {
+			return badRequest(ServiceNetwork, "Bad floatingip request: Network %s is not a valid external network.", network["id"])
+		}
+		var requested []object
+		if address := stringField(body, "floating_ip_address"); address != "" {
+			requested = []object{{"ip_address": address}}
+		}
+		id := newID()
+		fixedIPs, status, response := c.allocateFixedIPs(network["id"].(string), requested, id)
+		if fixedIPs == nil {
+			return status, response
+		}
+		if len(fixedIPs) == 0 {
+			return badRequest(ServiceNetwork, "Bad floatingip request: Network %s does not contain any IPv4 subnet.", network["id"])
+		}
+		c.add("ports", object{
+			"id": newID(), "network_id": network["id"], "device_id": id, "device_owner": "network:floatingip",
+			"fixed_ips": fixedIPs, "mac_address": newMAC(), "status": "N/A", "security_groups": []string{},
+			"name": "", "tenant_id": "", "project_id": "", "tags": []string{}, "admin_state_up": true,
+		})
+		fip := c.commonFields(object{"description": stringField(body, "description")})
+		fip["id"] = id
+		fip["floating_network_id"] = network["id"]
+		fip["floating_ip_address"] = fixedIPs[0]["ip_address"]
+		disassociateFloatingIP(fip)
+		if portID := stringField(body, "port_id"); portID != "" {
+			if status, response := c.associateFloatingIP(fip, portID, stringField(body, "fixed_ip_address")); status != 0 {
+				c.remove("ports", c.list("ports", func(p object) bool { return p["device_id"] == id })[0]["id"].(string))
+				return status, response
+			}
+		}
+		return http.StatusCreated, object{"floatingip": c.render("floatingips", c.add("floatingips", fip))}
+	case r.match(http.MethodPut, "floatingips", "*"):
+		fip := c.get("floatingips", r.segments[1])
+		if _, ok := body["port_id"]; ok {
+			if portID := stringField(body, "port_id"); portID == "" {
+				disassociateFloatingIP(fip)
+			} else if status, response := c.associateFloatingIP(fip, portID, stringField(body, "fixed_ip_address")); status != 0 {
+				return status, response
+			}
+		}
+		if description, ok := body["description"]; ok {
+			fip["description"] = description
+		}
+		return http.StatusOK, object{"floatingip": c.render("floatingips", fip)}
+	case r.match(http.MethodDelete, "floatingips", "*"):
+		id := r.segments[1]
+		for _, port := range c.list("ports", func(p object) bool { return p["device_id"] == id && p["device_owner"] == "network:floatingip" }) {
+			c.remove("ports", port["id"].(string))
+		}
+		c.remove("floatingips", id)
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+func disassociateFloatingIP(fip object) {
+	fip["port_id"] = nil
+	fip["fixed_ip_address"] = nil
+	fip["router_id"] = nil
+	fip["status"] = "DOWN"
+}
+
+// associateFloatingIP associates the floating IP with the port. The external network of the floating IP
+// must be the gateway of a router with an interface on the subnet of the port.
+func (c *Cloud) associateFloatingIP(fip object, portID, fixedIP string) (int, interface{}) {
+	port := c.get("ports", portID)
+	if port == nil {
+		return notFound(ServiceNetwork, "Port %s could not be found.", portID)
+	}
+	fixedIPs := objectList(port, "fixed_ips")
+	if len(fixedIPs) == 0 {
+		return badRequest(ServiceNetwork, "Bad floatingip request: Port %s does not have any IP addresses on it.", portID)
+	}
+	ip := fixedIPs[0]
+	for _, i := range fixedIPs {
+		if i["ip_address"] == fixedIP {
+			ip = i
+		}
+	}
+	for _, other := range c.resources["floatingips"] {
+		if other["id"] != fip["id"] && other["port_id"] == portID && other["fixed_ip_address"] == ip["ip_address"] {
+			return conflict(ServiceNetwork, "Cannot associate floating IP %s with port %s using fixed IP %s, as that fixed IP already has a floating IP on external network %s.",
+				fip["floating_ip_address"], portID, ip["ip_address"], other["floating_network_id"])
+		}
+	}
+	var routerID interface{}
+	for _, router := range c.resources["routers"] {
+		gateway := objectField(router, "external_gateway_info")
+		if gateway == nil || gateway["network_id"] != fip["floating_network_id"] {
+			continue
+		}
+		for _, iface := range c.routerPorts(router, "network:router_interface") {
+			for _, ifaceIP := range objectList(iface, "fixed_ips") {
+				if ifaceIP["subnet_id"] == ip["subnet_id"] {
+					routerID = router["id"]
+				}
+			}
+		}
+	}
+	if routerID == nil {
+		return notFound(ServiceNetwork, "External network %s is not reachable from subnet %s. Therefore, cannot associate Port %s with a Floating IP.",
+			fip["floating_network_id"], ip["subnet_id"], portID)
+	}
+	fip["port_id"] = portID
+	fip["fixed_ip_address"] = ip["ip_address"]
+	fip["router_id"] = routerID
+	fip["status"] = "ACTIVE"
+	return 0, nil
+}
+
+// defaultSecurityGroup returns the default security group of the project, creating it on first use like Neutron.
+func (c *Cloud) defaultSecurityGroup() object {
+	for _, sg := range c.resources["security-groups"] {
+		if sg["name"] == "default" {
+			return sg
+		}
+	}
+	sg := c.createSecurityGroup("default", "Default security group")
+	for _, etherType := range []string{"IPv4", "IPv6"} {
+		c.add("security-group-rules", c.newSecurityGroupRule(object{
+			"direction": "ingress", "ethertype": etherType, "security_group_id": sg["id"], "remote_group_id": sg["id"],
+		}))
+	}
+	return sg
+}
+
+// createSecurityGroup creates a security group with the rules Neutron adds to every new group, allowing all egress traffic.
+func (c *Cloud) createSecurityGroup(name, description string) object {
+	sg := c.add("security-groups", c.commonFields(object{"name": name, "description": description}))
+	for _, etherType := range []string{"IPv4", "IPv6"} {
+		c.add("security-group-rules", c.newSecurityGroupRule(object{"direction": "egress", "ethertype": etherType, "security_group_id": sg["id"]}))
+	}
+	return sg
+}
+
+func (c *Cloud) serveSecurityGroups(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "security-groups"):
+		if stringField(body, "name") == "default" {
+			return conflict(ServiceNetwork, "Default security group already exists.")
+		}
+		c.defaultSecurityGroup()
+		sg := c.createSecurityGroup(stringField(body, "name"), stringField(body, "description"))
+		return http.StatusCreated, object{"security_group": c.render("security-groups", sg)}
+	case r.match(http.MethodPut, "security-groups", "*"):
+		sg := c.get("security-groups", r.segments[1])
+		merge(sg, body, "id", "tenant_id", "project_id", "security_group_rules")
+		return http.StatusOK, object{"security_group": c.render("security-groups", sg)}
+	case r.match(http.MethodDelete, "security-groups", "*"):
+		sg := c.get("security-groups", r.segments[1])
+		if sg["name"] == "default" {
+			return conflict(ServiceNetwork, "Removing default security group not allowed.")
+		}
+		for _, port := range c.resources["ports"] {
+			if hasString(port["security_groups"], sg["id"].(string)) {
+				return conflict(ServiceNetwork, "Security Group %s in use.", sg["id"])
+			}
+		}
+		for _, rule := range c.list("security-group-rules", func(rule object) bool {
+			return rule["security_group_id"] == sg["id"] || rule["remote_group_id"] == sg["id"]
+		}) {
+			c.remove("security-group-rules", rule["id"].(string))
+		}
+		c.remove("security-groups", sg["id"].(string))
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
+
+func (c *Cloud) newSecurityGroupRule(body object) object {
+	rule := object{
+		"direction":         body["direction"],
+		"ethertype":         body["ethertype"],
+		"protocol":          nil,
+		"port_range_min":    nil,
+		"port_range_max":    nil,
+		"remote_ip_prefix":  nil,
+		"remote_group_id":   nil,
+		"security_group_id": body["security_group_id"],
+		"description":       "",
+		"tenant_id":         c.project["id"],
+		"project_id":        c.project["id"],
+		"revision_number":   1,
+		"created_at":        now(),
+		"updated_at":        now(),
+	}
+	for _, key := range []string{"protocol", "port_range_min", "port_range_max", "remote_ip_prefix", "remote_group_id", "description"} {
+		if v, ok := body[key]; ok && v != nil && v != "" {
+			rule[key] = v
+		}
+	}
+	return rule
+}
+
+// sameRule returns whether two security group rules allow the same traffic.
+func sameRule(a, b object) bool {
+	for _, key := range []string{"security_group_id", "direction", "ethertype", "protocol", "remote_ip_prefix", "remote_group_id"} {
+		if fieldString(a[key]) != fieldString(b[key]) {
+			return false
+		}
+	}
+	return intField(a, "port_range_min") == intField(b, "port_range_min") && intField(a, "port_range_max") == intField(b, "port_range_max")
+}
+
+func (c *Cloud) serveSecurityGroupRules(r *request, body object) (int, interface{}) {
+	switch {
+	case r.match(http.MethodPost, "security-group-rules"):
+		rule := c.newSecurityGroupRule(body)
+		if c.get("security-groups", stringField(rule, "security_group_id")) == nil {
+			return notFound(ServiceNetwork, "Security group %s does not exist", rule["security_group_id"])
+		}
+		if remote := stringField(rule, "remote_group_id"); remote != "" && c.get("security-groups", remote) == nil {
+			return notFound(ServiceNetwork, "Security group %s does not exist", remote)
+		}
+		if rule["direction"] != "ingress" && rule["direction"] != "egress" {
+			return badRequest(ServiceNetwork, "Invalid input for direction. Reason: %v is not in valid_values.", rule["direction"])
+		}
+		if rule["ethertype"] != "IPv4" && rule["ethertype"] != "IPv6" {
+			return badRequest(ServiceNetwork, "Invalid input for ethertype. Reason: %v is not in valid_values.", rule["ethertype"])
+		}
+		if rule["remote_ip_prefix"] != nil && rule["remote_group_id"] != nil {
+			return badRequest(ServiceNetwork, "Only remote_ip_prefix or remote_group_id may be provided.")
+		}
+		if prefix := stringField(rule, "remote_ip_prefix"); prefix != "" {
+			if _, _, err := net.ParseCIDR(prefix); err != nil {
+				return badRequest(ServiceNetwork, "Invalid input for remote_ip_prefix. Reason: '%s' is not a valid IP subnet.", prefix)
+			}
+		}
+		min, max := intField(rule, "port_range_min"), intField(rule, "port_range_max")
+		if (rule["port_range_min"] != nil || rule["port_range_max"] != nil) && rule["protocol"] == nil {
+			return badRequest(ServiceNetwork, "Must also specify protocol if port range is given.")
+		}
+		if (rule["protocol"] == "tcp" || rule["protocol"] == "udp") && min > max {
+			return badRequest(ServiceNetwork, "For TCP/UDP protocols, port_range_min must be <= port_range_max")
+		}
+		for _, existing := range c.resources["security-group-rules"] {
+			if sameRule(existing, rule) {
+				return conflict(ServiceNetwork, "Security group rule already exists. Rule id is %s.", existing["id"])
+			}
+		}
+		return http.StatusCreated, object{"security_group_rule": copyObject(c.add("security-group-rules", rule))}
+	case r.match(http.MethodDelete, "security-group-rules", "*"):
+		c.remove("security-group-rules", r.segments[1])
+		return http.StatusNoContent, nil
+	}
+	return notFound(ServiceNetwork, "The resource could not be found.")
+}
@@ -11,22 +11,59 @@ import (
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
 	"sigs.k8s.io/cluster-api/api/v1alpha2"
 	"sigs.k8s.io/cluster-api/util"
+	"strings"
 	"time"
 )
 
+// lbMethodSourceIPPort is the only algorithm supported by the OVN provider.
+const lbMethodSourceIPPort pools.LBMethod = "SOURCE_IP_PORT"
+
+// The defaults of the API server health monitors.
+const (
+	defaultMonitorType       = "TCP"
+	defaultMonitorURLPath    = "/healthz"
+	defaultMonitorDelay      = 30
+	defaultMonitorTimeout    = 5
+	defaultMonitorMaxRetries = 3
+)
+
+// providerOVN is the Octavia OVN provider, which only supports the SOURCE_IP_PORT
+// algorithm and TCP health monitors.
+const providerOVN = "ovn"
+
+// createOpts adds the Octavia flavor and availability zone, which gophercloud doesn't
+// support yet, to the load balancer create options.
+type createOpts struct {
+	loadbalancers.CreateOpts
+	FlavorID         string
+	AvailabilityZone string
+}
+
+func (c createOpts) ToLoadBalancerCreateMap() (map[string]interface{}, error) {
+	b, err := c.CreateOpts.ToLoadBalancerCreateMap()
+	if err != nil {
+		return nil, err
+	}
+	lb := b["loadbalancer"].(map[string]interface{})
+	if c.FlavorID != "" {
+		lb["flavor_id"] = c.FlavorID
+	}
+	if c.AvailabilityZone != "" {
+		lb["availability_zone"] = c.AvailabilityZone
+	}
+	return b, nil
+}
+
 func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *infrav1.OpenStackCluster) error {
 
-	if openStackCluster.Spec.ExternalNetworkID == "" {
+	if openStackCluster.Spec.ExternalNetworkID == "" && !openStackCluster.Spec.DisableAPIServerFloatingIP {
 		klog.V(3).Infof("No need to create loadbalancer, due to missing ExternalNetworkID")
 		return nil
 	}
-	if openStackCluster.Spec.APIServerLoadBalancerFloatingIP == "" {
-		klog.V(3).Infof("No need to create loadbalancer, due to missing APIServerLoadBalancerFloatingIP")
-		return nil
-	}
 	if openStackCluster.Spec.APIServerLoadBalancerPort == 0 {
 		klog.V(3).Infof("No need to create loadbalancer, due to missing APIServerLoadBalancerPort")
 		return nil
@@ -35,17 +72,20 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 	loadBalancerName := fmt.Sprintf("%s-cluster-%s-%s", networkPrefix, clusterName, kubeapiLBSuffix)
 	klog.Infof("Reconciling loadbalancer %s", loadBalancerName)
 
+	if !openStackCluster.Spec.UseOctavia {
+		if err := networking.RequireExtension(s.networkingClient, networking.ExtensionLBaaSV2, "the Neutron LBaaS v2 API server loadbalancer"); err != nil {
+			return err
+		}
+	}
+
 	// lb
-	lb, err := checkIfLbExists(s.loadbalancerClient, loadBalancerName)
+	lb, err := s.getLoadBalancer(loadBalancerName, clusterName, openStackCluster)
 	if err != nil {
 		return err
 	}
 	if lb == nil {
 		klog.Infof("Creating loadbalancer %s", loadBalancerName)
-		lbCreateOpts := loadbalancers.CreateOpts{
-			Name:        loadBalancerName,
-			VipSubnetID: openStackCluster.Status.Network.Subnet.ID,
-		}
+		lbCreateOpts := getLoadBalancerCreateOpts(loadBalancerName, clusterName, openStackCluster)
 
 		lb, err = loadbalancers.Create(s.loadbalancerClient, lbCreateOpts).Extract()
 		if err != nil {
@@ -57,38 +97,35 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 		}
 	}
 
-	// floating ip
-	fp, err := checkIfFloatingIPExists(s.networkingClient, openStackCluster.Spec.APIServerLoadBalancerFloatingIP)
-	if err != nil {
-		return err
-	}
-	if fp == nil {
-		klog.Infof("Creating floating ip %s", openStackCluster.Spec.APIServerLoadBalancerFloatingIP)
-		fpCreateOpts := &floatingips.CreateOpts{
-			FloatingIP:        openStackCluster.Spec.APIServerLoadBalancerFloatingIP,
-			FloatingNetworkID: openStackCluster.Spec.ExternalNetworkID,
-		}
-		fp, err = floatingips.Create(s.networkingClient, fpCreateOpts).Extract()
+	// floating ip, the VIP is the endpoint of private clusters
+	var floatingIP string
+	if !openStackCluster.Spec.DisableAPIServerFloatingIP {
+		floatingIP, err = s.reconcileLoadBalancerFloatingIP(openStackCluster, loadBalancerName, lb.VipPortID)
 		if err != nil {
-			return fmt.Errorf("error allocating floating IP: %s", err)
+			return err
 		}
 	}
 
-	// associate floating ip
-	klog.Infof("Associating floating ip %s", openStackCluster.Spec.APIServerLoadBalancerFloatingIP)
-	fpUpdateOpts := &floatingips.UpdateOpts{
-		PortID: &lb.VipPortID,
-	}
-	fp, err = floatingips.Update(s.networkingClient, fp.ID, fpUpdateOpts).Extract()
-	if err != nil {
-		return fmt.Errorf("error allocating floating IP: %s", err)
-	}
-	err = waitForFloatingIP(s.networkingClient, fp.ID, "ACTIVE")
-	if err != nil {
+	if infrav1.IsExternallyManaged(openStackCluster, lb.ID) {
+		klog.V(4).Infof("Loadbalancer %s is externally managed, not reconciling its listeners.", loadBalancerName)
+	} else if err := s.reconcileLoadBalancerListeners(openStackCluster, lb, loadBalancerName); err != nil {
 		return err
 	}
 
+	openStackCluster.Status.Network.APIServerLoadBalancer = &infrav1.LoadBalancer{
+		Name:       lb.Name,
+		ID:         lb.ID,
+		InternalIP: lb.VipAddress,
+		IP:         floatingIP,
+	}
+	return nil
+}
+
+// reconcileLoadBalancerListeners creates or updates the listeners, pools and monitors of the
+// APIServer loadbalancer for the APIServer port and the additional ports.
+func (s *Service) reconcileLoadBalancerListeners(openStackCluster *infrav1.OpenStackCluster, lb *loadbalancers.LoadBalancer, loadBalancerName string) error {
 	// lb listener
+	lbSpec := openStackCluster.Spec.APIServerLoadBalancer
 	portList := []int{openStackCluster.Spec.APIServerLoadBalancerPort}
 	portList = append(portList, openStackCluster.Spec.APIServerLoadBalancerAdditionalPorts...)
 	for _, port := range portList {
@@ -106,6 +143,12 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 				ProtocolPort:   port,
 				LoadbalancerID: lb.ID,
 			}
+			if lbSpec.Listener != nil {
+				listenerCreateOpts.ConnLimit = lbSpec.Listener.ConnectionLimit
+				listenerCreateOpts.TimeoutClientData = lbSpec.Listener.TimeoutClientData
+				listenerCreateOpts.TimeoutMemberData = lbSpec.Listener.TimeoutMemberData
+				listenerCreateOpts.TimeoutMemberConnect = lbSpec.Listener.TimeoutMemberConnect
+			}
 			listener, err = listeners.Create(s.loadbalancerClient, listenerCreateOpts).Extract()
 			if err != nil {
 				return fmt.Errorf("error creating listener: %s", err)
@@ -118,9 +161,20 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 			if err != nil {
 				return err
 			}
+		} else if listenerUpdateOpts := getListenerUpdateOpts(listener, lbSpec.Listener); listenerUpdateOpts != nil {
+			klog.Infof("Updating lb listener %s", lbPortObjectsName)
+			_, err = listeners.Update(s.loadbalancerClient, listener.ID, *listenerUpdateOpts).Extract()
+			if err != nil {
+				return fmt.Errorf("error updating listener: %s", err)
+			}
+			err = waitForLoadBalancer(s.loadbalancerClient, lb.ID, "ACTIVE")
+			if err != nil {
+				return err
+			}
 		}
 
 		// lb pool
+		method := lbMethod(lb.Provider, lbSpec.Algorithm)
 		pool, err := checkIfPoolExists(s.loadbalancerClient, lbPortObjectsName)
 		if err != nil {
 			return err
@@ -130,7 +184,7 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 			poolCreateOpts := pools.CreateOpts{
 				Name:       lbPortObjectsName,
 				Protocol:   "TCP",
-				LBMethod:   pools.LBMethodRoundRobin,
+				LBMethod:   method,
 				ListenerID: listener.ID,
 			}
 			pool, err = pools.Create(s.loadbalancerClient, poolCreateOpts).Extract()
@@ -141,22 +195,51 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 			if err != nil {
 				return err
 			}
+		} else if pool.LBMethod != string(method) {
+			klog.Infof("Updating lb pool %s algorithm from %s to %s", lbPortObjectsName, pool.LBMethod, method)
+			_, err = pools.Update(s.loadbalancerClient, pool.ID, pools.UpdateOpts{LBMethod: method}).Extract()
+			if err != nil {
+				return fmt.Errorf("error updating pool: %s", err)
+			}
+			err = waitForLoadBalancer(s.loadbalancerClient, lb.ID, "ACTIVE")
+			if err != nil {
+				return err
+			}
 		}
 
 		// lb monitor
+		monitorSpec := getMonitorSpec(lb.Provider, lbSpec.Monitor)
 		monitor, err := checkIfMonitorExists(s.loadbalancerClient, lbPortObjectsName)
 		if err != nil {
 			return err
 		}
+		if monitor != nil && monitor.Type != monitorSpec.Type {
+			// The type of a monitor can't be updated, so it's replaced.
+			klog.Infof("Deleting lb monitor %s (because its type changed from %s to %s)", lbPortObjectsName, monitor.Type, monitorSpec.Type)
+			err = monitors.Delete(s.loadbalancerClient, monitor.ID).ExtractErr()
+			if err != nil {
+				return fmt.Errorf("error deleting monitor: %s", err)
+			}
+			err = waitForLoadBalancer(s.loadbalancerClient, lb.ID, "ACTIVE")
+			if err != nil {
+				return err
+			}
+			monitor = nil
+		}
 		if monitor == nil {
 			klog.Infof("Creating lb monitor %s", lbPortObjectsName)
 			monitorCreateOpts := monitors.CreateOpts{
 				Name:       lbPortObjectsName,
 				PoolID:     pool.ID,
-				Type:       "TCP",
-				Delay:      30,
-				Timeout:    5,
-				MaxRetries: 3,
+				Type:       monitorSpec.Type,
+				Delay:      monitorSpec.Delay,
+				Timeout:    monitorSpec.Timeout,
+				MaxRetries: monitorSpec.MaxRetries,
+			}
+			if isHTTPMonitor(monitorSpec.Type) {
+				monitorCreateOpts.URLPath = monitorSpec.URLPath
+				monitorCreateOpts.HTTPMethod = "GET"
+				monitorCreateOpts.ExpectedCodes = "200"
 			}
 			_, err = monitors.Create(s.loadbalancerClient, monitorCreateOpts).Extract()
 			if err != nil {
@@ -166,16 +249,181 @@ func (s *Service) ReconcileLoadBalancer(clusterName string, openStackCluster *in
 			if err != nil {
 				return err
 			}
+		} else if monitorUpdateOpts := getMonitorUpdateOpts(monitor, monitorSpec); monitorUpdateOpts != nil {
+			klog.Infof("Updating lb monitor %s", lbPortObjectsName)
+			_, err = monitors.Update(s.loadbalancerClient, monitor.ID, *monitorUpdateOpts).Extract()
+			if err != nil {
+				return fmt.Errorf("error updating monitor: %s", err)
+			}
+			err = waitForLoadBalancer(s.loadbalancerClient, lb.ID, "ACTIVE")
+			if err != nil {
+				return err
+			}
 		}
 	}
+	return nil
+}
 
-	openStackCluster.Status.Network.APIServerLoadBalancer = &infrav1.LoadBalancer{
-		Name:       lb.Name,
-		ID:         lb.ID,
-		InternalIP: lb.VipAddress,
-		IP:         fp.FloatingIP,
+// reconcileLoadBalancerFloatingIP associates the floating IP of the cluster with the VIP
+// port of the loadbalancer and returns it.
+func (s *Service) reconcileLoadBalancerFloatingIP(openStackCluster *infrav1.OpenStackCluster, loadBalancerName, vipPortID string) (string, error) {
+	fp, err := s.networkingService.GetOrCreateFloatingIP(openStackCluster, openStackCluster.Spec.APIServerLoadBalancerFloatingIP, loadBalancerName)
+	if err != nil {
+		return "", err
 	}
-	return nil
+	if fp.PortID == vipPortID {
+		return fp.FloatingIP, nil
+	}
+	if infrav1.IsExternallyManaged(openStackCluster, fp.ID) {
+		klog.V(4).Infof("Skipped associating floating ip %s that is externally managed", fp.FloatingIP)
+		return fp.FloatingIP, nil
+	}
+
+	klog.Infof("Associating floating ip %s", fp.FloatingIP)
+	fpUpdateOpts := &floatingips.UpdateOpts{
+		PortID: &vipPortID,
+	}
+	fp, err = floatingips.Update(s.networkingClient, fp.ID, fpUpdateOpts).Extract()
+	if err != nil {
+		return "", fmt.Errorf("error allocating floating IP: %s", err)
+	}
+	err = waitForFloatingIP(s.networkingClient, fp.ID, "ACTIVE")
+	if err != nil {
+		return "", err
+	}
+	return fp.FloatingIP, nil
+}
+
+// getLoadBalancerCreateOpts returns the create options of the API server load balancer.
+// The VIP is allocated from the subnet of the cluster unless a VIP port or network is set. The
+// description identifies the cluster as owner, as Neutron LBaaS doesn't support tags.
+func getLoadBalancerCreateOpts(name, clusterName string, openStackCluster *infrav1.OpenStackCluster) createOpts {
+	spec := openStackCluster.Spec.APIServerLoadBalancer
+	opts := createOpts{
+		CreateOpts: loadbalancers.CreateOpts{
+			Name:        name,
+			Description: networking.OwnerDescription(clusterName),
+			Provider:    spec.Provider,
+		},
+		FlavorID:         spec.FlavorID,
+		AvailabilityZone: spec.AvailabilityZone,
+	}
+	switch {
+	case spec.VipPortID != "":
+		opts.VipPortID = spec.VipPortID
+	case spec.VipNetworkID != "":
+		opts.VipNetworkID = spec.VipNetworkID
+		opts.VipAddress = spec.VipAddress
+	default:
+		opts.VipSubnetID = openStackCluster.Status.Network.Subnet.ID
+		opts.VipAddress = spec.VipAddress
+	}
+	return opts
+}
+
+// lbMethod returns the algorithm of the API server pools, which falls back to
+// SOURCE_IP_PORT with the OVN provider as it doesn't support other algorithms.
+func lbMethod(provider, algorithm string) pools.LBMethod {
+	if provider == providerOVN {
+		return lbMethodSourceIPPort
+	}
+	if algorithm != "" {
+		return pools.LBMethod(algorithm)
+	}
+	return pools.LBMethodRoundRobin
+}
+
+// monitorType returns the type of the API server health monitors supported by the
+// provider, which falls back to TCP if the provider doesn't support HTTP monitors.
+func monitorType(provider, monitorType string) string {
+	if provider == providerOVN && isHTTPMonitor(monitorType) {
+		return "TCP"
+	}
+	return monitorType
+}
+
+func isHTTPMonitor(monitorType string) bool {
+	return monitorType == "HTTP" || monitorType == "HTTPS"
+}
+
+// getMonitorSpec returns the settings of the API server health monitors with the
+// defaults for the unset fields.
+func getMonitorSpec(provider string, spec *infrav1.LoadBalancerMonitor) infrav1.LoadBalancerMonitor {
+	monitor := infrav1.LoadBalancerMonitor{
+		Type:       defaultMonitorType,
+		URLPath:    defaultMonitorURLPath,
+		Delay:      defaultMonitorDelay,
+		Timeout:    defaultMonitorTimeout,
+		MaxRetries: defaultMonitorMaxRetries,
+	}
+	if spec != nil {
+		if spec.Type != "" {
+			monitor.Type = spec.Type
+		}
+		if spec.URLPath != "" {
+			monitor.URLPath = spec.URLPath
+		}
+		if spec.Delay != 0 {
+			monitor.Delay = spec.Delay
+		}
+		if spec.Timeout != 0 {
+			monitor.Timeout = spec.Timeout
+		}
+		if spec.MaxRetries != 0 {
+			monitor.MaxRetries = spec.MaxRetries
+		}
+	}
+	monitor.Type = monitorType(provider, monitor.Type)
+	return monitor
+}
+
+// getMonitorUpdateOpts returns the update of a health monitor to the settings, or nil
+// if it's up to date.
+func getMonitorUpdateOpts(monitor *monitors.Monitor, spec infrav1.LoadBalancerMonitor) *monitors.UpdateOpts {
+	opts := monitors.UpdateOpts{}
+	changed := false
+	if monitor.Delay != spec.Delay || monitor.Timeout != spec.Timeout || monitor.MaxRetries != spec.MaxRetries {
+		opts.Delay, opts.Timeout, opts.MaxRetries = spec.Delay, spec.Timeout, spec.MaxRetries
+		changed = true
+	}
+	if isHTTPMonitor(spec.Type) && monitor.URLPath != spec.URLPath {
+		opts.URLPath = spec.URLPath
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return &opts
+}
+
+// getListenerUpdateOpts returns the update of a listener to the settings, or nil if
+// it's up to date. Unset settings aren't updated.
+func getListenerUpdateOpts(listener *listeners.Listener, spec *infrav1.LoadBalancerListener) *listeners.UpdateOpts {
+	if spec == nil {
+		return nil
+	}
+	opts := listeners.UpdateOpts{}
+	changed := false
+	if spec.ConnectionLimit != nil && *spec.ConnectionLimit != listener.ConnLimit {
+		opts.ConnLimit = spec.ConnectionLimit
+		changed = true
+	}
+	if spec.TimeoutClientData != nil && *spec.TimeoutClientData != listener.TimeoutClientData {
+		opts.TimeoutClientData = spec.TimeoutClientData
+		changed = true
+	}
+	if spec.TimeoutMemberData != nil && *spec.TimeoutMemberData != listener.TimeoutMemberData {
+		opts.TimeoutMemberData = spec.TimeoutMemberData
+		changed = true
+	}
+	if spec.TimeoutMemberConnect != nil && *spec.TimeoutMemberConnect != listener.TimeoutMemberConnect {
+		opts.TimeoutMemberConnect = spec.TimeoutMemberConnect
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return &opts
 }
 
 func (s *Service) ReconcileLoadBalancerMember(clusterName string, machine *v1alpha2.Machine, openStackMachine *infrav1.OpenStackMachine, openStackCluster *infrav1.OpenStackCluster, ip string) error {
@@ -192,6 +440,10 @@ func (s *Service) ReconcileLoadBalancerMember(clusterName string, machine *v1alp
 	if openStackCluster.Status.Network.APIServerLoadBalancer == nil {
 		return errors.New("network.APIServerLoadBalancer is not yet available in openStackCluster.Status")
 	}
+	if infrav1.IsExternallyManaged(openStackCluster, openStackCluster.Status.Network.APIServerLoadBalancer.ID) {
+		klog.V(4).Infof("Skipped adding member %s to loadbalancer that is externally managed", openStackMachine.Name)
+		return nil
+	}
 
 	loadBalancerName := fmt.Sprintf("%s-cluster-%s-%s", networkPrefix, clusterName, kubeapiLBSuffix)
 	klog.Infof("Reconciling loadbalancer %s for member %s", loadBalancerName, openStackMachine.Name)
@@ -268,15 +520,84 @@ func (s *Service) ReconcileLoadBalancerMember(clusterName string, machine *v1alp
 	return nil
 }
 
+// DeleteStaleLoadBalancerMembers deletes the members of the API server pools which don't
+// belong to one of the given control plane machines, e.g. the members of machines whose
+// OpenStackMachine was deleted without removing its members.
+func (s *Service) DeleteStaleLoadBalancerMembers(clusterName string, openStackCluster *infrav1.OpenStackCluster, openStackMachineNames []string) error {
+	if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.APIServerLoadBalancer == nil {
+		return nil
+	}
+	if infrav1.IsExternallyManaged(openStackCluster, openStackCluster.Status.Network.APIServerLoadBalancer.ID) {
+		return nil
+	}
+
+	loadBalancerName := fmt.Sprintf("%s-cluster-%s-%s", networkPrefix, clusterName, kubeapiLBSuffix)
+	klog.V(4).Infof("Reconciling members of loadbalancer %s", loadBalancerName)
+
+	lbID := openStackCluster.Status.Network.APIServerLoadBalancer.ID
+
+	portList := []int{openStackCluster.Spec.APIServerLoadBalancerPort}
+	portList = append(portList, openStackCluster.Spec.APIServerLoadBalancerAdditionalPorts...)
+	for _, port := range portList {
+		lbPortObjectsName := fmt.Sprintf("%s-%d", loadBalancerName, port)
+
+		pool, err := checkIfPoolExists(s.loadbalancerClient, lbPortObjectsName)
+		if err != nil {
+			return err
+		}
+		if pool == nil {
+			continue
+		}
+
+		allPages, err := pools.ListMembers(s.loadbalancerClient, pool.ID, pools.ListMembersOpts{}).AllPages()
+		if err != nil {
+			return fmt.Errorf("error listing members of pool %s: %v", pool.ID, err)
+		}
+		members, err := pools.ExtractMembers(allPages)
+		if err != nil {
+			return fmt.Errorf("unable to extract members: %v", err)
+		}
+
+		live := map[string]bool{}
+		for _, name := range openStackMachineNames {
+			live[lbPortObjectsName+"-"+name] = true
+		}
+		for _, member := range members {
+			// Only the members created for machines are managed, others were added by the user.
+			if !strings.HasPrefix(member.Name, lbPortObjectsName+"-") || live[member.Name] {
+				continue
+			}
+
+			klog.Infof("Deleting lb member %s (because its machine doesn't exist anymore)", member.Name)
+			err = waitForLoadBalancer(s.loadbalancerClient, lbID, "ACTIVE")
+			if err != nil {
+				return err
+			}
+			err = pools.DeleteMember(s.loadbalancerClient, pool.ID, member.ID).ExtractErr()
+			if err != nil {
+				return fmt.Errorf("error deleting lbmember: %s", err)
+			}
+			err = waitForLoadBalancer(s.loadbalancerClient, lbID, "ACTIVE")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (s *Service) DeleteLoadBalancer(clusterName string, openStackCluster *infrav1.OpenStackCluster) error {
 	loadBalancerName := fmt.Sprintf("%s-cluster-%s-%s", networkPrefix, clusterName, kubeapiLBSuffix)
-	lb, err := checkIfLbExists(s.loadbalancerClient, loadBalancerName)
+	lb, err := s.getLoadBalancer(loadBalancerName, clusterName, openStackCluster)
 	if err != nil {
 		return err
 	}
-
-	// only Octavia supports Cascade
-	if openStackCluster.Spec.UseOctavia {
+	if lb == nil {
+		klog.V(4).Infof("Skipped deleting loadbalancer %s that is already deleted", loadBalancerName)
+	} else if infrav1.IsExternallyManaged(openStackCluster, lb.ID) {
+		klog.V(4).Infof("Skipped deleting loadbalancer %s that is externally managed", loadBalancerName)
+	} else if openStackCluster.Spec.UseOctavia {
+		// only Octavia supports Cascade
 		deleteOpts := loadbalancers.DeleteOpts{
 			Cascade: true,
 		}
@@ -292,9 +613,11 @@ func (s *Service) DeleteLoadBalancer(clusterName string, openStackCluster *infra
 	}
 
 	// floating ip
-	// TODO: need delete floating IP if it's created when doing the cluster provisioning
-	// but keep the floating ips if it's original exist (probably should store it in the
-	// Cluster status if the floating ip has been created by us)
+	if openStackCluster.Status.Network != nil && openStackCluster.Status.Network.APIServerLoadBalancer != nil {
+		if err := s.networkingService.DeleteFloatingIP(openStackCluster.Status.Network.APIServerLoadBalancer.IP, loadBalancerName); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -402,6 +725,10 @@ func (s *Service) DeleteLoadBalancerMember(clusterName string, machine *v1alpha2
 	klog.Infof("Reconciling loadbalancer %s", loadBalancerName)
 
 	lbID := openStackCluster.Status.Network.APIServerLoadBalancer.ID
+	if infrav1.IsExternallyManaged(openStackCluster, lbID) {
+		klog.V(4).Infof("Skipped deleting member %s from loadbalancer %s that is externally managed", openStackMachine.Name, loadBalancerName)
+		return nil
+	}
 
 	portList := []int{openStackCluster.Spec.APIServerLoadBalancerPort}
 	portList = append(portList, openStackCluster.Spec.APIServerLoadBalancerAdditionalPorts...)
@@ -443,6 +770,33 @@ func (s *Service) DeleteLoadBalancerMember(clusterName string, machine *v1alpha2
 	return nil
 }
 
+// getLoadBalancer returns the loadbalancer recorded in the status if it is owned by the cluster,
+// or the loadbalancer with the name otherwise, e.g. because the status was lost when the cluster
+// was moved.
+func (s *Service) getLoadBalancer(name, clusterName string, openStackCluster *infrav1.OpenStackCluster) (*loadbalancers.LoadBalancer, error) {
+	if openStackCluster.Status.Network != nil && openStackCluster.Status.Network.APIServerLoadBalancer != nil &&
+		openStackCluster.Status.Network.APIServerLoadBalancer.ID != "" {
+		lb, err := loadbalancers.Get(s.loadbalancerClient, openStackCluster.Status.Network.APIServerLoadBalancer.ID).Extract()
+		if err != nil && !networking.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if isOwned(openStackCluster, clusterName, lb) {
+				return lb, nil
+			}
+			klog.V(3).Infof("Loadbalancer %s of the status isn't owned by cluster %s, looking it up by name", lb.ID, clusterName)
+		}
+	}
+	return checkIfLbExists(s.loadbalancerClient, name)
+}
+
+// isOwned returns whether the loadbalancer is tagged or described as owned by the cluster, or is
+// externally managed.
+func isOwned(openStackCluster *infrav1.OpenStackCluster, clusterName string, lb *loadbalancers.LoadBalancer) bool {
+	return networking.HasOwnershipTags(lb.Tags, clusterName) || lb.Description == networking.OwnerDescription(clusterName) ||
+		infrav1.IsExternallyManaged(openStackCluster, lb.ID)
+}
+
 func checkIfLbExists(client *gophercloud.ServiceClient, name string) (*loadbalancers.LoadBalancer, error) {
 	allPages, err := loadbalancers.List(client, loadbalancers.ListOpts{Name: name}).AllPages()
 	if err != nil {
@@ -458,21 +812,6 @@ func checkIfLbExists(client *gophercloud.ServiceClient, name string) (*loadbalan
 	return &lbList[0], nil
 }
 
-func checkIfFloatingIPExists(client *gophercloud.ServiceClient, ip string) (*floatingips.FloatingIP, error) {
-	allPages, err := floatingips.List(client, floatingips.ListOpts{FloatingIP: ip}).AllPages()
-	if err != nil {
-		return nil, err
-	}
-	fpList, err := floatingips.ExtractFloatingIPs(allPages)
-	if err != nil {
-		return nil, err
-	}
-	if len(fpList) == 0 {
-		return nil, nil
-	}
-	return &fpList[0], nil
-}
-
 func checkIfListenerExists(client *gophercloud.ServiceClient, name string) (*listeners.Listener, error) {
 	allPages, err := listeners.List(client, listeners.ListOpts{Name: name}).AllPages()
 	if err != nil {
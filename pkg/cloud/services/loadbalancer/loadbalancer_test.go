@@ -0,0 +1,438 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
+	"sigs.k8s.io/cluster-api/api/v1alpha2"
+)
+
+// newTestCluster reconciles the network of a cluster with an API server load balancer on the cloud.
+func newTestCluster(t *testing.T, cloud *fake.Cloud, useOctavia bool) (*Service, *infrav1.OpenStackCluster) {
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	client, clientOpts, err := cloud.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{
+			NodeCIDR:                        "10.6.0.0/24",
+			ExternalNetworkID:               externalNetworkID,
+			UseOctavia:                      useOctavia,
+			APIServerLoadBalancerFloatingIP: "172.24.4.10",
+			APIServerLoadBalancerPort:       6443,
+		},
+	}
+	networkingService, err := networking.NewService(client, clientOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := networkingService.ReconcileNetwork("test", openStackCluster); err != nil {
+		t.Fatal(err)
+	}
+	if err := networkingService.ReconcileSubnet("test", openStackCluster); err != nil {
+		t.Fatal(err)
+	}
+	if err := networkingService.ReconcileRouter("test", openStackCluster); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewService(client, clientOpts, useOctavia)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, openStackCluster
+}
+
+func TestLoadBalancerLifecycle(t *testing.T) {
+	for _, useOctavia := range []bool{true, false} {
+		cloud := fake.NewCloud()
+		s, openStackCluster := newTestCluster(t, cloud, useOctavia)
+
+		if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+			t.Fatalf("failed to reconcile load balancer (octavia: %t): %v", useOctavia, err)
+		}
+		lb := openStackCluster.Status.Network.APIServerLoadBalancer
+		if lb == nil || lb.IP != "172.24.4.10" || lb.InternalIP == "" {
+			t.Fatalf("expected load balancer with floating IP 172.24.4.10 in the status, got %+v", lb)
+		}
+		if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+			t.Fatalf("failed to reconcile existing load balancer (octavia: %t): %v", useOctavia, err)
+		}
+		for _, collection := range []string{"loadbalancers", "listeners", "pools", "healthmonitors"} {
+			if n := len(cloud.Resources(collection)); n != 1 {
+				t.Errorf("expected 1 of %s, got %d", collection, n)
+			}
+		}
+
+		machine := &v1alpha2.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:   "control-plane-0",
+			Labels: map[string]string{v1alpha2.MachineControlPlaneLabelName: "true"},
+		}}
+		openStackMachine := &infrav1.OpenStackMachine{ObjectMeta: metav1.ObjectMeta{Name: "control-plane-0"}}
+		for _, ip := range []string{"10.6.0.5", "10.6.0.6"} {
+			if err := s.ReconcileLoadBalancerMember("test", machine, openStackMachine, openStackCluster, ip); err != nil {
+				t.Fatalf("failed to reconcile load balancer member (octavia: %t): %v", useOctavia, err)
+			}
+		}
+		members := cloud.Resources("members")
+		if len(members) != 1 || members[0]["address"] != "10.6.0.6" {
+			t.Errorf("expected the member to be recreated with the new address, got %v", members)
+		}
+		if err := s.DeleteLoadBalancerMember("test", machine, openStackMachine, openStackCluster); err != nil {
+			t.Fatalf("failed to delete load balancer member (octavia: %t): %v", useOctavia, err)
+		}
+		if n := len(cloud.Resources("members")); n != 0 {
+			t.Errorf("expected no members, got %d", n)
+		}
+
+		if err := s.DeleteLoadBalancer("test", openStackCluster); err != nil {
+			t.Fatalf("failed to delete load balancer (octavia: %t): %v", useOctavia, err)
+		}
+		for _, collection := range []string{"loadbalancers", "listeners", "pools", "healthmonitors", "floatingips"} {
+			if n := len(cloud.Resources(collection)); n != 0 {
+				t.Errorf("expected no %s after deleting the load balancer, got %d", collection, n)
+			}
+		}
+		cloud.Close()
+	}
+}
+
+func TestReconcileLoadBalancerAfterMove(t *testing.T) {
+	for _, useOctavia := range []bool{true, false} {
+		cloud := fake.NewCloud()
+		s, openStackCluster := newTestCluster(t, cloud, useOctavia)
+
+		machine := &v1alpha2.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:   "control-plane-0",
+			Labels: map[string]string{v1alpha2.MachineControlPlaneLabelName: "true"},
+		}}
+		openStackMachine := &infrav1.OpenStackMachine{ObjectMeta: metav1.ObjectMeta{Name: "control-plane-0"}}
+		if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+			t.Fatalf("failed to reconcile load balancer (octavia: %t): %v", useOctavia, err)
+		}
+		if err := s.ReconcileLoadBalancerMember("test", machine, openStackMachine, openStackCluster, "10.6.0.5"); err != nil {
+			t.Fatalf("failed to reconcile load balancer member (octavia: %t): %v", useOctavia, err)
+		}
+
+		// The load balancer in the moved status is adopted without changing it.
+		moved := openStackCluster.DeepCopy()
+		cloud.ResetRequests()
+		if err := s.ReconcileLoadBalancer("test", moved); err != nil {
+			t.Fatalf("failed to reconcile moved load balancer (octavia: %t): %v", useOctavia, err)
+		}
+		if err := s.ReconcileLoadBalancerMember("test", machine, openStackMachine, moved, "10.6.0.5"); err != nil {
+			t.Fatalf("failed to reconcile moved load balancer member (octavia: %t): %v", useOctavia, err)
+		}
+		for _, service := range []string{fake.ServiceLoadBalancer, fake.ServiceNetwork} {
+			for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+				if n := cloud.CountRequests(service, method, "."); n != 0 {
+					t.Errorf("expected the load balancer not to be changed (octavia: %t), got %d %s %s requests", useOctavia, n, service, method)
+				}
+			}
+		}
+		if !reflect.DeepEqual(moved.Status.Network.APIServerLoadBalancer, openStackCluster.Status.Network.APIServerLoadBalancer) {
+			t.Errorf("expected the load balancer to be adopted (octavia: %t), got %+v", useOctavia, moved.Status.Network.APIServerLoadBalancer)
+		}
+
+		// The load balancer in the status isn't adopted by another cluster, which looks up its own
+		// load balancer by name.
+		lb, err := s.getLoadBalancer(fmt.Sprintf("%s-cluster-other-%s", networkPrefix, kubeapiLBSuffix), "other", moved)
+		if err != nil || lb != nil {
+			t.Errorf("expected the load balancer not to be adopted by another cluster (octavia: %t), got %+v: %v", useOctavia, lb, err)
+		}
+		cloud.Close()
+	}
+}
+
+func TestReconcileLoadBalancerAllocatesFloatingIP(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	openStackCluster.Spec.APIServerLoadBalancerFloatingIP = ""
+
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	lb := openStackCluster.Status.Network.APIServerLoadBalancer
+	if lb == nil || lb.IP == "" {
+		t.Fatalf("expected load balancer with an allocated floating IP in the status, got %+v", lb)
+	}
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile existing load balancer: %v", err)
+	}
+	fips := cloud.Resources("floatingips")
+	if len(fips) != 1 || fips[0]["floating_ip_address"] != lb.IP {
+		t.Errorf("expected the allocated floating IP %s to be reused, got %v", lb.IP, fips)
+	}
+
+	if err := s.DeleteLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to delete load balancer: %v", err)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 0 {
+		t.Errorf("expected the allocated floating IP to be released, got %d floating IPs", n)
+	}
+}
+
+func TestReconcileLoadBalancerWithoutFloatingIP(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	openStackCluster.Spec.ExternalNetworkID = ""
+	openStackCluster.Spec.APIServerLoadBalancerFloatingIP = ""
+	openStackCluster.Spec.DisableAPIServerFloatingIP = true
+
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	lb := openStackCluster.Status.Network.APIServerLoadBalancer
+	if lb == nil || lb.IP != "" || lb.InternalIP == "" {
+		t.Fatalf("expected load balancer with only a VIP in the status, got %+v", lb)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 0 {
+		t.Errorf("expected no floating IPs, got %d", n)
+	}
+	if err := s.DeleteLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to delete load balancer: %v", err)
+	}
+}
+
+func TestReconcileLoadBalancerWithoutLBaaS(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, false)
+	cloud.SetExtensions("external-net", "router", "security-group", "standard-attr-tag")
+
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err == nil {
+		t.Fatalf("expected reconciliation to fail without the lbaasv2 extension")
+	}
+}
+
+func TestReconcileLoadBalancerWithProvider(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	vipNetworkID := cloud.AddNetwork("vip", false)
+	vipSubnetID := cloud.AddSubnet(vipNetworkID, "vip", "10.8.0.0/24")
+	// The floating IP can only be associated with a VIP which is reachable from the external network.
+	if _, err := routers.AddInterface(s.networkingClient, openStackCluster.Status.Network.Router.ID, routers.AddInterfaceOpts{SubnetID: vipSubnetID}).Extract(); err != nil {
+		t.Fatal(err)
+	}
+	openStackCluster.Spec.APIServerLoadBalancer = infrav1.APIServerLoadBalancer{
+		Provider:         "ovn",
+		FlavorID:         "flavor",
+		AvailabilityZone: "az1",
+		VipNetworkID:     vipNetworkID,
+		VipAddress:       "10.8.0.10",
+	}
+
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	lb := cloud.Resources("loadbalancers")[0]
+	if lb["provider"] != "ovn" || lb["flavor_id"] != "flavor" || lb["availability_zone"] != "az1" {
+		t.Errorf("expected the load balancer to use the provider, flavor and availability zone, got %v", lb)
+	}
+	if lb["vip_network_id"] != vipNetworkID || lb["vip_address"] != "10.8.0.10" {
+		t.Errorf("expected the VIP 10.8.0.10 on network %s, got %v", vipNetworkID, lb)
+	}
+	if pool := cloud.Resources("pools")[0]; pool["lb_algorithm"] != string(lbMethodSourceIPPort) {
+		t.Errorf("expected the OVN pool to use SOURCE_IP_PORT, got %v", pool["lb_algorithm"])
+	}
+}
+
+func TestReconcileLoadBalancerWithVipPort(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	port, err := ports.Create(s.networkingClient, ports.CreateOpts{NetworkID: openStackCluster.Status.Network.ID}).Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	openStackCluster.Spec.APIServerLoadBalancer.VipPortID = port.ID
+
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	if lb := openStackCluster.Status.Network.APIServerLoadBalancer; lb.InternalIP != port.FixedIPs[0].IPAddress {
+		t.Errorf("expected the VIP %s of the port, got %s", port.FixedIPs[0].IPAddress, lb.InternalIP)
+	}
+	if err := s.DeleteLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to delete load balancer: %v", err)
+	}
+	if _, err := ports.Get(s.networkingClient, port.ID).Extract(); err != nil {
+		t.Errorf("expected the VIP port to be kept, got %v", err)
+	}
+}
+
+func TestProviderConstraints(t *testing.T) {
+	if m := lbMethod("", ""); m != pools.LBMethodRoundRobin {
+		t.Errorf("expected ROUND_ROBIN for the default provider, got %s", m)
+	}
+	if m := lbMethod(providerOVN, "LEAST_CONNECTIONS"); m != lbMethodSourceIPPort {
+		t.Errorf("expected OVN to fall back to SOURCE_IP_PORT, got %s", m)
+	}
+	if m := monitorType(providerOVN, "HTTPS"); m != "TCP" {
+		t.Errorf("expected OVN to fall back to TCP monitors, got %s", m)
+	}
+	if m := monitorType("amphora", "HTTPS"); m != "HTTPS" {
+		t.Errorf("expected amphora to support HTTPS monitors, got %s", m)
+	}
+}
+
+func TestReconcileLoadBalancerSettings(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	monitor := cloud.Resources("healthmonitors")[0]
+	if monitor["type"] != "TCP" || monitor["delay"] != float64(30) {
+		t.Errorf("expected the default TCP monitor, got %v", monitor)
+	}
+
+	connectionLimit, timeout := 1000, 10000
+	openStackCluster.Spec.APIServerLoadBalancer = infrav1.APIServerLoadBalancer{
+		Algorithm: "LEAST_CONNECTIONS",
+		Monitor:   &infrav1.LoadBalancerMonitor{Type: "HTTPS", Delay: 5, Timeout: 3, MaxRetries: 2},
+		Listener:  &infrav1.LoadBalancerListener{ConnectionLimit: &connectionLimit, TimeoutClientData: &timeout},
+	}
+	cloud.ResetRequests()
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer settings: %v", err)
+	}
+	if n := cloud.CountRequests(fake.ServiceLoadBalancer, http.MethodPost, "^lbaas/(loadbalancers|listeners|pools)$"); n != 0 {
+		t.Errorf("expected the load balancer, listener and pool to be kept, got %d creates", n)
+	}
+	listener := cloud.Resources("listeners")[0]
+	if listener["connection_limit"] != float64(1000) || listener["timeout_client_data"] != float64(10000) || listener["timeout_member_data"] != float64(50000) {
+		t.Errorf("expected the listener to be updated, got %v", listener)
+	}
+	if pool := cloud.Resources("pools")[0]; pool["lb_algorithm"] != "LEAST_CONNECTIONS" {
+		t.Errorf("expected the pool algorithm to be updated, got %v", pool["lb_algorithm"])
+	}
+	monitors := cloud.Resources("healthmonitors")
+	if len(monitors) != 1 || monitors[0]["type"] != "HTTPS" || monitors[0]["url_path"] != "/healthz" || monitors[0]["delay"] != float64(5) {
+		t.Errorf("expected the monitor to be replaced by an HTTPS /healthz monitor, got %v", monitors)
+	}
+
+	openStackCluster.Spec.APIServerLoadBalancer.Monitor.Delay = 10
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer settings: %v", err)
+	}
+	if monitor := cloud.Resources("healthmonitors")[0]; monitor["id"] != monitors[0]["id"] || monitor["delay"] != float64(10) {
+		t.Errorf("expected the monitor to be updated in place, got %v", monitor)
+	}
+}
+
+func TestDeleteStaleLoadBalancerMembers(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	openStackCluster.Spec.APIServerLoadBalancerAdditionalPorts = []int{22}
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	for i, name := range []string{"control-plane-0", "control-plane-1"} {
+		machine := &v1alpha2.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1alpha2.MachineControlPlaneLabelName: "true"},
+		}}
+		openStackMachine := &infrav1.OpenStackMachine{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if err := s.ReconcileLoadBalancerMember("test", machine, openStackMachine, openStackCluster, fmt.Sprintf("10.6.0.%d", 5+i)); err != nil {
+			t.Fatalf("failed to reconcile load balancer member: %v", err)
+		}
+	}
+	pool := cloud.Resources("pools")[0]
+	if _, err := pools.CreateMember(s.loadbalancerClient, pool["id"].(string), pools.CreateMemberOpts{Name: "manual", Address: "10.6.0.100", ProtocolPort: 6443}).Extract(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteStaleLoadBalancerMembers("test", openStackCluster, []string{"control-plane-0"}); err != nil {
+		t.Fatalf("failed to delete stale load balancer members: %v", err)
+	}
+	var names []string
+	for _, member := range cloud.Resources("members") {
+		names = append(names, member["name"].(string))
+	}
+	sort.Strings(names)
+	expected := []string{
+		"k8s-clusterapi-cluster-test-kubeapi-22-control-plane-0",
+		"k8s-clusterapi-cluster-test-kubeapi-6443-control-plane-0",
+		"manual",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected members %v, got %v", expected, names)
+	}
+}
+
+func TestExternallyManagedLoadBalancer(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s, openStackCluster := newTestCluster(t, cloud, true)
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer: %v", err)
+	}
+	openStackCluster.Annotations = map[string]string{
+		infrav1.ExternallyManagedAnnotation: openStackCluster.Status.Network.APIServerLoadBalancer.ID,
+	}
+
+	// The listeners and members of an externally managed load balancer aren't changed.
+	openStackCluster.Spec.APIServerLoadBalancer.Monitor = &infrav1.LoadBalancerMonitor{Type: "HTTPS", URLPath: "/readyz"}
+	cloud.ResetRequests()
+	if err := s.ReconcileLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile load balancer again: %v", err)
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		if n := cloud.CountRequests(fake.ServiceLoadBalancer, method, "."); n != 0 {
+			t.Errorf("expected the externally managed load balancer not to be changed, got %d %s requests", n, method)
+		}
+	}
+	machine := &v1alpha2.Machine{ObjectMeta: metav1.ObjectMeta{
+		Name:   "control-plane-0",
+		Labels: map[string]string{v1alpha2.MachineControlPlaneLabelName: "true"},
+	}}
+	openStackMachine := &infrav1.OpenStackMachine{ObjectMeta: metav1.ObjectMeta{Name: "control-plane-0"}}
+	if err := s.ReconcileLoadBalancerMember("test", machine, openStackMachine, openStackCluster, "10.6.0.5"); err != nil {
+		t.Fatalf("failed to reconcile load balancer member: %v", err)
+	}
+	if n := len(cloud.Resources("members")); n != 0 {
+		t.Errorf("expected no members to be added to the externally managed load balancer, got %d", n)
+	}
+
+	if err := s.DeleteLoadBalancer("test", openStackCluster); err != nil {
+		t.Fatalf("failed to delete load balancer: %v", err)
+	}
+	if n := len(cloud.Resources("loadbalancers")); n != 1 {
+		t.Errorf("expected the externally managed load balancer to be kept, got %d load balancers", n)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/gophercloud/utils/openstack/clientconfig"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/networking"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
 
 	"github.com/gophercloud/gophercloud"
 )
@@ -33,6 +35,7 @@ const (
 type Service struct {
 	loadbalancerClient *gophercloud.ServiceClient
 	networkingClient   *gophercloud.ServiceClient
+	networkingService  *networking.Service
 }
 
 // NewService returns an instance of the loadbalancer service
@@ -40,26 +43,31 @@ func NewService(client *gophercloud.ProviderClient, clientOpts *clientconfig.Cli
 	var err error
 	var loadbalancerClient *gophercloud.ServiceClient
 	if useOctavia {
-		loadbalancerClient, err = openstack.NewLoadBalancerV2(client, gophercloud.EndpointOpts{
+		loadbalancerClient, err = provider.NewServiceClient(client, "load-balancer", gophercloud.EndpointOpts{
 			Region: clientOpts.RegionName,
-		})
+		}, openstack.NewLoadBalancerV2)
 	} else {
-		loadbalancerClient, err = openstack.NewNetworkV2(client, gophercloud.EndpointOpts{
+		loadbalancerClient, err = provider.NewServiceClient(client, "network", gophercloud.EndpointOpts{
 			Region: clientOpts.RegionName,
-		})
+		}, openstack.NewNetworkV2)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create loadbalancer service client: %v", err)
 	}
-	networkingClient, err := openstack.NewNetworkV2(client, gophercloud.EndpointOpts{
+	networkingClient, err := provider.NewServiceClient(client, "network", gophercloud.EndpointOpts{
 		Region: clientOpts.RegionName,
-	})
+	}, openstack.NewNetworkV2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create networking service client: %v", err)
 	}
+	networkingService, err := networking.NewService(client, clientOpts)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Service{
 		loadbalancerClient: loadbalancerClient,
 		networkingClient:   networkingClient,
+		networkingService:  networkingService,
 	}, nil
 }
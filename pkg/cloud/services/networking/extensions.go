@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/common/extensions"
+	netext "github.com/gophercloud/gophercloud/openstack/networking/v2/extensions"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+)
+
+// ownershipTag is, together with the cluster name, the tag of the resources created by CAPO.
+const ownershipTag = "cluster-api-provider-openstack"
+
+// Aliases of the Neutron extensions CAPO relies on.
+const (
+	ExtensionAllowedAddressPairs = "allowed-address-pairs"
+	ExtensionDNSIntegration      = "dns-integration"
+	ExtensionLBaaSV2             = "lbaasv2"
+	ExtensionPortBinding         = "binding"
+	ExtensionPortSecurity        = "port-security"
+	ExtensionQoS                 = "qos"
+	ExtensionRouter              = "router"
+	ExtensionSecurityGroup       = "security-group"
+	ExtensionStandardAttrTag     = "standard-attr-tag"
+	ExtensionTrunk               = "trunk"
+)
+
+// ExtensionCacheTTL is how long the extensions of a Neutron endpoint are cached.
+const ExtensionCacheTTL = 10 * time.Minute
+
+// now returns the current time, it's replaced in tests to expire the cache.
+var now = time.Now
+
+type extensionCacheEntry struct {
+	aliases map[string]bool
+	expires time.Time
+}
+
+// extensionCache caches the extensions of every Neutron endpoint we talked to,
+// so they are shared by the networking, compute and loadbalancer services.
+var extensionCache = struct {
+	sync.Mutex
+	entries map[string]extensionCacheEntry
+}{entries: map[string]extensionCacheEntry{}}
+
+// HasExtension returns whether the Neutron endpoint of the given client supports the extension.
+func HasExtension(client *gophercloud.ServiceClient, alias string) (bool, error) {
+	aliases, err := getExtensions(client)
+	if err != nil {
+		return false, err
+	}
+	return aliases[alias], nil
+}
+
+// RequireExtension returns an error if the Neutron endpoint of the given client doesn't
+// support the extension. The feature is used to explain what needs the extension.
+func RequireExtension(client *gophercloud.ServiceClient, alias string, feature string) error {
+	ok, err := HasExtension(client, alias)
+	if err != nil {
+		return fmt.Errorf("failed to verify whether the Neutron extension %q needed for %s is available: %v", alias, feature, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s requires the Neutron extension %q, which is not available on %s", feature, alias, client.Endpoint)
+	}
+	return nil
+}
+
+// ReplaceAllAttributesTags replaces the tags of the given Neutron resource,
+// failing early if the cloud doesn't support tagging.
+func ReplaceAllAttributesTags(client *gophercloud.ServiceClient, resourceType string, resourceID string, tags []string) error {
+	if err := RequireExtension(client, ExtensionStandardAttrTag, "tagging "+resourceType); err != nil {
+		return err
+	}
+	_, err := attributestags.ReplaceAll(client, resourceType, resourceID, attributestags.ReplaceAllOpts{
+		Tags: tags,
+	}).Extract()
+	return err
+}
+
+// EnsureAttributesTags adds the tags the given Neutron resource doesn't have yet one by one, so
+// resources which are already tagged, e.g. after the cluster was moved to another management
+// cluster, aren't changed and tags added by users are kept.
+func EnsureAttributesTags(client *gophercloud.ServiceClient, resourceType string, resourceID string, observed, tags []string) error {
+	if hasAllTags(observed, tags) {
+		return nil
+	}
+	if err := RequireExtension(client, ExtensionStandardAttrTag, "tagging "+resourceType); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if hasTag(observed, tag) {
+			continue
+		}
+		if err := attributestags.Add(client, resourceType, resourceID, tag).ExtractErr(); err != nil {
+			return fmt.Errorf("failed to add tag %s to %s %s: %v", tag, resourceType, resourceID, err)
+		}
+	}
+	return nil
+}
+
+// OwnershipTags returns the tags of the resources CAPO creates for the cluster.
+func OwnershipTags(clusterName string) []string {
+	return []string{ownershipTag, clusterName}
+}
+
+// HasOwnershipTags returns whether a resource is tagged as owned by the cluster. Resources which
+// are recorded in the status of a cluster are only adopted by ID if they are owned by it, so IDs
+// from another cloud or a stale status don't hijack resources of other clusters.
+func HasOwnershipTags(tags []string, clusterName string) bool {
+	return hasAllTags(tags, OwnershipTags(clusterName))
+}
+
+func hasAllTags(observed, tags []string) bool {
+	for _, tag := range tags {
+		if !hasTag(observed, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwned returns whether the resource recorded in the status of the cluster may be adopted by
+// ID, because it is owned by the cluster or externally managed.
+func isOwned(openStackCluster *infrav1.OpenStackCluster, clusterName, id string, tags []string) bool {
+	return HasOwnershipTags(tags, clusterName) || infrav1.IsExternallyManaged(openStackCluster, id)
+}
+
+// IsNotFound returns whether an OpenStack request failed because the resource doesn't exist.
+func IsNotFound(err error) bool {
+	_, ok := err.(gophercloud.ErrDefault404)
+	return ok
+}
+
+func getExtensions(client *gophercloud.ServiceClient) (map[string]bool, error) {
+	extensionCache.Lock()
+	entry, ok := extensionCache.entries[client.Endpoint]
+	extensionCache.Unlock()
+	if ok && now().Before(entry.expires) {
+		return entry.aliases, nil
+	}
+
+	// The lock isn't held while listing the extensions, so a slow endpoint doesn't block
+	// the other clouds. Concurrent discoveries of the same endpoint store the same aliases.
+	allPages, err := netext.List(client).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	allExts, err := extensions.ExtractExtensions(allPages)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]bool, len(allExts))
+	for _, ext := range allExts {
+		aliases[ext.Alias] = true
+	}
+	klog.V(4).Infof("Discovered %d Neutron extensions on %s", len(aliases), client.Endpoint)
+	extensionCache.Lock()
+	extensionCache.entries[client.Endpoint] = extensionCacheEntry{
+		aliases: aliases,
+		expires: now().Add(ExtensionCacheTTL),
+	}
+	extensionCache.Unlock()
+	return aliases, nil
+}
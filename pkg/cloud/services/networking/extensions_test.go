@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestRequireExtension(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	cloud.SetExtensions(ExtensionRouter, ExtensionTrunk)
+	s := newTestService(t, cloud)
+
+	tests := []struct {
+		alias   string
+		has     bool
+		wantErr bool
+	}{
+		{alias: ExtensionRouter, has: true},
+		{alias: ExtensionTrunk, has: true},
+		{alias: ExtensionQoS, has: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		has, err := HasExtension(s.client, tt.alias)
+		if err != nil || has != tt.has {
+			t.Errorf("HasExtension(%q): expected %t, got %t: %v", tt.alias, tt.has, has, err)
+		}
+		if err := RequireExtension(s.client, tt.alias, "the test"); (err != nil) != tt.wantErr {
+			t.Errorf("RequireExtension(%q): expected error %t, got %v", tt.alias, tt.wantErr, err)
+		}
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodGet, "^extensions$"); n != 1 {
+		t.Errorf("expected the extensions to be listed once, got %d requests", n)
+	}
+}
+
+func TestExtensionCacheExpiry(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	cloud.SetExtensions(ExtensionRouter)
+	s := newTestService(t, cloud)
+
+	start := time.Now()
+	defer func() { now = time.Now }()
+	tests := []struct {
+		elapsed time.Duration
+		has     bool
+	}{
+		{elapsed: 0, has: false},
+		{elapsed: ExtensionCacheTTL - time.Second, has: false},
+		{elapsed: ExtensionCacheTTL + time.Second, has: true},
+	}
+	for i, tt := range tests {
+		now = func() time.Time { return start.Add(tt.elapsed) }
+		has, err := HasExtension(s.client, ExtensionTrunk)
+		if err != nil || has != tt.has {
+			t.Errorf("after %s: expected the trunk extension %t, got %t: %v", tt.elapsed, tt.has, has, err)
+		}
+		// The trunk extension is enabled after the extensions were cached.
+		if i == 0 {
+			cloud.SetExtensions(ExtensionRouter, ExtensionTrunk)
+		}
+	}
+}
+
+func TestGetExtensionsFailure(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s := newTestService(t, cloud)
+
+	cloud.InjectFault(fake.Fault{Service: fake.ServiceNetwork, Path: "^extensions$", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if err := RequireExtension(s.client, ExtensionRouter, "the test"); err == nil {
+		t.Errorf("expected an error if the extensions can't be listed")
+	}
+	// Failures aren't cached.
+	if err := RequireExtension(s.client, ExtensionRouter, "the test"); err != nil {
+		t.Errorf("expected the extensions to be listed again, got %v", err)
+	}
+}
+
+func TestEnsureAttributesTags(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s := newTestService(t, cloud)
+	networkID := cloud.AddNetwork("cluster", false)
+
+	tests := []struct {
+		name     string
+		observed []string
+		tags     []string
+		expected []string
+		puts     int
+	}{
+		{
+			name:     "missing tags",
+			tags:     OwnershipTags("test"),
+			expected: OwnershipTags("test"),
+			puts:     2,
+		},
+		{
+			name:     "all tags",
+			observed: OwnershipTags("test"),
+			tags:     OwnershipTags("test"),
+			expected: OwnershipTags("test"),
+		},
+		{
+			name:     "tags of users are kept",
+			observed: OwnershipTags("test"),
+			tags:     []string{"user", ownershipTag},
+			expected: []string{ownershipTag, "test", "user"},
+			puts:     1,
+		},
+	}
+	for _, tt := range tests {
+		cloud.ResetRequests()
+		if err := EnsureAttributesTags(s.client, "networks", networkID, tt.observed, tt.tags); err != nil {
+			t.Errorf("%s: failed to ensure tags: %v", tt.name, err)
+			continue
+		}
+		if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPut, "^networks/.*/tags/"); n != tt.puts {
+			t.Errorf("%s: expected %d tags to be added, got %d requests", tt.name, tt.puts, n)
+		}
+		if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPut, "^networks/.*/tags$"); n != 0 {
+			t.Errorf("%s: expected the tags not to be replaced, got %d requests", tt.name, n)
+		}
+		if tags := fmt.Sprint(cloud.Resources("networks")[0]["tags"]); tags != fmt.Sprint(tt.expected) {
+			t.Errorf("%s: expected tags %v, got %s", tt.name, tt.expected, tags)
+		}
+	}
+}
+
+func TestHasOwnershipTags(t *testing.T) {
+	tests := []struct {
+		tags     []string
+		expected bool
+	}{
+		{tags: nil},
+		{tags: []string{ownershipTag}},
+		{tags: []string{"test"}},
+		{tags: []string{ownershipTag, "other"}},
+		{tags: []string{ownershipTag, "test"}, expected: true},
+		{tags: []string{"user", "test", ownershipTag}, expected: true},
+	}
+	for _, tt := range tests {
+		if owned := HasOwnershipTags(tt.tags, "test"); owned != tt.expected {
+			t.Errorf("HasOwnershipTags(%v): expected %t, got %t", tt.tags, tt.expected, owned)
+		}
+	}
+}
@@ -5,30 +5,80 @@ import (
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
 	"k8s.io/klog"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 )
 
-func (s *Service) GetOrCreateFloatingIP(openStackCluster *infrav1.OpenStackCluster, ip string) error {
-	fp, err := checkIfFloatingIPExists(s.client, ip)
+// floatingIPDescriptionPrefix is followed by the owner in the description of the floating IPs
+// created by CAPO. Only these floating IPs are released when their owner is deleted.
+const floatingIPDescriptionPrefix = "Created by cluster-api-provider-openstack for "
+
+// OwnerDescription returns the description of the resources CAPO creates for the owner, which
+// identifies the owner of resources that can't be tagged.
+func OwnerDescription(owner string) string {
+	return floatingIPDescriptionPrefix + owner
+}
+
+// GetOrCreateFloatingIP returns the floating IP ip, which is created on the external network of
+// the cluster if it doesn't exist. If ip is empty, the floating IP created for the owner before
+// is returned, or any free floating IP of the external network is allocated for the owner.
+func (s *Service) GetOrCreateFloatingIP(openStackCluster *infrav1.OpenStackCluster, ip, owner string) (*floatingips.FloatingIP, error) {
+	description := OwnerDescription(owner)
+	listOpts := floatingips.ListOpts{FloatingIP: ip}
+	if ip == "" {
+		listOpts = floatingips.ListOpts{
+			Description:       description,
+			FloatingNetworkID: openStackCluster.Spec.ExternalNetworkID,
+		}
+	}
+	fp, err := checkIfFloatingIPExists(s.client, listOpts)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if fp != nil {
+		return fp, nil
 	}
-	if fp == nil {
+
+	if ip == "" {
+		klog.Infof("Allocating floating ip for %s", owner)
+	} else {
 		klog.Infof("Creating floating ip %s", ip)
-		fpCreateOpts := &floatingips.CreateOpts{
-			FloatingIP:        ip,
-			FloatingNetworkID: openStackCluster.Spec.ExternalNetworkID,
-		}
-		fp, err = floatingips.Create(s.client, fpCreateOpts).Extract()
-		if err != nil {
-			return fmt.Errorf("error allocating floating IP: %s", err)
-		}
+	}
+	fpCreateOpts := &floatingips.CreateOpts{
+		FloatingIP:        ip,
+		FloatingNetworkID: openStackCluster.Spec.ExternalNetworkID,
+		Description:       description,
+	}
+	fp, err = floatingips.Create(s.client, fpCreateOpts).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("error allocating floating IP: %s", err)
+	}
+	return fp, nil
+}
+
+// DeleteFloatingIP releases the floating IP ip if it was created for the owner by
+// GetOrCreateFloatingIP. Floating IPs which already existed are kept.
+func (s *Service) DeleteFloatingIP(ip, owner string) error {
+	if ip == "" {
+		return nil
+	}
+	fp, err := checkIfFloatingIPExists(s.client, floatingips.ListOpts{FloatingIP: ip})
+	if err != nil {
+		return err
+	}
+	if fp == nil || fp.Description != OwnerDescription(owner) {
+		klog.V(4).Infof("Skipped releasing floating ip %s that wasn't created for %s", ip, owner)
+		return nil
+	}
+
+	klog.Infof("Releasing floating ip %s", ip)
+	if err := floatingips.Delete(s.client, fp.ID).ExtractErr(); err != nil {
+		return fmt.Errorf("error releasing floating IP %s: %v", ip, err)
 	}
 	return nil
 }
 
-func checkIfFloatingIPExists(client *gophercloud.ServiceClient, ip string) (*floatingips.FloatingIP, error) {
-	allPages, err := floatingips.List(client, floatingips.ListOpts{FloatingIP: ip}).AllPages()
+func checkIfFloatingIPExists(client *gophercloud.ServiceClient, listOpts floatingips.ListOpts) (*floatingips.FloatingIP, error) {
+	allPages, err := floatingips.List(client, listOpts).AllPages()
 	if err != nil {
 		return nil, err
 	}
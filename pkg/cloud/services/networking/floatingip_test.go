@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestGetOrCreateFloatingIP(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{ExternalNetworkID: externalNetworkID},
+	}
+
+	// A floating IP is allocated for the owner and found again by its description.
+	allocated, err := s.GetOrCreateFloatingIP(openStackCluster, "", "test-machine")
+	if err != nil {
+		t.Fatalf("failed to allocate floating IP: %v", err)
+	}
+	if allocated.FloatingIP == "" || allocated.Description != floatingIPDescriptionPrefix+"test-machine" {
+		t.Errorf("expected an allocated floating IP with the owner in its description, got %+v", allocated)
+	}
+	cloud.ResetRequests()
+	fp, err := s.GetOrCreateFloatingIP(openStackCluster, "", "test-machine")
+	if err != nil {
+		t.Fatalf("failed to get floating IP: %v", err)
+	}
+	if fp.ID != allocated.ID {
+		t.Errorf("expected the floating IP of the owner to be reused, got %s instead of %s", fp.FloatingIP, allocated.FloatingIP)
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPost, "^floatingips$"); n != 0 {
+		t.Errorf("expected no floating IP to be created again, got %d", n)
+	}
+
+	// A requested floating IP is created with the owner in its description.
+	requested, err := s.GetOrCreateFloatingIP(openStackCluster, "172.24.4.100", "test-lb")
+	if err != nil {
+		t.Fatalf("failed to create floating IP: %v", err)
+	}
+	if requested.FloatingIP != "172.24.4.100" {
+		t.Errorf("expected floating IP 172.24.4.100, got %s", requested.FloatingIP)
+	}
+
+	// Floating IPs are only released by their owner.
+	if err := s.DeleteFloatingIP(allocated.FloatingIP, "other-machine"); err != nil {
+		t.Fatalf("failed to delete floating IP: %v", err)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 2 {
+		t.Fatalf("expected the floating IP of another owner to be kept, got %d floating IPs", n)
+	}
+	if err := s.DeleteFloatingIP(allocated.FloatingIP, "test-machine"); err != nil {
+		t.Fatalf("failed to delete floating IP: %v", err)
+	}
+	if err := s.DeleteFloatingIP(requested.FloatingIP, "test-lb"); err != nil {
+		t.Fatalf("failed to delete floating IP: %v", err)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 0 {
+		t.Errorf("expected the floating IPs to be released, got %d floating IPs", n)
+	}
+}
+
+func TestDeleteFloatingIPKeepsExisting(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{ExternalNetworkID: externalNetworkID},
+	}
+	existing, err := floatingips.Create(s.client, floatingips.CreateOpts{
+		FloatingIP:        "172.24.4.100",
+		FloatingNetworkID: externalNetworkID,
+	}).Extract()
+	if err != nil {
+		t.Fatalf("failed to create floating IP: %v", err)
+	}
+
+	// The existing floating IP is used, but not released.
+	fp, err := s.GetOrCreateFloatingIP(openStackCluster, "172.24.4.100", "test-lb")
+	if err != nil {
+		t.Fatalf("failed to get floating IP: %v", err)
+	}
+	if fp.ID != existing.ID {
+		t.Errorf("expected the existing floating IP to be used, got %+v", fp)
+	}
+	if err := s.DeleteFloatingIP(fp.FloatingIP, "test-lb"); err != nil {
+		t.Fatalf("failed to delete floating IP: %v", err)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 1 {
+		t.Errorf("expected the existing floating IP to be kept, got %d floating IPs", n)
+	}
+}
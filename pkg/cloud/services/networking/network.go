@@ -19,12 +19,11 @@ package networking
 import (
 	"fmt"
 	"github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/pkg/errors"
 	"k8s.io/klog"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 )
 
 type createOpts struct {
@@ -42,41 +41,38 @@ func (s *Service) ReconcileNetwork(clusterName string, openStackCluster *infrav1
 	networkName := fmt.Sprintf("%s-cluster-%s", networkPrefix, clusterName)
 	klog.Infof("Reconciling network %s", networkName)
 
-	res, err := s.getNetworkByName(networkName)
+	res, err := s.getNetwork(networkName, clusterName, openStackCluster)
 	if err != nil {
 		return err
 	}
 
 	if res.ID != "" {
-		// Network exists
-		openStackCluster.Status.Network = &infrav1.Network{
-			ID:   res.ID,
-			Name: res.Name,
+		// Network exists, the IDs of the other resources of the network in the status are kept
+		// to adopt them.
+		if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.ID != res.ID {
+			openStackCluster.Status.Network = &infrav1.Network{}
 		}
+		openStackCluster.Status.Network.ID = res.ID
+		openStackCluster.Status.Network.Name = res.Name
 		return nil
 	}
 
-	var portSecurityEnabled gophercloud.EnabledState
-	if openStackCluster.Spec.DisablePortSecurity {
-		portSecurityEnabled = gophercloud.Disabled
-	} else {
-		portSecurityEnabled = gophercloud.Enabled
-	}
 	opts := createOpts{
-		AdminStateUp:        gophercloud.Enabled,
-		Name:                networkName,
-		PortSecurityEnabled: portSecurityEnabled,
+		AdminStateUp: gophercloud.Enabled,
+		Name:         networkName,
+	}
+	if openStackCluster.Spec.DisablePortSecurity {
+		if err := RequireExtension(s.client, ExtensionPortSecurity, "disabling port security"); err != nil {
+			return err
+		}
+		opts.PortSecurityEnabled = gophercloud.Disabled
 	}
 	network, err := networks.Create(s.client, opts).Extract()
 	if err != nil {
 		return err
 	}
 
-	_, err = attributestags.ReplaceAll(s.client, "networks", network.ID, attributestags.ReplaceAllOpts{
-		Tags: []string{
-			"cluster-api-provider-openstack",
-			clusterName,
-		}}).Extract()
+	err = ReplaceAllAttributesTags(s.client, "networks", network.ID, OwnershipTags(clusterName))
 	if err != nil {
 		return err
 	}
@@ -112,6 +108,7 @@ func (s *Service) ReconcileSubnet(clusterName string, openStackCluster *infrav1.
 	}
 
 	var observedSubnet infrav1.Subnet
+	var observedTags []string
 	if len(subnetList) > 1 {
 		// Not panicing here, because every other cluster might work.
 		return fmt.Errorf("found more than 1 network with the expected name (%d) and CIDR (%s), which should not be able to exist in OpenStack", len(subnetList), openStackCluster.Spec.NodeCIDR)
@@ -135,6 +132,7 @@ func (s *Service) ReconcileSubnet(clusterName string, openStackCluster *infrav1.
 
 			CIDR: newSubnet.CIDR,
 		}
+		observedTags = newSubnet.Tags
 	} else if len(subnetList) == 1 {
 		observedSubnet = infrav1.Subnet{
 			ID:   subnetList[0].ID,
@@ -142,21 +140,39 @@ func (s *Service) ReconcileSubnet(clusterName string, openStackCluster *infrav1.
 
 			CIDR: subnetList[0].CIDR,
 		}
+		observedTags = subnetList[0].Tags
 	}
 
-	_, err = attributestags.ReplaceAll(s.client, "subnets", observedSubnet.ID, attributestags.ReplaceAllOpts{
-		Tags: []string{
-			"cluster-api-provider-openstack",
-			clusterName,
-		}}).Extract()
-	if err != nil {
-		return err
+	if !infrav1.IsExternallyManaged(openStackCluster, observedSubnet.ID) {
+		err = EnsureAttributesTags(s.client, "subnets", observedSubnet.ID, observedTags, OwnershipTags(clusterName))
+		if err != nil {
+			return err
+		}
 	}
 
 	openStackCluster.Status.Network.Subnet = &observedSubnet
 	return nil
 }
 
+// getNetwork returns the network of the cluster recorded in the status if it is owned by the
+// cluster, or the network with the name otherwise, e.g. because the status was lost when the
+// cluster was moved.
+func (s *Service) getNetwork(networkName, clusterName string, openStackCluster *infrav1.OpenStackCluster) (networks.Network, error) {
+	if openStackCluster.Status.Network != nil && openStackCluster.Status.Network.ID != "" {
+		network, err := networks.Get(s.client, openStackCluster.Status.Network.ID).Extract()
+		if err != nil && !IsNotFound(err) {
+			return networks.Network{}, err
+		}
+		if err == nil {
+			if isOwned(openStackCluster, clusterName, network.ID, network.Tags) {
+				return *network, nil
+			}
+			klog.V(3).Infof("Network %s of the status isn't owned by cluster %s, looking it up by name", network.ID, clusterName)
+		}
+	}
+	return s.getNetworkByName(networkName)
+}
+
 func (s *Service) getNetworkByName(networkName string) (networks.Network, error) {
 	opts := networks.ListOpts{
 		Name: networkName,
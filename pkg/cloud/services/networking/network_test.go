@@ -0,0 +1,253 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func newTestService(t *testing.T, cloud *fake.Cloud) *Service {
+	client, clientOpts, err := cloud.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewService(client, clientOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func reconcileNetworking(s *Service, clusterName string, openStackCluster *infrav1.OpenStackCluster) error {
+	if err := s.ReconcileNetwork(clusterName, openStackCluster); err != nil {
+		return err
+	}
+	if err := s.ReconcileSubnet(clusterName, openStackCluster); err != nil {
+		return err
+	}
+	if err := s.ReconcileRouter(clusterName, openStackCluster); err != nil {
+		return err
+	}
+	return s.ReconcileSecurityGroups(clusterName, openStackCluster)
+}
+
+func TestReconcileNetworking(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{
+			NodeCIDR:              "10.6.0.0/24",
+			ExternalNetworkID:     externalNetworkID,
+			ManagedSecurityGroups: true,
+		},
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking: %v", err)
+	}
+
+	status := openStackCluster.Status
+	if status.Network == nil || status.Network.Subnet == nil || status.Network.Router == nil {
+		t.Fatalf("expected network, subnet and router in the status, got %+v", status.Network)
+	}
+	if status.Network.Subnet.CIDR != "10.6.0.0/24" {
+		t.Errorf("expected subnet CIDR 10.6.0.0/24, got %s", status.Network.Subnet.CIDR)
+	}
+	for _, group := range []*infrav1.SecurityGroup{status.ControlPlaneSecurityGroup, status.GlobalSecurityGroup} {
+		if group == nil || group.ID == "" {
+			t.Fatalf("expected security groups in the status, got %+v", group)
+		}
+	}
+	if n := len(status.GlobalSecurityGroup.Rules); n != 5 {
+		t.Errorf("expected 5 rules in the global security group, got %d", n)
+	}
+	for _, network := range cloud.Resources("networks") {
+		if network["id"] == status.Network.ID && len(network["tags"].([]interface{})) != 2 {
+			t.Errorf("expected the network to be tagged, got %v", network["tags"])
+		}
+	}
+
+	// A second reconciliation finds the existing resources.
+	cloud.ResetRequests()
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking again: %v", err)
+	}
+	for _, collection := range []string{"networks", "subnets", "routers", "security-groups", "security-group-rules"} {
+		if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPost, "^"+collection+"$"); n != 0 {
+			t.Errorf("expected no %s to be created on the second reconciliation, got %d", collection, n)
+		}
+	}
+	if n := len(cloud.Resources("routers")); n != 1 {
+		t.Errorf("expected 1 router, got %d", n)
+	}
+}
+
+func TestReconcileNetworkingExternallyManaged(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{
+			NodeCIDR:              "10.6.0.0/24",
+			ExternalNetworkID:     externalNetworkID,
+			ManagedSecurityGroups: true,
+		},
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking: %v", err)
+	}
+	group := openStackCluster.Status.ControlPlaneSecurityGroup
+	_, err := rules.Create(s.client, rules.CreateOpts{
+		Direction:    rules.DirIngress,
+		EtherType:    rules.EtherType4,
+		SecGroupID:   group.ID,
+		Protocol:     rules.ProtocolTCP,
+		PortRangeMin: 80,
+		PortRangeMax: 80,
+	}).Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The rules, tags and interfaces of externally managed resources aren't changed.
+	openStackCluster.Annotations = map[string]string{
+		infrav1.ExternallyManagedAnnotation: group.ID + ", " + openStackCluster.Status.Network.Router.ID,
+	}
+	cloud.ResetRequests()
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking again: %v", err)
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodDelete, "^security-group-rules/"); n != 0 {
+		t.Errorf("expected the rules of the externally managed security group to be kept, got %d deleted rules", n)
+	}
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		if n := cloud.CountRequests(fake.ServiceNetwork, method, "^routers/"); n != 0 {
+			t.Errorf("expected the externally managed router not to be changed, got %d %s requests", n, method)
+		}
+	}
+	if openStackCluster.Status.Network.Router == nil {
+		t.Errorf("expected the externally managed router in the status")
+	}
+
+	if err := s.DeleteSecurityGroups(openStackCluster, group); err != nil {
+		t.Fatalf("failed to delete security group: %v", err)
+	}
+	if exists, err := s.exists(group.ID); err != nil || !exists {
+		t.Errorf("expected the externally managed security group to be kept, got %v", err)
+	}
+}
+
+func TestReconcileNetworkingAfterMove(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{
+			NodeCIDR:              "10.6.0.0/24",
+			ExternalNetworkID:     externalNetworkID,
+			ManagedSecurityGroups: true,
+			APIServerVirtualIP:    &infrav1.APIServerVirtualIP{},
+		},
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking: %v", err)
+	}
+	if err := s.ReconcileVirtualIP("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile virtual IP: %v", err)
+	}
+
+	otherCluster := openStackCluster.DeepCopy()
+	otherCluster.Status = infrav1.OpenStackClusterStatus{}
+	if err := reconcileNetworking(s, "other", otherCluster); err != nil {
+		t.Fatalf("failed to reconcile networking of the other cluster: %v", err)
+	}
+	if err := s.ReconcileVirtualIP("other", otherCluster); err != nil {
+		t.Fatalf("failed to reconcile virtual IP of the other cluster: %v", err)
+	}
+
+	// The manager of the new management cluster adopts the resources by the IDs in the moved
+	// status, or by their names if the status was lost or records resources which aren't owned
+	// by the cluster, without changing them.
+	for name, status := range map[string]infrav1.OpenStackClusterStatus{
+		"moved status":         *openStackCluster.Status.DeepCopy(),
+		"lost status":          {},
+		"other cluster status": *otherCluster.Status.DeepCopy(),
+	} {
+		moved := openStackCluster.DeepCopy()
+		moved.Status = status
+		cloud.ResetRequests()
+		if err := reconcileNetworking(newTestService(t, cloud), "test", moved); err != nil {
+			t.Fatalf("%s: failed to reconcile networking: %v", name, err)
+		}
+		if err := s.ReconcileVirtualIP("test", moved); err != nil {
+			t.Fatalf("%s: failed to reconcile virtual IP: %v", name, err)
+		}
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+			if n := cloud.CountRequests(fake.ServiceNetwork, method, "."); n != 0 {
+				t.Errorf("%s: expected no resources to be changed, got %d %s requests", name, n, method)
+			}
+		}
+		if moved.Status.Network == nil || moved.Status.Network.ID != openStackCluster.Status.Network.ID ||
+			moved.Status.Network.Router == nil || moved.Status.Network.Router.ID != openStackCluster.Status.Network.Router.ID {
+			t.Errorf("%s: expected the network and router to be adopted, got %+v", name, moved.Status.Network)
+		}
+		if moved.Status.GlobalSecurityGroup == nil || moved.Status.GlobalSecurityGroup.ID != openStackCluster.Status.GlobalSecurityGroup.ID {
+			t.Errorf("%s: expected the security group to be adopted, got %+v", name, moved.Status.GlobalSecurityGroup)
+		}
+		vip := moved.Status.Network.APIServerVirtualIP
+		if vip == nil || vip.PortID != openStackCluster.Status.Network.APIServerVirtualIP.PortID {
+			t.Errorf("%s: expected the virtual IP port to be adopted, got %+v", name, vip)
+		}
+	}
+}
+
+func TestReconcileNetworkingFault(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s := newTestService(t, cloud)
+
+	cloud.InjectFault(fake.Fault{Service: fake.ServiceNetwork, Method: http.MethodPost, Path: "^subnets$", StatusCode: http.StatusInternalServerError, Times: 1})
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{NodeCIDR: "10.6.0.0/24"},
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err == nil {
+		t.Fatalf("expected the subnet creation to fail")
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking after the fault: %v", err)
+	}
+	if n := len(cloud.Resources("networks")); n != 1 {
+		t.Errorf("expected the network to be reused, got %d networks", n)
+	}
+	if openStackCluster.Status.Network.Subnet == nil {
+		t.Errorf("expected a subnet in the status")
+	}
+}
@@ -19,13 +19,12 @@ package networking
 import (
 	"fmt"
 	"github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/routers"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/gophercloud/gophercloud/pagination"
 	"k8s.io/klog"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 )
 
 func (s *Service) ReconcileRouter(clusterName string, openStackCluster *infrav1.OpenStackCluster) error {
@@ -46,14 +45,11 @@ func (s *Service) ReconcileRouter(clusterName string, openStackCluster *infrav1.
 	routerName := fmt.Sprintf("%s-cluster-%s", networkPrefix, clusterName)
 	klog.Infof("Reconciling router %s", routerName)
 
-	allPages, err := routers.List(s.client, routers.ListOpts{
-		Name: routerName,
-	}).AllPages()
-	if err != nil {
+	if err := RequireExtension(s.client, ExtensionRouter, "routers"); err != nil {
 		return err
 	}
 
-	routerList, err := routers.ExtractRouters(allPages)
+	routerList, err := s.getRouters(routerName, clusterName, openStackCluster)
 	if err != nil {
 		return err
 	}
@@ -80,6 +76,16 @@ func (s *Service) ReconcileRouter(clusterName string, openStackCluster *infrav1.
 		router = routerList[0]
 	}
 
+	observedRouter := infrav1.Router{
+		Name: router.Name,
+		ID:   router.ID,
+	}
+	if infrav1.IsExternallyManaged(openStackCluster, router.ID) {
+		klog.V(4).Infof("Router %s is externally managed, not reconciling its gateway and interfaces.", routerName)
+		openStackCluster.Status.Network.Router = &observedRouter
+		return nil
+	}
+
 	if len(openStackCluster.Spec.ExternalRouterIPs) > 0 {
 		var updateOpts routers.UpdateOpts
 		updateOpts.GatewayInfo = &routers.GatewayInfo{
@@ -104,17 +110,14 @@ func (s *Service) ReconcileRouter(clusterName string, openStackCluster *infrav1.
 			})
 		}
 
-		_, err = routers.Update(s.client, router.ID, updateOpts).Extract()
-		if err != nil {
-			return fmt.Errorf("error updating OpenStack Neutron Router: %s", err)
+		if !gatewayMatches(router.GatewayInfo, *updateOpts.GatewayInfo) {
+			_, err = routers.Update(s.client, router.ID, updateOpts).Extract()
+			if err != nil {
+				return fmt.Errorf("error updating OpenStack Neutron Router: %s", err)
+			}
 		}
 	}
 
-	observedRouter := infrav1.Router{
-		Name: router.Name,
-		ID:   router.ID,
-	}
-
 	routerInterfaces, err := s.getRouterInterfaces(router.ID)
 	if err != nil {
 		return err
@@ -144,11 +147,7 @@ INTERFACE_LOOP:
 		klog.V(4).Infof("Created RouterInterface: %v", iface)
 	}
 
-	_, err = attributestags.ReplaceAll(s.client, "routers", observedRouter.ID, attributestags.ReplaceAllOpts{
-		Tags: []string{
-			"cluster-api-provider-openstack",
-			clusterName,
-		}}).Extract()
+	err = EnsureAttributesTags(s.client, "routers", observedRouter.ID, router.Tags, OwnershipTags(clusterName))
 	if err != nil {
 		return err
 	}
@@ -159,6 +158,53 @@ INTERFACE_LOOP:
 	return nil
 }
 
+// getRouters returns the router of the cluster recorded in the status if it is owned by the
+// cluster, or the routers with the name otherwise, e.g. because the status was lost when the
+// cluster was moved.
+func (s *Service) getRouters(routerName, clusterName string, openStackCluster *infrav1.OpenStackCluster) ([]routers.Router, error) {
+	if openStackCluster.Status.Network.Router != nil && openStackCluster.Status.Network.Router.ID != "" {
+		router, err := routers.Get(s.client, openStackCluster.Status.Network.Router.ID).Extract()
+		if err != nil && !IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if isOwned(openStackCluster, clusterName, router.ID, router.Tags) {
+				return []routers.Router{*router}, nil
+			}
+			klog.V(3).Infof("Router %s of the status isn't owned by cluster %s, looking it up by name", router.ID, clusterName)
+		}
+	}
+
+	allPages, err := routers.List(s.client, routers.ListOpts{
+		Name: routerName,
+	}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	return routers.ExtractRouters(allPages)
+}
+
+// gatewayMatches returns whether the observed gateway of a router is on the external network of the
+// desired gateway and has all of its external fixed IPs. Fixed IPs without address match any address.
+func gatewayMatches(observed, desired routers.GatewayInfo) bool {
+	if observed.NetworkID != desired.NetworkID {
+		return false
+	}
+	for _, d := range desired.ExternalFixedIPs {
+		found := false
+		for _, o := range observed.ExternalFixedIPs {
+			if o.SubnetID == d.SubnetID && (d.IPAddress == "" || o.IPAddress == d.IPAddress) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Service) getRouterInterfaces(routerID string) ([]ports.Port, error) {
 	allPages, err := ports.List(s.client, ports.ListOpts{
 		DeviceID: routerID,
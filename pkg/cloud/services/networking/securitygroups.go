@@ -22,7 +22,7 @@ import (
 
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 )
 
 const (
@@ -57,23 +57,33 @@ func (s *Service) ReconcileSecurityGroups(clusterName string, openStackCluster *
 		klog.V(4).Infof("No need to reconcile security groups for cluster %s", clusterName)
 		return nil
 	}
+	if err := RequireExtension(s.client, ExtensionSecurityGroup, "managed security groups"); err != nil {
+		return err
+	}
 	desiredSecGroups := map[string]infrav1.SecurityGroup{
 		"controlplane": generateControlPlaneGroup(clusterName),
 		"global":       generateGlobalGroup(clusterName),
 	}
-	observedSecGroups := make(map[string]*infrav1.SecurityGroup)
+	observedSecGroups := map[string]*infrav1.SecurityGroup{
+		"controlplane": openStackCluster.Status.ControlPlaneSecurityGroup,
+		"global":       openStackCluster.Status.GlobalSecurityGroup,
+	}
 
 	for k, desiredSecGroup := range desiredSecGroups {
 		klog.Infof("Reconciling security group %s", desiredSecGroup.Name)
 
 		var err error
-		observedSecGroups[k], err = s.getSecurityGroupByName(desiredSecGroup.Name)
+		observedSecGroups[k], err = s.getSecurityGroup(desiredSecGroup.Name, clusterName, openStackCluster, observedSecGroups[k])
 
 		if err != nil {
 			return err
 		}
 
 		if observedSecGroups[k].ID != "" {
+			if infrav1.IsExternallyManaged(openStackCluster, observedSecGroups[k].ID) {
+				klog.V(4).Infof("Group %s is externally managed, not reconciling its rules.", desiredSecGroup.Name)
+				continue
+			}
 			if matchGroups(&desiredSecGroup, observedSecGroups[k]) {
 				klog.V(6).Infof("Group %s matched, have nothing to do.", desiredSecGroup.Name)
 				continue
@@ -88,7 +98,10 @@ func (s *Service) ReconcileSecurityGroups(clusterName string, openStackCluster *
 		}
 
 		klog.V(6).Infof("Group %s doesn't exist, creating it.", desiredSecGroup.Name)
-		observedSecGroups[k], err = s.createSecGroup(desiredSecGroup)
+		observedSecGroups[k], err = s.createSecGroup(clusterName, desiredSecGroup)
+		if err != nil {
+			return err
+		}
 	}
 
 	openStackCluster.Status.ControlPlaneSecurityGroup = observedSecGroups["controlplane"]
@@ -97,7 +110,11 @@ func (s *Service) ReconcileSecurityGroups(clusterName string, openStackCluster *
 	return nil
 }
 
-func (s *Service) DeleteSecurityGroups(group *infrav1.SecurityGroup) error {
+func (s *Service) DeleteSecurityGroups(openStackCluster *infrav1.OpenStackCluster, group *infrav1.SecurityGroup) error {
+	if infrav1.IsExternallyManaged(openStackCluster, group.ID) {
+		klog.V(4).Infof("Skipped deleting security group %s that is externally managed", group.Name)
+		return nil
+	}
 	exists, err := s.exists(group.ID)
 	if err != nil {
 		return err
@@ -252,7 +269,9 @@ func (s *Service) reconcileGroup(desired, observed *infrav1.SecurityGroup) (*inf
 	return observed, nil
 }
 
-func (s *Service) createSecGroup(group infrav1.SecurityGroup) (*infrav1.SecurityGroup, error) {
+// createSecGroup creates the group with its rules. The rules Neutron creates with every group, i.e. the
+// default egress rules, are reused instead of being created again.
+func (s *Service) createSecGroup(clusterName string, group infrav1.SecurityGroup) (*infrav1.SecurityGroup, error) {
 	createOpts := groups.CreateOpts{
 		Name:        group.Name,
 		Description: "Cluster API managed group",
@@ -262,6 +281,9 @@ func (s *Service) createSecGroup(group infrav1.SecurityGroup) (*infrav1.Security
 	if err != nil {
 		return &infrav1.SecurityGroup{}, err
 	}
+	if err := ReplaceAllAttributesTags(s.client, "security-groups", g.ID, OwnershipTags(clusterName)); err != nil {
+		return &infrav1.SecurityGroup{}, err
+	}
 
 	newGroup := convertOSSecGroupToConfigSecGroup(*g)
 	securityGroupRules := make([]infrav1.SecurityGroupRule, 0, len(group.Rules))
@@ -272,6 +294,11 @@ func (s *Service) createSecGroup(group infrav1.SecurityGroup) (*infrav1.Security
 		if r.RemoteGroupID == "self" {
 			r.RemoteGroupID = newGroup.ID
 		}
+		// Neutron creates the default egress rules with the group, creating them again would conflict.
+		if existing, ok := findRule(newGroup.Rules, r); ok {
+			securityGroupRules = append(securityGroupRules, existing)
+			continue
+		}
 		newRule, err := s.createRule(r)
 		if err != nil {
 			return &infrav1.SecurityGroup{}, err
@@ -283,6 +310,35 @@ func (s *Service) createSecGroup(group infrav1.SecurityGroup) (*infrav1.Security
 	return newGroup, nil
 }
 
+// findRule returns the rule of the list equal to the given rule.
+func findRule(list []infrav1.SecurityGroupRule, rule infrav1.SecurityGroupRule) (infrav1.SecurityGroupRule, bool) {
+	for _, r := range list {
+		if r.Equal(rule) {
+			return r, true
+		}
+	}
+	return infrav1.SecurityGroupRule{}, false
+}
+
+// getSecurityGroup returns the security group recorded in the status if it is owned by the
+// cluster, or the security group with the name otherwise, e.g. because the status was lost when
+// the cluster was moved.
+func (s *Service) getSecurityGroup(name, clusterName string, openStackCluster *infrav1.OpenStackCluster, recorded *infrav1.SecurityGroup) (*infrav1.SecurityGroup, error) {
+	if recorded != nil && recorded.ID != "" {
+		group, err := groups.Get(s.client, recorded.ID).Extract()
+		if err != nil && !IsNotFound(err) {
+			return &infrav1.SecurityGroup{}, err
+		}
+		if err == nil {
+			if isOwned(openStackCluster, clusterName, group.ID, group.Tags) {
+				return convertOSSecGroupToConfigSecGroup(*group), nil
+			}
+			klog.V(3).Infof("Security group %s of the status isn't owned by cluster %s, looking it up by name", group.ID, clusterName)
+		}
+	}
+	return s.getSecurityGroupByName(name)
+}
+
 func (s *Service) getSecurityGroupByName(name string) (*infrav1.SecurityGroup, error) {
 	opts := groups.ListOpts{
 		Name: name,
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"net/http"
+	"testing"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestFindRule(t *testing.T) {
+	egressIPv4 := infrav1.SecurityGroupRule{ID: "egress-ipv4", Direction: "egress", EtherType: "IPv4"}
+	egressIPv6 := infrav1.SecurityGroupRule{ID: "egress-ipv6", Direction: "egress", EtherType: "IPv6"}
+	list := []infrav1.SecurityGroupRule{egressIPv4, egressIPv6}
+
+	tests := []struct {
+		name     string
+		rule     infrav1.SecurityGroupRule
+		expected string
+	}{
+		{name: "default egress rule", rule: infrav1.SecurityGroupRule{Direction: "egress", EtherType: "IPv6", SecurityGroupID: "group"}, expected: "egress-ipv6"},
+		{name: "egress rule with protocol", rule: infrav1.SecurityGroupRule{Direction: "egress", EtherType: "IPv4", Protocol: "tcp"}},
+		{name: "egress rule with remote IP prefix", rule: infrav1.SecurityGroupRule{Direction: "egress", EtherType: "IPv4", RemoteIPPrefix: "10.0.0.0/8"}},
+		{name: "ingress rule", rule: infrav1.SecurityGroupRule{Direction: "ingress", EtherType: "IPv4"}},
+	}
+	for _, tt := range tests {
+		rule, ok := findRule(list, tt.rule)
+		if ok != (tt.expected != "") || rule.ID != tt.expected {
+			t.Errorf("%s: expected rule %q, got %q %t", tt.name, tt.expected, rule.ID, ok)
+		}
+	}
+}
+
+func TestReconcileSecurityGroupsReusesDefaultRules(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{Spec: infrav1.OpenStackClusterSpec{ManagedSecurityGroups: true}}
+	if err := s.ReconcileSecurityGroups("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile security groups: %v", err)
+	}
+
+	// Neutron creates the default egress rules with every group, only the other rules are created.
+	desiredGroups := map[string]infrav1.SecurityGroup{}
+	expectedRules := 0
+	for _, group := range []infrav1.SecurityGroup{generateControlPlaneGroup("test"), generateGlobalGroup("test")} {
+		desiredGroups[group.Name] = group
+		for _, rule := range group.Rules {
+			if rule.Direction != "egress" || rule.Protocol != "" || rule.RemoteIPPrefix != "" || rule.RemoteGroupID != "" {
+				expectedRules++
+			}
+		}
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPost, "^security-group-rules$"); n != expectedRules {
+		t.Errorf("expected %d rules to be created, got %d requests", expectedRules, n)
+	}
+	for _, group := range []*infrav1.SecurityGroup{openStackCluster.Status.ControlPlaneSecurityGroup, openStackCluster.Status.GlobalSecurityGroup} {
+		desired := desiredGroups[group.Name]
+		if len(group.Rules) != len(desired.Rules) {
+			t.Errorf("expected the %d rules of group %s in the status, got %+v", len(desired.Rules), group.Name, group.Rules)
+		}
+		for _, rule := range group.Rules {
+			if rule.ID == "" {
+				t.Errorf("expected the rules of group %s to have IDs, got %+v", group.Name, rule)
+			}
+		}
+	}
+}
+
+func TestReconcileSecurityGroupsCreateFailure(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s := newTestService(t, cloud)
+
+	cloud.InjectFault(fake.Fault{Service: fake.ServiceNetwork, Method: http.MethodPost, Path: "^security-groups$", StatusCode: http.StatusInternalServerError, Times: 1})
+	openStackCluster := &infrav1.OpenStackCluster{Spec: infrav1.OpenStackClusterSpec{ManagedSecurityGroups: true}}
+	if err := s.ReconcileSecurityGroups("test", openStackCluster); err == nil {
+		t.Fatalf("expected an error if a security group can't be created")
+	}
+	if openStackCluster.Status.ControlPlaneSecurityGroup != nil || openStackCluster.Status.GlobalSecurityGroup != nil {
+		t.Errorf("expected no security groups in the status after the failure, got %+v and %+v",
+			openStackCluster.Status.ControlPlaneSecurityGroup, openStackCluster.Status.GlobalSecurityGroup)
+	}
+
+	if err := s.ReconcileSecurityGroups("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile security groups after the failure: %v", err)
+	}
+	groups := map[string]int{}
+	for _, group := range cloud.Resources("security-groups") {
+		groups[group["name"].(string)]++
+	}
+	for _, desired := range []infrav1.SecurityGroup{generateControlPlaneGroup("test"), generateGlobalGroup("test")} {
+		if groups[desired.Name] != 1 {
+			t.Errorf("expected 1 security group %s, got %d", desired.Name, groups[desired.Name])
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/gophercloud/utils/openstack/clientconfig"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/provider"
 
 	"github.com/gophercloud/gophercloud"
 )
@@ -36,9 +37,9 @@ type Service struct {
 
 // NewService returns an instance of the networking service
 func NewService(client *gophercloud.ProviderClient, clientOpts *clientconfig.ClientOpts) (*Service, error) {
-	serviceClient, err := openstack.NewNetworkV2(client, gophercloud.EndpointOpts{
+	serviceClient, err := provider.NewServiceClient(client, "network", gophercloud.EndpointOpts{
 		Region: clientOpts.RegionName,
-	})
+	}, openstack.NewNetworkV2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create networking service client: %v", err)
 	}
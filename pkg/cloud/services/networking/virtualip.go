@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+)
+
+const virtualIPSuffix = "kubeapi-vip"
+
+// ReconcileVirtualIP reserves a port on the cluster subnet for the virtual IP of the APIServer
+// and associates a floating IP with it, unless the floating IP of the APIServer is disabled.
+func (s *Service) ReconcileVirtualIP(clusterName string, openStackCluster *infrav1.OpenStackCluster) error {
+	if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.Subnet == nil {
+		klog.V(4).Infof("No need to reconcile virtual IP since no subnet exists.")
+		return nil
+	}
+	if err := RequireExtension(s.client, ExtensionAllowedAddressPairs, "the APIServer virtual IP"); err != nil {
+		return err
+	}
+	vip := openStackCluster.Spec.APIServerVirtualIP
+	portName := fmt.Sprintf("%s-cluster-%s-%s", networkPrefix, clusterName, virtualIPSuffix)
+	klog.Infof("Reconciling virtual IP %s", portName)
+
+	port, err := s.getVirtualIPPort(portName, clusterName, openStackCluster)
+	if err != nil {
+		return err
+	}
+	if port == nil {
+		klog.Infof("Creating virtual IP port %s", portName)
+		port, err = ports.Create(s.client, ports.CreateOpts{
+			Name:           portName,
+			NetworkID:      openStackCluster.Status.Network.ID,
+			FixedIPs:       []ports.IP{{SubnetID: openStackCluster.Status.Network.Subnet.ID, IPAddress: vip.Address}},
+			SecurityGroups: &[]string{},
+		}).Extract()
+		if err != nil {
+			return fmt.Errorf("error creating virtual IP port: %v", err)
+		}
+		err = ReplaceAllAttributesTags(s.client, "ports", port.ID, OwnershipTags(clusterName))
+		if err != nil {
+			return err
+		}
+	}
+	if len(port.FixedIPs) == 0 {
+		return fmt.Errorf("virtual IP port %s has no fixed IP", portName)
+	}
+
+	virtualIP := &infrav1.VirtualIP{
+		Name:       port.Name,
+		PortID:     port.ID,
+		InternalIP: port.FixedIPs[0].IPAddress,
+	}
+	if !openStackCluster.Spec.DisableAPIServerFloatingIP && openStackCluster.Spec.ExternalNetworkID != "" {
+		fp, err := s.GetOrCreateFloatingIP(openStackCluster, vip.FloatingIP, portName)
+		if err != nil {
+			return err
+		}
+		if fp.PortID != port.ID && !infrav1.IsExternallyManaged(openStackCluster, fp.ID) {
+			klog.Infof("Associating floating ip %s", fp.FloatingIP)
+			_, err = floatingips.Update(s.client, fp.ID, floatingips.UpdateOpts{PortID: &port.ID}).Extract()
+			if err != nil {
+				return fmt.Errorf("error associating floating IP: %v", err)
+			}
+		}
+		virtualIP.IP = fp.FloatingIP
+	}
+	openStackCluster.Status.Network.APIServerVirtualIP = virtualIP
+	return nil
+}
+
+// DeleteVirtualIP releases the floating IP of the virtual IP of the APIServer and deletes its port.
+func (s *Service) DeleteVirtualIP(clusterName string, openStackCluster *infrav1.OpenStackCluster) error {
+	if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.APIServerVirtualIP == nil {
+		return nil
+	}
+	virtualIP := openStackCluster.Status.Network.APIServerVirtualIP
+	portName := fmt.Sprintf("%s-cluster-%s-%s", networkPrefix, clusterName, virtualIPSuffix)
+	if err := s.DeleteFloatingIP(virtualIP.IP, portName); err != nil {
+		return err
+	}
+
+	port, err := s.getVirtualIPPort(portName, clusterName, openStackCluster)
+	if err != nil {
+		return err
+	}
+	if port == nil {
+		klog.V(4).Infof("Skipped deleting virtual IP port %s that is already deleted", portName)
+		return nil
+	}
+	if infrav1.IsExternallyManaged(openStackCluster, port.ID) {
+		klog.V(4).Infof("Skipped deleting virtual IP port %s that is externally managed", portName)
+		return nil
+	}
+	klog.Infof("Deleting virtual IP port %s", portName)
+	if err := ports.Delete(s.client, port.ID).ExtractErr(); err != nil {
+		return fmt.Errorf("error deleting virtual IP port: %v", err)
+	}
+	return nil
+}
+
+// ReconcileVirtualIPAddressPairs adds the virtual IP of the APIServer as an allowed address pair
+// to the ports of the instance on the cluster network, so the instance can take it over.
+func (s *Service) ReconcileVirtualIPAddressPairs(instanceID string, openStackCluster *infrav1.OpenStackCluster) error {
+	if openStackCluster.Status.Network == nil || openStackCluster.Status.Network.APIServerVirtualIP == nil {
+		return nil
+	}
+	address := openStackCluster.Status.Network.APIServerVirtualIP.InternalIP
+
+	allPages, err := ports.List(s.client, ports.ListOpts{
+		DeviceID:  instanceID,
+		NetworkID: openStackCluster.Status.Network.ID,
+	}).AllPages()
+	if err != nil {
+		return err
+	}
+	portList, err := ports.ExtractPorts(allPages)
+	if err != nil {
+		return err
+	}
+	for _, port := range portList {
+		if hasAllowedAddressPair(port, address) || infrav1.IsExternallyManaged(openStackCluster, port.ID) {
+			continue
+		}
+		klog.Infof("Adding virtual IP %s to the allowed address pairs of port %s", address, port.ID)
+		pairs := append(port.AllowedAddressPairs, ports.AddressPair{IPAddress: address})
+		_, err := ports.Update(s.client, port.ID, ports.UpdateOpts{AllowedAddressPairs: &pairs}).Extract()
+		if err != nil {
+			return fmt.Errorf("error adding the virtual IP to port %s: %v", port.ID, err)
+		}
+	}
+	return nil
+}
+
+func hasAllowedAddressPair(port ports.Port, address string) bool {
+	for _, pair := range port.AllowedAddressPairs {
+		if pair.IPAddress == address {
+			return true
+		}
+	}
+	return false
+}
+
+// getVirtualIPPort returns the virtual IP port recorded in the status if it is owned by the
+// cluster, or the port with the name otherwise, e.g. because the status was lost when the cluster
+// was moved.
+func (s *Service) getVirtualIPPort(name, clusterName string, openStackCluster *infrav1.OpenStackCluster) (*ports.Port, error) {
+	if virtualIP := openStackCluster.Status.Network.APIServerVirtualIP; virtualIP != nil && virtualIP.PortID != "" {
+		port, err := ports.Get(s.client, virtualIP.PortID).Extract()
+		if err != nil && !IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			if isOwned(openStackCluster, clusterName, port.ID, port.Tags) {
+				return port, nil
+			}
+			klog.V(3).Infof("Virtual IP port %s of the status isn't owned by cluster %s, looking it up by name", port.ID, clusterName)
+		}
+	}
+	return s.getPortByName(name, openStackCluster.Status.Network.ID)
+}
+
+func (s *Service) getPortByName(name, networkID string) (*ports.Port, error) {
+	allPages, err := ports.List(s.client, ports.ListOpts{
+		Name:      name,
+		NetworkID: networkID,
+	}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	portList, err := ports.ExtractPorts(allPages)
+	if err != nil {
+		return nil, err
+	}
+	if len(portList) == 0 {
+		return nil, nil
+	}
+	return &portList[0], nil
+}
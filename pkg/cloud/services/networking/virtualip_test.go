@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networking
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestVirtualIPLifecycle(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	externalNetworkID := cloud.AddNetwork("public", true)
+	cloud.AddSubnet(externalNetworkID, "public", "172.24.4.0/24")
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{
+			NodeCIDR:           "10.6.0.0/24",
+			ExternalNetworkID:  externalNetworkID,
+			APIServerVirtualIP: &infrav1.APIServerVirtualIP{Address: "10.6.0.10"},
+		},
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking: %v", err)
+	}
+	if err := s.ReconcileVirtualIP("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile virtual IP: %v", err)
+	}
+	vip := openStackCluster.Status.Network.APIServerVirtualIP
+	if vip == nil || vip.InternalIP != "10.6.0.10" || vip.IP == "" || vip.PortID == "" {
+		t.Fatalf("expected the virtual IP 10.6.0.10 with a floating IP in the status, got %+v", vip)
+	}
+	fips := cloud.Resources("floatingips")
+	if len(fips) != 1 || fips[0]["port_id"] != vip.PortID {
+		t.Errorf("expected the floating IP to be associated with the virtual IP port, got %v", fips)
+	}
+
+	// A second reconciliation finds the existing port and floating IP.
+	cloud.ResetRequests()
+	if err := s.ReconcileVirtualIP("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile virtual IP again: %v", err)
+	}
+	if n := cloud.CountRequests(fake.ServiceNetwork, http.MethodPost, "."); n != 0 {
+		t.Errorf("expected nothing to be created on the second reconciliation, got %d requests", n)
+	}
+
+	// The virtual IP is added once to the ports of the instance.
+	instancePort, err := ports.Create(s.client, ports.CreateOpts{
+		Name:      "control-plane-0",
+		NetworkID: openStackCluster.Status.Network.ID,
+		DeviceID:  "instance",
+	}).Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.ReconcileVirtualIPAddressPairs("instance", openStackCluster); err != nil {
+			t.Fatalf("failed to reconcile allowed address pairs: %v", err)
+		}
+	}
+	port, err := ports.Get(s.client, instancePort.ID).Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(port.AllowedAddressPairs) != 1 || port.AllowedAddressPairs[0].IPAddress != "10.6.0.10" {
+		t.Errorf("expected the virtual IP as allowed address pair, got %+v", port.AllowedAddressPairs)
+	}
+
+	if err := s.DeleteVirtualIP("test", openStackCluster); err != nil {
+		t.Fatalf("failed to delete virtual IP: %v", err)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 0 {
+		t.Errorf("expected the floating IP to be released, got %d floating IPs", n)
+	}
+	for _, p := range cloud.Resources("ports") {
+		if p["id"] == vip.PortID {
+			t.Errorf("expected the virtual IP port to be deleted")
+		}
+	}
+}
+
+func TestVirtualIPWithoutFloatingIP(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	s := newTestService(t, cloud)
+
+	openStackCluster := &infrav1.OpenStackCluster{
+		Spec: infrav1.OpenStackClusterSpec{
+			NodeCIDR:                   "10.6.0.0/24",
+			DisableAPIServerFloatingIP: true,
+			APIServerVirtualIP:         &infrav1.APIServerVirtualIP{},
+		},
+	}
+	if err := reconcileNetworking(s, "test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile networking: %v", err)
+	}
+	if err := s.ReconcileVirtualIP("test", openStackCluster); err != nil {
+		t.Fatalf("failed to reconcile virtual IP: %v", err)
+	}
+	vip := openStackCluster.Status.Network.APIServerVirtualIP
+	if vip == nil || vip.InternalIP == "" || vip.IP != "" {
+		t.Fatalf("expected an allocated virtual IP without a floating IP in the status, got %+v", vip)
+	}
+	if n := len(cloud.Resources("floatingips")); n != 0 {
+		t.Errorf("expected no floating IPs, got %d", n)
+	}
+}
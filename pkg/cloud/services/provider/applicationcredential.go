@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/applicationcredentials"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/gophercloud/utils/openstack/clientconfig"
+)
+
+// ApplicationCredentialExpiryWarningPeriod is how long before its expiry we start
+// warning about an application credential.
+const ApplicationCredentialExpiryWarningPeriod = 7 * 24 * time.Hour
+
+// applicationCredentialExpiries caches the expiry of the application credentials keyed by the
+// auth URL and the application credential ID. Application credentials can't be updated, so
+// their expiry only has to be looked up once instead of on every reconcile.
+var applicationCredentialExpiries = struct {
+	sync.Mutex
+	entries map[string]*time.Time
+}{entries: map[string]*time.Time{}}
+
+// isApplicationCredential returns whether the cloud authenticates with an application credential.
+func isApplicationCredential(cloud clientconfig.Cloud) bool {
+	if cloud.AuthType == clientconfig.AuthV3ApplicationCredential {
+		return true
+	}
+	if cloud.AuthType != "" || cloud.AuthInfo == nil {
+		return false
+	}
+	return cloud.AuthInfo.ApplicationCredentialID != "" || cloud.AuthInfo.ApplicationCredentialName != ""
+}
+
+// validateApplicationCredential verifies the cloud contains everything needed to authenticate
+// with an application credential, and nothing which would make Keystone reject the request.
+func validateApplicationCredential(cloud clientconfig.Cloud) error {
+	auth := cloud.AuthInfo
+	if auth == nil {
+		return fmt.Errorf("cloud uses auth_type %s but has no auth section", clientconfig.AuthV3ApplicationCredential)
+	}
+	if auth.AuthURL == "" {
+		return fmt.Errorf("application credential requires auth_url")
+	}
+	if auth.ApplicationCredentialSecret == "" {
+		return fmt.Errorf("application credential requires application_credential_secret")
+	}
+	if auth.ApplicationCredentialID == "" {
+		if auth.ApplicationCredentialName == "" {
+			return fmt.Errorf("application credential requires application_credential_id or application_credential_name")
+		}
+		if auth.UserID == "" && auth.Username == "" {
+			return fmt.Errorf("application credential referenced by application_credential_name requires user_id or username")
+		}
+		if auth.UserID == "" && auth.UserDomainID == "" && auth.UserDomainName == "" && auth.DomainID == "" && auth.DomainName == "" {
+			return fmt.Errorf("application credential referenced by application_credential_name and username requires user_domain_id or user_domain_name")
+		}
+	}
+	if auth.Password != "" || auth.Token != "" {
+		return fmt.Errorf("application credential must not be combined with password or token")
+	}
+	if auth.ProjectID != "" || auth.ProjectName != "" {
+		return fmt.Errorf("application credential is already scoped to a project, project_id and project_name must not be set")
+	}
+	return nil
+}
+
+// GetApplicationCredentialExpiry returns when the application credential the provider client
+// authenticated with expires. It returns nil if the client didn't authenticate with an
+// application credential or the application credential doesn't expire.
+func GetApplicationCredentialExpiry(provider *gophercloud.ProviderClient) (*time.Time, error) {
+	result, ok := provider.GetAuthResult().(tokens.CreateResult)
+	if !ok {
+		return nil, nil
+	}
+	// ExtractInto already descends into the token object.
+	var token struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		ApplicationCredential *struct {
+			ID string `json:"id"`
+		} `json:"application_credential"`
+	}
+	if err := result.ExtractInto(&token); err != nil {
+		return nil, fmt.Errorf("failed to extract token: %v", err)
+	}
+	if token.ApplicationCredential == nil {
+		return nil, nil
+	}
+
+	key := provider.IdentityEndpoint + "/" + token.ApplicationCredential.ID
+	applicationCredentialExpiries.Lock()
+	expiresAt, ok := applicationCredentialExpiries.entries[key]
+	applicationCredentialExpiries.Unlock()
+	if ok {
+		return expiresAt, nil
+	}
+
+	identityClient, err := NewServiceClient(provider, "identity", gophercloud.EndpointOpts{}, openstack.NewIdentityV3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity service client: %v", err)
+	}
+	appCred, err := applicationcredentials.Get(identityClient, token.User.ID, token.ApplicationCredential.ID).Extract()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application credential %s: %v", token.ApplicationCredential.ID, err)
+	}
+	if !appCred.ExpiresAt.IsZero() {
+		expiresAt = &appCred.ExpiresAt
+	}
+
+	applicationCredentialExpiries.Lock()
+	defer applicationCredentialExpiries.Unlock()
+	// Expired application credentials can't authenticate anymore, drop them so the cache doesn't
+	// grow with every rotated credential.
+	now := time.Now()
+	for k, e := range applicationCredentialExpiries.entries {
+		if e != nil && e.Before(now) {
+			delete(applicationCredentialExpiries.entries, k)
+		}
+	}
+	applicationCredentialExpiries.entries[key] = expiresAt
+	return expiresAt, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/utils/openstack/clientconfig"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+)
+
+func TestValidateApplicationCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    *clientconfig.AuthInfo
+		wantErr bool
+	}{
+		{
+			name: "id and secret",
+			auth: &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret"},
+		},
+		{
+			name: "name with user ID",
+			auth: &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialName: "name", ApplicationCredentialSecret: "secret", UserID: "user"},
+		},
+		{
+			name: "name with username and user domain",
+			auth: &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialName: "name", ApplicationCredentialSecret: "secret", Username: "user", UserDomainName: "Default"},
+		},
+		{
+			name:    "without auth section",
+			wantErr: true,
+		},
+		{
+			name:    "without auth URL",
+			auth:    &clientconfig.AuthInfo{ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "without secret",
+			auth:    &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialID: "id"},
+			wantErr: true,
+		},
+		{
+			name:    "without id or name",
+			auth:    &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialSecret: "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "name without user",
+			auth:    &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialName: "name", ApplicationCredentialSecret: "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "name with username without domain",
+			auth:    &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialName: "name", ApplicationCredentialSecret: "secret", Username: "user"},
+			wantErr: true,
+		},
+		{
+			name:    "with password",
+			auth:    &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret", Password: "password"},
+			wantErr: true,
+		},
+		{
+			name:    "with project",
+			auth:    &clientconfig.AuthInfo{AuthURL: "https://keystone", ApplicationCredentialID: "id", ApplicationCredentialSecret: "secret", ProjectName: "admin"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		cloud := clientconfig.Cloud{AuthType: clientconfig.AuthV3ApplicationCredential, AuthInfo: tt.auth}
+		if err := validateApplicationCredential(cloud); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestIsApplicationCredential(t *testing.T) {
+	tests := []struct {
+		name     string
+		cloud    clientconfig.Cloud
+		expected bool
+	}{
+		{
+			name:     "auth type",
+			cloud:    clientconfig.Cloud{AuthType: clientconfig.AuthV3ApplicationCredential},
+			expected: true,
+		},
+		{
+			name:     "application credential ID without auth type",
+			cloud:    clientconfig.Cloud{AuthInfo: &clientconfig.AuthInfo{ApplicationCredentialID: "id"}},
+			expected: true,
+		},
+		{
+			name:  "password",
+			cloud: clientconfig.Cloud{AuthType: clientconfig.AuthV3Password, AuthInfo: &clientconfig.AuthInfo{ApplicationCredentialID: "id"}},
+		},
+		{
+			name:  "without auth section",
+			cloud: clientconfig.Cloud{},
+		},
+	}
+	for _, tt := range tests {
+		if actual := isApplicationCredential(tt.cloud); actual != tt.expected {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+// applicationCredentialSecret returns a clouds secret authenticating with the application credential.
+func applicationCredentialSecret(cloud *fake.Cloud, id, secret string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud-config", Namespace: "default"},
+		Data: map[string][]byte{CloudsSecretKey: []byte(fmt.Sprintf(`clouds:
+  openstack:
+    auth_type: v3applicationcredential
+    auth:
+      auth_url: %s
+      application_credential_id: %s
+      application_credential_secret: %s
+    region_name: %s
+`, cloud.AuthURL(), id, secret, fake.RegionName))},
+	}
+}
+
+func TestGetApplicationCredentialExpiry(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	expiresAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Microsecond)
+	expiring := cloud.AddApplicationCredential("expiring", "secret", &expiresAt)
+	permanent := cloud.AddApplicationCredential("permanent", "secret", nil)
+
+	tests := []struct {
+		name     string
+		id       string
+		expected *time.Time
+	}{
+		{name: "expiring", id: expiring, expected: &expiresAt},
+		{name: "permanent", id: permanent},
+	}
+	for _, tt := range tests {
+		cloudConfig, err := getCloudFromSecret(applicationCredentialSecret(cloud, tt.id, "secret"), "openstack", false)
+		if err != nil {
+			t.Fatalf("%s: failed to parse clouds secret: %v", tt.name, err)
+		}
+		provider, _, err := newClient(cloudConfig)
+		if err != nil {
+			t.Fatalf("%s: failed to authenticate: %v", tt.name, err)
+		}
+		expiry, err := GetApplicationCredentialExpiry(provider)
+		if err != nil {
+			t.Errorf("%s: failed to get expiry: %v", tt.name, err)
+			continue
+		}
+		if (expiry == nil) != (tt.expected == nil) || (expiry != nil && !expiry.Equal(*tt.expected)) {
+			t.Errorf("%s: expected expiry %v, got %v", tt.name, tt.expected, expiry)
+		}
+	}
+
+	// Clients which don't authenticate with an application credential have no expiry.
+	provider, _, err := cloud.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expiry, err := GetApplicationCredentialExpiry(provider); err != nil || expiry != nil {
+		t.Errorf("expected no expiry for a password, got %v: %v", expiry, err)
+	}
+}
+
+func TestGetApplicationCredentialExpiryCached(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	expiresAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Microsecond)
+	id := cloud.AddApplicationCredential("expiring", "secret", &expiresAt)
+
+	cloudConfig, err := getCloudFromSecret(applicationCredentialSecret(cloud, id, "secret"), "openstack", false)
+	if err != nil {
+		t.Fatalf("failed to parse clouds secret: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		provider, _, err := newClient(cloudConfig)
+		if err != nil {
+			t.Fatalf("failed to authenticate: %v", err)
+		}
+		expiry, err := GetApplicationCredentialExpiry(provider)
+		if err != nil || expiry == nil || !expiry.Equal(expiresAt) {
+			t.Errorf("expected expiry %v, got %v: %v", expiresAt, expiry, err)
+		}
+	}
+	if n := cloud.CountRequests(fake.ServiceIdentity, http.MethodGet, "application_credentials/"); n != 1 {
+		t.Errorf("expected the application credential to be looked up once, got %d requests", n)
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	tokensv2 "github.com/gophercloud/gophercloud/openstack/identity/v2/tokens"
+	tokensv3 "github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	"github.com/gophercloud/utils/openstack/clientconfig"
+	"k8s.io/klog"
+)
+
+// TokenExpiryMargin is how long before the expiry of its token a cached provider client is
+// replaced, so that requests in flight don't fail because of an expired token.
+const TokenExpiryMargin = 5 * time.Minute
+
+type cachedClient struct {
+	provider    *gophercloud.ProviderClient
+	clientOpts  *clientconfig.ClientOpts
+	tokenExpiry time.Time
+	// availability and endpointOverrides configure the service clients.
+	availability      gophercloud.Availability
+	endpointOverrides map[string]string
+	// serviceClients are the service clients created for the provider client.
+	serviceClients map[string]*gophercloud.ServiceClient
+}
+
+// cachedSecret holds the provider clients created from one version of a clouds secret, keyed by cloud name.
+type cachedSecret struct {
+	// version identifies the secret contents the clients were created from.
+	version string
+	clients map[string]*cachedClient
+}
+
+// clientCache caches the authenticated provider clients keyed by the namespace/name of the clouds secret
+// and the cloud name, so reconciles reuse the token instead of authenticating against Keystone again.
+// providers indexes the same clients by provider client for NewServiceClient.
+var clientCache = struct {
+	sync.Mutex
+	secrets   map[string]*cachedSecret
+	providers map[*gophercloud.ProviderClient]*cachedClient
+}{
+	secrets:   map[string]*cachedSecret{},
+	providers: map[*gophercloud.ProviderClient]*cachedClient{},
+}
+
+// getCachedClient returns the provider client cached for the cloud of the secret if it was created from
+// the same secret version and its token is still valid. Stale clients are evicted, and a changed secret
+// evicts the clients of all its clouds.
+func getCachedClient(secretKey, cloudName, version string) (*gophercloud.ProviderClient, *clientconfig.ClientOpts) {
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	secret, ok := clientCache.secrets[secretKey]
+	if ok && secret.version != version {
+		klog.V(4).Infof("Invalidating cached OpenStack clients of %s, the clouds secret changed", secretKey)
+		evictSecret(secretKey)
+		ok = false
+	}
+	if !ok {
+		// Recording the version makes putCachedClient drop clients created from an older version.
+		clientCache.secrets[secretKey] = &cachedSecret{version: version, clients: map[string]*cachedClient{}}
+		return nil, nil
+	}
+	entry, ok := secret.clients[cloudName]
+	if !ok {
+		return nil, nil
+	}
+	if !entry.tokenExpiry.IsZero() && time.Now().Add(TokenExpiryMargin).After(entry.tokenExpiry) {
+		klog.V(4).Infof("Invalidating cached OpenStack client %s/%s, its token expires at %s", secretKey, cloudName, entry.tokenExpiry)
+		delete(secret.clients, cloudName)
+		delete(clientCache.providers, entry.provider)
+		return nil, nil
+	}
+	return entry.provider, entry.clientOpts
+}
+
+// putCachedClient caches the provider client created from the given version of the secret. It isn't cached
+// if the secret changed or was deleted since getCachedClient was called, the next call creates a new one.
+func putCachedClient(secretKey, cloudName, version string, provider *gophercloud.ProviderClient, clientOpts *clientconfig.ClientOpts, cloud *cloudConfig) {
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	secret, ok := clientCache.secrets[secretKey]
+	if !ok || secret.version != version {
+		klog.V(4).Infof("Not caching OpenStack client %s/%s, the clouds secret changed", secretKey, cloudName)
+		return
+	}
+	if old, ok := secret.clients[cloudName]; ok {
+		delete(clientCache.providers, old.provider)
+	}
+	entry := &cachedClient{
+		provider:          provider,
+		clientOpts:        clientOpts,
+		tokenExpiry:       getTokenExpiry(provider),
+		availability:      gophercloud.Availability(cloud.Interface),
+		endpointOverrides: cloud.EndpointOverrides,
+		serviceClients:    map[string]*gophercloud.ServiceClient{},
+	}
+	secret.clients[cloudName] = entry
+	clientCache.providers[provider] = entry
+}
+
+// InvalidateSecret evicts the provider clients created from the clouds secret, e.g. because it was deleted.
+func InvalidateSecret(namespace, name string) {
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	evictSecret(secretCacheKey(namespace, name))
+}
+
+// evictSecret removes the clients of the secret from the cache. The cache must be locked.
+func evictSecret(secretKey string) {
+	secret, ok := clientCache.secrets[secretKey]
+	if !ok {
+		return
+	}
+	for _, entry := range secret.clients {
+		delete(clientCache.providers, entry.provider)
+	}
+	delete(clientCache.secrets, secretKey)
+}
+
+func secretCacheKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// getTokenExpiry returns when the token of the provider client expires,
+// or the zero time if it can't be determined.
+func getTokenExpiry(provider *gophercloud.ProviderClient) time.Time {
+	switch result := provider.GetAuthResult().(type) {
+	case tokensv3.CreateResult:
+		token, err := result.ExtractToken()
+		if err == nil {
+			return token.ExpiresAt
+		}
+	case tokensv2.CreateResult:
+		token, err := result.ExtractToken()
+		if err == nil {
+			return token.ExpiresAt
+		}
+	}
+	return time.Time{}
+}
+
+// NewServiceClient returns the service client of the provider client for the service type and endpoint options,
+// creating it with newFunc on first use. Service clients of cached provider clients are cached with them, and use
+// the interface and endpoint overrides configured in clouds.yaml.
+func NewServiceClient(provider *gophercloud.ProviderClient, serviceType string, eo gophercloud.EndpointOpts,
+	newFunc func(*gophercloud.ProviderClient, gophercloud.EndpointOpts) (*gophercloud.ServiceClient, error)) (*gophercloud.ServiceClient, error) {
+	key := fmt.Sprintf("%s/%s/%s", serviceType, eo.Region, eo.Availability)
+
+	clientCache.Lock()
+	defer clientCache.Unlock()
+
+	entry, ok := clientCache.providers[provider]
+	if !ok {
+		serviceClient, err := newFunc(provider, eo)
+		if err != nil {
+			return nil, err
+		}
+		registerServiceEndpoint(serviceClient.Endpoint, serviceType)
+		return serviceClient, nil
+	}
+	if serviceClient, ok := entry.serviceClients[key]; ok {
+		return serviceClient, nil
+	}
+	if eo.Availability == "" {
+		eo.Availability = entry.availability
+	}
+	serviceClient, err := newFunc(provider, eo)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint, ok := entry.endpointOverrides[serviceType]; ok {
+		overrideEndpoint(serviceClient, endpoint)
+	}
+	registerServiceEndpoint(serviceClient.Endpoint, serviceType)
+	entry.serviceClients[key] = serviceClient
+	return serviceClient, nil
+}
+
+// overrideEndpoint replaces the endpoint of the service client from the service catalog.
+// The path a service client appends to its endpoint, e.g. the API version, is kept.
+func overrideEndpoint(serviceClient *gophercloud.ServiceClient, endpoint string) {
+	endpoint = gophercloud.NormalizeURL(endpoint)
+	if serviceClient.ResourceBase != "" {
+		serviceClient.ResourceBase = endpoint + strings.TrimPrefix(serviceClient.ResourceBase, serviceClient.Endpoint)
+	}
+	serviceClient.Endpoint = endpoint
+}
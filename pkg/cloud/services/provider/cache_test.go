@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const tokensPath = "^v3/auth/tokens$"
+
+func cloudsSecret(cloud *fake.Cloud, resourceVersion string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloud-config", Namespace: "default", UID: "uid", ResourceVersion: resourceVersion},
+		Data:       map[string][]byte{CloudsSecretKey: cloud.CloudsYAML("openstack")},
+	}
+}
+
+func TestGetClientFromSecret(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	secret := cloudsSecret(cloud, "1")
+	ctrlClient := ctrlfake.NewFakeClientWithScheme(scheme.Scheme, secret)
+	defer InvalidateSecret(secret.Namespace, secret.Name)
+
+	tests := []struct {
+		name            string
+		update          func(*testing.T)
+		authentications int
+	}{
+		{
+			name:            "first client",
+			authentications: 1,
+		},
+		{
+			name:            "unchanged secret",
+			authentications: 0,
+		},
+		{
+			name: "changed resourceVersion",
+			update: func(t *testing.T) {
+				if err := ctrlClient.Update(context.TODO(), cloudsSecret(cloud, "2")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			authentications: 1,
+		},
+		{
+			name: "evicted secret",
+			update: func(t *testing.T) {
+				InvalidateSecret(secret.Namespace, secret.Name)
+			},
+			authentications: 1,
+		},
+	}
+	var previous *gophercloud.ProviderClient
+	for _, tt := range tests {
+		if tt.update != nil {
+			tt.update(t)
+		}
+		cloud.ResetRequests()
+		provider, _, err := getClientFromSecret(ctrlClient, secret.Namespace, secret.Name, "openstack")
+		if err != nil {
+			t.Fatalf("%s: failed to get client: %v", tt.name, err)
+		}
+		if n := cloud.CountRequests(fake.ServiceIdentity, http.MethodPost, tokensPath); n != tt.authentications {
+			t.Errorf("%s: expected %d authentications, got %d", tt.name, tt.authentications, n)
+		}
+		if (provider == previous) != (tt.authentications == 0) {
+			t.Errorf("%s: expected the cached client to be reused %t", tt.name, tt.authentications == 0)
+		}
+		previous = provider
+	}
+
+	// Deleted secrets are evicted when they aren't found.
+	if err := ctrlClient.Delete(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := getClientFromSecret(ctrlClient, secret.Namespace, secret.Name, "openstack"); err == nil {
+		t.Errorf("expected an error for a deleted secret")
+	}
+	clientCache.Lock()
+	_, cached := clientCache.secrets[secretCacheKey(secret.Namespace, secret.Name)]
+	_, indexed := clientCache.providers[previous]
+	clientCache.Unlock()
+	if cached || indexed {
+		t.Errorf("expected the clients of the deleted secret to be evicted")
+	}
+}
+
+func TestPutCachedClient(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	provider, clientOpts, err := cloud.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := secretCacheKey("default", "put")
+	defer InvalidateSecret("default", "put")
+
+	tests := []struct {
+		name   string
+		setup  func()
+		cached bool
+	}{
+		{
+			name:   "unchanged secret",
+			setup:  func() { getCachedClient(key, "openstack", "1") },
+			cached: true,
+		},
+		{
+			name: "secret changed while authenticating",
+			setup: func() {
+				getCachedClient(key, "openstack", "1")
+				getCachedClient(key, "openstack", "2")
+			},
+		},
+		{
+			name: "secret deleted while authenticating",
+			setup: func() {
+				getCachedClient(key, "openstack", "1")
+				InvalidateSecret("default", "put")
+			},
+		},
+	}
+	for _, tt := range tests {
+		InvalidateSecret("default", "put")
+		tt.setup()
+		putCachedClient(key, "openstack", "1", provider, clientOpts, &cloudConfig{})
+		clientCache.Lock()
+		_, indexed := clientCache.providers[provider]
+		clientCache.Unlock()
+		if indexed != tt.cached {
+			t.Errorf("%s: expected the client to be cached %t", tt.name, tt.cached)
+		}
+		if cached, _ := getCachedClient(key, "openstack", "1"); (cached == provider) != tt.cached {
+			t.Errorf("%s: expected the client to be returned %t", tt.name, tt.cached)
+		}
+	}
+}
+
+func TestNewServiceClient(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	secret := cloudsSecret(cloud, "1")
+	key := secretCacheKey(secret.Namespace, secret.Name)
+	defer InvalidateSecret(secret.Namespace, secret.Name)
+
+	provider, _, err := getClient(key, "1", secret, "openstack", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eo := gophercloud.EndpointOpts{Region: fake.RegionName}
+	first, err := NewServiceClient(provider, "network", eo, openstack.NewNetworkV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewServiceClient(provider, "network", eo, openstack.NewNetworkV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("expected the service client of a cached provider client to be reused")
+	}
+
+	// Service clients of evicted provider clients aren't cached anymore.
+	InvalidateSecret(secret.Namespace, secret.Name)
+	third, err := NewServiceClient(provider, "network", eo, openstack.NewNetworkV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first {
+		t.Errorf("expected a new service client for an evicted provider client")
+	}
+}
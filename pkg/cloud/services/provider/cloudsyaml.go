@@ -0,0 +1,299 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/utils/openstack/clientconfig"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	CloudsPublicSecretKey = "clouds-public.yaml"
+	CertSecretKey         = "cert"
+	KeySecretKey          = "key"
+
+	endpointOverrideSuffix = "_endpoint_override"
+)
+
+// cloudConfig is a cloud of a clouds.yaml, including the settings gophercloud/utils doesn't parse
+// and the certificates stored alongside the clouds.yaml in the clouds secret.
+type cloudConfig struct {
+	clientconfig.Cloud
+
+	// Interface is the endpoint interface of the service catalog to use, one of public, internal or admin.
+	Interface string
+	// EndpointOverrides maps service types to the endpoint used instead of the one in the service catalog.
+	EndpointOverrides map[string]string
+	// RateLimit configures the rate limiting and retries of the requests to the cloud.
+	RateLimit rateLimitConfig
+
+	CACert     []byte
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// cloudExtensions are the settings of a cloud in clouds.yaml which clientconfig.Cloud doesn't contain.
+type cloudExtensions struct {
+	Interface    string `json:"interface"`
+	EndpointType string `json:"endpoint_type"`
+}
+
+// getCloudFromSecret extracts the cloud with the given name from the clouds.yaml in the secret,
+// merged with its profile from clouds-public.yaml if the secret contains one. Certificate files
+// referenced by the cloud are only read if allowFiles is set, i.e. for the default identity of
+// the manager, as the files of the manager must not be readable through namespaced secrets.
+func getCloudFromSecret(secret *v1.Secret, cloudName string, allowFiles bool) (*cloudConfig, error) {
+	content, ok := secret.Data[CloudsSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("OpenStack credentials secret %v did not contain key %v",
+			secret.Name, CloudsSecretKey)
+	}
+	var clouds struct {
+		Clouds map[string]map[string]interface{} `json:"clouds"`
+	}
+	if err := yaml.Unmarshal(content, &clouds); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clouds credentials stored in secret %v: %v", secret.Name, err)
+	}
+	rawCloud, ok := clouds.Clouds[cloudName]
+	if !ok {
+		return nil, fmt.Errorf("cloud %q not found in %v of secret %v, available clouds: %s",
+			cloudName, CloudsSecretKey, secret.Name, strings.Join(sortedKeys(clouds.Clouds), ", "))
+	}
+
+	if profile, ok := rawCloud["profile"].(string); ok && profile != "" {
+		publicContent, ok := secret.Data[CloudsPublicSecretKey]
+		if !ok {
+			return nil, fmt.Errorf("cloud %q uses profile %q, but secret %v did not contain key %v",
+				cloudName, profile, secret.Name, CloudsPublicSecretKey)
+		}
+		var publicClouds struct {
+			PublicClouds map[string]map[string]interface{} `json:"public-clouds"`
+		}
+		if err := yaml.Unmarshal(publicContent, &publicClouds); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %v stored in secret %v: %v", CloudsPublicSecretKey, secret.Name, err)
+		}
+		publicCloud, ok := publicClouds.PublicClouds[profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q of cloud %q not found in %v of secret %v", profile, cloudName, CloudsPublicSecretKey, secret.Name)
+		}
+		rawCloud = mergeCloud(rawCloud, publicCloud)
+	}
+
+	cloud, err := parseCloud(rawCloud)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cloud %q in secret %v: %v", cloudName, secret.Name, err)
+	}
+
+	cloud.CACert = secret.Data[CaSecretKey]
+	if len(cloud.CACert) > 0 && !isPEM(cloud.CACert) {
+		// The secrets generated by the examples contain a placeholder if the cloud has no CA.
+		klog.V(4).Infof("Ignoring %s of secret %v, it isn't PEM encoded", CaSecretKey, secret.Name)
+		cloud.CACert = nil
+	}
+	cloud.ClientCert = secret.Data[CertSecretKey]
+	cloud.ClientKey = secret.Data[KeySecretKey]
+	if err := cloud.loadCertificateFiles(allowFiles); err != nil {
+		return nil, fmt.Errorf("invalid cloud %q in secret %v: %v", cloudName, secret.Name, err)
+	}
+
+	if err := validateCloud(cloud); err != nil {
+		return nil, fmt.Errorf("invalid cloud %q in secret %v: %v", cloudName, secret.Name, err)
+	}
+	return cloud, nil
+}
+
+// parseCloud converts the raw cloud of a clouds.yaml into a cloudConfig.
+func parseCloud(rawCloud map[string]interface{}) (*cloudConfig, error) {
+	content, err := json.Marshal(rawCloud)
+	if err != nil {
+		return nil, err
+	}
+	cloud := &cloudConfig{}
+	if err := json.Unmarshal(content, &cloud.Cloud); err != nil {
+		return nil, err
+	}
+	var extensions cloudExtensions
+	if err := json.Unmarshal(content, &extensions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &cloud.RateLimit); err != nil {
+		return nil, err
+	}
+
+	cloud.Interface = extensions.Interface
+	if cloud.Interface == "" {
+		cloud.Interface = extensions.EndpointType
+	}
+	// "publicURL" is the legacy spelling of "public".
+	cloud.Interface = strings.TrimSuffix(cloud.Interface, "URL")
+
+	for key, value := range rawCloud {
+		if !strings.HasSuffix(key, endpointOverrideSuffix) {
+			continue
+		}
+		endpoint, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string", key)
+		}
+		if cloud.EndpointOverrides == nil {
+			cloud.EndpointOverrides = map[string]string{}
+		}
+		serviceType := strings.Replace(strings.TrimSuffix(key, endpointOverrideSuffix), "_", "-", -1)
+		cloud.EndpointOverrides[serviceType] = endpoint
+	}
+	return cloud, nil
+}
+
+// mergeCloud merges a cloud with its profile. Settings of the cloud take precedence.
+func mergeCloud(cloud, profile map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(profile))
+	for k, v := range profile {
+		merged[k] = v
+	}
+	for k, v := range cloud {
+		cloudMap, cloudIsMap := v.(map[string]interface{})
+		profileMap, profileIsMap := merged[k].(map[string]interface{})
+		if cloudIsMap && profileIsMap {
+			merged[k] = mergeCloud(cloudMap, profileMap)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// loadCertificateFiles reads the cacert, cert and key files referenced in clouds.yaml,
+// unless the certificates are stored in the clouds secret, in which case the paths are
+// ignored. Without allowFiles referencing a file which isn't in the secret is an error.
+func (c *cloudConfig) loadCertificateFiles(allowFiles bool) error {
+	files := []struct {
+		key     string
+		path    string
+		content *[]byte
+	}{
+		{key: CaSecretKey, path: c.CACertFile, content: &c.CACert},
+		{key: CertSecretKey, path: c.ClientCertFile, content: &c.ClientCert},
+		{key: KeySecretKey, path: c.ClientKeyFile, content: &c.ClientKey},
+	}
+	for _, f := range files {
+		if f.path == "" || len(*f.content) > 0 {
+			continue
+		}
+		if !allowFiles {
+			return fmt.Errorf("%s must not reference a file, store its content in the %s key of the secret instead", f.key, f.key)
+		}
+		content, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", f.key, err)
+		}
+		*f.content = content
+	}
+	return nil
+}
+
+// validateCloud verifies the cloud contains everything needed to authenticate.
+func validateCloud(cloud *cloudConfig) error {
+	if cloud.AuthInfo == nil {
+		return fmt.Errorf("auth is missing")
+	}
+	if cloud.AuthInfo.AuthURL == "" {
+		return fmt.Errorf("auth.auth_url is missing")
+	}
+	switch {
+	case isApplicationCredential(cloud.Cloud):
+		if err := validateApplicationCredential(cloud.Cloud); err != nil {
+			return err
+		}
+	case cloud.AuthInfo.Token != "" || strings.Contains(string(cloud.AuthType), "token"):
+		if cloud.AuthInfo.Token == "" {
+			return fmt.Errorf("auth.token is missing")
+		}
+	default:
+		if cloud.AuthInfo.Username == "" && cloud.AuthInfo.UserID == "" {
+			return fmt.Errorf("auth.username or auth.user_id is missing")
+		}
+		if cloud.AuthInfo.Password == "" {
+			return fmt.Errorf("auth.password is missing")
+		}
+	}
+
+	if cloud.RateLimit.RateLimit < 0 || cloud.RateLimit.RateLimitBurst < 0 || (cloud.RateLimit.MaxRetries != nil && *cloud.RateLimit.MaxRetries < 0) {
+		return fmt.Errorf("rate_limit, rate_limit_burst and max_retries must not be negative")
+	}
+
+	switch gophercloud.Availability(cloud.Interface) {
+	case "", gophercloud.AvailabilityPublic, gophercloud.AvailabilityInternal, gophercloud.AvailabilityAdmin:
+	default:
+		return fmt.Errorf("interface %q is invalid, must be one of public, internal or admin", cloud.Interface)
+	}
+
+	if len(cloud.ClientCert) > 0 || len(cloud.ClientKey) > 0 {
+		if _, err := tls.X509KeyPair(cloud.ClientCert, cloud.ClientKey); err != nil {
+			return fmt.Errorf("invalid client certificate and key: %v", err)
+		}
+	}
+	if len(cloud.CACert) > 0 && !x509.NewCertPool().AppendCertsFromPEM(cloud.CACert) {
+		return fmt.Errorf("cacert doesn't contain any valid PEM encoded certificate")
+	}
+	return nil
+}
+
+// isPEM returns whether the content contains a PEM block.
+func isPEM(content []byte) bool {
+	block, _ := pem.Decode(content)
+	return block != nil
+}
+
+// tlsConfig returns the TLS configuration for the cloud. Certificates are verified unless verify is false.
+func (c *cloudConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: c.Verify != nil && !*c.Verify,
+	}
+	if len(c.CACert) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		config.RootCAs.AppendCertsFromPEM(c.CACert)
+	}
+	if len(c.ClientCert) > 0 {
+		cert, err := tls.X509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate and key: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+func sortedKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// testCACert returns a PEM encoded self-signed CA certificate.
+func testCACert(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+const testAuth = `
+    auth:
+      auth_url: https://keystone:5000/v3
+      username: admin
+      password: password
+      project_name: admin
+      user_domain_name: Default
+      project_domain_name: Default`
+
+func TestGetCloudFromSecret(t *testing.T) {
+	caCert := testCACert(t)
+	dir, err := ioutil.TempDir("", "cloudsyaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "cacert")
+	if err := ioutil.WriteFile(caFile, caCert, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       map[string]string
+		cloudName  string
+		allowFiles bool
+		wantErr    string
+		expected   func(*testing.T, *cloudConfig)
+	}{
+		{
+			name: "profile",
+			data: map[string]string{
+				CloudsSecretKey: `clouds:
+  openstack:
+    profile: example
+    region_name: RegionTwo
+    auth:
+      username: admin
+      password: password
+      project_name: admin
+      user_domain_name: Default
+      project_domain_name: Default`,
+				CloudsPublicSecretKey: `public-clouds:
+  example:
+    region_name: RegionOne
+    interface: internal
+    auth:
+      auth_url: https://example:5000/v3
+      username: other`,
+			},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if cloud.AuthInfo.AuthURL != "https://example:5000/v3" || cloud.AuthInfo.Username != "admin" {
+					t.Errorf("expected the auth URL of the profile and the username of the cloud, got %+v", cloud.AuthInfo)
+				}
+				if cloud.RegionName != "RegionTwo" || cloud.Interface != "internal" {
+					t.Errorf("expected region RegionTwo and interface internal, got %s and %s", cloud.RegionName, cloud.Interface)
+				}
+			},
+		},
+		{
+			name:    "profile without clouds-public.yaml",
+			data:    map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    profile: example" + testAuth},
+			wantErr: "did not contain key " + CloudsPublicSecretKey,
+		},
+		{
+			name: "unknown profile",
+			data: map[string]string{
+				CloudsSecretKey:       "clouds:\n  openstack:\n    profile: example" + testAuth,
+				CloudsPublicSecretKey: "public-clouds:\n  other: {}",
+			},
+			wantErr: `profile "example" of cloud "openstack" not found`,
+		},
+		{
+			name: "endpoint overrides",
+			data: map[string]string{CloudsSecretKey: `clouds:
+  openstack:
+    compute_endpoint_override: https://nova:8774/v2.1
+    load_balancer_endpoint_override: https://octavia:9876` + testAuth},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				expected := map[string]string{"compute": "https://nova:8774/v2.1", "load-balancer": "https://octavia:9876"}
+				if !reflect.DeepEqual(cloud.EndpointOverrides, expected) {
+					t.Errorf("expected endpoint overrides %v, got %v", expected, cloud.EndpointOverrides)
+				}
+			},
+		},
+		{
+			name: "endpoint_type",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    endpoint_type: internalURL" + testAuth},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if cloud.Interface != "internal" {
+					t.Errorf("expected interface internal, got %q", cloud.Interface)
+				}
+			},
+		},
+		{
+			name: "interface takes precedence over endpoint_type",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    interface: admin\n    endpoint_type: internal" + testAuth},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if cloud.Interface != "admin" {
+					t.Errorf("expected interface admin, got %q", cloud.Interface)
+				}
+			},
+		},
+		{
+			name:    "invalid interface",
+			data:    map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    interface: private" + testAuth},
+			wantErr: `interface "private" is invalid`,
+		},
+		{
+			name:      "unknown cloud",
+			data:      map[string]string{CloudsSecretKey: "clouds:\n  b:" + testAuth + "\n  a:" + testAuth},
+			cloudName: "missing",
+			wantErr:   `cloud "missing" not found in clouds.yaml of secret cloud-config, available clouds: a, b`,
+		},
+		{
+			name:    "without clouds.yaml",
+			data:    map[string]string{},
+			wantErr: "did not contain key " + CloudsSecretKey,
+		},
+		{
+			name: "verify false",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    verify: false" + testAuth},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				config, err := cloud.tlsConfig()
+				if err != nil || !config.InsecureSkipVerify {
+					t.Errorf("expected certificates not to be verified, got %+v: %v", config, err)
+				}
+			},
+		},
+		{
+			name: "certificates are verified by default",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:" + testAuth},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				config, err := cloud.tlsConfig()
+				if err != nil || config.InsecureSkipVerify {
+					t.Errorf("expected certificates to be verified, got %+v: %v", config, err)
+				}
+			},
+		},
+		{
+			name: "cacert in the secret",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:" + testAuth, CaSecretKey: string(caCert)},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if !bytes.Equal(cloud.CACert, caCert) {
+					t.Errorf("expected the cacert of the secret")
+				}
+			},
+		},
+		{
+			name: "placeholder cacert",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:" + testAuth, CaSecretKey: "dummy\n"},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if len(cloud.CACert) != 0 {
+					t.Errorf("expected the placeholder cacert to be ignored, got %q", cloud.CACert)
+				}
+			},
+		},
+		{
+			name: "empty cacert",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:" + testAuth, CaSecretKey: ""},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if len(cloud.CACert) != 0 {
+					t.Errorf("expected no cacert, got %q", cloud.CACert)
+				}
+			},
+		},
+		{
+			name:    "invalid cacert",
+			data:    map[string]string{CloudsSecretKey: "clouds:\n  openstack:" + testAuth, CaSecretKey: "-----BEGIN CERTIFICATE-----\naW52YWxpZA==\n-----END CERTIFICATE-----\n"},
+			wantErr: "cacert doesn't contain any valid PEM encoded certificate",
+		},
+		{
+			name:    "cacert file of a clouds secret",
+			data:    map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    cacert: " + caFile + testAuth},
+			wantErr: "cacert must not reference a file",
+		},
+		{
+			name: "cacert file of a clouds secret with cacert key",
+			data: map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    cacert: /etc/certs/missing" + testAuth, CaSecretKey: string(caCert)},
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if !bytes.Equal(cloud.CACert, caCert) {
+					t.Errorf("expected the cacert of the secret instead of the file")
+				}
+			},
+		},
+		{
+			name:    "cacert file of a clouds secret with placeholder cacert key",
+			data:    map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    cacert: " + caFile + testAuth, CaSecretKey: "dummy"},
+			wantErr: "cacert must not reference a file",
+		},
+		{
+			name:       "cacert file of the default identity",
+			data:       map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    cacert: " + caFile + testAuth},
+			allowFiles: true,
+			expected: func(t *testing.T, cloud *cloudConfig) {
+				if !bytes.Equal(cloud.CACert, caCert) {
+					t.Errorf("expected the cacert of the file")
+				}
+			},
+		},
+		{
+			name:       "missing cacert file of the default identity",
+			data:       map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    cacert: " + filepath.Join(dir, "missing") + testAuth},
+			allowFiles: true,
+			wantErr:    "failed to read cacert",
+		},
+		{
+			name:    "without password",
+			data:    map[string]string{CloudsSecretKey: "clouds:\n  openstack:\n    auth:\n      auth_url: https://keystone:5000/v3\n      username: admin"},
+			wantErr: "auth.password is missing",
+		},
+	}
+	for _, tt := range tests {
+		secret := &v1.Secret{Data: map[string][]byte{}}
+		secret.Name = "cloud-config"
+		for k, v := range tt.data {
+			secret.Data[k] = []byte(v)
+		}
+		cloudName := tt.cloudName
+		if cloudName == "" {
+			cloudName = "openstack"
+		}
+		cloud, err := getCloudFromSecret(secret, cloudName, tt.allowFiles)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		tt.expected(t, cloud)
+	}
+}
+
+func TestMergeCloud(t *testing.T) {
+	cloud := map[string]interface{}{
+		"region_name": "RegionTwo",
+		"auth":        map[string]interface{}{"username": "admin"},
+	}
+	profile := map[string]interface{}{
+		"region_name": "RegionOne",
+		"interface":   "public",
+		"auth":        map[string]interface{}{"auth_url": "https://keystone", "username": "other"},
+	}
+	expected := map[string]interface{}{
+		"region_name": "RegionTwo",
+		"interface":   "public",
+		"auth":        map[string]interface{}{"auth_url": "https://keystone", "username": "admin"},
+	}
+	if merged := mergeCloud(cloud, profile); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %v, got %v", expected, merged)
+	}
+	if profile["region_name"] != "RegionOne" || profile["auth"].(map[string]interface{})["username"] != "other" {
+		t.Errorf("expected the profile not to be modified, got %v", profile)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/utils/openstack/clientconfig"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultIdentity is the identity used by OpenStackClusters without credentials.
+var defaultIdentity struct {
+	path      string
+	cloudName string
+}
+
+// SetDefaultIdentity configures the identity used by OpenStackClusters without credentials.
+// The path is a directory containing the keys of a clouds secret as files, e.g. a mounted clouds secret.
+func SetDefaultIdentity(path, cloudName string) {
+	defaultIdentity.path = path
+	defaultIdentity.cloudName = cloudName
+}
+
+// getClientFromDefaultIdentity returns the client for the default identity. The files are
+// read on every call, so a mounted secret is picked up when it is updated.
+func getClientFromDefaultIdentity() (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
+	if defaultIdentity.cloudName == "" {
+		return nil, nil, fmt.Errorf("default identity %s is configured without cloud name", defaultIdentity.path)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultIdentity.path},
+		Data:       map[string][]byte{},
+	}
+	for _, key := range []string{CloudsSecretKey, CloudsPublicSecretKey, CaSecretKey, CertSecretKey, KeySecretKey} {
+		content, err := ioutil.ReadFile(filepath.Join(defaultIdentity.path, key))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to read default identity: %v", err)
+		}
+		secret.Data[key] = content
+	}
+
+	// Secret names can't contain slashes, so the key doesn't collide with a clouds secret.
+	key := secretCacheKey("default", defaultIdentity.path)
+	return getClient(key, secretVersion(secret), secret, defaultIdentity.cloudName, true)
+}
+
+// secretVersion returns a hash of the contents of the secret.
+func secretVersion(secret *v1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s\x00%s\x00", k, secret.Data[k])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// getIdentity returns the OpenStackClusterIdentity referenced by the OpenStackCluster,
+// if the namespace of the cluster is allowed to use it.
+func getIdentity(ctrlClient client.Client, openStackCluster *infrav1.OpenStackCluster) (*infrav1.OpenStackClusterIdentity, error) {
+	identity := &infrav1.OpenStackClusterIdentity{}
+	err := ctrlClient.Get(context.TODO(), types.NamespacedName{Name: openStackCluster.Spec.IdentityRef.Name}, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenStackClusterIdentity %s: %v", openStackCluster.Spec.IdentityRef.Name, err)
+	}
+	allowed, err := IsNamespaceAllowed(ctrlClient, identity.Spec.AllowedNamespaces, openStackCluster.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("OpenStackClusterIdentity %s is not allowed to be used in namespace %s", identity.Name, openStackCluster.Namespace)
+	}
+	return identity, nil
+}
+
+// IsNamespaceAllowed returns whether the namespace is selected by the allowed namespaces of an identity.
+func IsNamespaceAllowed(ctrlClient client.Client, allowedNamespaces *infrav1.AllowedNamespaces, namespace string) (bool, error) {
+	if allowedNamespaces == nil {
+		return false, nil
+	}
+	if len(allowedNamespaces.NamespaceList) == 0 && allowedNamespaces.Selector == nil {
+		return true, nil
+	}
+	for _, allowed := range allowedNamespaces.NamespaceList {
+		if allowed == namespace {
+			return true, nil
+		}
+	}
+	if allowedNamespaces.Selector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(allowedNamespaces.Selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid allowed namespaces selector: %v", err)
+	}
+	ns := &v1.Namespace{}
+	if err := ctrlClient.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %v", namespace, err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/metrics"
+)
+
+// idPattern matches path segments which are IDs, e.g. UUIDs or numbers.
+var idPattern = regexp.MustCompile(`^([0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}|[0-9a-fA-F]{32}|[0-9]+)$`)
+
+// serviceEndpoints maps the endpoints of the service clients to their service type,
+// so requests can be attributed to a service in the metrics.
+var serviceEndpoints = struct {
+	sync.RWMutex
+	entries map[string]string
+}{entries: map[string]string{}}
+
+func registerServiceEndpoint(endpoint, serviceType string) {
+	serviceEndpoints.Lock()
+	defer serviceEndpoints.Unlock()
+	serviceEndpoints.entries[endpoint] = serviceType
+}
+
+// getService returns the service type and the path relative to the endpoint of the service
+// the URL belongs to, using the longest matching endpoint.
+func getService(url string) (string, string) {
+	serviceEndpoints.RLock()
+	defer serviceEndpoints.RUnlock()
+
+	var service, endpoint string
+	for e, s := range serviceEndpoints.entries {
+		if strings.HasPrefix(url, e) && len(e) > len(endpoint) {
+			service, endpoint = s, e
+		}
+	}
+	if service == "" {
+		return "unknown", ""
+	}
+	return service, strings.TrimPrefix(url, endpoint)
+}
+
+// getOperation returns the operation of a request, its method and the path relative
+// to the endpoint of the service with IDs replaced, e.g. "GET servers/{id}".
+func getOperation(method, path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if idPattern.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// instrumentedTransport records the duration and result of every request to a cloud.
+type instrumentedTransport struct {
+	next  http.RoundTripper
+	cloud string
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	service, path := getService(req.URL.String())
+	operation := getOperation(req.Method, path)
+	code := ""
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.APIRequestDuration.WithLabelValues(service, operation, code, t.cloud).Observe(time.Since(start).Seconds())
+	metrics.APIRequests.WithLabelValues(service, operation, code, t.cloud).Inc()
+	return resp, err
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/metrics"
+)
+
+func TestGetOperation(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{method: http.MethodGet, path: "servers/detail", expected: "GET servers/detail"},
+		{method: http.MethodGet, path: "servers/2b5d8f0e-6b5e-4b43-9a55-8f6c3f2a1c0d", expected: "GET servers/{id}"},
+		{method: http.MethodDelete, path: "/v2.0/ports/2b5d8f0e6b5e4b439a558f6c3f2a1c0d/", expected: "DELETE v2.0/ports/{id}"},
+		{method: http.MethodPut, path: "v2.0/ports/2b5d8f0e-6b5e-4b43-9a55-8f6c3f2a1c0d/tags/cluster", expected: "PUT v2.0/ports/{id}/tags/cluster"},
+		{method: http.MethodGet, path: "flavors/42?name=m1.small", expected: "GET flavors/{id}"},
+		{method: http.MethodGet, path: "v2.0/networks#fragment", expected: "GET v2.0/networks"},
+		{method: http.MethodGet, path: "", expected: "GET "},
+	}
+	for _, tt := range tests {
+		if operation := getOperation(tt.method, tt.path); operation != tt.expected {
+			t.Errorf("getOperation(%s, %q): expected %q, got %q", tt.method, tt.path, tt.expected, operation)
+		}
+	}
+}
+
+func TestGetService(t *testing.T) {
+	registerServiceEndpoint("https://instrument.test:9696/", "network")
+	registerServiceEndpoint("https://instrument.test:9696/v2.0/", "network-v2")
+	registerServiceEndpoint("https://instrument.test:8774/v2.1/", "compute")
+
+	tests := []struct {
+		url     string
+		service string
+		path    string
+	}{
+		{url: "https://instrument.test:9696/v2.0/ports", service: "network-v2", path: "ports"},
+		{url: "https://instrument.test:9696/", service: "network", path: ""},
+		{url: "https://instrument.test:8774/v2.1/servers/detail", service: "compute", path: "servers/detail"},
+		{url: "https://other.test/v3/auth/tokens", service: "unknown", path: ""},
+	}
+	for _, tt := range tests {
+		service, path := getService(tt.url)
+		if service != tt.service || path != tt.path {
+			t.Errorf("getService(%q): expected %s %q, got %s %q", tt.url, tt.service, tt.path, service, path)
+		}
+	}
+}
+
+func TestInstrumentedTransport(t *testing.T) {
+	registerServiceEndpoint("https://instrumented.test/compute/", "compute")
+	errRefused := errors.New("connection refused")
+	transport := &instrumentedTransport{
+		cloud: "instrumented.test",
+		next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost {
+				return nil, errRefused
+			}
+			return stubResponse(http.StatusOK), nil
+		}),
+	}
+
+	requests := []struct {
+		method string
+		url    string
+	}{
+		{method: http.MethodGet, url: "https://instrumented.test/compute/servers/42"},
+		{method: http.MethodGet, url: "https://instrumented.test/compute/servers/43"},
+		{method: http.MethodPost, url: "https://instrumented.test/compute/servers"},
+	}
+	for _, r := range requests {
+		req, _ := http.NewRequest(r.method, r.url, nil)
+		_, _ = transport.RoundTrip(req)
+	}
+
+	expected := []struct {
+		operation string
+		code      string
+		count     float64
+	}{
+		{operation: "GET servers/{id}", code: "200", count: 2},
+		{operation: "POST servers", code: "", count: 1},
+	}
+	for _, e := range expected {
+		count := testutil.ToFloat64(metrics.APIRequests.WithLabelValues("compute", e.operation, e.code, "instrumented.test"))
+		if count != e.count {
+			t.Errorf("expected %v requests of %s with code %q, got %v", e.count, e.operation, e.code, count)
+		}
+	}
+}
@@ -2,17 +2,17 @@ package provider
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack"
 	"github.com/gophercloud/utils/openstack/clientconfig"
-	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
 	"net/http"
-	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha2"
+	"net/url"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -21,49 +21,126 @@ const (
 	CaSecretKey     = "cacert"
 )
 
-func NewClientFromMachine(ctrlClient client.Client, openStackMachine *infrav1.OpenStackMachine) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
-	var cloud clientconfig.Cloud
-	var caCert []byte
-
+// NewClientFromMachine returns the client for the OpenStackMachine, which uses the credentials of its
+// OpenStackCluster. The deprecated CloudsSecret of the machine must be unset or reference the clouds secret
+// and cloud of the cluster. It is only used if the cluster has no credentials of its own, i.e. uses the
+// default identity of the manager or the environment.
+func NewClientFromMachine(ctrlClient client.Client, openStackCluster *infrav1.OpenStackCluster, openStackMachine *infrav1.OpenStackMachine) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
 	if openStackMachine.Spec.CloudsSecret != nil && openStackMachine.Spec.CloudsSecret.Name != "" {
 		namespace := openStackMachine.Spec.CloudsSecret.Namespace
 		if namespace == "" {
 			namespace = openStackMachine.Namespace
 		}
-		var err error
-		cloud, caCert, err = getCloudFromSecret(ctrlClient, namespace, openStackMachine.Spec.CloudsSecret.Name, openStackMachine.Spec.CloudName)
+		machineSecret := secretRef{namespace: namespace, name: openStackMachine.Spec.CloudsSecret.Name, cloudName: openStackMachine.Spec.CloudName}
+		clusterSecret, err := getClusterSecretRef(ctrlClient, openStackCluster)
 		if err != nil {
 			return nil, nil, err
 		}
+		if clusterSecret == nil {
+			klog.Warningf("OpenStackMachine %s/%s sets the deprecated cloudsSecret, it is used as OpenStackCluster %s has no credentials of its own",
+				openStackMachine.Namespace, openStackMachine.Name, openStackCluster.Name)
+			return getClientFromSecret(ctrlClient, machineSecret.namespace, machineSecret.name, machineSecret.cloudName)
+		}
+		if *clusterSecret != machineSecret {
+			return nil, nil, fmt.Errorf("the deprecated cloudsSecret %s/%s and cloud %q of OpenStackMachine %s/%s differ from the credentials of OpenStackCluster %s, remove them from the machine",
+				machineSecret.namespace, machineSecret.name, machineSecret.cloudName, openStackMachine.Namespace, openStackMachine.Name, openStackCluster.Name)
+		}
+		klog.Warningf("OpenStackMachine %s/%s sets the deprecated cloudsSecret, it uses the credentials of OpenStackCluster %s",
+			openStackMachine.Namespace, openStackMachine.Name, openStackCluster.Name)
 	}
-	return newClient(cloud, caCert)
+	return NewClientFromCluster(ctrlClient, openStackCluster)
 }
 
+// NewClientFromCluster returns the client for the credentials of the OpenStackCluster. In order of precedence
+// these are the referenced OpenStackClusterIdentity, the CloudsSecret, the default identity of the manager
+// or the environment.
 func NewClientFromCluster(ctrlClient client.Client, openStackCluster *infrav1.OpenStackCluster) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
-	var cloud clientconfig.Cloud
-	var caCert []byte
+	ref, err := getClusterSecretRef(ctrlClient, openStackCluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ref != nil {
+		return getClientFromSecret(ctrlClient, ref.namespace, ref.name, ref.cloudName)
+	}
+	if defaultIdentity.path != "" {
+		return getClientFromDefaultIdentity()
+	}
+	return getClient("", "", nil, "", false)
+}
+
+// secretRef references a cloud in a clouds secret.
+type secretRef struct {
+	namespace string
+	name      string
+	cloudName string
+}
 
+// getClusterSecretRef returns the clouds secret and cloud of the OpenStackCluster, from its
+// OpenStackClusterIdentity or its CloudsSecret, or nil if it has no credentials configured.
+func getClusterSecretRef(ctrlClient client.Client, openStackCluster *infrav1.OpenStackCluster) (*secretRef, error) {
+	if openStackCluster.Spec.IdentityRef != nil {
+		identity, err := getIdentity(ctrlClient, openStackCluster)
+		if err != nil {
+			return nil, err
+		}
+		return &secretRef{namespace: identity.Spec.SecretRef.Namespace, name: identity.Spec.SecretRef.Name, cloudName: identity.Spec.CloudName}, nil
+	}
 	if openStackCluster.Spec.CloudsSecret != nil && openStackCluster.Spec.CloudsSecret.Name != "" {
 		namespace := openStackCluster.Spec.CloudsSecret.Namespace
 		if namespace == "" {
 			namespace = openStackCluster.Namespace
 		}
+		return &secretRef{namespace: namespace, name: openStackCluster.Spec.CloudsSecret.Name, cloudName: openStackCluster.Spec.CloudName}, nil
+	}
+	return nil, nil
+}
+
+func getClientFromSecret(ctrlClient client.Client, secretNamespace, secretName, cloudName string) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
+	secret, err := getSecret(ctrlClient, secretNamespace, secretName, cloudName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			InvalidateSecret(secretNamespace, secretName)
+		}
+		return nil, nil, err
+	}
+	version := fmt.Sprintf("%s/%s", secret.UID, secret.ResourceVersion)
+	return getClient(secretCacheKey(secretNamespace, secretName), version, secret, cloudName, false)
+}
+
+// getClient returns the cached provider client for the cloud in the given secret, and creates
+// a new one if the secret changed since it was cached or its token is about to expire.
+// Without secret the client is configured from the environment. allowFiles allows the cloud
+// to reference certificate files.
+func getClient(secretKey, version string, secret *v1.Secret, cloudName string, allowFiles bool) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
+	if provider, clientOpts := getCachedClient(secretKey, cloudName, version); provider != nil {
+		return provider, clientOpts, nil
+	}
+
+	cloud := &cloudConfig{}
+	if secret != nil {
 		var err error
-		cloud, caCert, err = getCloudFromSecret(ctrlClient, namespace, openStackCluster.Spec.CloudsSecret.Name, openStackCluster.Spec.CloudName)
+		cloud, err = getCloudFromSecret(secret, cloudName, allowFiles)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
-	return newClient(cloud, caCert)
+	provider, clientOpts, err := newClient(cloud)
+	if err != nil {
+		return nil, nil, err
+	}
+	putCachedClient(secretKey, cloudName, version, provider, clientOpts, cloud)
+	return provider, clientOpts, nil
 }
 
-func newClient(cloud clientconfig.Cloud, caCert []byte) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
+func newClient(cloud *cloudConfig) (*gophercloud.ProviderClient, *clientconfig.ClientOpts, error) {
 	clientOpts := new(clientconfig.ClientOpts)
 	if cloud.AuthInfo != nil {
 		clientOpts.AuthInfo = cloud.AuthInfo
 		clientOpts.AuthType = cloud.AuthType
-		clientOpts.Cloud = cloud.Cloud
 		clientOpts.RegionName = cloud.RegionName
+		if isApplicationCredential(cloud.Cloud) {
+			clientOpts.AuthType = clientconfig.AuthV3ApplicationCredential
+		}
 	}
 
 	opts, err := clientconfig.AuthOptions(clientOpts)
@@ -77,13 +154,18 @@ func newClient(cloud clientconfig.Cloud, caCert []byte) (*gophercloud.ProviderCl
 		return nil, nil, fmt.Errorf("create providerClient err: %v", err)
 	}
 
-	config := &tls.Config{
-		RootCAs:            x509.NewCertPool(),
-		InsecureSkipVerify: !*cloud.Verify,
+	config, err := cloud.tlsConfig()
+	if err != nil {
+		return nil, nil, err
 	}
-	config.RootCAs.AppendCertsFromPEM(caCert)
 
-	provider.HTTPClient.Transport = &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: config}
+	cloudName := cloudLabel(opts.IdentityEndpoint)
+	transport := &instrumentedTransport{
+		next:  &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: config},
+		cloud: cloudName,
+	}
+	provider.HTTPClient.Transport = newRateLimitedTransport(transport, cloudName, rateLimitIdentity(opts), cloud.RateLimit)
+	registerServiceEndpoint(provider.IdentityBase, "identity")
 	err = openstack.Authenticate(provider, *opts)
 	if err != nil {
 		return nil, nil, fmt.Errorf("providerClient authentication err: %v", err)
@@ -91,44 +173,28 @@ func newClient(cloud clientconfig.Cloud, caCert []byte) (*gophercloud.ProviderCl
 	return provider, clientOpts, nil
 }
 
-// getCloudFromSecret extract a Cloud from the given namespace:secretName
-func getCloudFromSecret(ctrlClient client.Client, secretNamespace string, secretName string, cloudName string) (clientconfig.Cloud, []byte, error) {
-	ctx := context.TODO()
-	emptyCloud := clientconfig.Cloud{}
-
-	if secretName == "" {
-		return emptyCloud, nil, nil
-	}
-
+// getSecret fetches the clouds secret namespace:secretName
+func getSecret(ctrlClient client.Client, secretNamespace string, secretName string, cloudName string) (*v1.Secret, error) {
 	if cloudName == "" {
-		return emptyCloud, nil, fmt.Errorf("secret name set to %v but no cloud was specified. Please set cloud_name in your machine spec", secretName)
+		return nil, fmt.Errorf("secret name set to %v but no cloud was specified. Please set cloud_name in your machine spec", secretName)
 	}
 
 	secret := &v1.Secret{}
-	err := ctrlClient.Get(ctx, types.NamespacedName{
+	err := ctrlClient.Get(context.TODO(), types.NamespacedName{
 		Namespace: secretNamespace,
 		Name:      secretName,
 	}, secret)
 	if err != nil {
-		return emptyCloud, nil, err
-	}
-
-	content, ok := secret.Data[CloudsSecretKey]
-	if !ok {
-		return emptyCloud, nil, fmt.Errorf("OpenStack credentials secret %v did not contain key %v",
-			secretName, CloudsSecretKey)
-	}
-	var clouds clientconfig.Clouds
-	err = yaml.Unmarshal(content, &clouds)
-	if err != nil {
-		return emptyCloud, nil, fmt.Errorf("failed to unmarshal clouds credentials stored in secret %v: %v", secretName, err)
+		return nil, err
 	}
+	return secret, nil
+}
 
-	// get caCert
-	caCert, ok := secret.Data[CaSecretKey]
-	if !ok {
-		return emptyCloud, nil, err
+// cloudLabel returns the name of a cloud used in metrics, the host of its auth URL.
+func cloudLabel(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil || u.Host == "" {
+		return authURL
 	}
-
-	return clouds.Clouds[cloudName], caCert, nil
+	return u.Host
 }
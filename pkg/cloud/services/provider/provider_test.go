@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/cloud/services/fake"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewClientFromMachine(t *testing.T) {
+	cloud := fake.NewCloud()
+	defer cloud.Close()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "credentials", Name: "cloud-config", UID: "uid", ResourceVersion: "1"},
+		Data:       map[string][]byte{CloudsSecretKey: cloud.CloudsYAML("openstack")},
+	}
+	identity := &infrav1.OpenStackClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{Name: "identity"},
+		Spec: infrav1.OpenStackClusterIdentitySpec{
+			SecretRef:         infrav1.OpenStackIdentitySecretReference{Namespace: secret.Namespace, Name: secret.Name},
+			CloudName:         "openstack",
+			AllowedNamespaces: &infrav1.AllowedNamespaces{},
+		},
+	}
+	ctrlClient := ctrlfake.NewFakeClientWithScheme(scheme, secret, identity)
+	defer InvalidateSecret(secret.Namespace, secret.Name)
+
+	withSecret := &infrav1.OpenStackCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "credentials", Name: "cluster"},
+		Spec: infrav1.OpenStackClusterSpec{
+			CloudsSecret: &v1.SecretReference{Name: secret.Name},
+			CloudName:    "openstack",
+		},
+	}
+	withIdentity := &infrav1.OpenStackCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cluster", Name: "cluster"},
+		Spec:       infrav1.OpenStackClusterSpec{IdentityRef: &infrav1.OpenStackIdentityReference{Name: identity.Name}},
+	}
+	withoutCredentials := &infrav1.OpenStackCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "credentials", Name: "cluster"},
+	}
+
+	tests := []struct {
+		name         string
+		cluster      *infrav1.OpenStackCluster
+		cloudsSecret *v1.SecretReference
+		cloudName    string
+		wantErr      bool
+	}{
+		{
+			name:    "without cloudsSecret",
+			cluster: withSecret,
+		},
+		{
+			name:         "cloudsSecret of the cluster",
+			cluster:      withSecret,
+			cloudsSecret: &v1.SecretReference{Name: secret.Name},
+			cloudName:    "openstack",
+		},
+		{
+			name:         "cloudsSecret of the identity",
+			cluster:      withIdentity,
+			cloudsSecret: &v1.SecretReference{Namespace: secret.Namespace, Name: secret.Name},
+			cloudName:    "openstack",
+		},
+		{
+			name:         "other cloudsSecret",
+			cluster:      withSecret,
+			cloudsSecret: &v1.SecretReference{Name: "other"},
+			cloudName:    "openstack",
+			wantErr:      true,
+		},
+		{
+			name:         "other namespace",
+			cluster:      withIdentity,
+			cloudsSecret: &v1.SecretReference{Name: secret.Name},
+			cloudName:    "openstack",
+			wantErr:      true,
+		},
+		{
+			name:         "other cloud",
+			cluster:      withSecret,
+			cloudsSecret: &v1.SecretReference{Name: secret.Name},
+			cloudName:    "other",
+			wantErr:      true,
+		},
+		{
+			name:         "cluster without credentials",
+			cluster:      withoutCredentials,
+			cloudsSecret: &v1.SecretReference{Name: secret.Name},
+			cloudName:    "openstack",
+		},
+		{
+			name:         "cluster without credentials and other cloud",
+			cluster:      withoutCredentials,
+			cloudsSecret: &v1.SecretReference{Name: secret.Name},
+			cloudName:    "other",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		machine := &infrav1.OpenStackMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: tt.cluster.Namespace, Name: "machine"},
+			Spec:       infrav1.OpenStackMachineSpec{CloudsSecret: tt.cloudsSecret, CloudName: tt.cloudName},
+		}
+		provider, _, err := NewClientFromMachine(ctrlClient, tt.cluster, machine)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if err == nil && provider == nil {
+			t.Errorf("%s: expected a client", tt.name)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+	"golang.org/x/time/rate"
+	"k8s.io/klog"
+	"sigs.k8s.io/cluster-api-provider-openstack/pkg/metrics"
+)
+
+const (
+	// DefaultRateLimit is the default number of requests per second sent to a cloud.
+	DefaultRateLimit = 10
+	// DefaultRateLimitBurst is the default number of requests which can be sent to a cloud at once.
+	DefaultRateLimitBurst = 20
+	// DefaultMaxRetries is the default number of times a throttled or failed request is retried.
+	DefaultMaxRetries = 5
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+
+	// limiterIdleTimeout is the time after which the limiter of an identity which sent no requests is removed.
+	limiterIdleTimeout = time.Hour
+)
+
+// rateLimitConfig configures the rate limiting and retries of the requests to a cloud.
+type rateLimitConfig struct {
+	// RateLimit is the number of requests per second.
+	RateLimit float64 `json:"rate_limit"`
+	// RateLimitBurst is the number of requests which can be sent at once.
+	RateLimitBurst int `json:"rate_limit_burst"`
+	// MaxRetries is the number of times a request is retried.
+	MaxRetries *int `json:"max_retries"`
+}
+
+func (c rateLimitConfig) withDefaults() rateLimitConfig {
+	if c.RateLimit <= 0 {
+		c.RateLimit = DefaultRateLimit
+	}
+	if c.RateLimitBurst <= 0 {
+		c.RateLimitBurst = DefaultRateLimitBurst
+	}
+	if c.MaxRetries == nil {
+		maxRetries := DefaultMaxRetries
+		c.MaxRetries = &maxRetries
+	}
+	return c
+}
+
+// limiters are the rate limiters of every identity, shared by all clients authenticating as the same
+// identity with the same settings. The limiters of identities which are no longer used, e.g. of deleted
+// clusters or replaced credentials, are removed after limiterIdleTimeout.
+var limiters = struct {
+	sync.Mutex
+	entries map[string]*limiterEntry
+}{entries: map[string]*limiterEntry{}}
+
+// limiterEntry is a shared limiter with the time it was last used.
+type limiterEntry struct {
+	// lastUsed is the time in Unix nanoseconds, accessed atomically.
+	lastUsed int64
+	limiter  *rate.Limiter
+}
+
+func (e *limiterEntry) touch(now time.Time) {
+	atomic.StoreInt64(&e.lastUsed, now.UnixNano())
+}
+
+func (e *limiterEntry) idle(now time.Time) bool {
+	return now.Sub(time.Unix(0, atomic.LoadInt64(&e.lastUsed))) > limiterIdleTimeout
+}
+
+// getLimiter returns the limiter of the identity for the configuration. Clients of the same identity
+// with different settings, e.g. while a clouds secret is updated, get separate limiters.
+func getLimiter(identity string, config rateLimitConfig) *limiterEntry {
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	now := time.Now()
+	evictIdleLimiters(now)
+	key := fmt.Sprintf("%s/%g/%d", identity, config.RateLimit, config.RateLimitBurst)
+	entry, ok := limiters.entries[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(rate.Limit(config.RateLimit), config.RateLimitBurst)}
+		limiters.entries[key] = entry
+	}
+	entry.touch(now)
+	return entry
+}
+
+// evictIdleLimiters removes the limiters which weren't used for limiterIdleTimeout. It must be called
+// with the limiters locked.
+func evictIdleLimiters(now time.Time) {
+	for key, entry := range limiters.entries {
+		if entry.idle(now) {
+			delete(limiters.entries, key)
+		}
+	}
+}
+
+// rateLimitIdentity identifies the user and project the client authenticates as, without any secret.
+func rateLimitIdentity(opts *gophercloud.AuthOptions) string {
+	user := opts.UserID
+	if user == "" {
+		user = opts.DomainID + "/" + opts.DomainName + "/" + opts.Username
+	}
+	if opts.ApplicationCredentialID != "" || opts.ApplicationCredentialName != "" {
+		user = opts.ApplicationCredentialID + "/" + opts.ApplicationCredentialName + "/" + user
+	}
+	project := opts.TenantID + "/" + opts.TenantName
+	if opts.Scope != nil {
+		project = fmt.Sprintf("%+v", *opts.Scope)
+	}
+	return fmt.Sprintf("%s|%s|%s", opts.IdentityEndpoint, user, project)
+}
+
+// rateLimitedTransport rate limits the requests of an identity with a token bucket, and retries
+// requests which were throttled or failed temporarily with exponential backoff.
+type rateLimitedTransport struct {
+	next       http.RoundTripper
+	cloud      string
+	limiter    *limiterEntry
+	maxRetries int
+}
+
+// newRateLimitedTransport returns the transport for the identity. The cloud is the name of the cloud in the metrics.
+func newRateLimitedTransport(next http.RoundTripper, cloud, identity string, config rateLimitConfig) *rateLimitedTransport {
+	config = config.withDefaults()
+	return &rateLimitedTransport{
+		next:       next,
+		cloud:      cloud,
+		limiter:    getLimiter(identity, config),
+		maxRetries: *config.MaxRetries,
+	}
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		t.limiter.touch(start)
+		if err := t.limiter.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		metrics.APIThrottleDuration.WithLabelValues(t.cloud).Observe(time.Since(start).Seconds())
+
+		var written int32
+		trace := &httptrace.ClientTrace{
+			WroteRequest: func(httptrace.WroteRequestInfo) { atomic.StoreInt32(&written, 1) },
+		}
+		resp, err := t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+		sent := atomic.LoadInt32(&written) == 1
+		if attempt >= t.maxRetries || !shouldRetry(req, resp, err, sent) || !canRewind(req) {
+			return resp, err
+		}
+
+		delay := backoff(attempt)
+		code := ""
+		if resp != nil {
+			code = strconv.Itoa(resp.StatusCode)
+			if retryAfter, ok := parseRetryAfter(resp); ok {
+				delay = retryAfter
+			}
+			// Drain the body so the connection can be reused.
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		metrics.APIRetries.WithLabelValues(t.cloud, code).Inc()
+		klog.V(4).Infof("Retrying %s %s in %s, attempt %d failed with status %q: %v", req.Method, req.URL, delay, attempt+1, code, err)
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry returns whether the request can be retried. Throttled requests, i.e. with status 429 or with
+// status 503 and a Retry-After header, weren't processed, so they are always retried. Idempotent requests
+// are also retried when they failed temporarily. Other requests may have been processed even if they
+// failed, so they are only retried if they weren't sent at all.
+func shouldRetry(req *http.Request, resp *http.Response, err error, sent bool) bool {
+	if err != nil {
+		return isIdempotent(req.Method) || !sent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != "" || isIdempotent(req.Method)
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(req.Method)
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the exponential backoff delay before retrying the given attempt.
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << uint(attempt)
+	if delay <= 0 || delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or a date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay, true
+}
+
+// canRewind returns whether the body of the request can be sent again.
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns a copy of the request with a fresh body, so it can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind the body of %s %s: %v", req.Method, req.URL, err)
+	}
+	newReq := *req
+	newReq.Body = body
+	return &newReq, nil
+}
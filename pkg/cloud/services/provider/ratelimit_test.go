@@ -0,0 +1,325 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud"
+)
+
+func TestGetLimiter(t *testing.T) {
+	config := rateLimitConfig{RateLimit: 1, RateLimitBurst: 2}
+	limiter := getLimiter("limiter-a", config)
+	if limiter.limiter.Limit() != 1 || limiter.limiter.Burst() != 2 {
+		t.Errorf("expected a limiter of 1 request per second with a burst of 2, got %v and %d", limiter.limiter.Limit(), limiter.limiter.Burst())
+	}
+
+	tests := []struct {
+		name     string
+		identity string
+		config   rateLimitConfig
+		shared   bool
+	}{
+		{name: "same identity and settings", identity: "limiter-a", config: config, shared: true},
+		{name: "other identity", identity: "limiter-b", config: config},
+		{name: "other rate", identity: "limiter-a", config: rateLimitConfig{RateLimit: 2, RateLimitBurst: 2}},
+		{name: "other burst", identity: "limiter-a", config: rateLimitConfig{RateLimit: 1, RateLimitBurst: 3}},
+	}
+	for _, tt := range tests {
+		if shared := getLimiter(tt.identity, tt.config) == limiter; shared != tt.shared {
+			t.Errorf("%s: expected the limiter to be shared %t", tt.name, tt.shared)
+		}
+	}
+	if getLimiter("limiter-a", config) != limiter {
+		t.Errorf("expected the limiter not to be replaced by a limiter with other settings")
+	}
+}
+
+func TestEvictIdleLimiters(t *testing.T) {
+	config := rateLimitConfig{RateLimit: 1, RateLimitBurst: 2}
+	idle := getLimiter("limiter-idle", config)
+	used := getLimiter("limiter-used", config)
+
+	limiters.Lock()
+	later := time.Now().Add(limiterIdleTimeout + time.Minute)
+	used.touch(later.Add(-time.Minute))
+	evictIdleLimiters(later)
+	_, idleKept := limiters.entries["limiter-idle/1/2"]
+	_, usedKept := limiters.entries["limiter-used/1/2"]
+	limiters.Unlock()
+
+	if idleKept {
+		t.Errorf("expected the idle limiter to be removed")
+	}
+	if !usedKept {
+		t.Errorf("expected the recently used limiter to be kept")
+	}
+	if getLimiter("limiter-idle", config) == idle {
+		t.Errorf("expected a new limiter after the idle limiter was removed")
+	}
+}
+
+func TestRateLimitIdentity(t *testing.T) {
+	opts := gophercloud.AuthOptions{IdentityEndpoint: "https://keystone/v3", Username: "admin", DomainName: "Default", Password: "secret", TenantName: "admin"}
+	identity := rateLimitIdentity(&opts)
+	if strings.Contains(identity, opts.Password) {
+		t.Errorf("expected the identity not to contain the password, got %s", identity)
+	}
+
+	tests := []struct {
+		name   string
+		update func(*gophercloud.AuthOptions)
+		same   bool
+	}{
+		{name: "other password", update: func(o *gophercloud.AuthOptions) { o.Password = "other" }, same: true},
+		{name: "other user", update: func(o *gophercloud.AuthOptions) { o.Username = "other" }},
+		{name: "other project", update: func(o *gophercloud.AuthOptions) { o.TenantName = "other" }},
+		{name: "other Keystone", update: func(o *gophercloud.AuthOptions) { o.IdentityEndpoint = "https://other/v3" }},
+		{name: "application credential", update: func(o *gophercloud.AuthOptions) { o.ApplicationCredentialID = "id" }},
+	}
+	for _, tt := range tests {
+		other := opts
+		tt.update(&other)
+		if same := rateLimitIdentity(&other) == identity; same != tt.same {
+			t.Errorf("%s: expected the same identity %t", tt.name, tt.same)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 0, expected: 500 * time.Millisecond},
+		{attempt: 1, expected: time.Second},
+		{attempt: 3, expected: 4 * time.Second},
+		{attempt: 6, expected: retryMaxDelay},
+		{attempt: 100, expected: retryMaxDelay},
+	}
+	for _, tt := range tests {
+		if delay := backoff(tt.attempt); delay != tt.expected {
+			t.Errorf("backoff(%d): expected %s, got %s", tt.attempt, tt.expected, delay)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "3", expected: 3 * time.Second, ok: true},
+		{value: "0", expected: 0, ok: true},
+		{value: "3600", expected: retryMaxDelay, ok: true},
+		{value: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0, ok: true},
+		{value: "soon", ok: false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		delay, ok := parseRetryAfter(resp)
+		if ok != tt.ok || delay != tt.expected {
+			t.Errorf("parseRetryAfter(%q): expected %s %t, got %s %t", tt.value, tt.expected, tt.ok, delay, ok)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)}}}
+	if delay, ok := parseRetryAfter(resp); !ok || delay <= 5*time.Second || delay > 10*time.Second {
+		t.Errorf("expected a delay of about 10s for a date, got %s %t", delay, ok)
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	errFailed := errors.New("connection refused")
+	tests := []struct {
+		method     string
+		status     int
+		retryAfter bool
+		err        error
+		sent       bool
+		expected   bool
+	}{
+		{method: http.MethodGet, status: http.StatusTooManyRequests, sent: true, expected: true},
+		{method: http.MethodGet, status: http.StatusServiceUnavailable, sent: true, expected: true},
+		{method: http.MethodGet, status: http.StatusBadGateway, sent: true, expected: true},
+		{method: http.MethodGet, status: http.StatusGatewayTimeout, sent: true, expected: true},
+		{method: http.MethodGet, status: http.StatusInternalServerError, sent: true, expected: false},
+		{method: http.MethodGet, status: http.StatusNotFound, sent: true, expected: false},
+		{method: http.MethodGet, err: errFailed, sent: true, expected: true},
+		{method: http.MethodDelete, status: http.StatusServiceUnavailable, sent: true, expected: true},
+		{method: http.MethodPut, err: errFailed, sent: true, expected: true},
+		{method: http.MethodPost, status: http.StatusTooManyRequests, sent: true, expected: true},
+		{method: http.MethodPost, status: http.StatusServiceUnavailable, retryAfter: true, sent: true, expected: true},
+		{method: http.MethodPost, status: http.StatusServiceUnavailable, sent: true, expected: false},
+		{method: http.MethodPost, status: http.StatusBadGateway, sent: true, expected: false},
+		{method: http.MethodPost, err: errFailed, sent: true, expected: false},
+		{method: http.MethodPost, err: errFailed, sent: false, expected: true},
+		{method: http.MethodPatch, err: errFailed, sent: false, expected: true},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "https://keystone", nil)
+		var resp *http.Response
+		if tt.err == nil {
+			resp = &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.retryAfter {
+				resp.Header.Set("Retry-After", "1")
+			}
+		}
+		if retry := shouldRetry(req, resp, tt.err, tt.sent); retry != tt.expected {
+			t.Errorf("%s with status %d, error %v and sent %t: expected retry %t, got %t", tt.method, tt.status, tt.err, tt.sent, tt.expected, retry)
+		}
+	}
+}
+
+// roundTripperFunc is a stub transport.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubResponse returns a response which is retried right away.
+func stubResponse(status int) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Retry-After": []string{"0"}},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}
+}
+
+func TestRateLimitedTransport(t *testing.T) {
+	errRefused := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		respond  func(attempt int, req *http.Request) (*http.Response, error)
+		attempts int
+		status   int
+		wantErr  bool
+	}{
+		{
+			name:   "GET recovers from unavailability",
+			method: http.MethodGet,
+			respond: func(attempt int, req *http.Request) (*http.Response, error) {
+				if attempt == 0 {
+					return stubResponse(http.StatusServiceUnavailable), nil
+				}
+				return stubResponse(http.StatusOK), nil
+			},
+			attempts: 2,
+			status:   http.StatusOK,
+		},
+		{
+			name:   "GET gives up after the retries",
+			method: http.MethodGet,
+			respond: func(int, *http.Request) (*http.Response, error) {
+				return stubResponse(http.StatusTooManyRequests), nil
+			},
+			attempts: 3,
+			status:   http.StatusTooManyRequests,
+		},
+		{
+			name:   "throttled POST is retried with its body",
+			method: http.MethodPost,
+			body:   "{}",
+			respond: func(attempt int, req *http.Request) (*http.Response, error) {
+				if body, _ := ioutil.ReadAll(req.Body); string(body) != "{}" {
+					t.Errorf("expected the body to be sent again, got %q", body)
+				}
+				if attempt == 0 {
+					return stubResponse(http.StatusTooManyRequests), nil
+				}
+				return stubResponse(http.StatusCreated), nil
+			},
+			attempts: 2,
+			status:   http.StatusCreated,
+		},
+		{
+			name:   "failed POST isn't retried",
+			method: http.MethodPost,
+			body:   "{}",
+			respond: func(int, *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusBadGateway, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			},
+			attempts: 1,
+			status:   http.StatusBadGateway,
+		},
+		{
+			name:   "sent POST isn't retried",
+			method: http.MethodPost,
+			body:   "{}",
+			respond: func(_ int, req *http.Request) (*http.Response, error) {
+				if trace := httptrace.ContextClientTrace(req.Context()); trace != nil && trace.WroteRequest != nil {
+					trace.WroteRequest(httptrace.WroteRequestInfo{})
+				}
+				return nil, errRefused
+			},
+			attempts: 1,
+			wantErr:  true,
+		},
+		{
+			name:   "unsent POST is retried with its body",
+			method: http.MethodPost,
+			body:   "{}",
+			respond: func(attempt int, req *http.Request) (*http.Response, error) {
+				if body, _ := ioutil.ReadAll(req.Body); string(body) != "{}" {
+					t.Errorf("expected the body to be sent again, got %q", body)
+				}
+				if attempt == 0 {
+					return nil, errRefused
+				}
+				return stubResponse(http.StatusCreated), nil
+			},
+			attempts: 2,
+			status:   http.StatusCreated,
+		},
+	}
+	maxRetries := 2
+	for _, tt := range tests {
+		attempts := 0
+		next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			defer func() { attempts++ }()
+			return tt.respond(attempts, req)
+		})
+		transport := newRateLimitedTransport(next, "test", "transport "+tt.name, rateLimitConfig{RateLimit: 1000, MaxRetries: &maxRetries})
+		req, _ := http.NewRequest(tt.method, "https://keystone", strings.NewReader(tt.body))
+		resp, err := transport.RoundTrip(req)
+		if attempts != tt.attempts {
+			t.Errorf("%s: expected %d attempts, got %d", tt.name, tt.attempts, attempts)
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.wantErr, err)
+			continue
+		}
+		if err == nil && resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+	}
+}
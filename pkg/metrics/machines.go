@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var machinesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "machines"),
+	"Number of OpenStackMachines by instance state. The state is empty for machines without instance.",
+	[]string{"instance_state"}, nil,
+)
+
+// machineCollector counts the OpenStackMachines by the state of their instance when the metrics are scraped.
+type machineCollector struct {
+	client client.Client
+}
+
+// RegisterMachineCollector registers the gauge of the OpenStackMachines by instance state.
+// The client should read from the cache of the manager.
+func RegisterMachineCollector(c client.Client) error {
+	return metrics.Registry.Register(&machineCollector{client: c})
+}
+
+func (c *machineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- machinesDesc
+}
+
+func (c *machineCollector) Collect(ch chan<- prometheus.Metric) {
+	machineList := &infrav1.OpenStackMachineList{}
+	if err := c.client.List(context.Background(), machineList); err != nil {
+		klog.Errorf("Failed to list OpenStackMachines for metrics: %v", err)
+		return
+	}
+	counts := map[infrav1.InstanceState]int{}
+	for _, machine := range machineList.Items {
+		var state infrav1.InstanceState
+		if machine.Status.InstanceState != nil {
+			state = *machine.Status.InstanceState
+		}
+		counts[state]++
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(machinesDesc, prometheus.GaugeValue, float64(count), string(state))
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-openstack/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMachineCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	states := []*infrav1.InstanceState{
+		instanceState(infrav1.InstanceStateActive),
+		instanceState(infrav1.InstanceStateActive),
+		instanceState(infrav1.InstanceStateError),
+		nil,
+	}
+	var machines []runtime.Object
+	for i, state := range states {
+		machines = append(machines, &infrav1.OpenStackMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("machine-%d", i)},
+			Status:     infrav1.OpenStackMachineStatus{InstanceState: state},
+		})
+	}
+	collector := &machineCollector{client: fake.NewFakeClientWithScheme(scheme, machines...)}
+
+	expected := fmt.Sprintf(`
+# HELP capo_machines Number of OpenStackMachines by instance state. The state is empty for machines without instance.
+# TYPE capo_machines gauge
+capo_machines{instance_state=""} 1
+capo_machines{instance_state=%q} 2
+capo_machines{instance_state=%q} 1
+`, infrav1.InstanceStateActive, infrav1.InstanceStateError)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "capo_machines"); err != nil {
+		t.Error(err)
+	}
+}
+
+func instanceState(state infrav1.InstanceState) *infrav1.InstanceState {
+	return &state
+}
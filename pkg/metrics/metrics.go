@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics contains the Prometheus metrics of the OpenStack provider.
+// They are registered in the controller-runtime metrics registry, so they are
+// served on the metrics endpoint of the manager.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "capo"
+
+var (
+	// APIThrottleDuration is the time OpenStack API requests waited for the rate limiter of their cloud.
+	APIThrottleDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "openstack_api_throttle_duration_seconds",
+		Help:      "Time OpenStack API requests waited for the rate limiter of their cloud.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	}, []string{"cloud"})
+
+	// APIRetries counts the OpenStack API requests retried, by the status code of the failed attempt.
+	// The code is empty if the request failed without response.
+	APIRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "openstack_api_retries_total",
+		Help:      "Number of retried OpenStack API requests by status code of the failed attempt.",
+	}, []string{"cloud", "code"})
+
+	// APIRequestDuration is the duration of the OpenStack API requests.
+	APIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "openstack_api_request_duration_seconds",
+		Help:      "Duration of OpenStack API requests by service, operation, status code and cloud.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"service", "operation", "code", "cloud"})
+
+	// APIRequests counts the OpenStack API requests. The code is empty if the request failed without response.
+	APIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "openstack_api_requests_total",
+		Help:      "Number of OpenStack API requests by service, operation, status code and cloud.",
+	}, []string{"service", "operation", "code", "cloud"})
+
+	// ReconcilePhaseDuration is the duration of the phases of the reconciles.
+	ReconcilePhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_phase_duration_seconds",
+		Help:      "Duration of the phases of the reconciles by controller, phase and result.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"controller", "phase", "result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		APIThrottleDuration,
+		APIRetries,
+		APIRequestDuration,
+		APIRequests,
+		ReconcilePhaseDuration,
+	)
+}
+
+// ObservePhase runs a phase of a reconcile and records its duration.
+func ObservePhase(controller, phase string, f func() error) error {
+	start := time.Now()
+	err := f()
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	ReconcilePhaseDuration.WithLabelValues(controller, phase, result).Observe(time.Since(start).Seconds())
+	return err
+}
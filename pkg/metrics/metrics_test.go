@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// sampleCount returns the number of observations of the histogram with the given labels
+// in the controller-runtime registry.
+func sampleCount(t *testing.T, name string, labels map[string]string) uint64 {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func TestObservePhase(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		phase  string
+		err    error
+		result string
+	}{
+		{phase: "network", result: "success"},
+		{phase: "network", result: "success"},
+		{phase: "router", err: errFailed, result: "error"},
+	}
+	for _, tt := range tests {
+		if err := ObservePhase("test", tt.phase, func() error { return tt.err }); err != tt.err {
+			t.Errorf("%s: expected the error of the phase %v, got %v", tt.phase, tt.err, err)
+		}
+	}
+
+	expected := []struct {
+		phase  string
+		result string
+		count  uint64
+	}{
+		{phase: "network", result: "success", count: 2},
+		{phase: "network", result: "error", count: 0},
+		{phase: "router", result: "error", count: 1},
+	}
+	for _, e := range expected {
+		labels := map[string]string{"controller": "test", "phase": e.phase, "result": e.result}
+		if count := sampleCount(t, "capo_reconcile_phase_duration_seconds", labels); count != e.count {
+			t.Errorf("expected %d observations of %s with result %s, got %d", e.count, e.phase, e.result, count)
+		}
+	}
+}